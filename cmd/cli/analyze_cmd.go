@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"personal-ai-board/internal/analysis"
+	"personal-ai-board/internal/config"
+)
+
+// runAnalyze runs an analysis with a board and prints its result. Progress is
+// reported on stderr; Ctrl+C cancels the analysis and saves what was produced.
+func runAnalyze(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	boardID := flags.String("board", "", "ID of the board to consult")
+	projectID := flags.String("project", "", "ID of the project the analysis belongs to")
+	mode := flags.String("mode", cfg.Analysis.DefaultMode, "analysis mode: "+strings.Join(analysis.Modes, ", "))
+	topic := flags.String("topic", "", "topic or question for the board")
+	rounds := flags.Int("rounds", 1, "number of discussion rounds")
+	timeout := flags.Duration("timeout", 0, "cancel the analysis after this long (0 for no limit)")
+	providerName := flags.String("provider", "", "LLM provider to use (default from config)")
+	model := flags.String("model", "", "model to use (default from provider config)")
+	quiet := flags.Bool("quiet", false, "do not report progress on stderr")
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+
+	if *topic == "" {
+		*topic = strings.Join(positional, " ")
+	}
+	if *boardID == "" || *projectID == "" || strings.TrimSpace(*topic) == "" {
+		return usageError("personal-ai-board analyze --board BOARD_ID --project PROJECT_ID [--mode MODE] --topic \"question\"")
+	}
+	if !analysis.ValidMode(*mode) {
+		return argumentError(fmt.Errorf("invalid mode %q (must be one of: %s)", *mode, strings.Join(analysis.Modes, ", ")))
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	provider, err := app.personaProvider(*providerName, *model)
+	if err != nil {
+		return reportError(err)
+	}
+	engine := analysis.NewEngine(app.DB.DB, provider, cfg.Analysis.MaxConcurrent, app.Logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	progressDone := make(chan struct{})
+	if *quiet {
+		close(progressDone)
+	} else {
+		events, unsubscribe := engine.Subscribe(analysisEventBuffer)
+		defer unsubscribe()
+		go func() {
+			reportProgress(events)
+			close(progressDone)
+		}()
+	}
+
+	watcher := app.watchWebhooks(engine)
+	result, err := engine.Run(ctx, &analysis.Request{
+		ID:        generateID("request"),
+		ProjectID: *projectID,
+		BoardID:   *boardID,
+		Mode:      *mode,
+		Topic:     strings.TrimSpace(*topic),
+		Rounds:    *rounds,
+		CreatedAt: time.Now(),
+	})
+	watcher.Stop()
+	app.deliverWebhooks()
+	if result == nil {
+		return reportError(err)
+	}
+	<-progressDone
+
+	if code := printOutput(*format, result, func(tw *tabwriter.Writer) {
+		fmt.Fprint(tw, analysisReport(result))
+	}); code != exitOK {
+		return code
+	}
+
+	switch result.Status {
+	case analysis.StatusCompleted:
+		return exitOK
+	case analysis.StatusCancelled:
+		fmt.Fprintln(os.Stderr, "Analysis cancelled")
+		return exitCancelled
+	default:
+		return reportError(err)
+	}
+}
+
+// reportProgress prints analysis progress events to stderr
+func reportProgress(events <-chan analysis.Event) {
+	for event := range events {
+		switch event.Type {
+		case analysis.EventRoundStarted:
+			fmt.Fprintf(os.Stderr, "Round %d/%d\n", event.Round, event.Rounds)
+		case analysis.EventPersonaCompleted:
+			fmt.Fprintf(os.Stderr, "  ✓ %s (%.0f%%, %s)\n", event.PersonaName, event.Response.Confidence*100, event.Response.EmotionalTone)
+		case analysis.EventPersonaFailed:
+			fmt.Fprintf(os.Stderr, "  ✗ %s: %s\n", event.PersonaName, event.Error)
+		}
+		if event.Final() {
+			fmt.Fprintf(os.Stderr, "%s: %d tokens, $%.4f\n", event.Type, event.Metrics.TotalTokens, event.Metrics.CostUSD)
+			return
+		}
+	}
+}
+
+// analysisReport formats a result with every response in full
+func analysisReport(result *analysis.Result) string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "%s\n\n", result.Topic)
+	fmt.Fprintf(&s, "Result:   %s\n", result.ID)
+	fmt.Fprintf(&s, "Mode:     %s\n", result.Mode)
+	fmt.Fprintf(&s, "Status:   %s\n", result.Status)
+	fmt.Fprintf(&s, "Duration: %s\n", result.Duration.Round(time.Millisecond))
+	fmt.Fprintf(&s, "Tokens:   %d ($%.4f)\n", result.Metrics.TotalTokens, result.Metrics.CostUSD)
+	if result.Error != "" {
+		fmt.Fprintf(&s, "Error:    %s\n", result.Error)
+	}
+	if result.Summary != "" {
+		fmt.Fprintf(&s, "\nSummary\n  %s\n", result.Summary)
+	}
+	if len(result.Insights) > 0 {
+		s.WriteString("\nInsights\n")
+		for _, insight := range result.Insights {
+			fmt.Fprintf(&s, "  • %s\n", insight)
+		}
+	}
+	for _, resp := range result.Responses {
+		fmt.Fprintf(&s, "\n[round %d] %s", resp.Round, resp.PersonaName)
+		if resp.Error != "" {
+			fmt.Fprintf(&s, " failed: %s\n", resp.Error)
+			continue
+		}
+		fmt.Fprintf(&s, " (%.0f%%, %s)\n%s\n", resp.Confidence*100, resp.EmotionalTone, strings.TrimSpace(resp.Content))
+	}
+	return s.String()
+}
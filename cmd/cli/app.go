@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"personal-ai-board/internal/analysis"
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/db"
+	"personal-ai-board/internal/llm"
+	"personal-ai-board/internal/persona"
+	"personal-ai-board/internal/search"
+	"personal-ai-board/internal/webhook"
+	"personal-ai-board/pkg/logger"
+)
+
+// App bundles the services shared by the TUI and the subcommands
+type App struct {
+	Config *config.Config
+	DB     *db.Database
+	LLM    *llm.Manager
+	Traits *persona.TraitReloader
+	Logger logger.Logger
+
+	logFile   *os.File
+	retention *db.RetentionJob
+	snapshots *db.SnapshotJob
+}
+
+// openApp connects to the configured database and applies pending migrations.
+// Logs go to stderr so that command output on stdout stays machine readable.
+func openApp(cfg *config.Config) (*App, error) {
+	return openAppWithLogger(cfg, logger.NewWithWriter(cfg.Log.Level, cfg.Log.Format, os.Stderr))
+}
+
+// openTUIApp opens the application with logs written to the configured log
+// file, since the interactive UI owns the terminal
+func openTUIApp(cfg *config.Config) (*App, error) {
+	var out io.Writer = io.Discard
+	var logFile *os.File
+	if cfg.Log.File != "" {
+		file, err := os.OpenFile(cfg.Log.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		out, logFile = file, file
+	}
+
+	app, err := openAppWithLogger(cfg, logger.NewWithWriter(cfg.Log.Level, cfg.Log.Format, out))
+	if err != nil {
+		if logFile != nil {
+			logFile.Close()
+		}
+		return nil, err
+	}
+	app.logFile = logFile
+
+	return app, nil
+}
+
+// openAppWithLogger connects to the database, migrates it, prepares the search
+// index and registers the configured LLM providers
+func openAppWithLogger(cfg *config.Config, log logger.Logger) (*App, error) {
+	database, err := connectDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := database.Migrate(); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+	fullText, err := search.EnsureIndex(database.DB)
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
+	if !fullText {
+		// Reported by the search command and view, not by every command
+		log.Debug(search.FallbackNotice)
+	}
+
+	// Settings stored in the database take precedence over the file
+	values, err := database.GetAllSystemConfig()
+	if err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to load database settings: %w", err)
+	}
+	for _, err := range cfg.ApplySystemConfig(values) {
+		log.Warn("Ignoring invalid database setting", "error", err)
+	}
+
+	// API keys are resolved as the providers are created; keep them out of the logs
+	cfg.SecretResolver().Observe(logger.Redact)
+	manager, errs := llm.NewManagerFromConfig(cfg, log)
+	for _, err := range errs {
+		log.Warn("LLM provider not available", "error", err)
+	}
+	app := &App{
+		Config: cfg,
+		DB:     database,
+		LLM:    manager,
+		Traits: persona.NewTraitReloader(defaultTraitsDir, log),
+		Logger: log,
+	}
+	app.enableReplay(cfg)
+
+	return app, nil
+}
+
+// enableReplay has the mock provider replay recorded interactions, if cfg
+// asks for it
+func (a *App) enableReplay(cfg *config.Config) {
+	if !cfg.LLM.Mock.Enabled || !cfg.LLM.Mock.Replay {
+		return
+	}
+	if count, err := llm.EnableReplay(a.LLM, a.DB.DB); err != nil {
+		a.Logger.Warn("Mock provider cannot replay recorded interactions", "error", err)
+	} else {
+		a.Logger.Debug("Mock provider replaying recorded interactions", "count", count)
+	}
+}
+
+// connectDatabase opens the configured database without migrating it
+func connectDatabase(cfg *config.Config) (*db.Database, error) {
+	dbConfig := db.DefaultConfig()
+	dbConfig.Path = cfg.Database.Path
+	dbConfig.MaxOpenConns = cfg.Database.MaxOpenConns
+	dbConfig.MaxIdleConns = cfg.Database.MaxIdleConns
+	dbConfig.ConnMaxLifetime = cfg.GetConnMaxLifetime()
+	dbConfig.ConnMaxIdleTime = cfg.GetConnMaxIdleTime()
+	dbConfig.EnableWAL = cfg.Database.EnableWAL
+	dbConfig.EnableForeignKeys = cfg.Database.EnableForeignKeys
+
+	database, err := db.Connect(dbConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return database, nil
+}
+
+// personaProvider returns an LLM provider for personas. Empty values select the
+// configured default provider and that provider's model.
+func (a *App) personaProvider(providerName, model string) (*llm.PersonaProvider, error) {
+	if len(a.LLM.ListProviders()) == 0 {
+		return nil, fmt.Errorf("no LLM provider configured; set an API key such as OPENAI_API_KEY, or PAB_LLM_MOCK_ENABLED=true to work offline")
+	}
+	if _, err := a.LLM.GetProvider(providerName); err != nil {
+		return nil, err
+	}
+	return llm.NewPersonaProvider(a.LLM, providerName, model), nil
+}
+
+// analysisEngine creates an analysis engine that uses the default LLM provider
+func (a *App) analysisEngine() (*analysis.Engine, error) {
+	provider, err := a.personaProvider("", "")
+	if err != nil {
+		return nil, err
+	}
+	engine := analysis.NewEngine(a.DB.DB, provider, a.Config.Analysis.MaxConcurrent, a.Logger)
+	engine.TrackPersonas(a.Traits)
+	return engine, nil
+}
+
+// startRetention runs the interaction log retention job until ctx is cancelled
+func (a *App) startRetention(ctx context.Context) {
+	a.retention = db.NewRetentionJob(a.DB, a.Config.Memory.RetentionDays, a.Config.GetCleanupInterval(), a.Logger)
+	a.retention.Start(ctx)
+}
+
+// snapshotPolicy returns the snapshot schedule and retention in cfg
+func snapshotPolicy(cfg *config.Config) db.SnapshotPolicy {
+	return db.SnapshotPolicy{
+		Dir:        cfg.BackupDir(),
+		Interval:   cfg.GetBackupInterval(),
+		KeepDaily:  cfg.Backup.KeepDaily,
+		KeepWeekly: cfg.Backup.KeepWeekly,
+		Compress:   cfg.Backup.Compress,
+	}
+}
+
+// startSnapshots takes scheduled database snapshots until ctx is cancelled,
+// if backups are enabled
+func (a *App) startSnapshots(ctx context.Context) {
+	if !a.Config.Backup.Enabled {
+		return
+	}
+	a.snapshots = db.NewSnapshotJob(a.DB, snapshotPolicy(a.Config), a.Logger)
+	a.snapshots.Start(ctx)
+}
+
+// webhookPolicy returns how webhook deliveries are attempted in cfg
+func webhookPolicy(cfg *config.Config) webhook.Policy {
+	return webhook.Policy{
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		Backoff:     cfg.GetWebhookBackoff(),
+		Timeout:     cfg.GetWebhookTimeout(),
+	}
+}
+
+// webhookDispatcher creates a dispatcher for the queued webhook deliveries
+func (a *App) webhookDispatcher() *webhook.Dispatcher {
+	return webhook.NewDispatcher(webhook.NewStorage(a.DB.DB), webhookPolicy(a.Config), a.Logger)
+}
+
+// startWebhooks delivers queued webhook events, retrying failures, until ctx
+// is cancelled
+func (a *App) startWebhooks(ctx context.Context) {
+	a.webhookDispatcher().Start(ctx)
+}
+
+// watchWebhooks queues webhook events for the analyses an engine runs
+func (a *App) watchWebhooks(engine *analysis.Engine) *webhook.Watcher {
+	return webhook.Watch(engine, a.DB.DB, a.Config.Webhooks.BudgetUSD, a.Logger)
+}
+
+// notifyWebhooks queues an event for the webhooks that subscribe to it
+func (a *App) notifyWebhooks(eventType string, data interface{}) {
+	if _, err := webhook.NewStorage(a.DB.DB).Notify(eventType, data); err != nil {
+		a.Logger.Error("Failed to queue webhook event", "event", eventType, "error", err)
+	}
+}
+
+// deliverWebhooks makes one attempt at the webhook deliveries that are due, so
+// that a command's events go out before it exits. Deliveries that fail, or
+// that the time limit interrupts, stay queued for the interactive UI, the API
+// server or "webhook deliver" to retry.
+func (a *App) deliverWebhooks() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*a.Config.GetWebhookTimeout())
+	defer cancel()
+	if _, err := a.webhookDispatcher().RunOnce(ctx); err != nil {
+		a.Logger.Error("Webhook delivery failed", "error", err)
+	}
+}
+
+// Close releases the application's resources
+func (a *App) Close() error {
+	err := a.DB.Close()
+	if a.logFile != nil {
+		a.logFile.Close()
+	}
+	return err
+}
+
+// generateID creates a unique identifier with the given prefix
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d", prefix, time.Now().UnixNano())
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/db"
+)
+
+// runDBBackup writes a verified copy of the database to a file, or a
+// timestamped snapshot to the backup directory when no file is given
+func runDBBackup(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("db backup", flag.ContinueOnError)
+	force := flags.Bool("force", false, "overwrite FILE if it exists")
+	compress := flags.Bool("compress", cfg.Backup.Compress, "compress the backup with gzip (a FILE is compressed if it ends in .gz)")
+	prune := flags.Bool("prune", false, "delete snapshots outside the retention policy afterwards")
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) > 1 {
+		return usageError("personal-ai-board db backup [FILE] [--force] [--compress] [--prune] [--output table|json|yaml]")
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	// A file named on the command line is compressed when its name says so, so
+	// that it can be restored as named; --compress adds the .gz suffix
+	var dest string
+	if len(positional) == 1 {
+		dest = positional[0]
+		compressRequested := false
+		flags.Visit(func(f *flag.Flag) {
+			compressRequested = compressRequested || f.Name == "compress"
+		})
+		if compressRequested && *compress && !strings.HasSuffix(dest, ".gz") {
+			dest += ".gz"
+		}
+		if _, err := os.Stat(dest); err == nil && !*force {
+			fmt.Fprintf(os.Stderr, "Error: %s already exists (use --force to overwrite)\n", dest)
+			return exitValidation
+		}
+	}
+
+	database, err := connectDatabase(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer database.Close()
+
+	var snapshot *db.Snapshot
+	if len(positional) == 1 {
+		snapshot, err = database.BackupFile(dest, strings.HasSuffix(dest, ".gz"))
+	} else {
+		snapshot, err = database.Snapshot(cfg.BackupDir(), *compress)
+	}
+	if err != nil {
+		return reportError(err)
+	}
+
+	pruned := make([]db.Snapshot, 0)
+	if *prune {
+		if pruned, err = database.PruneSnapshots(cfg.BackupDir(), cfg.Backup.KeepDaily, cfg.Backup.KeepWeekly); err != nil {
+			return reportError(err)
+		}
+	}
+
+	result := struct {
+		*db.Snapshot
+		Pruned []db.Snapshot `json:"pruned"`
+	}{snapshot, pruned}
+	return printOutput(*format, result, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "✓ Backed up %s to %s (%s, schema version %d, integrity ok)\n",
+			cfg.Database.Path, snapshot.Path, formatBytes(snapshot.Size), snapshot.SchemaVersion)
+		for _, old := range pruned {
+			fmt.Fprintf(tw, "  Pruned %s\n", old.Path)
+		}
+	})
+}
+
+// runDBSnapshots lists the snapshots in the backup directory
+func runDBSnapshots(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("db snapshots", flag.ContinueOnError)
+	format := addOutputFlag(flags)
+	if _, err := parseCommandFlags(flags, args); err != nil {
+		return exitUsage
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	snapshots, err := db.ListSnapshots(cfg.BackupDir(), cfg.Database.Path)
+	if err != nil {
+		return reportError(err)
+	}
+
+	return printOutput(*format, snapshots, func(tw *tabwriter.Writer) {
+		if len(snapshots) == 0 {
+			fmt.Fprintf(tw, "No snapshots in %s\n", cfg.BackupDir())
+			return
+		}
+		tableRow(tw, "CREATED", "SIZE", "PATH")
+		for _, snapshot := range snapshots {
+			tableRow(tw, snapshot.CreatedAt.Local().Format("2006-01-02 15:04:05"), formatBytes(snapshot.Size), snapshot.Path)
+		}
+	})
+}
+
+// runDBPrune deletes snapshots outside the retention policy
+func runDBPrune(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("db prune", flag.ContinueOnError)
+	keepDaily := flags.Int("keep-daily", cfg.Backup.KeepDaily, "days to keep the newest snapshot of")
+	keepWeekly := flags.Int("keep-weekly", cfg.Backup.KeepWeekly, "weeks to keep the newest snapshot of")
+	dryRun := flags.Bool("dry-run", false, "list the snapshots that would be deleted without deleting them")
+	if _, err := parseCommandFlags(flags, args); err != nil {
+		return exitUsage
+	}
+	if *keepDaily < 0 || *keepWeekly < 0 {
+		return argumentError(fmt.Errorf("--keep-daily and --keep-weekly cannot be negative"))
+	}
+
+	snapshots, err := db.ListSnapshots(cfg.BackupDir(), cfg.Database.Path)
+	if err != nil {
+		return reportError(err)
+	}
+	expired := db.ExpiredSnapshots(snapshots, *keepDaily, *keepWeekly)
+
+	verb := "Deleted"
+	if *dryRun {
+		verb = "Would delete"
+	} else {
+		for _, snapshot := range expired {
+			if err := os.Remove(snapshot.Path); err != nil {
+				return reportError(fmt.Errorf("failed to remove snapshot: %w", err))
+			}
+		}
+	}
+
+	for _, snapshot := range expired {
+		fmt.Printf("%s %s\n", verb, snapshot.Path)
+	}
+	fmt.Printf("✓ Kept %d of %d snapshots\n", len(snapshots)-len(expired), len(snapshots))
+	return exitOK
+}
+
+// runDBVerify checks the integrity of a backup and reports its schema version
+func runDBVerify(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("db verify", flag.ContinueOnError)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board db verify FILE|latest")
+	}
+
+	path, err := resolveSnapshot(cfg, positional[0])
+	if err != nil {
+		return reportError(err)
+	}
+
+	version, err := db.VerifyFile(path)
+	if err != nil {
+		return backupError(fmt.Errorf("%s: %w", path, err))
+	}
+
+	fmt.Printf("✓ %s passed the integrity check (schema version %d)\n", path, version)
+	return exitOK
+}
+
+// runDBRestore replaces the database with a backup
+func runDBRestore(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("db restore", flag.ContinueOnError)
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board db restore FILE|latest [--output table|json|yaml]")
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	path, err := resolveSnapshot(cfg, positional[0])
+	if err != nil {
+		return reportError(err)
+	}
+
+	result, err := db.RestoreSnapshot(path, cfg.Database.Path)
+	if err != nil {
+		return backupError(err)
+	}
+
+	return printOutput(*format, result, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "✓ Restored %s from %s (schema version %d)\n", result.Database, result.Snapshot, result.SchemaVersion)
+		if result.SafetyCopy != "" {
+			fmt.Fprintf(tw, "  The previous database (schema version %d) was saved to %s\n", result.PreviousVersion, result.SafetyCopy)
+		}
+		if result.SchemaVersion < result.LatestVersion {
+			fmt.Fprintf(tw, "  Run 'db migrate' to bring the schema up to version %d\n", result.LatestVersion)
+		}
+	})
+}
+
+// backupError reports a backup that is missing or failed its checks
+func backupError(err error) int {
+	if errors.Is(err, os.ErrNotExist) {
+		return reportError(err)
+	}
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	return exitValidation
+}
+
+// resolveSnapshot returns the path of a backup, where "latest" names the
+// newest snapshot in the backup directory
+func resolveSnapshot(cfg *config.Config, name string) (string, error) {
+	if name != "latest" {
+		return name, nil
+	}
+
+	snapshots, err := db.ListSnapshots(cfg.BackupDir(), cfg.Database.Path)
+	if err != nil {
+		return "", err
+	}
+	if len(snapshots) == 0 {
+		return "", fmt.Errorf("no snapshots in %s: %w", cfg.BackupDir(), os.ErrNotExist)
+	}
+	return snapshots[0].Path, nil
+}
+
+// formatBytes formats a size in bytes for people
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
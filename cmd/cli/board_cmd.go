@@ -0,0 +1,224 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"personal-ai-board/internal/board"
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/persona"
+)
+
+// runBoardList prints every board
+func runBoardList(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("board list", flag.ContinueOnError)
+	format := addOutputFlag(flags)
+	if _, err := parseCommandFlags(flags, args); err != nil {
+		return exitUsage
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	boards, err := board.NewStorage(app.DB.DB).ListBoards()
+	if err != nil {
+		return reportError(err)
+	}
+
+	return printOutput(*format, boards, func(tw *tabwriter.Writer) {
+		tableRow(tw, "ID", "NAME", "PERSONAS", "TEMPLATE", "UPDATED")
+		for _, b := range boards {
+			tableRow(tw, b.ID, b.Name, b.PersonaCount, b.IsTemplate, b.UpdatedAt.Format("2006-01-02 15:04"))
+		}
+	})
+}
+
+// runBoardShow prints a board and its members
+func runBoardShow(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("board show", flag.ContinueOnError)
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board board show BOARD_ID")
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	b, err := board.NewStorage(app.DB.DB).LoadBoard(positional[0])
+	if err != nil {
+		return reportError(err)
+	}
+
+	return printOutput(*format, b, func(tw *tabwriter.Writer) {
+		fmt.Fprint(tw, boardDetail(b))
+	})
+}
+
+// runBoardCreate creates a board, optionally seating personas on it
+func runBoardCreate(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("board create", flag.ContinueOnError)
+	description := flags.String("description", "", "board description")
+	personas := flags.String("personas", "", "comma separated persona IDs to seat on the board")
+	role := flags.String("role", "advisor", "role given to the seated personas")
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) == 0 {
+		return usageError("personal-ai-board board create NAME [--description TEXT] [--personas ID,ID]")
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	b, err := board.New(generateID("board"), strings.Join(positional, " "), *description)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitValidation
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	personaStorage := persona.NewStorage(app.DB.DB)
+	for _, id := range splitList(*personas) {
+		if _, err := personaStorage.LoadPersona(id, nil, app.Logger); err != nil {
+			return reportError(err)
+		}
+		if err := b.AddMember(id, *role); err != nil {
+			return reportError(err)
+		}
+	}
+
+	storage := board.NewStorage(app.DB.DB)
+	if err := storage.SaveBoard(b); err != nil {
+		return reportError(err)
+	}
+	if b, err = storage.LoadBoard(b.ID); err != nil {
+		return reportError(err)
+	}
+
+	return printOutput(*format, b, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "✓ Created board %q (%s) with %d persona(s)\n", b.Name, b.ID, len(b.Members))
+	})
+}
+
+// runBoardAddMember seats a persona on a board
+func runBoardAddMember(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("board add-member", flag.ContinueOnError)
+	role := flags.String("role", "advisor", "role of the persona on the board")
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 2 {
+		return usageError("personal-ai-board board add-member BOARD_ID PERSONA_ID [--role ROLE]")
+	}
+
+	return updateBoard(cfg, positional[0], func(app *App, b *board.Board) error {
+		p, err := persona.NewStorage(app.DB.DB).LoadPersona(positional[1], nil, app.Logger)
+		if err != nil {
+			return err
+		}
+		if err := b.AddMember(p.ID, *role); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Added %q to board %q\n", p.Name, b.Name)
+		return nil
+	})
+}
+
+// runBoardRemoveMember removes a persona from a board
+func runBoardRemoveMember(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("board remove-member", flag.ContinueOnError)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 2 {
+		return usageError("personal-ai-board board remove-member BOARD_ID PERSONA_ID")
+	}
+
+	return updateBoard(cfg, positional[0], func(app *App, b *board.Board) error {
+		if err := b.RemoveMember(positional[1]); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Removed %s from board %q\n", positional[1], b.Name)
+		return nil
+	})
+}
+
+// updateBoard loads a board, applies change to it and saves it
+func updateBoard(cfg *config.Config, id string, change func(app *App, b *board.Board) error) int {
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	storage := board.NewStorage(app.DB.DB)
+	b, err := storage.LoadBoard(id)
+	if err != nil {
+		return reportError(err)
+	}
+	if err := change(app, b); err != nil {
+		return reportError(err)
+	}
+	if err := storage.SaveBoard(b); err != nil {
+		return reportError(err)
+	}
+	return exitOK
+}
+
+// runBoardDelete removes a board
+func runBoardDelete(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("board delete", flag.ContinueOnError)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board board delete BOARD_ID")
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	storage := board.NewStorage(app.DB.DB)
+	b, err := storage.LoadBoard(positional[0])
+	if err != nil {
+		return reportError(err)
+	}
+	if err := storage.DeleteBoard(b.ID); err != nil {
+		return reportError(err)
+	}
+
+	fmt.Printf("✓ Deleted board %q (%s)\n", b.Name, b.ID)
+	return exitOK
+}
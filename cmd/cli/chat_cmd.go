@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"personal-ai-board/internal/chat"
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/persona"
+	"personal-ai-board/internal/project"
+)
+
+// chatCommandsHelp lists the commands available while chatting
+const chatCommandsHelp = `Commands:
+  /save idea [PROJECT_ID]      Save the transcript as an idea of a project
+  /save document [PROJECT_ID]  Save the transcript as a project document
+  /transcript                  Print the conversation so far
+  /help                        Show this help
+  /quit                        Leave the chat`
+
+// runChat holds a one-on-one conversation with a persona on stdin and stdout
+func runChat(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("chat", flag.ContinueOnError)
+	projectID := flags.String("project", "", "project the conversation is about, and where transcripts are saved")
+	providerName := flags.String("provider", "", "LLM provider to use (default from config)")
+	model := flags.String("model", "", "model to use (default from provider config)")
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board chat PERSONA_ID [--project PROJECT_ID] [--provider NAME] [--model NAME]")
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	session, err := app.chatSession(positional[0], *projectID, *providerName, *model)
+	if err != nil {
+		return reportError(err)
+	}
+
+	fmt.Printf("Chatting with %s. Type /help for commands, /quit to leave.\n", session.Persona.Name)
+	input := newPrompter(os.Stdin, os.Stdout)
+	for {
+		line, err := input.ask("\nyou")
+		if err == io.EOF {
+			fmt.Println()
+			return exitOK
+		}
+		if err != nil {
+			return reportError(err)
+		}
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "/") {
+			if quit := runChatCommand(app, session, *projectID, line); quit {
+				return exitOK
+			}
+			continue
+		}
+
+		sendChatMessage(session, line)
+	}
+}
+
+// sendChatMessage sends a message and prints the reply as it is written.
+// Ctrl+C cancels the reply without ending the chat.
+func sendChatMessage(session *chat.Session, message string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("\n%s: ", session.Persona.Name)
+	result, err := session.Send(ctx, message, func(chunk string) {
+		fmt.Print(chunk)
+	})
+	fmt.Println()
+	if err != nil {
+		if ctx.Err() != nil {
+			fmt.Fprintln(os.Stderr, "Reply cancelled")
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		return
+	}
+
+	fmt.Printf("  (confidence %.0f%%, %s, %d tokens)\n", result.Confidence*100, result.EmotionalTone, result.TokensUsed)
+	printChatList("Questions", "?", result.Questions)
+	printChatList("Recommendations", "→", result.Recommendations)
+	if turn := session.LastReply(); turn != nil {
+		printChatList("Memories used", "•", turn.Memories)
+	}
+}
+
+// printChatList prints one of the extras of a reply
+func printChatList(title, bullet string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Printf("  %s:\n", title)
+	for _, item := range items {
+		fmt.Printf("    %s %s\n", bullet, item)
+	}
+}
+
+// runChatCommand runs a slash command typed during a chat. It reports whether
+// the chat should end.
+func runChatCommand(app *App, session *chat.Session, projectID, line string) bool {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case "/quit", "/exit":
+		return true
+
+	case "/help":
+		fmt.Println(chatCommandsHelp)
+
+	case "/transcript":
+		fmt.Println(session.Transcript())
+
+	case "/save":
+		if len(fields) < 2 || len(fields) > 3 {
+			fmt.Fprintln(os.Stderr, "Usage: /save idea|document [PROJECT_ID]")
+			return false
+		}
+		if len(fields) == 3 {
+			projectID = fields[2]
+		}
+		if projectID == "" {
+			fmt.Fprintln(os.Stderr, "Error: name the project to save to, or start the chat with --project")
+			return false
+		}
+
+		message, err := app.saveChat(session, fields[1], projectID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+		fmt.Println(message)
+
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %s. Type /help for commands.\n", fields[0])
+	}
+	return false
+}
+
+// chatSession starts a conversation with a persona, optionally about a project
+func (a *App) chatSession(personaID, projectID, providerName, model string) (*chat.Session, error) {
+	provider, err := a.personaProvider(providerName, model)
+	if err != nil {
+		return nil, err
+	}
+
+	personas := persona.NewStorage(a.DB.DB)
+	p, err := personas.LoadPersona(personaID, provider, a.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var proj *project.Project
+	if projectID != "" {
+		if proj, err = project.NewStorage(a.DB.DB).LoadProject(projectID); err != nil {
+			return nil, err
+		}
+	}
+
+	return chat.NewSession(personas, p, proj, a.Logger), nil
+}
+
+// saveChat saves a chat transcript to a project as an idea or a document and
+// returns a message describing what was saved
+func (a *App) saveChat(session *chat.Session, kind, projectID string) (string, error) {
+	storage := project.NewStorage(a.DB.DB)
+	proj, err := storage.LoadProject(projectID)
+	if err != nil {
+		return "", err
+	}
+
+	switch kind {
+	case "idea":
+		idea, err := session.SaveAsIdea(storage, proj.ID)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("✓ Saved the transcript as idea %q in %s", idea.Title, proj.Name), nil
+	case "document", "doc":
+		doc, err := session.SaveAsDocument(storage, proj.ID, a.documentsDir())
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("✓ Saved the transcript to %s in %s", doc.FilePath, proj.Name), nil
+	default:
+		return "", fmt.Errorf("cannot save as %q (must be idea or document)", kind)
+	}
+}
+
+// documentsDir is where project documents are written, next to the database
+func (a *App) documentsDir() string {
+	return filepath.Join(filepath.Dir(a.Config.Database.Path), "documents")
+}
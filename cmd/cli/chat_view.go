@@ -0,0 +1,372 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"personal-ai-board/internal/chat"
+	"personal-ai-board/internal/project"
+)
+
+// chatChunkBuffer is how many reply chunks may queue for the chat view
+const chatChunkBuffer = 256
+
+// chatOpenedMsg carries a new chat session and the projects it can be saved to
+type chatOpenedMsg struct {
+	session  *chat.Session
+	projects []project.ProjectInfo
+	err      error
+}
+
+// chatChunkMsg carries part of a reply as it is written
+type chatChunkMsg struct {
+	chunk string
+}
+
+// chatReplyMsg reports that the persona has finished replying
+type chatReplyMsg struct {
+	session *chat.Session
+	err     error
+}
+
+// chatSavedMsg reports the outcome of saving a transcript
+type chatSavedMsg struct {
+	message string
+	err     error
+}
+
+// chatView is a one-on-one conversation with a persona
+type chatView struct {
+	session    *chat.Session
+	projects   []project.ProjectInfo
+	projectIdx int
+	loading    bool
+
+	input     string
+	pending   string
+	sending   bool
+	cancelled bool
+	chunks    chan string
+	cancel    context.CancelFunc
+
+	notice string
+	err    error
+}
+
+// newChatView creates an empty chat view
+func newChatView() *chatView {
+	return &chatView{}
+}
+
+// openChatCmd starts a chat session with a persona
+func openChatCmd(app *App, personaID string) tea.Cmd {
+	return func() tea.Msg {
+		session, err := app.chatSession(personaID, "", "", "")
+		if err != nil {
+			return chatOpenedMsg{err: err}
+		}
+		projects, err := project.NewStorage(app.DB.DB).ListProjects()
+		if err != nil {
+			return chatOpenedMsg{err: err}
+		}
+		return chatOpenedMsg{session: session, projects: projects}
+	}
+}
+
+// waitForChunkCmd waits for the next part of a reply
+func waitForChunkCmd(chunks <-chan string) tea.Cmd {
+	return func() tea.Msg {
+		chunk, ok := <-chunks
+		if !ok {
+			return nil
+		}
+		return chatChunkMsg{chunk: chunk}
+	}
+}
+
+// saveChatCmd saves the transcript to the selected project
+func saveChatCmd(app *App, session *chat.Session, kind, projectID string) tea.Cmd {
+	return func() tea.Msg {
+		message, err := app.saveChat(session, kind, projectID)
+		return chatSavedMsg{message: message, err: err}
+	}
+}
+
+// reset clears the view before a new session is opened
+func (c *chatView) reset() {
+	c.release()
+	*c = chatView{loading: true}
+}
+
+// opened shows a newly opened session
+func (c *chatView) opened(msg chatOpenedMsg) {
+	c.loading = false
+	c.err = msg.err
+	c.session = msg.session
+	c.projects = msg.projects
+	c.projectIdx = 0
+}
+
+// send passes the typed message to the persona and streams its reply
+func (c *chatView) send() tea.Cmd {
+	message := strings.TrimSpace(c.input)
+	if c.session == nil || c.sending || message == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chunks := make(chan string, chatChunkBuffer)
+	session := c.session
+	c.input = ""
+	c.pending = ""
+	c.sending = true
+	c.cancelled = false
+	c.chunks = chunks
+	c.cancel = cancel
+	c.notice = ""
+	c.err = nil
+
+	reply := func() tea.Msg {
+		_, err := session.Send(ctx, message, func(chunk string) {
+			// The full reply replaces the streamed text once it is done,
+			// so chunks are dropped rather than blocking the persona
+			select {
+			case chunks <- chunk:
+			default:
+			}
+		})
+		close(chunks)
+		cancel()
+		return chatReplyMsg{session: session, err: err}
+	}
+	return tea.Batch(reply, waitForChunkCmd(chunks))
+}
+
+// handleChunk adds part of a reply to the conversation
+func (c *chatView) handleChunk(msg chatChunkMsg) tea.Cmd {
+	if !c.sending {
+		return nil
+	}
+	c.pending += msg.chunk
+	return waitForChunkCmd(c.chunks)
+}
+
+// handleReply records the end of a reply
+func (c *chatView) handleReply(msg chatReplyMsg) {
+	if msg.session != c.session {
+		return
+	}
+	c.sending = false
+	c.pending = ""
+	c.cancel = nil
+	switch {
+	case c.cancelled:
+		c.notice = "Reply cancelled"
+	case msg.err != nil:
+		c.err = msg.err
+	}
+}
+
+// handleSaved shows the outcome of saving the transcript
+func (c *chatView) handleSaved(msg chatSavedMsg) {
+	c.err = msg.err
+	c.notice = msg.message
+}
+
+// release cancels a reply that is being written
+func (c *chatView) release() {
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
+}
+
+// save saves the transcript as an idea or document of the selected project
+func (c *chatView) save(app *App, kind string) tea.Cmd {
+	if c.session == nil {
+		return nil
+	}
+	if len(c.projects) == 0 {
+		c.err = fmt.Errorf("create a project to save transcripts to")
+		return nil
+	}
+	return saveChatCmd(app, c.session, kind, c.projects[c.projectIdx].ID)
+}
+
+// handleKey handles keys on the chat view. It reports whether the key was
+// consumed.
+func (c *chatView) handleKey(msg tea.KeyMsg, app *App) (tea.Cmd, bool) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		if c.sending {
+			c.cancelled = true
+			c.release()
+			return nil, true
+		}
+		return nil, false
+	case tea.KeyEnter:
+		return c.send(), true
+	case tea.KeyTab:
+		if len(c.projects) > 0 {
+			c.projectIdx = (c.projectIdx + 1) % len(c.projects)
+		}
+	case tea.KeyCtrlS:
+		return c.save(app, "idea"), true
+	case tea.KeyCtrlD:
+		return c.save(app, "document"), true
+	case tea.KeyBackspace:
+		if runes := []rune(c.input); len(runes) > 0 {
+			c.input = string(runes[:len(runes)-1])
+		}
+	case tea.KeySpace:
+		c.input += " "
+	case tea.KeyRunes:
+		c.input += string(msg.Runes)
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// render draws the conversation with the extras of the last reply alongside
+func (c *chatView) render(width, height int) string {
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).PaddingLeft(2)
+	if c.loading {
+		return mutedStyle.Render("⏳ Loading persona...")
+	}
+	if c.session == nil {
+		if c.err != nil {
+			return lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).PaddingLeft(2).Render("❌ " + c.err.Error())
+		}
+		return mutedStyle.Render("No persona selected.")
+	}
+
+	sideWidth := 0
+	if width >= 80 {
+		sideWidth = width / 3
+	}
+	mainWidth := width - sideWidth - 6
+	if mainWidth < 20 {
+		mainWidth = 20
+	}
+	historyHeight := height - 3
+	if historyHeight < 3 {
+		historyHeight = 3
+	}
+
+	history := c.renderHistory(mainWidth, historyHeight)
+	body := history
+	if sideWidth > 0 {
+		side := c.renderSidePanel(sideWidth-2, historyHeight)
+		body = lipgloss.JoinHorizontal(lipgloss.Top, lipgloss.NewStyle().Width(mainWidth+2).Render(history), side)
+	}
+
+	var s strings.Builder
+	s.WriteString(lipgloss.NewStyle().PaddingLeft(2).Render(body))
+	s.WriteString("\n\n")
+
+	prompt := "> " + c.input + "█"
+	if c.sending {
+		prompt = fmt.Sprintf("✍ %s is replying...", c.session.Persona.Name)
+	}
+	s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#00FFFF")).PaddingLeft(2).Render(truncate(prompt, width-4)))
+	s.WriteString("\n")
+
+	switch {
+	case c.err != nil:
+		s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).PaddingLeft(2).Render(truncate("❌ "+c.err.Error(), width-4)))
+	case c.notice != "":
+		s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#2ECC71")).PaddingLeft(2).Render(truncate(c.notice, width-4)))
+	}
+
+	return s.String()
+}
+
+// renderHistory draws the tail of the conversation that fits in height lines
+func (c *chatView) renderHistory(width, height int) string {
+	userStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#3498DB")).Bold(true)
+	personaStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#9B59B6")).Bold(true)
+	textStyle := lipgloss.NewStyle().Width(width)
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Width(width)
+
+	blocks := make([]string, 0)
+	name := c.session.Persona.Name
+	for _, turn := range c.session.History() {
+		blocks = append(blocks, userStyle.Render(chat.UserSpeaker), textStyle.Render(turn.Message), "")
+		switch {
+		case turn.Error != "":
+			blocks = append(blocks, personaStyle.Render(name), errorStyle.Render("No reply: "+turn.Error), "")
+		case turn.Result != nil:
+			blocks = append(blocks, personaStyle.Render(name), textStyle.Render(strings.TrimSpace(turn.Result.Response)), "")
+		}
+	}
+	if c.sending {
+		blocks = append(blocks, personaStyle.Render(name+" ✍"), textStyle.Render(strings.TrimSpace(c.pending)))
+	}
+	if len(blocks) == 0 {
+		blocks = append(blocks, lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf("Say something to %s.", name)))
+	}
+
+	lines := strings.Split(strings.Join(blocks, "\n"), "\n")
+	if len(lines) > height {
+		lines = lines[len(lines)-height:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderSidePanel draws the extras of the last reply and the save target
+func (c *chatView) renderSidePanel(width, height int) string {
+	headingStyle := lipgloss.NewStyle().Bold(true)
+	var s strings.Builder
+
+	p := c.session.Persona
+	s.WriteString(headingStyle.Render("💬 " + p.Name))
+	s.WriteString("\n")
+	s.WriteString(lipgloss.NewStyle().Faint(true).Render(p.Description))
+	s.WriteString("\n")
+
+	if turn := c.session.LastReply(); turn != nil {
+		result := turn.Result
+		fmt.Fprintf(&s, "\nConfidence %.0f%% · %s\n", result.Confidence*100, result.EmotionalTone)
+		sections := []struct {
+			title  string
+			bullet string
+			items  []string
+		}{
+			{"Questions", "?", result.Questions},
+			{"Recommendations", "→", result.Recommendations},
+			{"Memories used", "•", turn.Memories},
+		}
+		for _, section := range sections {
+			if len(section.items) == 0 {
+				continue
+			}
+			s.WriteString("\n" + headingStyle.Render(section.title) + "\n")
+			for _, item := range section.items {
+				s.WriteString(section.bullet + " " + item + "\n")
+			}
+		}
+	}
+
+	target := "(no projects)"
+	if len(c.projects) > 0 {
+		target = c.projects[c.projectIdx].Name
+	}
+	s.WriteString("\n" + headingStyle.Render("Save to") + "\n" + target + "\n")
+
+	lines := strings.Split(lipgloss.NewStyle().Width(width-4).Render(strings.TrimRight(s.String(), "\n")), "\n")
+	if height > 3 && len(lines) > height-2 {
+		lines = append(lines[:height-3], "…")
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#7D56F4")).
+		Padding(0, 1).
+		Width(width).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"personal-ai-board/internal/config"
+)
+
+// Exit codes returned by subcommands
+const (
+	exitOK         = 0
+	exitError      = 1
+	exitUsage      = 2
+	exitValidation = 3
+	exitNotFound   = 4
+	exitUnhealthy  = 5
+	exitCancelled  = 130
+)
+
+// command is a non-interactive subcommand
+type command struct {
+	Name        string
+	Usage       string
+	Description string
+	Run         func(cfg *config.Config, args []string) int
+}
+
+// commandGroup groups related subcommands under a common name. A group with
+// Run set is a single command that takes no subcommand.
+type commandGroup struct {
+	Name        string
+	Usage       string
+	Description string
+	Commands    []command
+	Run         func(cfg *config.Config, args []string) int
+}
+
+// commandGroups returns every subcommand group known to the CLI
+func commandGroups() []commandGroup {
+	return []commandGroup{
+		{
+			Name:        "persona",
+			Description: "Create, inspect and validate personas",
+			Commands: []command{
+				{"list", "persona list [--output table|json|yaml]",
+					"List saved personas", runPersonaList},
+				{"show", "persona show PERSONA_ID [--output table|json|yaml]",
+					"Show a persona's personality profile", runPersonaShow},
+				{"create", "persona create [--from FILE] [--name NAME] [--description TEXT] [--defaults]",
+					"Interactively author a persona from the base trait definitions", runPersonaCreate},
+				{"generate", "persona generate [--provider NAME] [--model NAME] [--dry-run] [--save-traits FILE] \"description\"",
+					"Generate a persona from a natural-language description using an LLM", runPersonaGenerate},
+				{"clone", "persona clone PERSONA_ID [--name NAME] [--output table|json|yaml]",
+					"Save a copy of a persona with slightly varied traits", runPersonaClone},
+				{"delete", "persona delete PERSONA_ID",
+					"Delete a persona", runPersonaDelete},
+				{"lint", "persona lint [--traits-dir DIR] [file.json ...]",
+					"Check trait files against base.json ranges, options and constraints", runPersonaLint},
+			},
+		},
+		{
+			Name:        "board",
+			Description: "Assemble advisory boards from personas",
+			Commands: []command{
+				{"list", "board list [--output table|json|yaml]",
+					"List boards", runBoardList},
+				{"show", "board show BOARD_ID [--output table|json|yaml]",
+					"Show a board and its members", runBoardShow},
+				{"create", "board create NAME [--description TEXT] [--personas ID,ID] [--role ROLE] [--output table|json|yaml]",
+					"Create a board", runBoardCreate},
+				{"add-member", "board add-member BOARD_ID PERSONA_ID [--role ROLE]",
+					"Seat a persona on a board", runBoardAddMember},
+				{"remove-member", "board remove-member BOARD_ID PERSONA_ID",
+					"Remove a persona from a board", runBoardRemoveMember},
+				{"delete", "board delete BOARD_ID",
+					"Delete a board", runBoardDelete},
+			},
+		},
+		{
+			Name:        "project",
+			Description: "Organize ideas into projects",
+			Commands: []command{
+				{"list", "project list [--output table|json|yaml]",
+					"List projects", runProjectList},
+				{"show", "project show PROJECT_ID [--output table|json|yaml]",
+					"Show a project and its ideas", runProjectShow},
+				{"create", "project create NAME [--description TEXT] [--output table|json|yaml]",
+					"Create a project", runProjectCreate},
+				{"add-idea", "project add-idea PROJECT_ID TITLE [--content TEXT] [--priority N] [--tags a,b]",
+					"Add an idea to a project", runProjectAddIdea},
+				{"delete", "project delete PROJECT_ID",
+					"Delete a project and its ideas", runProjectDelete},
+			},
+		},
+		{
+			Name:        "analyze",
+			Usage:       "analyze --board BOARD_ID --project PROJECT_ID [--mode MODE] [--rounds N] [--timeout 5m] --topic \"question\" [--output table|json|yaml]",
+			Description: "Run an analysis with a board and print the result",
+			Run:         runAnalyze,
+		},
+		{
+			Name:        "chat",
+			Usage:       "chat PERSONA_ID [--project PROJECT_ID] [--provider NAME] [--model NAME]",
+			Description: "Talk one-on-one with a persona",
+			Run:         runChat,
+		},
+		{
+			Name:        "search",
+			Usage:       "search \"query\" [--project ID] [--board ID] [--persona ID|NAME] [--type TYPE,TYPE] [--since DATE] [--until DATE] [--limit N] [--output table|json|yaml]",
+			Description: "Search personas, ideas, documents and analyses",
+			Run:         runSearch,
+		},
+		{
+			Name:        "db",
+			Description: "Manage the database",
+			Commands: []command{
+				{"migrate", "db migrate [--force] [--output table|json|yaml] | db migrate create NAME [--dir DIR]",
+					"Apply pending schema migrations, or scaffold a new one", runDBMigrate},
+				{"status", "db status [--output table|json|yaml]",
+					"Show which schema migrations have been applied, and any that are dirty or modified", runDBStatus},
+				{"validate", "db validate [--output table|json|yaml]",
+					"Compare the live schema with the one its migrations produce", runDBValidate},
+				{"rollback", "db rollback [--to VERSION] [--output table|json|yaml]",
+					"Roll the schema back one migration or to a version", runDBRollback},
+				{"backup", "db backup [FILE] [--force] [--compress] [--prune] [--output table|json|yaml]",
+					"Write a verified copy of the database to FILE, or a timestamped snapshot to the backup directory", runDBBackup},
+				{"snapshots", "db snapshots [--output table|json|yaml]",
+					"List the snapshots in the backup directory", runDBSnapshots},
+				{"prune", "db prune [--keep-daily N] [--keep-weekly N] [--dry-run]",
+					"Delete snapshots outside the retention policy", runDBPrune},
+				{"verify", "db verify FILE|latest",
+					"Check the integrity and schema version of a backup", runDBVerify},
+				{"restore", "db restore FILE|latest [--output table|json|yaml]",
+					"Replace the database with a backup, keeping a copy of the current one", runDBRestore},
+				{"vacuum", "db vacuum",
+					"Rebuild the database file to reclaim space", runDBVacuum},
+			},
+		},
+		{
+			Name:        "webhook",
+			Description: "Send analysis and persona events to HTTP endpoints",
+			Commands: []command{
+				{"add", "webhook add URL [--events a,b] [--secret SECRET] [--description TEXT] [--output table|json|yaml]",
+					"Subscribe an endpoint to events; payloads are signed with the secret", runWebhookAdd},
+				{"list", "webhook list [--output table|json|yaml]",
+					"List webhooks", runWebhookList},
+				{"remove", "webhook remove WEBHOOK_ID",
+					"Delete a webhook and its delivery log", runWebhookRemove},
+				{"test", "webhook test WEBHOOK_ID|URL [--secret SECRET] [--output table|json|yaml]",
+					"Send a test event to a webhook, or to any URL such as a local receiver", runWebhookTest},
+				{"deliveries", "webhook deliveries [WEBHOOK_ID] [--limit N] [--output table|json|yaml]",
+					"Show the delivery log", runWebhookDeliveries},
+				{"deliver", "webhook deliver",
+					"Attempt the queued deliveries that are due", runWebhookDeliver},
+			},
+		},
+		{
+			Name:        "config",
+			Description: "Inspect the layered configuration",
+			Commands: []command{
+				{"show", "config show [SECTION|KEY] [--output table|json|yaml]",
+					"Show every effective configuration value and where it was set", runConfigShow},
+			},
+		},
+		{
+			Name:        "secret",
+			Description: "Keep provider API keys in the encrypted keystore",
+			Commands: []command{
+				{"set", "secret set NAME [--from-env VAR] < value",
+					"Store a secret, asking for it on the terminal or reading it from standard input", runSecretSet},
+				{"list", "secret list [--output table|json|yaml]",
+					"List the secrets in the keystore", runSecretList},
+				{"remove", "secret remove NAME",
+					"Delete a secret from the keystore", runSecretRemove},
+				{"check", "secret check [--output table|json|yaml]",
+					"Check that every configured API key can be resolved, without showing it", runSecretCheck},
+			},
+		},
+		{
+			Name:        "user",
+			Description: "Manage the users of the API server",
+			Commands: []command{
+				{"add", "user add NAME [--workspace WORKSPACE_ID] [--output table|json|yaml]",
+					"Add a user, in a new workspace unless one is given", runUserAdd},
+				{"list", "user list [--output table|json|yaml]",
+					"List users and their workspaces", runUserList},
+				{"remove", "user remove USER_ID",
+					"Delete a user and revoke their API tokens; their workspace is kept", runUserRemove},
+			},
+		},
+		{
+			Name:        "token",
+			Description: "Issue and revoke API tokens",
+			Commands: []command{
+				{"create", "token create USER_ID [--name NAME] [--expires DURATION] [--output table|json|yaml]",
+					"Issue an API token for a user; it is shown once", runTokenCreate},
+				{"list", "token list [USER_ID] [--output table|json|yaml]",
+					"List API tokens", runTokenList},
+				{"revoke", "token revoke TOKEN_ID",
+					"Revoke an API token", runTokenRevoke},
+			},
+		},
+		{
+			Name:        "providers",
+			Description: "Inspect LLM providers",
+			Commands: []command{
+				{"health", "providers health [--provider NAME] [--output table|json|yaml]",
+					"Check that each configured provider responds", runProvidersHealth},
+				{"models", "providers models [--provider NAME] [--refresh] [--output table|json|yaml]",
+					"List the model catalog's context sizes, prices and capabilities", runProvidersModels},
+			},
+		},
+	}
+}
+
+// runSubcommand dispatches args to a subcommand. It reports whether args named
+// a subcommand and, if so, the exit code to use.
+func runSubcommand(args []string) (bool, int) {
+	if len(args) == 0 {
+		return false, exitOK
+	}
+
+	for _, group := range commandGroups() {
+		if group.Name != args[0] {
+			continue
+		}
+
+		if group.Run != nil {
+			cfg, code := loadCommandConfig(group.Name)
+			if cfg == nil {
+				return true, code
+			}
+			return true, group.Run(cfg, args[1:])
+		}
+
+		if len(args) < 2 {
+			printGroupUsage(group)
+			return true, exitUsage
+		}
+
+		for _, cmd := range group.Commands {
+			if cmd.Name != args[1] {
+				continue
+			}
+
+			cfg, code := loadCommandConfig(group.Name)
+			if cfg == nil {
+				return true, code
+			}
+			return true, cmd.Run(cfg, args[2:])
+		}
+
+		fmt.Fprintf(os.Stderr, "Unknown %s command: %s\n\n", group.Name, args[1])
+		printGroupUsage(group)
+		return true, exitUsage
+	}
+
+	return false, exitOK
+}
+
+// loadCommandConfig loads the configuration for a subcommand of group,
+// reporting failures on stderr with the exit code to use. Only the config
+// commands, which report on the configuration, run with an invalid one.
+func loadCommandConfig(group string) (*config.Config, int) {
+	load := loadConfig
+	if group == "config" {
+		load = loadUncheckedConfig
+	}
+
+	cfg, err := load()
+	var invalid *config.ValidationError
+	if errors.As(err, &invalid) {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\nRun \"personal-ai-board config show\" to see where each value is set.\n", err)
+		return nil, exitValidation
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		return nil, exitError
+	}
+	configureMemory(cfg)
+	return cfg, exitOK
+}
+
+// printGroupUsage prints the commands available in a group
+func printGroupUsage(group commandGroup) {
+	fmt.Fprintf(os.Stderr, "%s - %s\n\nUSAGE:\n", group.Name, group.Description)
+	if group.Usage != "" {
+		fmt.Fprintf(os.Stderr, "  personal-ai-board %s\n", group.Usage)
+	}
+	for _, cmd := range group.Commands {
+		fmt.Fprintf(os.Stderr, "  personal-ai-board %s\n      %s\n", cmd.Usage, cmd.Description)
+	}
+}
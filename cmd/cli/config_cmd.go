@@ -0,0 +1,168 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"personal-ai-board/internal/config"
+)
+
+// globalOptions are the options given before a subcommand
+var globalOptions struct {
+	configPath string   // --config: the project file to load
+	sets       []string // --set KEY=VALUE: values that override every other layer
+}
+
+// configValue is one effective configuration value and where it was set
+type configValue struct {
+	Key    string        `json:"key" yaml:"key"`
+	Value  string        `json:"value" yaml:"value"`
+	Source config.Source `json:"source" yaml:"source"`
+}
+
+// configReport is the effective configuration as shown by config show
+type configReport struct {
+	Files  []config.Source `json:"files" yaml:"files"`
+	Values []configValue   `json:"values" yaml:"values"`
+	Error  string          `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// parseGlobalOptions consumes --config and --set from the start of args and
+// returns the arguments that follow them
+func parseGlobalOptions(args []string) ([]string, error) {
+	for len(args) > 0 {
+		name, value, inline := strings.Cut(args[0], "=")
+		if name != "--config" && name != "--set" {
+			return args, nil
+		}
+		if !inline {
+			if len(args) < 2 {
+				return nil, fmt.Errorf("%s needs a value", name)
+			}
+			value, args = args[1], args[1:]
+		}
+		args = args[1:]
+
+		if name == "--config" {
+			globalOptions.configPath = value
+		} else {
+			globalOptions.sets = append(globalOptions.sets, value)
+		}
+	}
+	return args, nil
+}
+
+// loadConfig loads the layered configuration, applies the --set flags and
+// checks the result
+func loadConfig() (*config.Config, error) {
+	cfg, err := loadUncheckedConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadUncheckedConfig loads the layered configuration and applies the --set
+// flags without checking the result, for commands that report on it
+func loadUncheckedConfig() (*config.Config, error) {
+	cfg, err := config.Load(globalOptions.configPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, set := range globalOptions.sets {
+		key, value, ok := strings.Cut(set, "=")
+		if !ok {
+			return nil, fmt.Errorf("--set %s must be KEY=VALUE", set)
+		}
+		if err := cfg.Set(strings.TrimSpace(key), value, config.FlagSource("set")); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// runConfigShow prints every effective configuration value and the layer it
+// came from, then checks the configuration
+func runConfigShow(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("config show", flag.ContinueOnError)
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+	if len(positional) > 1 {
+		return usageError("personal-ai-board config show [SECTION|KEY] [--output table|json|yaml]")
+	}
+
+	prefix := ""
+	if len(positional) == 1 {
+		prefix = positional[0]
+	}
+
+	// Settings stored in an existing database take precedence over the files
+	if _, err := os.Stat(cfg.Database.Path); err == nil {
+		if database, err := connectDatabase(cfg); err == nil {
+			if values, err := database.GetAllSystemConfig(); err == nil {
+				for _, err := range cfg.ApplySystemConfig(values) {
+					fmt.Fprintf(os.Stderr, "Warning: ignoring invalid database setting: %v\n", err)
+				}
+			}
+			database.Close()
+		}
+	}
+
+	report := configReport{Files: cfg.Files(), Values: make([]configValue, 0)}
+	for _, key := range cfg.Keys() {
+		if prefix != "" && key != prefix && !strings.HasPrefix(key, prefix+".") {
+			continue
+		}
+		value, _ := cfg.Get(key)
+		if strings.HasSuffix(key, ".api_key") {
+			value = config.MaskSecret(value)
+		}
+		report.Values = append(report.Values, configValue{Key: key, Value: value, Source: cfg.Origin(key)})
+	}
+	if len(report.Values) == 0 {
+		return argumentError(fmt.Errorf("no configuration key or section named %q", prefix))
+	}
+
+	validationErr := cfg.Validate()
+	if validationErr != nil {
+		report.Error = validationErr.Error()
+	}
+
+	if code := printOutput(*format, report, func(tw *tabwriter.Writer) {
+		if len(report.Files) == 0 {
+			fmt.Fprintln(tw, "Files:\tnone, defaults and environment only")
+		}
+		for i, file := range report.Files {
+			label := ""
+			if i == 0 {
+				label = "Files:"
+			}
+			tableRow(tw, label, file)
+		}
+		fmt.Fprintln(tw)
+		tableRow(tw, "KEY", "VALUE", "SOURCE")
+		for _, v := range report.Values {
+			tableRow(tw, v.Key, v.Value, v.Source)
+		}
+	}); code != exitOK {
+		return code
+	}
+
+	if validationErr != nil {
+		fmt.Fprintf(os.Stderr, "\n✗ Invalid configuration: %v\n", validationErr)
+		return exitValidation
+	}
+	return exitOK
+}
@@ -0,0 +1,614 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"personal-ai-board/internal/analysis"
+	"personal-ai-board/internal/board"
+	"personal-ai-board/internal/project"
+)
+
+const (
+	// maxAnalysisRounds is the most rounds the setup form offers
+	maxAnalysisRounds = 5
+	// analysisEventBuffer is how many progress events may queue for the dashboard
+	analysisEventBuffer = 256
+)
+
+// runStage is the step of the analysis view the user is on
+type runStage int
+
+const (
+	runSetup runStage = iota
+	runActive
+	runDone
+)
+
+// Setup form fields
+const (
+	fieldBoard = iota
+	fieldProject
+	fieldMode
+	fieldRounds
+	fieldTopic
+	fieldCount
+)
+
+// runOptionsMsg carries the boards and projects an analysis can be run with
+type runOptionsMsg struct {
+	boards   []board.BoardInfo
+	projects []project.ProjectInfo
+	err      error
+}
+
+// analysisEventMsg carries a progress event from the analysis engine
+type analysisEventMsg struct {
+	event analysis.Event
+}
+
+// analysisTickMsg refreshes the dashboard's elapsed time
+type analysisTickMsg struct{}
+
+// analysisDoneMsg reports that an analysis run has returned
+type analysisDoneMsg struct {
+	requestID string
+	result    *analysis.Result
+	err       error
+}
+
+// personaPane is the live state of one persona on the dashboard
+type personaPane struct {
+	id         string
+	name       string
+	role       string
+	state      analysis.EventType
+	round      int
+	content    string
+	confidence float64
+	tone       string
+	tokens     int
+	err        string
+}
+
+// runView is the analysis setup form and live dashboard
+type runView struct {
+	stage runStage
+
+	boards     []board.BoardInfo
+	projects   []project.ProjectInfo
+	loading    bool
+	boardIdx   int
+	projectIdx int
+	modeIdx    int
+	rounds     int
+	topic      string
+	field      int
+	err        error
+
+	requestID string
+	cancel    context.CancelFunc
+	stop      chan struct{}
+	events    <-chan analysis.Event
+	unsub     func()
+	panes     []*personaPane
+	round     int
+	metrics   analysis.Metrics
+	insights  []string
+	status    analysis.EventType
+	startedAt time.Time
+	elapsed   time.Duration
+	result    *analysis.Result
+}
+
+// newRunView creates the analysis view with the given default mode selected
+func newRunView(defaultMode string) *runView {
+	r := &runView{rounds: 1}
+	for i, mode := range analysis.Modes {
+		if mode == defaultMode {
+			r.modeIdx = i
+		}
+	}
+	return r
+}
+
+// loadRunOptionsCmd loads the boards and projects for the setup form
+func loadRunOptionsCmd(app *App) tea.Cmd {
+	return func() tea.Msg {
+		boards, err := board.NewStorage(app.DB.DB).ListBoards()
+		if err != nil {
+			return runOptionsMsg{err: err}
+		}
+		projects, err := project.NewStorage(app.DB.DB).ListProjects()
+		if err != nil {
+			return runOptionsMsg{err: err}
+		}
+		return runOptionsMsg{boards: boards, projects: projects}
+	}
+}
+
+// waitForEventCmd waits for the next progress event of an analysis
+func waitForEventCmd(events <-chan analysis.Event, stop <-chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			return analysisEventMsg{event: event}
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+// tickCmd schedules the next elapsed time refresh
+func tickCmd() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return analysisTickMsg{}
+	})
+}
+
+// running reports whether an analysis is in progress
+func (r *runView) running() bool {
+	return r.stage == runActive
+}
+
+// setOptions fills the setup form's choices
+func (r *runView) setOptions(msg runOptionsMsg) {
+	r.loading = false
+	r.err = msg.err
+	r.boards = msg.boards
+	r.projects = msg.projects
+	if r.boardIdx >= len(r.boards) {
+		r.boardIdx = 0
+	}
+	if r.projectIdx >= len(r.projects) {
+		r.projectIdx = 0
+	}
+}
+
+// cycle changes the value of the focused setup field
+func (r *runView) cycle(delta int) {
+	wrap := func(value, count int) int {
+		if count == 0 {
+			return 0
+		}
+		return ((value+delta)%count + count) % count
+	}
+
+	switch r.field {
+	case fieldBoard:
+		r.boardIdx = wrap(r.boardIdx, len(r.boards))
+	case fieldProject:
+		r.projectIdx = wrap(r.projectIdx, len(r.projects))
+	case fieldMode:
+		r.modeIdx = wrap(r.modeIdx, len(analysis.Modes))
+	case fieldRounds:
+		r.rounds = wrap(r.rounds-1, maxAnalysisRounds) + 1
+	}
+}
+
+// request builds an analysis request from the setup form
+func (r *runView) request() (*analysis.Request, error) {
+	if len(r.boards) == 0 {
+		return nil, fmt.Errorf("create a board before running an analysis")
+	}
+	if len(r.projects) == 0 {
+		return nil, fmt.Errorf("create a project before running an analysis")
+	}
+	if strings.TrimSpace(r.topic) == "" {
+		return nil, fmt.Errorf("enter a topic for the board to analyze")
+	}
+
+	return &analysis.Request{
+		ID:        generateID("request"),
+		ProjectID: r.projects[r.projectIdx].ID,
+		BoardID:   r.boards[r.boardIdx].ID,
+		Mode:      analysis.Modes[r.modeIdx],
+		Topic:     strings.TrimSpace(r.topic),
+		Rounds:    r.rounds,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// start runs an analysis in the background and subscribes to its events
+func (r *runView) start(app *App) tea.Cmd {
+	req, err := r.request()
+	if err != nil {
+		r.err = err
+		return nil
+	}
+	engine, err := app.analysisEngine()
+	if err != nil {
+		r.err = err
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.stage = runActive
+	r.err = nil
+	r.requestID = req.ID
+	r.cancel = cancel
+	r.stop = make(chan struct{})
+	r.events, r.unsub = engine.Subscribe(analysisEventBuffer)
+	r.panes = nil
+	r.round = 0
+	r.metrics = analysis.Metrics{}
+	r.insights = nil
+	r.status = ""
+	r.startedAt = time.Now()
+	r.elapsed = 0
+	r.result = nil
+
+	run := func() tea.Msg {
+		watcher := app.watchWebhooks(engine)
+		result, err := engine.Run(ctx, req)
+		watcher.Stop()
+		return analysisDoneMsg{requestID: req.ID, result: result, err: err}
+	}
+	return tea.Batch(run, waitForEventCmd(r.events, r.stop), tickCmd())
+}
+
+// stopListening unsubscribes from the engine's events
+func (r *runView) stopListening() {
+	if r.unsub != nil {
+		r.unsub()
+		r.unsub = nil
+	}
+	if r.stop != nil {
+		close(r.stop)
+		r.stop = nil
+	}
+}
+
+// cancelRun cancels the running analysis
+func (r *runView) cancelRun() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+}
+
+// release cancels any running analysis and stops listening for events
+func (r *runView) release() {
+	r.cancelRun()
+	r.stopListening()
+}
+
+// pane returns the pane of a persona, adding it if needed
+func (r *runView) pane(id, name string) *personaPane {
+	for _, pane := range r.panes {
+		if pane.id == id {
+			return pane
+		}
+	}
+	pane := &personaPane{id: id, name: name}
+	r.panes = append(r.panes, pane)
+	return pane
+}
+
+// handleEvent applies a progress event. It returns the command that waits for
+// the next event, or nil once the analysis has finished.
+func (r *runView) handleEvent(event analysis.Event) tea.Cmd {
+	if r.stop == nil {
+		return nil
+	}
+	if event.RequestID != r.requestID {
+		return waitForEventCmd(r.events, r.stop)
+	}
+
+	r.metrics = event.Metrics
+	r.elapsed = time.Since(r.startedAt)
+
+	switch event.Type {
+	case analysis.EventStarted:
+		for _, participant := range event.Participants {
+			r.pane(participant.PersonaID, participant.PersonaName).role = participant.Role
+		}
+	case analysis.EventRoundStarted:
+		r.round = event.Round
+	case analysis.EventPersonaStarted:
+		pane := r.pane(event.PersonaID, event.PersonaName)
+		pane.state = event.Type
+		pane.round = event.Round
+		pane.content = ""
+		pane.err = ""
+	case analysis.EventPersonaChunk:
+		pane := r.pane(event.PersonaID, event.PersonaName)
+		pane.state = event.Type
+		pane.content += event.Chunk
+	case analysis.EventPersonaCompleted, analysis.EventPersonaFailed:
+		pane := r.pane(event.PersonaID, event.PersonaName)
+		pane.state = event.Type
+		if resp := event.Response; resp != nil {
+			pane.content = resp.Content
+			pane.confidence = resp.Confidence
+			pane.tone = resp.EmotionalTone
+			pane.tokens += resp.TokensUsed
+		}
+		pane.err = event.Error
+	case analysis.EventInsight:
+		r.insights = append(r.insights, event.Insight)
+	}
+
+	if event.Final() {
+		r.status = event.Type
+		r.stage = runDone
+		if event.Error != "" && event.Type != analysis.EventCancelled {
+			r.err = fmt.Errorf("%s", event.Error)
+		}
+		r.stopListening()
+		return nil
+	}
+
+	return waitForEventCmd(r.events, r.stop)
+}
+
+// handleDone records the outcome of a finished run
+func (r *runView) handleDone(msg analysisDoneMsg) {
+	if msg.requestID != r.requestID {
+		return
+	}
+
+	r.result = msg.result
+	r.cancelRun()
+	r.cancel = nil
+	if msg.result == nil {
+		// The run failed before it started, so no events will follow
+		r.stage = runDone
+		r.status = analysis.EventFailed
+		r.err = msg.err
+		r.stopListening()
+	}
+}
+
+// handleKey handles keys on the analysis view. It reports whether the key was
+// consumed.
+func (r *runView) handleKey(msg tea.KeyMsg, app *App) (tea.Cmd, bool) {
+	switch r.stage {
+	case runActive:
+		if msg.String() == "c" {
+			r.cancelRun()
+		}
+		return nil, true
+
+	case runDone:
+		if msg.String() == "n" {
+			r.stage = runSetup
+			r.err = nil
+			return nil, true
+		}
+		return nil, false
+	}
+
+	switch msg.Type {
+	case tea.KeyTab, tea.KeyDown:
+		r.field = (r.field + 1) % fieldCount
+	case tea.KeyShiftTab, tea.KeyUp:
+		r.field = (r.field + fieldCount - 1) % fieldCount
+	case tea.KeyLeft:
+		r.cycle(-1)
+	case tea.KeyRight:
+		r.cycle(1)
+	case tea.KeyEnter:
+		return r.start(app), true
+	case tea.KeyEsc:
+		return nil, false
+	case tea.KeyBackspace:
+		if r.field == fieldTopic && len(r.topic) > 0 {
+			runes := []rune(r.topic)
+			r.topic = string(runes[:len(runes)-1])
+		}
+	case tea.KeySpace:
+		if r.field == fieldTopic {
+			r.topic += " "
+		}
+	case tea.KeyRunes:
+		if r.field == fieldTopic {
+			r.topic += string(msg.Runes)
+		} else if msg.String() == "q" {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+
+	return nil, true
+}
+
+// render draws the setup form or the dashboard
+func (r *runView) render(width, height int) string {
+	if r.stage == runSetup {
+		return r.renderSetup()
+	}
+	return r.renderDashboard(width, height)
+}
+
+// renderSetup draws the form that configures a new analysis
+func (r *runView) renderSetup() string {
+	var s strings.Builder
+
+	if r.loading {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).PaddingLeft(2).Render("⏳ Loading boards and projects...")
+	}
+
+	boardName, projectName := "(no boards)", "(no projects)"
+	if len(r.boards) > 0 {
+		b := r.boards[r.boardIdx]
+		boardName = fmt.Sprintf("%s (%d persona(s))", b.Name, b.PersonaCount)
+	}
+	if len(r.projects) > 0 {
+		projectName = r.projects[r.projectIdx].Name
+	}
+
+	fields := []struct {
+		label string
+		value string
+	}{
+		{"Board", boardName},
+		{"Project", projectName},
+		{"Mode", analysis.Modes[r.modeIdx]},
+		{"Rounds", fmt.Sprintf("%d", r.rounds)},
+		{"Topic", r.topic},
+	}
+
+	labelStyle := lipgloss.NewStyle().Width(10).Foreground(lipgloss.Color("#888888"))
+	for i, field := range fields {
+		value := field.value
+		style := lipgloss.NewStyle().PaddingLeft(2)
+		prefix := "  "
+		if i == r.field {
+			prefix = "→ "
+			style = style.Foreground(lipgloss.Color("#00FFFF")).Bold(true)
+			if i == fieldTopic {
+				value += "█"
+			} else {
+				value = "‹ " + value + " ›"
+			}
+		}
+		s.WriteString(style.Render(prefix + labelStyle.Render(field.label) + value))
+		s.WriteString("\n")
+	}
+
+	if r.err != nil {
+		s.WriteString("\n")
+		s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).PaddingLeft(2).Render("❌ " + r.err.Error()))
+		s.WriteString("\n")
+	}
+
+	return s.String()
+}
+
+// renderDashboard draws the live progress of an analysis with a pane per persona
+func (r *runView) renderDashboard(width, height int) string {
+	var s strings.Builder
+
+	statusText := "⏳ Running"
+	statusColor := "#F39C12"
+	switch r.status {
+	case analysis.EventCompleted:
+		statusText, statusColor = "✓ Completed", "#2ECC71"
+	case analysis.EventCancelled:
+		statusText, statusColor = "⏹ Cancelled", "#F39C12"
+	case analysis.EventFailed:
+		statusText, statusColor = "✗ Failed", "#FF0000"
+	}
+
+	elapsed := r.elapsed
+	if r.running() {
+		elapsed = time.Since(r.startedAt)
+	}
+
+	topicStyle := lipgloss.NewStyle().Bold(true).PaddingLeft(2)
+	s.WriteString(topicStyle.Render(truncate(r.topic, width-4)))
+	s.WriteString("\n")
+
+	stats := fmt.Sprintf("%s  ·  Round %d/%d  ·  %d tokens  ·  $%.4f  ·  %s",
+		lipgloss.NewStyle().Foreground(lipgloss.Color(statusColor)).Bold(true).Render(statusText),
+		r.round, r.rounds, r.metrics.TotalTokens, r.metrics.CostUSD, elapsed.Round(time.Second))
+	s.WriteString(lipgloss.NewStyle().PaddingLeft(2).Render(stats))
+	s.WriteString("\n")
+
+	if r.err != nil {
+		s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).PaddingLeft(2).Render(truncate("❌ "+r.err.Error(), width-4)))
+		s.WriteString("\n")
+	} else if r.result != nil && r.result.Summary != "" && r.stage == runDone {
+		s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#CCCCCC")).PaddingLeft(2).Render(r.result.Summary))
+		s.WriteString("\n")
+	} else if len(r.insights) > 0 {
+		insight := fmt.Sprintf("💡 %d insight(s), latest: %s", len(r.insights), r.insights[len(r.insights)-1])
+		s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#CCCCCC")).PaddingLeft(2).Render(truncate(insight, width-4)))
+		s.WriteString("\n")
+	}
+	s.WriteString("\n")
+
+	if len(r.panes) == 0 {
+		return s.String()
+	}
+
+	columns := 1
+	if width >= 100 && len(r.panes) > 1 {
+		columns = 2
+	}
+	paneWidth := (width-4)/columns - 2
+	paneRows := (len(r.panes) + columns - 1) / columns
+	contentHeight := (height-4)/paneRows - 4
+	if contentHeight < 2 {
+		contentHeight = 2
+	}
+
+	rows := make([]string, 0, paneRows)
+	for start := 0; start < len(r.panes); start += columns {
+		cells := make([]string, 0, columns)
+		for _, pane := range r.panes[start:minInt(start+columns, len(r.panes))] {
+			cells = append(cells, pane.render(paneWidth, contentHeight))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, cells...))
+	}
+	s.WriteString(lipgloss.NewStyle().PaddingLeft(2).Render(lipgloss.JoinVertical(lipgloss.Left, rows...)))
+
+	return s.String()
+}
+
+// render draws a persona's pane with the tail of its response
+func (p *personaPane) render(width, contentHeight int) string {
+	icon, color := "…", "#888888"
+	switch p.state {
+	case analysis.EventPersonaStarted, analysis.EventPersonaChunk:
+		icon, color = "✍", "#F39C12"
+	case analysis.EventPersonaCompleted:
+		icon, color = "✓", "#2ECC71"
+	case analysis.EventPersonaFailed:
+		icon, color = "✗", "#FF0000"
+	}
+
+	inner := width - 2
+	if inner < 10 {
+		inner = 10
+	}
+
+	title := lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("%s %s", icon, p.name))
+	if p.role != "" {
+		title += lipgloss.NewStyle().Faint(true).Render(" - " + p.role)
+	}
+
+	details := "waiting"
+	if p.round > 0 {
+		details = fmt.Sprintf("round %d", p.round)
+	}
+	if p.state == analysis.EventPersonaCompleted {
+		details += fmt.Sprintf(" · confidence %.0f%% · %s · %d tokens", p.confidence*100, p.tone, p.tokens)
+	}
+
+	body := p.content
+	if p.err != "" {
+		body = "Error: " + p.err
+	}
+	lines := strings.Split(lipgloss.NewStyle().Width(inner).Render(strings.TrimSpace(body)), "\n")
+	if len(lines) > contentHeight {
+		lines = lines[len(lines)-contentHeight:]
+	}
+	for len(lines) < contentHeight {
+		lines = append(lines, "")
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(color)).
+		Padding(0, 1).
+		Width(width).
+		Render(title + "\n" + lipgloss.NewStyle().Faint(true).Render(details) + "\n" + strings.Join(lines, "\n"))
+}
+
+// minInt returns the smaller of two ints
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
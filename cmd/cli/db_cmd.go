@@ -0,0 +1,289 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/db"
+)
+
+// migrationReport is the schema state printed by the migration commands
+type migrationReport struct {
+	Database   string               `json:"database"`
+	Version    int                  `json:"version"`
+	Migrations []db.MigrationStatus `json:"migrations"`
+}
+
+// newMigrationReport reads the schema state of a database
+func newMigrationReport(cfg *config.Config, database *db.Database) (*migrationReport, error) {
+	migrations, err := database.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &migrationReport{Database: cfg.Database.Path, Migrations: migrations}
+	for _, migration := range migrations {
+		if migration.Applied && migration.Version > report.Version {
+			report.Version = migration.Version
+		}
+	}
+	return report, nil
+}
+
+// printMigrationReport prints the schema state of a database
+func printMigrationReport(cfg *config.Config, database *db.Database, format string) int {
+	report, err := newMigrationReport(cfg, database)
+	if err != nil {
+		return reportError(err)
+	}
+
+	return printOutput(format, report, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "Database: %s (schema version %d)\n\n", report.Database, report.Version)
+		tableRow(tw, "VERSION", "NAME", "STATE", "APPLIED AT")
+		for _, migration := range report.Migrations {
+			appliedAt := "-"
+			if migration.AppliedAt != nil {
+				appliedAt = migration.AppliedAt.Local().Format("2006-01-02 15:04")
+			}
+			tableRow(tw, migration.Version, migration.Name, migrationState(migration), appliedAt)
+		}
+		for _, migration := range report.Migrations {
+			if migration.Error != "" {
+				fmt.Fprintf(tw, "\nMigration %d failed: %s\n", migration.Version, migration.Error)
+			}
+		}
+	})
+}
+
+// migrationState describes whether a migration has been applied cleanly
+func migrationState(migration db.MigrationStatus) string {
+	switch {
+	case migration.Dirty:
+		return "dirty"
+	case migration.Modified:
+		return "modified"
+	case migration.Applied:
+		return "applied"
+	default:
+		return "pending"
+	}
+}
+
+// runDBMigrate applies pending migrations, or scaffolds a new one with
+// "db migrate create"
+func runDBMigrate(cfg *config.Config, args []string) int {
+	if len(args) > 0 && args[0] == "create" {
+		return runDBMigrateCreate(args[1:])
+	}
+
+	flags := flag.NewFlagSet("db migrate", flag.ContinueOnError)
+	force := flags.Bool("force", false, "forget migrations left dirty by a failure, once the schema has been repaired, and run them again")
+	format := addOutputFlag(flags)
+	if _, err := parseCommandFlags(flags, args); err != nil {
+		return exitUsage
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	database, err := connectDatabase(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer database.Close()
+
+	if *force {
+		cleared, err := database.ClearDirtyMigrations()
+		if err != nil {
+			return reportError(err)
+		}
+		if cleared > 0 {
+			fmt.Fprintf(os.Stderr, "Cleared %d dirty migration(s)\n", cleared)
+		}
+	}
+
+	if err := database.Migrate(); err != nil {
+		return reportError(err)
+	}
+	return printMigrationReport(cfg, database, *format)
+}
+
+// runDBMigrateCreate writes an empty up/down pair for a new migration
+func runDBMigrateCreate(args []string) int {
+	flags := flag.NewFlagSet("db migrate create", flag.ContinueOnError)
+	dir := flags.String("dir", filepath.Join("internal", "db", "migrations"), "directory holding the migration files")
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board db migrate create NAME [--dir DIR]")
+	}
+
+	up, down, err := db.CreateMigration(*dir, positional[0])
+	if err != nil {
+		return reportError(err)
+	}
+
+	fmt.Printf("✓ Created %s\n✓ Created %s\n", up, down)
+	fmt.Println("Migrations are embedded in the binary; rebuild it to apply the new one.")
+	return exitOK
+}
+
+// schemaReport is the outcome of comparing the live schema with the expected one
+type schemaReport struct {
+	Database    string   `json:"database"`
+	Version     int      `json:"version"`
+	Valid       bool     `json:"valid"`
+	Differences []string `json:"differences"`
+}
+
+// runDBValidate compares the live schema with the one its migrations produce
+func runDBValidate(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("db validate", flag.ContinueOnError)
+	format := addOutputFlag(flags)
+	if _, err := parseCommandFlags(flags, args); err != nil {
+		return exitUsage
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	database, err := connectDatabase(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer database.Close()
+
+	migrations, err := newMigrationReport(cfg, database)
+	if err != nil {
+		return reportError(err)
+	}
+	differences, err := database.SchemaDifferences()
+	if err != nil {
+		return reportError(err)
+	}
+
+	report := schemaReport{
+		Database:    cfg.Database.Path,
+		Version:     migrations.Version,
+		Valid:       len(differences) == 0,
+		Differences: append(make([]string, 0), differences...),
+	}
+	if code := printOutput(*format, report, func(tw *tabwriter.Writer) {
+		if report.Valid {
+			fmt.Fprintf(tw, "✓ Schema of %s matches migration %d\n", report.Database, report.Version)
+			return
+		}
+		fmt.Fprintf(tw, "Schema of %s differs from migration %d:\n", report.Database, report.Version)
+		for _, difference := range report.Differences {
+			fmt.Fprintf(tw, "  - %s\n", difference)
+		}
+	}); code != exitOK {
+		return code
+	}
+
+	if !report.Valid {
+		return exitValidation
+	}
+	return exitOK
+}
+
+// runDBStatus prints which migrations have been applied
+func runDBStatus(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("db status", flag.ContinueOnError)
+	format := addOutputFlag(flags)
+	if _, err := parseCommandFlags(flags, args); err != nil {
+		return exitUsage
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	database, err := connectDatabase(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer database.Close()
+
+	return printMigrationReport(cfg, database, *format)
+}
+
+// runDBRollback rolls the schema back by one migration or to a given version
+func runDBRollback(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("db rollback", flag.ContinueOnError)
+	target := flags.Int("to", -1, "schema version to roll back to (default: the previous version)")
+	format := addOutputFlag(flags)
+	if _, err := parseCommandFlags(flags, args); err != nil {
+		return exitUsage
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	database, err := connectDatabase(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer database.Close()
+
+	report, err := newMigrationReport(cfg, database)
+	if err != nil {
+		return reportError(err)
+	}
+	if report.Version == 0 {
+		fmt.Fprintln(os.Stderr, "Nothing to roll back: no migrations have been applied")
+		return exitValidation
+	}
+
+	version := *target
+	if version < 0 {
+		version = report.Version - 1
+	}
+	if version >= report.Version {
+		return argumentError(fmt.Errorf("--to must be lower than the current schema version %d", report.Version))
+	}
+
+	if err := database.Rollback(version); err != nil {
+		return reportError(err)
+	}
+	return printMigrationReport(cfg, database, *format)
+}
+
+// runDBVacuum rebuilds the database file to reclaim unused space
+func runDBVacuum(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("db vacuum", flag.ContinueOnError)
+	if _, err := parseCommandFlags(flags, args); err != nil {
+		return exitUsage
+	}
+
+	database, err := connectDatabase(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer database.Close()
+
+	before := fileSize(cfg.Database.Path)
+	if err := database.Vacuum(); err != nil {
+		return reportError(err)
+	}
+	if err := database.Analyze(); err != nil {
+		return reportError(err)
+	}
+
+	fmt.Printf("✓ Vacuumed %s (%d → %d bytes)\n", cfg.Database.Path, before, fileSize(cfg.Database.Path))
+	return exitOK
+}
+
+// fileSize returns the size of a file, or zero if it cannot be read
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
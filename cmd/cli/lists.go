@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// listItem is a row in a data list together with the text of its detail pane
+type listItem struct {
+	ID       string
+	Title    string
+	Subtitle string
+	Detail   string
+}
+
+// listView is a scrollable, filterable list of items loaded from the database
+type listView struct {
+	items     []listItem
+	cursor    int
+	offset    int
+	filter    string
+	filtering bool
+	loading   bool
+	loaded    bool
+	err       error
+}
+
+// newListView creates an empty list view
+func newListView() *listView {
+	return &listView{items: make([]listItem, 0)}
+}
+
+// setItems replaces the list contents, keeping the cursor in range
+func (l *listView) setItems(items []listItem) {
+	l.items = items
+	l.loading = false
+	l.loaded = true
+	l.err = nil
+	l.clamp()
+}
+
+// visible returns the items matching the current filter
+func (l *listView) visible() []listItem {
+	if l.filter == "" {
+		return l.items
+	}
+
+	filter := strings.ToLower(l.filter)
+	matches := make([]listItem, 0, len(l.items))
+	for _, item := range l.items {
+		if strings.Contains(strings.ToLower(item.Title+" "+item.Subtitle+" "+item.ID), filter) {
+			matches = append(matches, item)
+		}
+	}
+	return matches
+}
+
+// selected returns the item under the cursor
+func (l *listView) selected() (listItem, bool) {
+	items := l.visible()
+	if l.cursor < 0 || l.cursor >= len(items) {
+		return listItem{}, false
+	}
+	return items[l.cursor], true
+}
+
+// move moves the cursor and scrolls so that it stays within a page of rows
+func (l *listView) move(delta, pageSize int) {
+	l.cursor += delta
+	l.clamp()
+
+	if pageSize < 1 {
+		pageSize = 1
+	}
+	if l.cursor < l.offset {
+		l.offset = l.cursor
+	} else if l.cursor >= l.offset+pageSize {
+		l.offset = l.cursor - pageSize + 1
+	}
+}
+
+// clamp keeps the cursor and scroll offset within the visible items
+func (l *listView) clamp() {
+	count := len(l.visible())
+	if l.cursor >= count {
+		l.cursor = count - 1
+	}
+	if l.cursor < 0 {
+		l.cursor = 0
+	}
+	if l.offset > l.cursor {
+		l.offset = l.cursor
+	}
+}
+
+// handleFilterKey edits the filter while filtering. It reports whether the key was consumed.
+func (l *listView) handleFilterKey(msg tea.KeyMsg) bool {
+	if !l.filtering {
+		return false
+	}
+
+	switch msg.Type {
+	case tea.KeyEnter:
+		l.filtering = false
+	case tea.KeyEsc:
+		l.filtering = false
+		l.filter = ""
+	case tea.KeyBackspace:
+		if len(l.filter) > 0 {
+			runes := []rune(l.filter)
+			l.filter = string(runes[:len(runes)-1])
+		}
+	case tea.KeySpace:
+		l.filter += " "
+	case tea.KeyRunes:
+		l.filter += string(msg.Runes)
+	default:
+		return true
+	}
+
+	l.cursor, l.offset = 0, 0
+	return true
+}
+
+// render draws the list and the detail pane of the selected item side by side
+func (l *listView) render(width, height int, empty string) string {
+	if l.err != nil {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).PaddingLeft(2).
+			Render(fmt.Sprintf("❌ Failed to load: %v (press r to retry)", l.err))
+	}
+	if l.loading && !l.loaded {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).PaddingLeft(2).Render("⏳ Loading...")
+	}
+
+	var s strings.Builder
+
+	filterStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#3498DB")).PaddingLeft(2)
+	switch {
+	case l.filtering:
+		s.WriteString(filterStyle.Render(fmt.Sprintf("Filter: %s█", l.filter)))
+	case l.filter != "":
+		s.WriteString(filterStyle.Render(fmt.Sprintf("Filter: %s (/ to edit, Esc to clear)", l.filter)))
+	default:
+		s.WriteString(filterStyle.Faint(true).Render("Press / to filter"))
+	}
+	s.WriteString("\n\n")
+
+	items := l.visible()
+	if len(items) == 0 {
+		message := empty
+		if l.filter != "" {
+			message = "No items match the filter."
+		}
+		s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).PaddingLeft(2).Render(message))
+		return s.String()
+	}
+
+	listWidth := width * 2 / 5
+	if listWidth < 24 {
+		listWidth = 24
+	}
+	detailWidth := width - listWidth - 6
+	if detailWidth < 20 {
+		detailWidth = 20
+	}
+
+	var rows strings.Builder
+	end := l.offset + height
+	if end > len(items) {
+		end = len(items)
+	}
+	for i := l.offset; i < end; i++ {
+		item := items[i]
+		style := lipgloss.NewStyle().Width(listWidth).MaxWidth(listWidth)
+		prefix := "  "
+		if i == l.cursor {
+			prefix = "→ "
+			style = style.Foreground(lipgloss.Color("#00FFFF")).Bold(true).Background(lipgloss.Color("#1a1a1a"))
+		}
+		line := prefix + item.Title
+		if item.Subtitle != "" {
+			line += lipgloss.NewStyle().Faint(true).Render("  " + item.Subtitle)
+		}
+		rows.WriteString(style.Render(line))
+		rows.WriteString("\n")
+	}
+	rows.WriteString(lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf("  %d of %d", l.cursor+1, len(items))))
+
+	detail := ""
+	if item, ok := l.selected(); ok {
+		detail = strings.TrimRight(item.Detail, "\n")
+	}
+	if lines := strings.Split(detail, "\n"); len(lines) > height {
+		detail = strings.Join(lines[:height-1], "\n") + "\n…"
+	}
+	detailPane := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#7D56F4")).
+		Padding(0, 1).
+		Width(detailWidth).
+		Render(detail)
+
+	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().PaddingLeft(2).Render(rows.String()),
+		"  ",
+		detailPane,
+	))
+
+	return s.String()
+}
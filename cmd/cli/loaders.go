@@ -0,0 +1,318 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"personal-ai-board/internal/analysis"
+	"personal-ai-board/internal/board"
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/persona"
+	"personal-ai-board/internal/project"
+)
+
+// analysisHistoryLimit caps how many past analyses the analysis view loads
+const analysisHistoryLimit = 50
+
+// appReadyMsg reports that the application services are ready
+type appReadyMsg struct {
+	app *App
+}
+
+// appErrorMsg reports that the application services could not be started
+type appErrorMsg struct {
+	err error
+}
+
+// listLoadedMsg carries the items loaded for a list view
+type listLoadedMsg struct {
+	view  ViewType
+	items []listItem
+	err   error
+}
+
+// bootCmd opens the database and LLM providers without blocking the UI
+func bootCmd(cfg *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		app, err := openTUIApp(cfg)
+		if err != nil {
+			return appErrorMsg{err: err}
+		}
+		return appReadyMsg{app: app}
+	}
+}
+
+// loadListCmd loads the items of a list view in the background
+func loadListCmd(app *App, view ViewType) tea.Cmd {
+	return func() tea.Msg {
+		var items []listItem
+		var err error
+
+		switch view {
+		case ViewPersonas:
+			items, err = loadPersonaItems(app)
+		case ViewBoards:
+			items, err = loadBoardItems(app)
+		case ViewProjects:
+			items, err = loadProjectItems(app)
+		case ViewAnalysis:
+			items, err = loadAnalysisItems(app)
+		default:
+			err = fmt.Errorf("view %s has no list", view)
+		}
+
+		return listLoadedMsg{view: view, items: items, err: err}
+	}
+}
+
+// loadPersonaItems lists personas with their personality profile as detail
+func loadPersonaItems(app *App) ([]listItem, error) {
+	storage := persona.NewStorage(app.DB.DB)
+	infos, err := storage.ListPersonas()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]listItem, 0, len(infos))
+	for _, info := range infos {
+		item := listItem{ID: info.ID, Title: info.Name, Subtitle: info.Description}
+
+		p, err := storage.LoadPersona(info.ID, nil, app.Logger)
+		if err != nil {
+			item.Detail = fmt.Sprintf("Failed to load persona: %v", err)
+		} else {
+			item.Subtitle = p.Traits.Config.PersonaType
+			item.Detail = personaDetail(p)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// loadBoardItems lists boards with their members as detail
+func loadBoardItems(app *App) ([]listItem, error) {
+	storage := board.NewStorage(app.DB.DB)
+	infos, err := storage.ListBoards()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]listItem, 0, len(infos))
+	for _, info := range infos {
+		item := listItem{
+			ID:       info.ID,
+			Title:    info.Name,
+			Subtitle: fmt.Sprintf("%d persona(s)", info.PersonaCount),
+		}
+
+		b, err := storage.LoadBoard(info.ID)
+		if err != nil {
+			item.Detail = fmt.Sprintf("Failed to load board: %v", err)
+		} else {
+			item.Detail = boardDetail(b)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// loadProjectItems lists projects with their ideas as detail
+func loadProjectItems(app *App) ([]listItem, error) {
+	storage := project.NewStorage(app.DB.DB)
+	infos, err := storage.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]listItem, 0, len(infos))
+	for _, info := range infos {
+		item := listItem{
+			ID:       info.ID,
+			Title:    info.Name,
+			Subtitle: fmt.Sprintf("%s, %d idea(s)", info.Status, info.IdeaCount),
+		}
+
+		p, err := storage.LoadProject(info.ID)
+		if err != nil {
+			item.Detail = fmt.Sprintf("Failed to load project: %v", err)
+		} else {
+			item.Detail = projectDetail(p)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// loadAnalysisItems lists recent analysis results with their responses as detail
+func loadAnalysisItems(app *App) ([]listItem, error) {
+	storage := analysis.NewStorage(app.DB.DB)
+	infos, err := storage.ListResults(analysisHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]listItem, 0, len(infos))
+	for _, info := range infos {
+		title := info.Topic
+		if title == "" {
+			title = info.Mode + " analysis"
+		}
+		item := listItem{
+			ID:       info.ID,
+			Title:    title,
+			Subtitle: fmt.Sprintf("%s · %s · %s", info.BoardName, info.Mode, info.Status),
+		}
+
+		result, err := storage.LoadResult(info.ID)
+		if err != nil {
+			item.Detail = fmt.Sprintf("Failed to load analysis: %v", err)
+		} else {
+			item.Detail = analysisDetail(result, info)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// personaDetail formats a persona's profile for the detail pane
+func personaDetail(p *persona.Persona) string {
+	profile := p.GetPersonalityProfile()
+
+	var s strings.Builder
+	fmt.Fprintf(&s, "%s\n%s\n\n", p.Name, p.Description)
+	fmt.Fprintf(&s, "ID:       %s\n", p.ID)
+	fmt.Fprintf(&s, "Type:     %s\n", p.Traits.Config.PersonaType)
+	if len(p.Traits.Lineage) > 0 {
+		fmt.Fprintf(&s, "Lineage:  %s\n", strings.Join(p.Traits.Lineage, " → "))
+	}
+	if updated, ok := profile["updated_at"].(time.Time); ok && !updated.IsZero() {
+		fmt.Fprintf(&s, "Updated:  %s\n", updated.Format("2006-01-02 15:04"))
+	}
+
+	writeSection(&s, "Core traits", profile["core_traits"])
+	writeSection(&s, "Communication", profile["communication_style"])
+
+	if len(p.Traits.ExpertiseAreas) > 0 {
+		fmt.Fprintf(&s, "\nExpertise\n  %s\n", strings.Join(p.Traits.ExpertiseAreas, ", "))
+	}
+	writeSection(&s, "Memory", profile["memory_stats"])
+
+	return s.String()
+}
+
+// boardDetail formats a board and its members for the detail pane
+func boardDetail(b *board.Board) string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "%s\n%s\n\n", b.Name, b.Description)
+	fmt.Fprintf(&s, "ID:       %s\n", b.ID)
+	fmt.Fprintf(&s, "Template: %t\n", b.IsTemplate)
+	fmt.Fprintf(&s, "Updated:  %s\n", b.UpdatedAt.Format("2006-01-02 15:04"))
+
+	s.WriteString("\nMembers\n")
+	if len(b.Members) == 0 {
+		s.WriteString("  (none)\n")
+	}
+	for _, member := range b.Members {
+		name := member.PersonaName
+		if name == "" {
+			name = member.PersonaID + " (missing)"
+		}
+		if member.Role != "" {
+			fmt.Fprintf(&s, "  %d. %s - %s\n", member.Position+1, name, member.Role)
+		} else {
+			fmt.Fprintf(&s, "  %d. %s\n", member.Position+1, name)
+		}
+	}
+
+	return s.String()
+}
+
+// projectDetail formats a project and its ideas for the detail pane
+func projectDetail(p *project.Project) string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "%s\n%s\n\n", p.Name, p.Description)
+	fmt.Fprintf(&s, "ID:       %s\n", p.ID)
+	fmt.Fprintf(&s, "Status:   %s\n", p.Status)
+	fmt.Fprintf(&s, "Updated:  %s\n", p.UpdatedAt.Format("2006-01-02 15:04"))
+
+	s.WriteString("\nIdeas\n")
+	if len(p.Ideas) == 0 {
+		s.WriteString("  (none)\n")
+	}
+	for _, idea := range p.Ideas {
+		fmt.Fprintf(&s, "  • %s [%s, priority %d]\n", idea.Title, idea.Status, idea.Priority)
+	}
+
+	return s.String()
+}
+
+// analysisDetail formats an analysis result for the detail pane
+func analysisDetail(result *analysis.Result, info analysis.ResultInfo) string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "%s\n\n", firstNonEmpty(result.Topic, result.Mode+" analysis"))
+	fmt.Fprintf(&s, "Project:  %s\n", firstNonEmpty(info.ProjectName, result.ProjectID))
+	fmt.Fprintf(&s, "Board:    %s\n", firstNonEmpty(info.BoardName, result.BoardID))
+	fmt.Fprintf(&s, "Mode:     %s\n", result.Mode)
+	fmt.Fprintf(&s, "Status:   %s\n", result.Status)
+	fmt.Fprintf(&s, "Started:  %s (%s)\n", result.StartedAt.Format("2006-01-02 15:04"), result.Duration.Round(time.Second))
+	fmt.Fprintf(&s, "Tokens:   %d ($%.4f)\n", result.Metrics.TotalTokens, result.Metrics.CostUSD)
+	if result.Error != "" {
+		fmt.Fprintf(&s, "Error:    %s\n", result.Error)
+	}
+
+	if result.Summary != "" {
+		fmt.Fprintf(&s, "\nSummary\n  %s\n", result.Summary)
+	}
+	if len(result.Insights) > 0 {
+		s.WriteString("\nInsights\n")
+		for _, insight := range result.Insights {
+			fmt.Fprintf(&s, "  • %s\n", insight)
+		}
+	}
+	if len(result.Responses) > 0 {
+		s.WriteString("\nResponses\n")
+		for _, resp := range result.Responses {
+			fmt.Fprintf(&s, "  [round %d] %s (%.0f%%, %s)\n    %s\n",
+				resp.Round, resp.PersonaName, resp.Confidence*100, resp.EmotionalTone, truncate(resp.Content, 200))
+		}
+	}
+
+	return s.String()
+}
+
+// writeSection writes a titled, sorted key/value section from a profile map
+func writeSection(s *strings.Builder, title string, value interface{}) {
+	values, ok := value.(map[string]interface{})
+	if !ok || len(values) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(s, "\n%s\n", title)
+	for _, key := range keys {
+		fmt.Fprintf(s, "  %-22s %v\n", key, values[key])
+	}
+}
+
+// truncate shortens text to at most n runes, collapsing whitespace
+func truncate(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n-1]) + "…"
+}
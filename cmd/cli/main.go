@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/persona"
 )
 
 const version = "1.0.0-dev"
@@ -20,6 +24,9 @@ const (
 	ViewBoards   ViewType = "boards"
 	ViewProjects ViewType = "projects"
 	ViewAnalysis ViewType = "analysis"
+	ViewRun      ViewType = "run"
+	ViewChat     ViewType = "chat"
+	ViewSearch   ViewType = "search"
 	ViewSettings ViewType = "settings"
 	ViewHelp     ViewType = "help"
 )
@@ -42,6 +49,16 @@ type Model struct {
 	items       []MenuItem
 	statusMsg   string
 	errorMsg    string
+
+	cfg            *config.Config
+	app            *App
+	lists          map[ViewType]*listView
+	run            *runView
+	chat           *chatView
+	settings       *settingsView
+	search         *searchView
+	stopBackground context.CancelFunc
+	reloads        <-chan reloadMsg
 }
 
 // StatusMsg represents a status message
@@ -49,8 +66,13 @@ type StatusMsg string
 
 func main() {
 	// Handle command line arguments
-	if len(os.Args) > 1 {
-		switch os.Args[1] {
+	args, err := parseGlobalOptions(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	if len(args) > 0 {
+		switch args[0] {
 		case "--version", "-v":
 			fmt.Printf("Personal AI Advisory Board v%s\n", version)
 			return
@@ -58,24 +80,49 @@ func main() {
 			printHelp()
 			return
 		}
+
+		if handled, code := runSubcommand(args); handled {
+			os.Exit(code)
+		}
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading configuration: %v\n", err)
+		os.Exit(1)
 	}
+	configureMemory(cfg)
 
-	model := NewModel()
+	model := NewModel(cfg)
 	program := tea.NewProgram(model, tea.WithAltScreen())
 
-	if _, err := program.Run(); err != nil {
+	final, err := program.Run()
+	if m, ok := final.(*Model); ok {
+		m.shutdown()
+	}
+	if err != nil {
 		fmt.Printf("Error running CLI: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// configureMemory applies the configured memory tier limits to new personas
+func configureMemory(cfg *config.Config) {
+	persona.SetDefaultMemorySettings(persona.MemorySettings{
+		ShortTermLimit: cfg.Memory.ShortTermLimit,
+		LongTermLimit:  cfg.Memory.LongTermLimit,
+		DecayRate:      cfg.Memory.DecayRate,
+	})
+}
+
 // NewModel creates a new application model
-func NewModel() *Model {
+func NewModel(cfg *config.Config) *Model {
 	menuItems := []MenuItem{
 		{"Manage Personas", "Create, edit, and manage AI personas for your advisory board", "personas", "👥"},
 		{"Manage Boards", "Create and configure advisory boards with different personas", "boards", "🏛️"},
 		{"Manage Projects", "Create and manage projects with ideas and documents", "projects", "📁"},
 		{"Run Analysis", "Analyze ideas and projects with your advisory boards", "analysis", "🔍"},
+		{"Search", "Search personas, ideas, documents and past analyses", "search", "🔎"},
 		{"Settings", "Configure application settings and preferences", "settings", "⚙️"},
 		{"Help", "View help, documentation, and usage guides", "help", "❓"},
 		{"Quit", "Exit the Personal AI Advisory Board application", "quit", "🚪"},
@@ -85,12 +132,54 @@ func NewModel() *Model {
 		currentView: ViewMenu,
 		cursor:      0,
 		items:       menuItems,
+		cfg:         cfg,
+		lists: map[ViewType]*listView{
+			ViewPersonas: newListView(),
+			ViewBoards:   newListView(),
+			ViewProjects: newListView(),
+			ViewAnalysis: newListView(),
+		},
+		run:      newRunView(cfg.Analysis.DefaultMode),
+		chat:     newChatView(),
+		settings: newSettingsView(),
+		search:   newSearchView(),
 	}
 }
 
 // Init implements tea.Model
 func (m *Model) Init() tea.Cmd {
-	return tea.EnterAltScreen
+	return tea.Batch(tea.EnterAltScreen, bootCmd(m.cfg))
+}
+
+// shutdown stops background jobs and releases the application's resources
+func (m *Model) shutdown() {
+	m.run.release()
+	m.chat.release()
+	m.untrackChat()
+	if m.stopBackground != nil {
+		m.stopBackground()
+	}
+	if m.app != nil {
+		m.app.Close()
+	}
+}
+
+// loadList starts loading the current list view if the application is ready
+func (m *Model) loadList(view ViewType) tea.Cmd {
+	list, ok := m.lists[view]
+	if !ok || m.app == nil {
+		return nil
+	}
+	list.loading = true
+	return loadListCmd(m.app, view)
+}
+
+// listPageSize returns how many list rows fit on screen
+func (m *Model) listPageSize() int {
+	if rows := m.height - 16; rows > 3 {
+		return rows
+	}
+	return 3
 }
 
 // Update implements tea.Model
@@ -107,6 +196,97 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.errorMsg = ""
 		return m, nil
 
+	case appReadyMsg:
+		m.app = msg.app
+		ctx, cancel := context.WithCancel(context.Background())
+		m.stopBackground = cancel
+		m.app.startRetention(ctx)
+		m.app.startSnapshots(ctx)
+		m.app.startWebhooks(ctx)
+		m.reloads = m.app.startReload(ctx)
+		m.statusMsg = fmt.Sprintf("✓ Connected to %s", m.cfg.Database.Path)
+		return m, tea.Batch(m.loadList(m.currentView), waitForReloadCmd(m.reloads))
+
+	case reloadMsg:
+		if msg.config != nil {
+			*m.cfg = *msg.config
+			configureMemory(m.cfg)
+		}
+		if len(msg.errs) > 0 {
+			m.errorMsg = joinErrors(msg.errs)
+		} else if msg.status != "" {
+			m.errorMsg = ""
+		}
+		if msg.status != "" {
+			m.statusMsg = msg.status
+		}
+		return m, waitForReloadCmd(m.reloads)
+
+	case appErrorMsg:
+		m.errorMsg = fmt.Sprintf("Failed to start: %v", msg.err)
+		return m, nil
+
+	case listLoadedMsg:
+		if list, ok := m.lists[msg.view]; ok {
+			if msg.err != nil {
+				list.loading = false
+				list.err = msg.err
+			} else {
+				list.setItems(msg.items)
+			}
+		}
+		return m, nil
+
+	case runOptionsMsg:
+		m.run.setOptions(msg)
+		return m, nil
+
+	case analysisEventMsg:
+		return m, m.run.handleEvent(msg.event)
+
+	case analysisDoneMsg:
+		m.run.handleDone(msg)
+		return m, nil
+
+	case settingSavedMsg:
+		m.settings.handleSaved(msg, m.cfg)
+		if msg.err == nil {
+			*m.cfg = *msg.config
+			configureMemory(m.cfg)
+			if _, errs := m.app.LLM.ApplyConfig(m.cfg); len(errs) > 0 {
+				m.errorMsg = joinErrors(errs)
+			}
+		}
+		return m, nil
+
+	case searchResultsMsg:
+		m.search.handleResults(msg)
+		return m, nil
+
+	case chatOpenedMsg:
+		m.chat.opened(msg)
+		if msg.session != nil {
+			m.app.Traits.Track(msg.session.Persona)
+		}
+		return m, nil
+
+	case chatChunkMsg:
+		return m, m.chat.handleChunk(msg)
+
+	case chatReplyMsg:
+		m.chat.handleReply(msg)
+		return m, nil
+
+	case chatSavedMsg:
+		m.chat.handleSaved(msg)
+		return m, nil
+
+	case analysisTickMsg:
+		if m.run.running() {
+			return m, tickCmd()
+		}
+		return m, nil
+
 	case tea.KeyMsg:
 		return m.handleKeyMsg(msg)
 	}
@@ -116,10 +296,88 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // handleKeyMsg handles keyboard input
 func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c":
+	if msg.String() == "ctrl+c" {
 		return m, tea.Quit
+	}
 
+	if m.currentView == ViewRun && m.app != nil {
+		if cmd, handled := m.run.handleKey(msg, m.app); handled {
+			return m, cmd
+		}
+		switch msg.String() {
+		case "esc", "q":
+			return m.navigateToView(ViewAnalysis)
+		}
+	}
+
+	if m.currentView == ViewSettings {
+		if cmd, handled := m.settings.handleKey(msg, m.cfg, m.app); handled {
+			return m, cmd
+		}
+	}
+
+	if m.currentView == ViewSearch {
+		if cmd, handled := m.search.handleKey(msg, m.app); handled {
+			return m, cmd
+		}
+	}
+
+	if m.currentView == ViewChat {
+		if cmd, handled := m.chat.handleKey(msg, m.app); handled {
+			return m, cmd
+		}
+		if msg.String() == "esc" {
+			m.chat.release()
+			return m.navigateToView(ViewPersonas)
+		}
+		return m, nil
+	}
+
+	list := m.lists[m.currentView]
+	if list != nil {
+		if list.handleFilterKey(msg) {
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "/":
+			list.filtering = true
+			return m, nil
+		case "esc":
+			if list.filter != "" {
+				list.filter = ""
+				list.clamp()
+				return m, nil
+			}
+		case "r":
+			return m, m.loadList(m.currentView)
+		case "n":
+			if m.currentView == ViewAnalysis {
+				return m.openRun()
+			}
+		case "c":
+			if m.currentView == ViewPersonas {
+				if item, ok := list.selected(); ok {
+					return m.openChat(item.ID)
+				}
+				return m, nil
+			}
+		case "pgdown":
+			list.move(m.listPageSize(), m.listPageSize())
+			return m, nil
+		case "pgup":
+			list.move(-m.listPageSize(), m.listPageSize())
+			return m, nil
+		case "g", "home":
+			list.move(-len(list.items), m.listPageSize())
+			return m, nil
+		case "G", "end":
+			list.move(len(list.items), m.listPageSize())
+			return m, nil
+		}
+	}
+
+	switch msg.String() {
 	case "q":
 		if m.currentView == ViewMenu {
 			return m, tea.Quit
@@ -165,7 +423,11 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.currentView == ViewMenu {
 			return m.navigateToView(ViewAnalysis)
 		}
-	case "5":
+	case "5", "/":
+		if m.currentView == ViewMenu {
+			return m.navigateToView(ViewSearch)
+		}
+	case "6":
 		if m.currentView == ViewMenu {
 			return m.navigateToView(ViewSettings)
 		}
@@ -180,6 +442,11 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // moveCursor moves the cursor up or down
 func (m *Model) moveCursor(direction int) (tea.Model, tea.Cmd) {
+	if list, ok := m.lists[m.currentView]; ok {
+		list.move(direction, m.listPageSize())
+		return m, nil
+	}
+
 	if m.currentView == ViewMenu {
 		m.cursor += direction
 		if m.cursor < 0 {
@@ -197,7 +464,39 @@ func (m *Model) navigateToView(view ViewType) (tea.Model, tea.Cmd) {
 	m.cursor = 0
 	m.statusMsg = ""
 	m.errorMsg = ""
-	return m, nil
+	return m, m.loadList(view)
+}
+
+// openRun shows the analysis setup form, or the dashboard of a running analysis
+func (m *Model) openRun() (tea.Model, tea.Cmd) {
+	m.currentView = ViewRun
+	m.statusMsg = ""
+	m.errorMsg = ""
+	if m.run.running() || m.app == nil {
+		return m, nil
+	}
+
+	m.run.stage = runSetup
+	m.run.err = nil
+	m.run.loading = true
+	return m, loadRunOptionsCmd(m.app)
+}
+
+// openChat starts a conversation with a persona
+func (m *Model) openChat(personaID string) (tea.Model, tea.Cmd) {
+	m.currentView = ViewChat
+	m.statusMsg = ""
+	m.errorMsg = ""
+	m.untrackChat()
+	m.chat.reset()
+	return m, openChatCmd(m.app, personaID)
+}
+
+// untrackChat stops reloading the traits of the chat's persona
+func (m *Model) untrackChat() {
+	if m.app != nil && m.chat.session != nil {
+		m.app.Traits.Untrack(m.chat.session.Persona)
+	}
 }
 
 // handleSelection handles item selection
@@ -214,6 +513,8 @@ func (m *Model) handleSelection() (tea.Model, tea.Cmd) {
 				return m.navigateToView(ViewProjects)
 			case "analysis":
 				return m.navigateToView(ViewAnalysis)
+			case "search":
+				return m.navigateToView(ViewSearch)
 			case "settings":
 				return m.navigateToView(ViewSettings)
 			case "help":
@@ -222,6 +523,8 @@ func (m *Model) handleSelection() (tea.Model, tea.Cmd) {
 				return m, tea.Quit
 			}
 		}
+	} else if _, isList := m.lists[m.currentView]; isList {
+		return m, nil
 	} else {
 		// Handle selections in other views
 		return m, func() tea.Msg {
@@ -250,6 +553,12 @@ func (m *Model) View() string {
 		content = m.renderProjectsView()
 	case ViewAnalysis:
 		content = m.renderAnalysisView()
+	case ViewRun:
+		content = m.renderRunView()
+	case ViewChat:
+		content = m.renderChatView()
+	case ViewSearch:
+		content = m.renderSearchView()
 	case ViewSettings:
 		content = m.renderSettingsView()
 	case ViewHelp:
@@ -355,72 +664,145 @@ func (m *Model) renderMainMenu() string {
 
 // renderPersonasView renders the personas management view
 func (m *Model) renderPersonasView() string {
-	return m.renderSimpleView("👥 Personas", "🏠 Home > 👥 Personas",
-		"Manage your AI personas. Each persona has unique traits, expertise, and communication styles.",
-		[]string{
-			"✨ Create New Persona - Design a custom AI persona with unique traits",
-			"📋 List All Personas - View your complete persona collection",
-			"✏️ Edit Persona - Modify existing persona traits and characteristics",
-			"🗑️ Delete Persona - Remove a persona from your collection",
-			"📤 Export Personas - Backup your personas to a file",
-			"📥 Import Personas - Restore personas from a backup file",
-		})
+	return m.renderListView(ViewPersonas, "🏠 Home > 👥 Personas",
+		"Your AI personas. Each persona has unique traits, expertise, and communication styles. Press c to chat with one.",
+		"No personas yet. Create one with: personal-ai-board persona create")
 }
 
 // renderBoardsView renders the boards management view
 func (m *Model) renderBoardsView() string {
-	return m.renderSimpleView("🏛️ Boards", "🏠 Home > 🏛️ Boards",
-		"Manage your advisory boards. Combine personas to create diverse expert panels.",
-		[]string{
-			"🆕 Create New Board - Assemble a new advisory board from your personas",
-			"📑 List All Boards - View your complete board collection",
-			"📋 Board Templates - Use pre-designed board templates (Executive, Technical, Creative)",
-			"⚙️ Edit Board - Modify board composition and settings",
-			"🧪 Test Board - Simulate board discussions with sample topics",
-			"📊 Board Analytics - View board performance and insights",
-		})
+	return m.renderListView(ViewBoards, "🏠 Home > 🏛️ Boards",
+		"Your advisory boards. Combine personas to create diverse expert panels.",
+		"No boards yet.")
 }
 
 // renderProjectsView renders the projects management view
 func (m *Model) renderProjectsView() string {
-	return m.renderSimpleView("📁 Projects", "🏠 Home > 📁 Projects",
-		"Organize your ideas into projects and collaborate with your AI advisory board.",
-		[]string{
-			"🆕 Create Project - Start a new project with goals and objectives",
-			"📂 List Projects - View all your active and completed projects",
-			"💡 Add Ideas - Brainstorm and capture new ideas within projects",
-			"📄 Add Documents - Upload supporting documents, images, and files",
-			"📈 Project Analytics - Track progress, milestones, and insights",
-			"🗂️ Archive Project - Move completed projects to archive",
-		})
+	return m.renderListView(ViewProjects, "🏠 Home > 📁 Projects",
+		"Your projects, with the ideas you are working on.",
+		"No projects yet.")
 }
 
 // renderAnalysisView renders the analysis view
 func (m *Model) renderAnalysisView() string {
-	return m.renderSimpleView("🔍 Analysis", "🏠 Home > 🔍 Analysis",
-		"Run different types of analysis with your advisory boards to gain deep insights.",
-		[]string{
-			"💬 Discussion Mode - Interactive debate and collaborative exploration",
-			"🎭 Simulation Mode - Model scenarios and predict potential outcomes",
-			"📊 Analysis Mode - Deep analytical breakdown with data-driven insights",
-			"⚖️ Comparison Mode - Compare multiple options side-by-side",
-			"🏆 Evaluation Mode - Score and rank alternatives systematically",
-			"🔮 Prediction Mode - Forecast future trends and possibilities",
-		})
-}
-
-// renderSettingsView renders the settings view
+	return m.renderListView(ViewAnalysis, "🏠 Home > 🔍 Analysis",
+		"Past analysis sessions with your advisory boards. Press n to run a new analysis.",
+		"No analyses have been run yet. Press n to run one.")
+}
+
+// renderRunView renders the analysis setup form or the live dashboard
+func (m *Model) renderRunView() string {
+	var s strings.Builder
+
+	breadcrumbStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888")).
+		PaddingLeft(2).
+		MarginBottom(1)
+	s.WriteString(breadcrumbStyle.Render("🏠 Home > 🔍 Analysis > ▶ Run"))
+	s.WriteString("\n\n")
+
+	if m.app == nil {
+		s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).PaddingLeft(2).Render("⏳ Connecting to the database..."))
+		return s.String()
+	}
+
+	if m.run.stage == runSetup {
+		descStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#CCCCCC")).
+			PaddingLeft(2)
+		s.WriteString(descStyle.Render("Choose a board and a project, then describe what the board should consider."))
+		s.WriteString("\n\n")
+	}
+
+	s.WriteString(m.run.render(m.width, m.height-12))
+	return s.String()
+}
+
+// renderChatView renders a conversation with a persona
+func (m *Model) renderChatView() string {
+	var s strings.Builder
+
+	breadcrumbStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888")).
+		PaddingLeft(2).
+		MarginBottom(1)
+	s.WriteString(breadcrumbStyle.Render("🏠 Home > 👥 Personas > 💬 Chat"))
+	s.WriteString("\n\n")
+
+	s.WriteString(m.chat.render(m.width, m.height-10))
+	return s.String()
+}
+
+// renderSearchView renders the search box and its results
+func (m *Model) renderSearchView() string {
+	var s strings.Builder
+
+	breadcrumbStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888")).
+		PaddingLeft(2).
+		MarginBottom(1)
+	s.WriteString(breadcrumbStyle.Render("🏠 Home > 🔎 Search"))
+	s.WriteString("\n\n")
+
+	if m.app == nil {
+		s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).PaddingLeft(2).Render("⏳ Connecting to the database..."))
+		return s.String()
+	}
+
+	s.WriteString(m.search.render(m.width, m.height-14))
+	return s.String()
+}
+
+// renderListView renders a data list view with its breadcrumb and description
+func (m *Model) renderListView(view ViewType, breadcrumb, description, empty string) string {
+	var s strings.Builder
+
+	breadcrumbStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888")).
+		PaddingLeft(2).
+		MarginBottom(1)
+	s.WriteString(breadcrumbStyle.Render(breadcrumb))
+	s.WriteString("\n\n")
+
+	descStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#CCCCCC")).
+		PaddingLeft(2)
+	s.WriteString(descStyle.Render(description))
+	s.WriteString("\n\n")
+
+	if m.app == nil {
+		message := "⏳ Connecting to the database..."
+		if m.errorMsg != "" {
+			message = "The database is not available."
+		}
+		s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).PaddingLeft(2).Render(message))
+		return s.String()
+	}
+
+	s.WriteString(m.lists[view].render(m.width, m.listPageSize(), empty))
+	return s.String()
+}
+
+// renderSettingsView renders the editable settings
 func (m *Model) renderSettingsView() string {
-	return m.renderSimpleView("⚙️ Settings", "🏠 Home > ⚙️ Settings",
-		"Configure application settings and preferences to customize your experience.",
-		[]string{
-			"🤖 LLM Providers - Configure AI language model providers (OpenAI, Anthropic, Google)",
-			"🗄️ Database Settings - Manage data storage and backup preferences",
-			"🧠 Memory Settings - Configure persona memory and context retention",
-			"🎨 Interface Themes - Customize colors and visual appearance",
-			"📤 Export Settings - Backup entire configuration for portability",
-			"📥 Import Settings - Restore settings from backup files",
-		})
+	var s strings.Builder
+
+	breadcrumbStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888")).
+		PaddingLeft(2).
+		MarginBottom(1)
+	s.WriteString(breadcrumbStyle.Render("🏠 Home > ⚙️ Settings"))
+	s.WriteString("\n\n")
+
+	descStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#CCCCCC")).
+		PaddingLeft(2).
+		Width(m.width - 2)
+	s.WriteString(descStyle.Render(fmt.Sprintf("Settings marked file are saved to %s. Settings marked database are saved in this database and override the file. Environment variables override both.", m.cfg.FilePath())))
+	s.WriteString("\n\n")
+
+	s.WriteString(m.settings.render(m.cfg, m.width))
+	return s.String()
 }
 
 // renderHelpView renders the help view
@@ -474,7 +856,8 @@ func (m *Model) renderHelpView() string {
 		"↑/↓ or j/k    - Navigate menu items up and down",
 		"Enter/Space   - Select the currently highlighted item",
 		"Esc or q      - Return to main menu from any view",
-		"1-5           - Quick access to main sections from menu",
+		"1-6           - Quick access to main sections from menu",
+		"/             - Search from menu",
 		"h             - Show this help screen from menu",
 		"Ctrl+C        - Force quit the application",
 	}
@@ -508,43 +891,6 @@ func (m *Model) renderHelpView() string {
 	return s.String()
 }
 
-// renderSimpleView renders a simple view with title, breadcrumb, description and items
-func (m *Model) renderSimpleView(title, breadcrumb, description string, items []string) string {
-	var s strings.Builder
-
-	breadcrumbStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#888888")).
-		PaddingLeft(2).
-		MarginBottom(1)
-	s.WriteString(breadcrumbStyle.Render(breadcrumb))
-	s.WriteString("\n\n")
-
-	descStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#CCCCCC")).
-		PaddingLeft(2).
-		MarginBottom(2)
-	s.WriteString(descStyle.Render(description))
-	s.WriteString("\n\n")
-
-	itemStyle := lipgloss.NewStyle().
-		PaddingLeft(4).
-		Foreground(lipgloss.Color("#AAAAAA"))
-
-	for _, item := range items {
-		s.WriteString(itemStyle.Render(item))
-		s.WriteString("\n")
-	}
-
-	s.WriteString("\n")
-	comingSoonStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#F39C12")).
-		PaddingLeft(2).
-		Bold(true)
-	s.WriteString(comingSoonStyle.Render("🚧 Full implementation in progress! Press Enter to see status message."))
-
-	return s.String()
-}
-
 // renderFooter renders the application footer
 func (m *Model) renderFooter() string {
 	var footerParts []string
@@ -585,6 +931,12 @@ func (m *Model) getViewTitle() string {
 		return "📁 Projects"
 	case ViewAnalysis:
 		return "🔍 Analysis"
+	case ViewRun:
+		return "▶ Run Analysis"
+	case ViewChat:
+		return "💬 Chat"
+	case ViewSearch:
+		return "🔎 Search"
 	case ViewSettings:
 		return "⚙️ Settings"
 	case ViewHelp:
@@ -599,7 +951,41 @@ func (m *Model) getNavigationHelp() string {
 	base := "Navigation: ↑/↓ or j/k to move, Enter/Space to select"
 
 	if m.currentView == ViewMenu {
-		return base + ", 1-5 for quick access, q to quit"
+		return base + ", 1-6 for quick access, / to search, q to quit"
+	}
+	if m.currentView == ViewRun {
+		switch m.run.stage {
+		case runSetup:
+			return "Navigation: ↑/↓ or Tab to choose a field, ←/→ to change it, type the topic, Enter to start, Esc to go back"
+		case runActive:
+			return "Analysis running: c to cancel"
+		default:
+			return "Navigation: n for a new analysis, Esc or q to return to the analysis history"
+		}
+	}
+	if m.currentView == ViewChat {
+		if m.chat.sending {
+			return "Esc to cancel the reply"
+		}
+		return "Type a message and press Enter to send, Tab to choose a project, Ctrl+S to save as an idea, Ctrl+D to save as a document, Esc to go back"
+	}
+	if m.currentView == ViewSearch {
+		return "Type to search, ↑/↓ to move, Tab to filter by type, Esc to clear the search or return to menu"
+	}
+	if m.currentView == ViewSettings {
+		if m.settings.editing {
+			return "Type the new value and press Enter to save, Tab to cycle choices, Esc to cancel"
+		}
+		return "Navigation: ↑/↓ or j/k to move, Enter to edit, Esc or q to return to menu"
+	}
+	if m.currentView == ViewPersonas {
+		return "Navigation: ↑/↓ or j/k to move, PgUp/PgDn to scroll, / to filter, c to chat, r to reload, Esc or q to return to menu"
+	}
+	if m.currentView == ViewAnalysis {
+		return "Navigation: ↑/↓ or j/k to move, PgUp/PgDn to scroll, / to filter, n for a new analysis, r to reload, Esc or q to return to menu"
+	}
+	if _, isList := m.lists[m.currentView]; isList {
+		return "Navigation: ↑/↓ or j/k to move, PgUp/PgDn to scroll, / to filter, r to reload, Esc or q to return to menu"
 	}
 	return base + ", Esc or q to return to menu"
 }
@@ -613,16 +999,34 @@ func printHelp() {
 	fmt.Println()
 	fmt.Println("USAGE:")
 	fmt.Println("  personal-ai-board [options]")
+	fmt.Println("  personal-ai-board [options] <command> <subcommand> [flags]")
+	fmt.Println()
+	fmt.Println("COMMANDS:")
+	for _, group := range commandGroups() {
+		if group.Run != nil {
+			fmt.Printf("  %-22s %s\n", group.Name, group.Description)
+		}
+		for _, cmd := range group.Commands {
+			fmt.Printf("  %-22s %s\n", group.Name+" "+cmd.Name, cmd.Description)
+		}
+	}
 	fmt.Println()
 	fmt.Println("OPTIONS:")
+	fmt.Println("  --config FILE     Use FILE as the project configuration file")
+	fmt.Println("  --set KEY=VALUE   Override a configuration value, e.g. --set log.level=debug")
 	fmt.Println("  --version, -v     Show version information")
 	fmt.Println("  --help, -h        Show this help message")
 	fmt.Println()
+	fmt.Println("EXIT CODES:")
+	fmt.Println("  0 success, 1 error, 2 usage error, 3 validation error, 4 not found,")
+	fmt.Println("  5 provider unavailable, 130 cancelled")
+	fmt.Println()
 	fmt.Println("KEYBOARD SHORTCUTS:")
 	fmt.Println("  ↑/↓ or j/k       Navigate menu items")
 	fmt.Println("  Enter/Space      Select current item")
 	fmt.Println("  Esc or q         Return to main menu")
-	fmt.Println("  1-5              Quick access to sections")
+	fmt.Println("  1-6              Quick access to sections")
+	fmt.Println("  /                Search")
 	fmt.Println("  h                Show help")
 	fmt.Println("  Ctrl+C           Force quit")
 	fmt.Println()
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by --output
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// addOutputFlag registers --output and its -o shorthand on a command's flags
+func addOutputFlag(flags *flag.FlagSet) *string {
+	format := flags.String("output", outputTable, "output format: table, json or yaml")
+	flags.StringVar(format, "o", outputTable, "shorthand for --output")
+	return format
+}
+
+// checkOutputFormat validates an --output value
+func checkOutputFormat(format string) error {
+	switch format {
+	case outputTable, outputJSON, outputYAML:
+		return nil
+	}
+	return fmt.Errorf("invalid output format %q (must be one of: table, json, yaml)", format)
+}
+
+// parseCommandFlags parses flags that may appear before, between or after
+// positional arguments and returns the positional arguments
+func parseCommandFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	positional := make([]string, 0)
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+
+		args = flags.Args()
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// writeOutput prints value as JSON or YAML, or calls table to print it as a
+// table for the table format
+func writeOutput(w io.Writer, format string, value interface{}, table func(tw *tabwriter.Writer)) error {
+	switch format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+
+	case outputYAML:
+		// Round-trip through JSON so YAML keys match the JSON field names
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+		var generic interface{}
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+		out, err := yaml.Marshal(generic)
+		if err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+		_, err = w.Write(out)
+		return err
+
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		table(tw)
+		return tw.Flush()
+	}
+}
+
+// printOutput writes value to stdout and returns the command's exit code
+func printOutput(format string, value interface{}, table func(tw *tabwriter.Writer)) int {
+	if err := writeOutput(os.Stdout, format, value, table); err != nil {
+		return reportError(err)
+	}
+	return exitOK
+}
+
+// tableRow writes tab separated cells as one table row
+func tableRow(tw *tabwriter.Writer, cells ...interface{}) {
+	parts := make([]string, len(cells))
+	for i, cell := range cells {
+		parts[i] = fmt.Sprint(cell)
+	}
+	fmt.Fprintln(tw, strings.Join(parts, "\t"))
+}
+
+// exitCodeFor maps an error to the exit code that describes it
+func exitCodeFor(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, os.ErrNotExist):
+		return exitNotFound
+	case errors.Is(err, context.Canceled):
+		return exitCancelled
+	default:
+		return exitError
+	}
+}
+
+// reportError prints an error to stderr and returns its exit code
+func reportError(err error) int {
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Fprintf(os.Stderr, "Error: not found: %v\n", err)
+	} else {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	return exitCodeFor(err)
+}
+
+// argumentError prints an invalid argument to stderr and returns exitUsage
+func argumentError(err error) int {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	return exitUsage
+}
+
+// usageError prints a usage problem to stderr and returns exitUsage
+func usageError(format string, args ...interface{}) int {
+	fmt.Fprintf(os.Stderr, "Usage: "+format+"\n", args...)
+	return exitUsage
+}
@@ -0,0 +1,510 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/persona"
+	"personal-ai-board/internal/webhook"
+)
+
+// runPersonaList prints every saved persona
+func runPersonaList(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("persona list", flag.ContinueOnError)
+	format := addOutputFlag(flags)
+	if _, err := parseCommandFlags(flags, args); err != nil {
+		return exitUsage
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	personas, err := persona.NewStorage(app.DB.DB).ListPersonas()
+	if err != nil {
+		return reportError(err)
+	}
+
+	return printOutput(*format, personas, func(tw *tabwriter.Writer) {
+		tableRow(tw, "ID", "NAME", "UPDATED", "DESCRIPTION")
+		for _, p := range personas {
+			tableRow(tw, p.ID, p.Name, p.UpdatedAt.Format("2006-01-02 15:04"), truncate(p.Description, 60))
+		}
+	})
+}
+
+// runPersonaShow prints a persona's personality profile
+func runPersonaShow(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("persona show", flag.ContinueOnError)
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board persona show PERSONA_ID")
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	p, err := persona.NewStorage(app.DB.DB).LoadPersona(positional[0], nil, app.Logger)
+	if err != nil {
+		return reportError(err)
+	}
+
+	return printOutput(*format, p.GetPersonalityProfile(), func(tw *tabwriter.Writer) {
+		fmt.Fprint(tw, personaDetail(p))
+	})
+}
+
+// runPersonaClone saves a copy of a persona with slightly varied traits
+func runPersonaClone(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("persona clone", flag.ContinueOnError)
+	name := flags.String("name", "", "name of the new persona (default: \"<name> (copy)\")")
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board persona clone PERSONA_ID [--name NAME]")
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	storage := persona.NewStorage(app.DB.DB)
+	original, err := storage.LoadPersona(positional[0], nil, app.Logger)
+	if err != nil {
+		return reportError(err)
+	}
+
+	clone, err := original.Clone(generateID("persona"), firstNonEmpty(*name, original.Name+" (copy)"))
+	if err != nil {
+		return reportError(err)
+	}
+	if err := storage.SavePersona(clone); err != nil {
+		return reportError(err)
+	}
+	app.notifyWebhooks(webhook.EventPersonaCreated, webhook.NewPersonaData(clone))
+	app.deliverWebhooks()
+
+	return printOutput(*format, clone.GetPersonalityProfile(), func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "✓ Cloned %q as %q (%s)\n", original.Name, clone.Name, clone.ID)
+	})
+}
+
+// runPersonaDelete removes a persona
+func runPersonaDelete(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("persona delete", flag.ContinueOnError)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board persona delete PERSONA_ID")
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	storage := persona.NewStorage(app.DB.DB)
+	p, err := storage.LoadPersona(positional[0], nil, app.Logger)
+	if err != nil {
+		return reportError(err)
+	}
+	if err := storage.DeletePersona(p.ID); err != nil {
+		return reportError(err)
+	}
+
+	fmt.Printf("✓ Deleted persona %q (%s)\n", p.Name, p.ID)
+	return exitOK
+}
+
+// runPersonaCreate walks the user through every trait defined in base.json
+// and saves the resulting persona
+func runPersonaCreate(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("persona create", flag.ContinueOnError)
+	from := flags.String("from", "", "trait file, or the name of one in config/traits, to use as a starting point")
+	name := flags.String("name", "", "persona name")
+	description := flags.String("description", "", "persona description")
+	traitsDir := flags.String("traits-dir", defaultTraitsDir, "directory containing traits/base.json")
+	useDefaults := flags.Bool("defaults", false, "accept default values without prompting for each trait")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	loader := persona.NewTraitLoader(*traitsDir)
+	fields, err := loader.TraitFields()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading trait definitions: %v\n", err)
+		return exitError
+	}
+
+	draft := &persona.PersonalityConfig{Extends: "base"}
+	if *from != "" {
+		template, err := loadTemplate(loader, *from)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading template: %v\n", err)
+			return exitError
+		}
+		draft = template
+	}
+
+	prompter := newPrompter(os.Stdin, os.Stdout)
+
+	draft.Name = prompter.askString("Name", firstNonEmpty(*name, draft.Name))
+	if strings.TrimSpace(draft.Name) == "" {
+		fmt.Fprintln(os.Stderr, "A persona name is required")
+		return exitValidation
+	}
+	draft.Description = prompter.askString("Description", firstNonEmpty(*description, draft.Description))
+	draft.PersonaType = prompter.askString("Persona type", firstNonEmpty(draft.PersonaType, "custom"))
+
+	expertise := prompter.askString("Expertise areas (comma separated)", strings.Join(draft.ExpertiseAreas, ", "))
+	draft.ExpertiseAreas = splitList(expertise)
+
+	if !*useDefaults {
+		if err := promptTraits(prompter, loader, fields, draft); err != nil {
+			fmt.Fprintf(os.Stderr, "\nAborted: %v\n", err)
+			return exitError
+		}
+	}
+
+	traits, err := loader.BuildTraits(draft)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Persona is not valid: %v\n", err)
+		return exitValidation
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+	defer app.Close()
+
+	storage := persona.NewStorage(app.DB.DB)
+	p, err := persona.NewWithTraits(generateID("persona"), draft.Name, draft.Description, traits, storage, nil, app.Logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating persona: %v\n", err)
+		return exitError
+	}
+
+	if err := storage.SavePersona(p); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving persona: %v\n", err)
+		return exitError
+	}
+	app.notifyWebhooks(webhook.EventPersonaCreated, webhook.NewPersonaData(p))
+	app.deliverWebhooks()
+
+	fmt.Printf("\n✓ Created persona %q (%s)\n", p.Name, p.ID)
+	return exitOK
+}
+
+// loadTemplate resolves the trait file at path, or the one of that name in the
+// traits directory if there is no such file
+func loadTemplate(loader *persona.TraitLoader, path string) (*persona.PersonalityConfig, error) {
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		return loader.ResolveConfigFile(path)
+	}
+	return loader.ResolveConfig(path)
+}
+
+// runPersonaGenerate asks an LLM to fill in a personality from a description,
+// repairs out-of-range values and saves the persona
+func runPersonaGenerate(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("persona generate", flag.ContinueOnError)
+	providerName := flags.String("provider", "", "LLM provider to use (default from config)")
+	model := flags.String("model", "", "model to use (default from provider config)")
+	traitsDir := flags.String("traits-dir", defaultTraitsDir, "directory containing traits/base.json")
+	dryRun := flags.Bool("dry-run", false, "print the generated traits without saving the persona")
+	saveTraits := flags.String("save-traits", "", "also write the generated traits to this file in the traits directory")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	description := strings.TrimSpace(strings.Join(flags.Args(), " "))
+	if description == "" {
+		fmt.Fprintln(os.Stderr, "Usage: personal-ai-board persona generate \"a frugal CFO who has seen three startups fail\"")
+		return exitUsage
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+	defer app.Close()
+
+	provider, err := app.personaProvider(*providerName, *model)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	loader := persona.NewTraitLoader(*traitsDir)
+	generator := persona.NewGenerator(loader, provider, app.Logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*cfg.GetTimeout())
+	defer cancel()
+
+	result, err := generator.Generate(ctx, description)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating persona: %v\n", err)
+		return exitError
+	}
+
+	for _, repair := range result.Repairs {
+		fmt.Printf("  ~ %s\n", repair)
+	}
+
+	data, err := json.MarshalIndent(result.Config, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding traits: %v\n", err)
+		return exitError
+	}
+
+	if *saveTraits != "" {
+		path := filepath.Join(*traitsDir, "traits", filepath.Base(*saveTraits))
+		if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing traits file: %v\n", err)
+			return exitError
+		}
+		fmt.Printf("✓ Wrote traits to %s\n", path)
+	}
+
+	if *dryRun {
+		fmt.Println(string(data))
+		return exitOK
+	}
+
+	storage := persona.NewStorage(app.DB.DB)
+	p, err := persona.NewWithTraits(generateID("persona"), result.Config.Name, result.Config.Description, result.Traits, storage, provider, app.Logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating persona: %v\n", err)
+		return exitError
+	}
+
+	if err := storage.SavePersona(p); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving persona: %v\n", err)
+		return exitError
+	}
+	app.notifyWebhooks(webhook.EventPersonaCreated, webhook.NewPersonaData(p))
+	app.deliverWebhooks()
+
+	fmt.Printf("✓ Created persona %q (%s) with %d repair(s)\n", p.Name, p.ID, len(result.Repairs))
+	return exitOK
+}
+
+// promptTraits asks for a value for every trait field, validating each answer
+// as soon as it is entered and re-asking when a constraint is broken
+func promptTraits(prompter *prompter, loader *persona.TraitLoader, fields []persona.TraitField, draft *persona.PersonalityConfig) error {
+	category := ""
+	for _, field := range fields {
+		if field.Category != category {
+			category = field.Category
+			fmt.Fprintf(prompter.out, "\n== %s ==\n", strings.ReplaceAll(category, "_", " "))
+		}
+
+		current, hasCurrent := lookupDraftValue(draft, field)
+		if !hasCurrent {
+			current = field.Definition.Default
+		}
+
+		for {
+			fmt.Fprintf(prompter.out, "\n%s - %s\n", field.Name, field.Definition.Description)
+			switch field.Definition.Type {
+			case "scale":
+				fmt.Fprintf(prompter.out, "  range %d-%d\n", field.Definition.Range[0], field.Definition.Range[1])
+			case "enum":
+				for i, option := range field.Definition.Options {
+					fmt.Fprintf(prompter.out, "  %d) %s\n", i+1, option)
+				}
+			}
+
+			input, err := prompter.ask(fmt.Sprintf("%s [%v]", field.Name, current))
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(input) == "" {
+				input = fmt.Sprintf("%v", current)
+			}
+
+			value, err := loader.ParseTraitInput(field, input)
+			if err != nil {
+				fmt.Fprintf(prompter.out, "  ✗ %v\n", err)
+				continue
+			}
+
+			if err := draft.SetValue(field.Category, field.Name, value); err != nil {
+				return err
+			}
+
+			if field.Category == "core_dimensions" {
+				partial := &persona.PersonalityTraits{CoreDimensions: draft.CoreDimensions}
+				if violations := loader.CheckConstraints(partial); len(violations) > 0 {
+					for _, violation := range violations {
+						fmt.Fprintf(prompter.out, "  ✗ %v\n", violation)
+					}
+					delete(draft.CoreDimensions, field.Name)
+					continue
+				}
+			}
+			break
+		}
+	}
+
+	return nil
+}
+
+// lookupDraftValue returns the value already set for a field in the draft
+func lookupDraftValue(draft *persona.PersonalityConfig, field persona.TraitField) (persona.TraitValue, bool) {
+	traits := &persona.PersonalityTraits{
+		CoreDimensions:      draft.CoreDimensions,
+		CommunicationStyle:  draft.CommunicationStyle,
+		BiasesAndTendencies: draft.BiasesAndTendencies,
+		ResponsePatterns:    draft.ResponsePatterns,
+		DecisionMaking:      draft.DecisionMaking,
+		TemporalOrientation: draft.TemporalOrientation,
+		LearningStyle:       draft.LearningStyle,
+	}
+	return traits.GetTraitValue(field.Category, field.Name)
+}
+
+// runPersonaLint validates trait files and reports every problem found
+func runPersonaLint(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("persona lint", flag.ContinueOnError)
+	traitsDir := flags.String("traits-dir", defaultTraitsDir, "directory containing the traits folder")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	loader := persona.NewTraitLoader(*traitsDir)
+
+	var issues []persona.LintIssue
+	if flags.NArg() == 0 {
+		all, err := loader.LintAll()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitError
+		}
+		issues = all
+	} else {
+		for _, file := range flags.Args() {
+			fileIssues, err := loader.LintFile(filepath.Base(file))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return exitError
+			}
+			issues = append(issues, fileIssues...)
+		}
+	}
+
+	errors := 0
+	for _, issue := range issues {
+		location := issue.File
+		if issue.Category != "" {
+			location += ": " + issue.Category
+			if issue.Trait != "" {
+				location += "." + issue.Trait
+			}
+		}
+		fmt.Printf("%s: %s: %s\n", location, issue.Severity, issue.Message)
+		if issue.Severity == "error" {
+			errors++
+		}
+	}
+
+	if errors > 0 {
+		fmt.Printf("\n%d error(s), %d warning(s)\n", errors, len(issues)-errors)
+		return exitValidation
+	}
+
+	fmt.Printf("✓ All trait files are valid (%d warning(s))\n", len(issues))
+	return exitOK
+}
+
+// prompter reads answers from a line-oriented input
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// newPrompter creates a prompter over the given streams
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+// ask prints a prompt and returns the trimmed answer
+func (p *prompter) ask(prompt string) (string, error) {
+	fmt.Fprintf(p.out, "%s: ", prompt)
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// askString asks for a value, returning the default on empty input or EOF
+func (p *prompter) askString(prompt, defaultValue string) string {
+	if defaultValue != "" {
+		prompt = fmt.Sprintf("%s [%s]", prompt, defaultValue)
+	}
+	answer, err := p.ask(prompt)
+	if err != nil || answer == "" {
+		return defaultValue
+	}
+	return answer
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// splitList splits a comma separated list, dropping empty entries
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
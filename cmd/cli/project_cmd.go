@@ -0,0 +1,187 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/project"
+)
+
+// runProjectList prints every project
+func runProjectList(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("project list", flag.ContinueOnError)
+	format := addOutputFlag(flags)
+	if _, err := parseCommandFlags(flags, args); err != nil {
+		return exitUsage
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	projects, err := project.NewStorage(app.DB.DB).ListProjects()
+	if err != nil {
+		return reportError(err)
+	}
+
+	return printOutput(*format, projects, func(tw *tabwriter.Writer) {
+		tableRow(tw, "ID", "NAME", "STATUS", "IDEAS", "UPDATED")
+		for _, p := range projects {
+			tableRow(tw, p.ID, p.Name, p.Status, p.IdeaCount, p.UpdatedAt.Format("2006-01-02 15:04"))
+		}
+	})
+}
+
+// runProjectShow prints a project and its ideas
+func runProjectShow(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("project show", flag.ContinueOnError)
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board project show PROJECT_ID")
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	p, err := project.NewStorage(app.DB.DB).LoadProject(positional[0])
+	if err != nil {
+		return reportError(err)
+	}
+
+	return printOutput(*format, p, func(tw *tabwriter.Writer) {
+		fmt.Fprint(tw, projectDetail(p))
+	})
+}
+
+// runProjectCreate creates a project
+func runProjectCreate(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("project create", flag.ContinueOnError)
+	description := flags.String("description", "", "project description")
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) == 0 {
+		return usageError("personal-ai-board project create NAME [--description TEXT]")
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	p, err := project.New(generateID("project"), strings.Join(positional, " "), *description)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitValidation
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	if err := project.NewStorage(app.DB.DB).SaveProject(p); err != nil {
+		return reportError(err)
+	}
+
+	return printOutput(*format, p, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "✓ Created project %q (%s)\n", p.Name, p.ID)
+	})
+}
+
+// runProjectAddIdea adds an idea to a project
+func runProjectAddIdea(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("project add-idea", flag.ContinueOnError)
+	content := flags.String("content", "", "idea details")
+	priority := flags.Int("priority", 0, "idea priority")
+	tags := flags.String("tags", "", "comma separated tags")
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) < 2 {
+		return usageError("personal-ai-board project add-idea PROJECT_ID TITLE [--content TEXT] [--priority N] [--tags a,b]")
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	storage := project.NewStorage(app.DB.DB)
+	p, err := storage.LoadProject(positional[0])
+	if err != nil {
+		return reportError(err)
+	}
+
+	idea, err := project.NewIdea(generateID("idea"), p.ID, strings.Join(positional[1:], " "), *content)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitValidation
+	}
+	idea.Priority = *priority
+	idea.Tags = splitList(*tags)
+
+	if err := storage.SaveIdea(idea); err != nil {
+		return reportError(err)
+	}
+
+	return printOutput(*format, idea, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "✓ Added idea %q to project %q (%s)\n", idea.Title, p.Name, idea.ID)
+	})
+}
+
+// runProjectDelete removes a project and its ideas
+func runProjectDelete(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("project delete", flag.ContinueOnError)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board project delete PROJECT_ID")
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	storage := project.NewStorage(app.DB.DB)
+	p, err := storage.LoadProject(positional[0])
+	if err != nil {
+		return reportError(err)
+	}
+	if err := storage.DeleteProject(p.ID); err != nil {
+		return reportError(err)
+	}
+
+	fmt.Printf("✓ Deleted project %q (%s)\n", p.Name, p.ID)
+	return exitOK
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/llm"
+	"personal-ai-board/internal/llm/catalog"
+	"personal-ai-board/internal/llm/types"
+)
+
+// runProvidersHealth sends a small request to each configured LLM provider
+// and reports which ones respond
+func runProvidersHealth(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("providers health", flag.ContinueOnError)
+	providerName := flags.String("provider", "", "only check this provider")
+	format := addOutputFlag(flags)
+	if _, err := parseCommandFlags(flags, args); err != nil {
+		return exitUsage
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	if len(app.LLM.ListProviders()) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no LLM provider configured; set an API key such as OPENAI_API_KEY")
+		return exitUnhealthy
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.GetTimeout())
+	defer cancel()
+
+	checker := llm.NewHealthChecker(app.LLM, app.Logger)
+	var statuses []types.ProviderStatus
+	if *providerName != "" {
+		statuses = []types.ProviderStatus{checker.CheckProvider(ctx, *providerName)}
+	} else {
+		statuses = checker.CheckAllProviders(ctx)
+	}
+
+	code := printOutput(*format, statuses, func(tw *tabwriter.Writer) {
+		tableRow(tw, "PROVIDER", "STATUS", "MODEL", "ERROR")
+		for _, status := range statuses {
+			state := "ok"
+			if !status.Available {
+				state = "unavailable"
+			}
+			tableRow(tw, status.Name, state, status.Model.Name, truncate(status.Error, 80))
+		}
+	})
+	if code != exitOK {
+		return code
+	}
+
+	for _, status := range statuses {
+		if !status.Available {
+			return exitUnhealthy
+		}
+	}
+	return exitOK
+}
+
+// runProvidersModels lists the model catalog, optionally after asking the
+// configured providers which models they offer
+func runProvidersModels(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("providers models", flag.ContinueOnError)
+	providerName := flags.String("provider", "", "only list this provider's models")
+	refresh := flags.Bool("refresh", false, "add the models the providers offer to the catalog file")
+	format := addOutputFlag(flags)
+	if _, err := parseCommandFlags(flags, args); err != nil {
+		return exitUsage
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	models, err := catalog.Load(cfg.ModelCatalogPath())
+	if err != nil {
+		return reportError(err)
+	}
+
+	if *refresh {
+		app, err := openApp(cfg)
+		if err != nil {
+			return reportError(err)
+		}
+		defer app.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.GetTimeout())
+		defer cancel()
+
+		added, unknown, errs := app.LLM.RefreshCatalog(ctx, models)
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "Warning: cannot list models of %v\n", err)
+		}
+		if len(added) > 0 {
+			if err := catalog.AppendFile(cfg.ModelCatalogPath(), added); err != nil {
+				return reportError(err)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Added %d models to %s\n", len(added), cfg.ModelCatalogPath())
+		providers := make([]string, 0, len(unknown))
+		for provider := range unknown {
+			providers = append(providers, provider)
+		}
+		sort.Strings(providers)
+		for _, provider := range providers {
+			fmt.Fprintf(os.Stderr, "Not in the catalog (%s): %s\n", provider, strings.Join(unknown[provider], ", "))
+		}
+	}
+
+	list := models.Models(*providerName)
+	return printOutput(*format, list, func(tw *tabwriter.Writer) {
+		tableRow(tw, "PROVIDER", "MODEL", "CONTEXT", "MAX OUTPUT", "INPUT $/1K", "OUTPUT $/1K", "CAPABILITIES")
+		for _, model := range list {
+			tableRow(tw, model.Provider, model.Name, model.ContextWindow, model.MaxOutput,
+				formatPrice(model.InputPer1K), formatPrice(model.OutputPer1K), strings.Join(model.Features(), ","))
+		}
+	})
+}
+
+// formatPrice formats a price per 1000 tokens without trailing zeros
+func formatPrice(price float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.6f", price), "0"), ".")
+}
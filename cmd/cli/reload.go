@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/filewatch"
+	"personal-ai-board/pkg/logger"
+)
+
+// defaultTraitsDir is the directory holding traits/base.json
+const defaultTraitsDir = "config"
+
+// reloadMsg reports configuration or trait files that were reloaded, or why
+// they could not be
+type reloadMsg struct {
+	config *config.Config // The new configuration, if it was reloaded
+	status string
+	errs   []error
+}
+
+// waitForReloadCmd waits for the next reload
+func waitForReloadCmd(reloads <-chan reloadMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-reloads
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// startReload watches the configuration files and the trait files until ctx
+// is cancelled, and reports every reload on the returned channel
+func (a *App) startReload(ctx context.Context) <-chan reloadMsg {
+	configFiles := make(map[string]bool)
+	for _, path := range append(config.WatchPaths(globalOptions.configPath), a.Config.ModelCatalogPath()) {
+		configFiles[filepath.Clean(path)] = true
+	}
+
+	watcher := filewatch.NewWatcher(filewatch.DefaultInterval, a.Logger)
+	for path := range configFiles {
+		watcher.Add(path)
+	}
+	watcher.Add(a.Traits.TraitsDir())
+
+	reloads := make(chan reloadMsg)
+	send := func(msg reloadMsg) {
+		select {
+		case reloads <- msg:
+		case <-ctx.Done():
+		}
+	}
+	watcher.Start(ctx, func(changed []string) {
+		for _, path := range changed {
+			if configFiles[filepath.Clean(path)] {
+				send(a.reloadConfig())
+				break
+			}
+		}
+		if msg, ok := a.reloadTraits(changed); ok {
+			send(msg)
+		}
+	})
+	return reloads
+}
+
+// reloadConfig loads the configuration again and rebuilds the LLM providers
+// whose settings changed. The running configuration is kept when the new one
+// is invalid.
+func (a *App) reloadConfig() reloadMsg {
+	cfg, err := loadConfig()
+	if err == nil {
+		var values map[string]string
+		if values, err = a.DB.GetAllSystemConfig(); err == nil {
+			for _, err := range cfg.ApplySystemConfig(values) {
+				a.Logger.Warn("Ignoring invalid database setting", "error", err)
+			}
+			err = cfg.Validate()
+		}
+	}
+	if err != nil {
+		a.Logger.Error("Configuration not reloaded", "error", err)
+		return reloadMsg{errs: []error{fmt.Errorf("configuration not reloaded: %w", err)}}
+	}
+
+	cfg.SecretResolver().Observe(logger.Redact)
+	changed, errs := a.LLM.ApplyConfig(cfg)
+	for _, err := range errs {
+		a.Logger.Warn("LLM provider not available", "error", err)
+	}
+	a.enableReplay(cfg)
+	a.Logger.Info("Configuration reloaded", "providers_changed", changed)
+
+	status := "✓ Reloaded configuration"
+	if len(changed) > 0 {
+		status += "; rebuilt " + strings.Join(changed, ", ")
+	}
+	return reloadMsg{config: cfg, status: status, errs: errs}
+}
+
+// reloadTraits swaps in the traits of loaded personas built from changed
+// trait files. It reports false if none of the files were trait files used
+// by a loaded persona.
+func (a *App) reloadTraits(changed []string) (reloadMsg, bool) {
+	swapped, errs := a.Traits.Reload(changed)
+	for _, err := range errs {
+		a.Logger.Error("Persona traits not reloaded", "error", err)
+	}
+	if len(swapped) == 0 && len(errs) == 0 {
+		return reloadMsg{}, false
+	}
+
+	msg := reloadMsg{errs: make([]error, 0, len(errs))}
+	for _, err := range errs {
+		msg.errs = append(msg.errs, fmt.Errorf("traits not reloaded: %w", err))
+	}
+	if len(swapped) > 0 {
+		msg.status = fmt.Sprintf("✓ Reloaded traits of %s", strings.Join(swapped, ", "))
+	}
+	return msg, true
+}
+
+// joinErrors describes several errors on one line
+func joinErrors(errs []error) string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/search"
+)
+
+// runSearch searches personas, ideas, documents and analyses
+func runSearch(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	projectID := flags.String("project", "", "only results from this project")
+	boardID := flags.String("board", "", "only results from analyses by this board")
+	personaName := flags.String("persona", "", "only results from this persona (ID or start of the name)")
+	kinds := flags.String("type", "", "only these result types: "+strings.Join(search.Kinds, ", "))
+	since := flags.String("since", "", "only results created on or after this date (YYYY-MM-DD or RFC 3339)")
+	until := flags.String("until", "", "only results created on or before this date (YYYY-MM-DD or RFC 3339)")
+	limit := flags.Int("limit", search.DefaultLimit, "maximum number of results")
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) == 0 {
+		return usageError("personal-ai-board search \"query\" [--project ID] [--board ID] [--persona ID|NAME] [--type TYPE,TYPE] [--since DATE] [--until DATE] [--limit N] [--output table|json|yaml]")
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+	if *limit < 1 {
+		return argumentError(fmt.Errorf("--limit must be at least 1"))
+	}
+
+	query := search.Query{
+		Text:      strings.Join(positional, " "),
+		Kinds:     splitList(*kinds),
+		ProjectID: *projectID,
+		BoardID:   *boardID,
+		Persona:   *personaName,
+		Limit:     *limit,
+	}
+	if *since != "" {
+		if query.Since, err = search.ParseDate(*since, false); err != nil {
+			return argumentError(fmt.Errorf("--since: %w", err))
+		}
+	}
+	if *until != "" {
+		if query.Until, err = search.ParseDate(*until, true); err != nil {
+			return argumentError(fmt.Errorf("--until: %w", err))
+		}
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	service := app.searchService()
+	results, err := service.Search(query)
+	if err != nil {
+		return argumentError(err)
+	}
+	if !service.FullText() {
+		fmt.Fprintf(os.Stderr, "Note: %s\n", search.FallbackNotice)
+	}
+	if results == nil {
+		results = []search.Result{}
+	}
+
+	return printOutput(*format, results, func(tw *tabwriter.Writer) {
+		if len(results) == 0 {
+			fmt.Fprintf(tw, "No results for %q\n", query.Text)
+			return
+		}
+		for i, result := range results {
+			if i > 0 {
+				fmt.Fprintln(tw)
+			}
+			fmt.Fprintf(tw, "%d. [%s] %s\n", i+1, result.Kind, resultTitle(result))
+			if context := resultContext(result); context != "" {
+				fmt.Fprintf(tw, "   %s\n", context)
+			}
+			if result.Snippet != "" {
+				fmt.Fprintf(tw, "   %s\n", result.Snippet)
+			}
+		}
+	})
+}
+
+// searchService creates a search service over the application's database
+func (a *App) searchService() *search.Service {
+	return search.NewService(a.DB.DB, a.Logger)
+}
+
+// resultTitle returns the heading of a search result
+func resultTitle(result search.Result) string {
+	switch {
+	case result.Kind == search.KindResponse && result.PersonaName != "" && result.Title != "":
+		return fmt.Sprintf("%s on %q", result.PersonaName, result.Title)
+	case result.Kind == search.KindResponse && result.PersonaName != "":
+		return result.PersonaName
+	case result.Title != "":
+		return result.Title
+	}
+	return "(untitled)"
+}
+
+// resultContext describes where a search result comes from
+func resultContext(result search.Result) string {
+	var parts []string
+	if result.ProjectName != "" {
+		parts = append(parts, "project "+result.ProjectName)
+	} else if result.ProjectID != "" {
+		parts = append(parts, "project "+result.ProjectID)
+	}
+	if result.BoardName != "" {
+		parts = append(parts, "board "+result.BoardName)
+	}
+	if result.PersonaName != "" && result.Kind != search.KindPersona && result.Kind != search.KindResponse {
+		parts = append(parts, "persona "+result.PersonaName)
+	}
+	if !result.CreatedAt.IsZero() {
+		parts = append(parts, result.CreatedAt.Local().Format("2006-01-02"))
+	}
+	parts = append(parts, result.ID)
+	return strings.Join(parts, " · ")
+}
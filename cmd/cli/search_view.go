@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"personal-ai-board/internal/search"
+)
+
+// searchResultsMsg carries the results of a search
+type searchResultsMsg struct {
+	seq      int
+	results  []search.Result
+	fullText bool
+	err      error
+}
+
+// searchView searches as the query is typed and lists the results
+type searchView struct {
+	input   string
+	kind    int
+	seq     int
+	results []search.Result
+	cursor  int
+	loading bool
+	err     error
+
+	// substring is set once a search has fallen back to substring matching
+	substring bool
+}
+
+// newSearchView creates the search view
+func newSearchView() *searchView {
+	return &searchView{}
+}
+
+// searchCmd runs a search in the background
+func searchCmd(app *App, seq int, query search.Query) tea.Cmd {
+	return func() tea.Msg {
+		service := app.searchService()
+		results, err := service.Search(query)
+		return searchResultsMsg{seq: seq, results: results, fullText: service.FullText(), err: err}
+	}
+}
+
+// kindFilter returns the result type the view is limited to, or "" for all
+func (v *searchView) kindFilter() string {
+	if v.kind == 0 {
+		return ""
+	}
+	return search.Kinds[v.kind-1]
+}
+
+// search starts a search for the current input, superseding any running one
+func (v *searchView) search(app *App) tea.Cmd {
+	v.seq++
+	v.cursor = 0
+	v.err = nil
+
+	query, err := search.ParseQuery(v.input)
+	if err != nil {
+		v.err = err
+		return nil
+	}
+	if strings.TrimSpace(query.Text) == "" {
+		v.results = nil
+		v.loading = false
+		return nil
+	}
+	if kind := v.kindFilter(); kind != "" {
+		query.Kinds = append(query.Kinds, kind)
+	}
+
+	v.loading = true
+	return searchCmd(app, v.seq, query)
+}
+
+// handleResults shows the results of the latest search
+func (v *searchView) handleResults(msg searchResultsMsg) {
+	if msg.seq != v.seq {
+		return
+	}
+	v.loading = false
+	v.results = msg.results
+	v.substring = !msg.fullText
+	v.err = msg.err
+	v.cursor = 0
+}
+
+// handleKey handles keys on the search view. It reports whether the key was
+// consumed.
+func (v *searchView) handleKey(msg tea.KeyMsg, app *App) (tea.Cmd, bool) {
+	if app == nil {
+		return nil, false
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		if v.input == "" {
+			return nil, false
+		}
+		v.input = ""
+		return v.search(app), true
+	case tea.KeyUp:
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case tea.KeyDown:
+		if v.cursor < len(v.results)-1 {
+			v.cursor++
+		}
+	case tea.KeyTab:
+		v.kind = (v.kind + 1) % (len(search.Kinds) + 1)
+		return v.search(app), true
+	case tea.KeyBackspace:
+		if runes := []rune(v.input); len(runes) > 0 {
+			v.input = string(runes[:len(runes)-1])
+			return v.search(app), true
+		}
+	case tea.KeySpace:
+		v.input += " "
+	case tea.KeyRunes:
+		v.input += string(msg.Runes)
+		return v.search(app), true
+	case tea.KeyEnter:
+		return v.search(app), true
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// render draws the query, the result type filter and the results
+func (v *searchView) render(width, height int) string {
+	var s strings.Builder
+
+	s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#00FFFF")).PaddingLeft(2).Render("🔎 " + v.input + "█"))
+	s.WriteString("\n")
+
+	kinds := []string{"all"}
+	kinds = append(kinds, search.Kinds...)
+	var tabs []string
+	for i, kind := range kinds {
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+		if i == v.kind {
+			style = style.Foreground(lipgloss.Color("#7D56F4")).Bold(true).Underline(true)
+		}
+		tabs = append(tabs, style.Render(kind))
+	}
+	s.WriteString(lipgloss.NewStyle().PaddingLeft(2).Render("Type: " + strings.Join(tabs, "  ")))
+	s.WriteString("\n\n")
+
+	faint := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).PaddingLeft(2)
+	switch {
+	case v.err != nil:
+		s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).PaddingLeft(2).Render(truncate("❌ "+v.err.Error(), width-4)))
+		return s.String()
+	case strings.TrimSpace(v.input) == "":
+		s.WriteString(faint.Render("Type to search. Narrow results with project:ID, board:ID, persona:NAME, since:2024-01-31 or until:2024-12-31."))
+		return s.String()
+	case v.loading && len(v.results) == 0:
+		s.WriteString(faint.Render("⏳ Searching..."))
+		return s.String()
+	case len(v.results) == 0:
+		s.WriteString(faint.Render("No results."))
+		s.WriteString(v.fallbackNotice(width))
+		return s.String()
+	}
+
+	// Each result takes three lines
+	perPage := height / 3
+	if perPage < 1 {
+		perPage = 1
+	}
+	start := 0
+	if v.cursor >= perPage {
+		start = v.cursor - perPage + 1
+	}
+	end := start + perPage
+	if end > len(v.results) {
+		end = len(v.results)
+	}
+
+	kindStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#7D56F4"))
+	contextStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).PaddingLeft(4)
+	for i := start; i < end; i++ {
+		result := v.results[i]
+		prefix := "  "
+		titleStyle := lipgloss.NewStyle().PaddingLeft(2)
+		if i == v.cursor {
+			prefix = "→ "
+			titleStyle = titleStyle.Foreground(lipgloss.Color("#00FFFF")).Bold(true)
+		}
+
+		s.WriteString(titleStyle.Render(prefix + kindStyle.Render(fmt.Sprintf("[%s]", result.Kind)) + " " + truncate(resultTitle(result), width-20)))
+		s.WriteString("\n")
+		s.WriteString(contextStyle.Render(truncate(resultContext(result), width-8)))
+		s.WriteString("\n")
+		s.WriteString(lipgloss.NewStyle().PaddingLeft(4).Render(renderHighlights(truncate(result.Snippet, width-8))))
+		s.WriteString("\n")
+	}
+
+	if len(v.results) > perPage {
+		s.WriteString(faint.Render(fmt.Sprintf("%d-%d of %d results", start+1, end, len(v.results))))
+	}
+	s.WriteString(v.fallbackNotice(width))
+	return s.String()
+}
+
+// fallbackNotice explains, once a search has fallen back to substring
+// matching, why results are not ranked by the full-text index
+func (v *searchView) fallbackNotice(width int) string {
+	if !v.substring {
+		return ""
+	}
+	return "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).PaddingLeft(2).Render(truncate("ℹ "+search.FallbackNotice, width-4))
+}
+
+// renderHighlights shows the terms a snippet marks as matches in bold
+func renderHighlights(snippet string) string {
+	plain := lipgloss.NewStyle().Foreground(lipgloss.Color("#CCCCCC"))
+	match := lipgloss.NewStyle().Foreground(lipgloss.Color("#F1C40F")).Bold(true)
+
+	var s strings.Builder
+	for i, part := range strings.Split(snippet, search.HighlightStart) {
+		if part == "" {
+			continue
+		}
+		if i%2 == 1 {
+			s.WriteString(match.Render(part))
+		} else {
+			s.WriteString(plain.Render(part))
+		}
+	}
+	return s.String()
+}
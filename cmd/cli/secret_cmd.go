@@ -0,0 +1,209 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/term"
+
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/secrets"
+)
+
+// secretStatus reports whether a provider's API key can be resolved
+type secretStatus struct {
+	Provider  string `json:"provider" yaml:"provider"`
+	Reference string `json:"reference" yaml:"reference"`
+	Resolved  bool   `json:"resolved" yaml:"resolved"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// openKeystore returns the configured keystore. When neither a key file nor
+// PAB_KEYSTORE_PASSPHRASE gives its passphrase, it is asked for on the
+// terminal, twice when the keystore is about to be created.
+func openKeystore(cfg *config.Config) *secrets.Keystore {
+	configured := secrets.Passphrase(cfg.Secrets.KeyFile, config.KeystorePassphraseEnv)
+	return secrets.NewKeystore(cfg.KeystorePath(), func() (string, error) {
+		passphrase, err := configured()
+		if !errors.Is(err, secrets.ErrNoPassphrase) || !term.IsTerminal(int(os.Stdin.Fd())) {
+			return passphrase, err
+		}
+
+		if passphrase, err = readHidden("Keystore passphrase: "); err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(cfg.KeystorePath()); errors.Is(err, os.ErrNotExist) {
+			confirmed, err := readHidden("Repeat the passphrase: ")
+			if err != nil {
+				return "", err
+			}
+			if confirmed != passphrase {
+				return "", fmt.Errorf("the passphrases do not match")
+			}
+		}
+		return passphrase, nil
+	})
+}
+
+// readHidden asks for a value on the terminal without echoing it
+func readHidden(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	value, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from the terminal: %w", err)
+	}
+	return strings.TrimSpace(string(value)), nil
+}
+
+// runSecretSet stores a secret in the keystore. The value is asked for on the
+// terminal, or read from standard input when it is not one.
+func runSecretSet(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("secret set", flag.ContinueOnError)
+	fromEnv := flags.String("from-env", "", "environment variable to copy the value from")
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board secret set NAME [--from-env VAR] < value")
+	}
+	name := positional[0]
+
+	var value string
+	switch {
+	case *fromEnv != "":
+		if value = os.Getenv(*fromEnv); value == "" {
+			return argumentError(fmt.Errorf("environment variable %s is not set", *fromEnv))
+		}
+	case term.IsTerminal(int(os.Stdin.Fd())):
+		if value, err = readHidden(fmt.Sprintf("Value of %s: ", name)); err != nil {
+			return reportError(err)
+		}
+	default:
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return reportError(fmt.Errorf("failed to read the value: %w", err))
+		}
+		value = strings.TrimSpace(string(data))
+	}
+	if value == "" {
+		return argumentError(fmt.Errorf("the value of %s cannot be empty", name))
+	}
+
+	keystore := openKeystore(cfg)
+	if err := keystore.Set(name, value); err != nil {
+		return reportError(err)
+	}
+
+	fmt.Printf("✓ Stored %s in %s\n", name, keystore.Path())
+	fmt.Printf("  Refer to it as %s:%s, e.g. llm.%s.api_key\n", secrets.SchemeKeystore, name, name)
+	return exitOK
+}
+
+// runSecretList prints the names of the secrets in the keystore
+func runSecretList(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("secret list", flag.ContinueOnError)
+	format := addOutputFlag(flags)
+	if _, err := parseCommandFlags(flags, args); err != nil {
+		return exitUsage
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	if _, err := os.Stat(cfg.KeystorePath()); errors.Is(err, os.ErrNotExist) {
+		return reportError(fmt.Errorf("no keystore at %s; add a secret with \"personal-ai-board secret set NAME\": %w", cfg.KeystorePath(), err))
+	}
+	names, err := openKeystore(cfg).Names()
+	if err != nil {
+		return reportError(err)
+	}
+
+	return printOutput(*format, names, func(tw *tabwriter.Writer) {
+		tableRow(tw, "NAME", "REFERENCE")
+		for _, name := range names {
+			tableRow(tw, name, secrets.SchemeKeystore+":"+name)
+		}
+	})
+}
+
+// runSecretRemove deletes a secret from the keystore
+func runSecretRemove(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("secret remove", flag.ContinueOnError)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board secret remove NAME")
+	}
+
+	keystore := openKeystore(cfg)
+	if err := keystore.Delete(positional[0]); err != nil {
+		return reportError(err)
+	}
+
+	fmt.Printf("✓ Removed %s from %s\n", positional[0], keystore.Path())
+	return exitOK
+}
+
+// runSecretCheck resolves the API key of every configured provider, without
+// printing the keys
+func runSecretCheck(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("secret check", flag.ContinueOnError)
+	format := addOutputFlag(flags)
+	if _, err := parseCommandFlags(flags, args); err != nil {
+		return exitUsage
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	resolver := cfg.SecretResolver()
+	resolver.Register(secrets.SchemeKeystore, openKeystore(cfg))
+
+	var statuses []secretStatus
+	failed := false
+	for _, provider := range []string{"openai", "anthropic", "google"} {
+		providerCfg, _ := cfg.GetProviderConfig(provider)
+		if providerCfg.APIKey == "" {
+			continue
+		}
+
+		status := secretStatus{Provider: provider, Reference: config.MaskSecret(providerCfg.APIKey), Resolved: true}
+		if key, err := resolver.Resolve(providerCfg.APIKey); err != nil {
+			status.Resolved, status.Error = false, err.Error()
+		} else if key == "" {
+			status.Resolved, status.Error = false, "the key is empty"
+		}
+		failed = failed || !status.Resolved
+		statuses = append(statuses, status)
+	}
+
+	if code := printOutput(*format, statuses, func(tw *tabwriter.Writer) {
+		if len(statuses) == 0 {
+			fmt.Fprintln(tw, "No provider has an API key configured")
+			return
+		}
+		tableRow(tw, "PROVIDER", "API KEY", "STATUS")
+		for _, s := range statuses {
+			result := "✓ resolved"
+			if !s.Resolved {
+				result = "✗ " + s.Error
+			}
+			tableRow(tw, s.Provider, s.Reference, result)
+		}
+	}); code != exitOK {
+		return code
+	}
+	if failed {
+		return exitError
+	}
+	return exitOK
+}
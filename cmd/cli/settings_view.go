@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/secrets"
+)
+
+// settingSavedMsg reports the outcome of saving a setting
+type settingSavedMsg struct {
+	setting config.Setting
+	config  *config.Config
+	err     error
+}
+
+// settingsView lists the editable settings and edits one at a time
+type settingsView struct {
+	settings []config.Setting
+	cursor   int
+	editing  bool
+	input    string
+	saving   bool
+	notice   string
+	err      error
+}
+
+// newSettingsView creates the settings view
+func newSettingsView() *settingsView {
+	return &settingsView{settings: config.Settings()}
+}
+
+// saveSettingCmd saves a setting in the background
+func saveSettingCmd(app *App, setting config.Setting, value string) tea.Cmd {
+	return func() tea.Msg {
+		updated, err := app.saveSetting(setting, value)
+		return settingSavedMsg{setting: setting, config: updated, err: err}
+	}
+}
+
+// saveSetting validates a new value for a setting against the whole
+// configuration and saves it where the setting is stored. It returns the
+// updated configuration, leaving the running one untouched.
+func (a *App) saveSetting(setting config.Setting, value string) (*config.Config, error) {
+	if source, ok := setting.Overridden(a.Config); ok {
+		return nil, fmt.Errorf("%s is set by %s", setting.Label, source)
+	}
+
+	// A key typed in is kept in the keystore, and only referred to in the file
+	value = strings.TrimSpace(value)
+	if setting.SecretName != "" && value != "" && !secrets.IsReference(value) {
+		if err := a.Config.Keystore().Set(setting.SecretName, value); err != nil {
+			return nil, fmt.Errorf("failed to store %s in the keystore: %w", setting.Label, err)
+		}
+		value = secrets.SchemeKeystore + ":" + setting.SecretName
+	}
+
+	updated := *a.Config
+	if err := setting.Set(&updated, value); err != nil {
+		return nil, err
+	}
+	if err := updated.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch setting.Store {
+	case config.StoreDatabase:
+		if err := a.DB.SetSystemConfig(setting.SystemKey, setting.Value(&updated), setting.Description); err != nil {
+			return nil, fmt.Errorf("failed to save %s: %w", setting.Label, err)
+		}
+	default:
+		if err := config.UpdateFile(a.Config.FilePath(), setting.Key, setting.Value(&updated)); err != nil {
+			return nil, err
+		}
+	}
+
+	return &updated, nil
+}
+
+// selected returns the setting under the cursor
+func (v *settingsView) selected() config.Setting {
+	return v.settings[v.cursor]
+}
+
+// handleSaved shows the outcome of saving a setting
+func (v *settingsView) handleSaved(msg settingSavedMsg, cfg *config.Config) {
+	v.saving = false
+	if msg.err != nil {
+		v.err = msg.err
+		return
+	}
+
+	v.editing = false
+	v.input = ""
+	v.err = nil
+	v.notice = fmt.Sprintf("✓ Saved %s to %s", msg.setting.Label, settingLocation(msg.setting, cfg))
+	if msg.setting.Restart {
+		v.notice += "; restart to apply it"
+	}
+}
+
+// handleKey handles keys on the settings view. It reports whether the key was
+// consumed.
+func (v *settingsView) handleKey(msg tea.KeyMsg, cfg *config.Config, app *App) (tea.Cmd, bool) {
+	if !v.editing {
+		switch msg.String() {
+		case "up", "k":
+			v.cursor = (v.cursor + len(v.settings) - 1) % len(v.settings)
+		case "down", "j":
+			v.cursor = (v.cursor + 1) % len(v.settings)
+		case "enter", " ":
+			setting := v.selected()
+			v.notice = ""
+			v.err = nil
+			if source, ok := setting.Overridden(cfg); ok {
+				v.err = fmt.Errorf("%s is set by %s", setting.Label, source)
+				return nil, true
+			}
+			if app == nil {
+				v.err = fmt.Errorf("settings can be changed once the database is connected")
+				return nil, true
+			}
+			v.editing = true
+			v.input = ""
+			if !setting.Secret {
+				v.input = setting.Value(app.Config)
+			}
+		default:
+			return nil, false
+		}
+		return nil, true
+	}
+
+	if v.saving {
+		return nil, true
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		v.editing = false
+		v.input = ""
+		v.err = nil
+	case tea.KeyEnter:
+		v.saving = true
+		return saveSettingCmd(app, v.selected(), v.input), true
+	case tea.KeyTab:
+		// Cycle through the allowed values of a choice
+		if options := v.selected().Options; len(options) > 0 {
+			next := 0
+			for i, option := range options {
+				if option == v.input {
+					next = (i + 1) % len(options)
+				}
+			}
+			v.input = options[next]
+		}
+	case tea.KeyBackspace:
+		if runes := []rune(v.input); len(runes) > 0 {
+			v.input = string(runes[:len(runes)-1])
+		}
+	case tea.KeySpace:
+		v.input += " "
+	case tea.KeyRunes:
+		v.input += string(msg.Runes)
+	}
+	return nil, true
+}
+
+// settingLocation describes where a setting is saved
+func settingLocation(setting config.Setting, cfg *config.Config) string {
+	if setting.Store == config.StoreDatabase {
+		return "the database (system_config." + setting.SystemKey + ")"
+	}
+	return cfg.FilePath()
+}
+
+// render draws the settings with their values and where each is stored
+func (v *settingsView) render(cfg *config.Config, width int) string {
+	var s strings.Builder
+
+	labelStyle := lipgloss.NewStyle().Width(22)
+	valueStyle := lipgloss.NewStyle().Width(28)
+	sourceStyle := lipgloss.NewStyle().Faint(true)
+
+	for i, setting := range v.settings {
+		prefix := "  "
+		style := lipgloss.NewStyle().PaddingLeft(2)
+		if i == v.cursor {
+			prefix = "→ "
+			style = style.Foreground(lipgloss.Color("#00FFFF")).Bold(true)
+		}
+
+		value := setting.Display(cfg)
+		if value == "" {
+			value = "(not set)"
+		}
+		source := string(setting.Store)
+		if overridden, ok := setting.Overridden(cfg); ok {
+			source = overridden.String()
+		}
+
+		s.WriteString(style.Render(prefix + labelStyle.Render(setting.Label) + valueStyle.Render(truncate(value, 26)) + sourceStyle.Render(source)))
+		s.WriteString("\n")
+	}
+
+	setting := v.selected()
+	s.WriteString("\n")
+	details := fmt.Sprintf("%s (%s) · saved to %s", setting.Description, setting.Key, settingLocation(setting, cfg))
+	s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#CCCCCC")).PaddingLeft(2).Width(width - 2).Render(details))
+	s.WriteString("\n")
+
+	if v.editing {
+		input := v.input
+		if setting.Secret {
+			input = strings.Repeat("•", len([]rune(input)))
+		}
+		prompt := fmt.Sprintf("%s: %s█", setting.Label, input)
+		if v.saving {
+			prompt = "⏳ Saving..."
+		}
+		s.WriteString("\n")
+		s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#00FFFF")).PaddingLeft(2).Render(prompt))
+		s.WriteString("\n")
+	}
+
+	switch {
+	case v.err != nil:
+		s.WriteString("\n")
+		s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).PaddingLeft(2).Render(truncate("❌ "+v.err.Error(), width-4)))
+	case v.notice != "":
+		s.WriteString("\n")
+		s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#2ECC71")).PaddingLeft(2).Render(truncate(v.notice, width-4)))
+	}
+
+	return s.String()
+}
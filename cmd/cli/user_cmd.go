@@ -0,0 +1,258 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"personal-ai-board/internal/auth"
+	"personal-ai-board/internal/config"
+)
+
+// runUserAdd creates an API user, in a workspace of their own unless one is
+// given
+func runUserAdd(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("user add", flag.ContinueOnError)
+	workspaceID := flags.String("workspace", "", "existing workspace to join, such as "+auth.DefaultWorkspaceID+" for the CLI's data (default: a new workspace)")
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board user add NAME [--workspace WORKSPACE_ID]")
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	storage := auth.NewStorage(app.DB.DB)
+	var workspace *auth.Workspace
+	if *workspaceID != "" {
+		workspace, err = storage.LoadWorkspace(*workspaceID)
+		if err != nil {
+			return reportError(err)
+		}
+	} else {
+		workspace, err = auth.NewWorkspace(generateID("workspace"), positional[0]+"'s workspace")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitValidation
+		}
+	}
+
+	u, err := auth.NewUser(generateID("user"), positional[0], workspace.ID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitValidation
+	}
+	if *workspaceID == "" {
+		if err := storage.SaveWorkspace(workspace); err != nil {
+			return reportError(err)
+		}
+	}
+	if err := storage.SaveUser(u); err != nil {
+		return reportError(err)
+	}
+
+	return printOutput(*format, u, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "✓ Added user %s (%s) in workspace %s (%s)\n", u.Name, u.ID, workspace.Name, workspace.ID)
+		fmt.Fprintf(tw, "  Issue an API token with: personal-ai-board token create %s\n", u.ID)
+	})
+}
+
+// runUserList prints every API user
+func runUserList(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("user list", flag.ContinueOnError)
+	format := addOutputFlag(flags)
+	if _, err := parseCommandFlags(flags, args); err != nil {
+		return exitUsage
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	users, err := auth.NewStorage(app.DB.DB).ListUsers()
+	if err != nil {
+		return reportError(err)
+	}
+
+	return printOutput(*format, users, func(tw *tabwriter.Writer) {
+		tableRow(tw, "ID", "NAME", "WORKSPACE", "CREATED")
+		for _, u := range users {
+			tableRow(tw, u.ID, u.Name, u.WorkspaceID, u.CreatedAt.Format("2006-01-02 15:04"))
+		}
+	})
+}
+
+// runUserRemove deletes an API user and revokes their tokens. Their
+// workspace and its data are kept.
+func runUserRemove(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("user remove", flag.ContinueOnError)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board user remove USER_ID")
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	storage := auth.NewStorage(app.DB.DB)
+	u, err := storage.LoadUser(positional[0])
+	if err != nil {
+		return reportError(err)
+	}
+	if err := storage.DeleteUser(u.ID); err != nil {
+		return reportError(err)
+	}
+
+	fmt.Printf("✓ Removed user %s (%s) and revoked their API tokens\n", u.ID, u.Name)
+	return exitOK
+}
+
+// runTokenCreate issues an API token for a user and prints it, once
+func runTokenCreate(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("token create", flag.ContinueOnError)
+	name := flags.String("name", "", "what the token is for")
+	expires := flags.Duration("expires", 0, "how long the token is valid, e.g. 720h (default: no expiry)")
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board token create USER_ID [--name NAME] [--expires DURATION]")
+	}
+	if *expires < 0 {
+		return argumentError(fmt.Errorf("--expires cannot be negative"))
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	var expiresAt *time.Time
+	if *expires > 0 {
+		at := time.Now().Add(*expires)
+		expiresAt = &at
+	}
+
+	token, record, err := auth.NewStorage(app.DB.DB).IssueToken(positional[0], *name, expiresAt)
+	if err != nil {
+		return reportError(err)
+	}
+
+	// Only the token's hash is stored, so this is the one chance to copy it
+	created := struct {
+		*auth.Token
+		Secret string `json:"token"`
+	}{record, token}
+	return printOutput(*format, created, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "✓ Created API token %s for %s\n", record.ID, record.UserID)
+		fmt.Fprintf(tw, "  Token: %s\n", token)
+		fmt.Fprintln(tw, "  It cannot be shown again; send it as \"Authorization: Bearer TOKEN\"")
+	})
+}
+
+// runTokenList prints the API tokens of a user, or of every user
+func runTokenList(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("token list", flag.ContinueOnError)
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) > 1 {
+		return usageError("personal-ai-board token list [USER_ID]")
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	storage := auth.NewStorage(app.DB.DB)
+	userID := ""
+	if len(positional) == 1 {
+		if _, err := storage.LoadUser(positional[0]); err != nil {
+			return reportError(err)
+		}
+		userID = positional[0]
+	}
+
+	tokens, err := storage.ListTokens(userID)
+	if err != nil {
+		return reportError(err)
+	}
+
+	return printOutput(*format, tokens, func(tw *tabwriter.Writer) {
+		tableRow(tw, "ID", "USER", "NAME", "TOKEN", "EXPIRES", "LAST USED", "CREATED")
+		for _, t := range tokens {
+			expiresAt, lastUsed := "never", "never"
+			if t.ExpiresAt != nil {
+				expiresAt = t.ExpiresAt.Local().Format("2006-01-02 15:04")
+				if t.Expired() {
+					expiresAt += " (expired)"
+				}
+			}
+			if t.LastUsedAt != nil {
+				lastUsed = t.LastUsedAt.Local().Format("2006-01-02 15:04")
+			}
+			tableRow(tw, t.ID, t.UserID, t.Name, t.Prefix+"…", expiresAt, lastUsed, t.CreatedAt.Format("2006-01-02 15:04"))
+		}
+	})
+}
+
+// runTokenRevoke deletes an API token, so that it is no longer accepted
+func runTokenRevoke(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("token revoke", flag.ContinueOnError)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board token revoke TOKEN_ID")
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	if err := auth.NewStorage(app.DB.DB).RevokeToken(positional[0]); err != nil {
+		return reportError(err)
+	}
+
+	fmt.Printf("✓ Revoked API token %s\n", positional[0])
+	return exitOK
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/webhook"
+)
+
+// runWebhookAdd subscribes an endpoint to events
+func runWebhookAdd(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("webhook add", flag.ContinueOnError)
+	events := flags.String("events", "", "comma separated events to send (default: all of "+strings.Join(webhook.Events, ", ")+")")
+	secret := flags.String("secret", "", "secret that signs the payloads (default: a random one)")
+	description := flags.String("description", "", "what the webhook is for")
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board webhook add URL [--events a,b] [--secret SECRET] [--description TEXT]")
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	w, err := webhook.New(generateID("webhook"), positional[0], *secret, splitList(*events))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitValidation
+	}
+	w.Description = *description
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	if err := webhook.NewStorage(app.DB.DB).SaveWebhook(w); err != nil {
+		return reportError(err)
+	}
+
+	// The secret is shown once, so that it can be given to the receiver
+	created := struct {
+		*webhook.Webhook
+		Secret string `json:"secret"`
+	}{w, w.Secret}
+	return printOutput(*format, created, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "✓ Added webhook %s for %s\n", w.ID, strings.Join(w.Events, ", "))
+		fmt.Fprintf(tw, "  Secret: %s\n", w.Secret)
+	})
+}
+
+// runWebhookList prints every webhook
+func runWebhookList(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("webhook list", flag.ContinueOnError)
+	format := addOutputFlag(flags)
+	if _, err := parseCommandFlags(flags, args); err != nil {
+		return exitUsage
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	webhooks, err := webhook.NewStorage(app.DB.DB).ListWebhooks()
+	if err != nil {
+		return reportError(err)
+	}
+
+	return printOutput(*format, webhooks, func(tw *tabwriter.Writer) {
+		tableRow(tw, "ID", "URL", "EVENTS", "ACTIVE", "CREATED")
+		for _, w := range webhooks {
+			tableRow(tw, w.ID, w.URL, strings.Join(w.Events, ","), w.Active, w.CreatedAt.Format("2006-01-02 15:04"))
+		}
+	})
+}
+
+// runWebhookRemove deletes a webhook and its delivery log
+func runWebhookRemove(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("webhook remove", flag.ContinueOnError)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board webhook remove WEBHOOK_ID")
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	storage := webhook.NewStorage(app.DB.DB)
+	w, err := storage.LoadWebhook(positional[0])
+	if err != nil {
+		return reportError(err)
+	}
+	if err := storage.DeleteWebhook(w.ID); err != nil {
+		return reportError(err)
+	}
+
+	fmt.Printf("✓ Removed webhook %s (%s)\n", w.ID, w.URL)
+	return exitOK
+}
+
+// runWebhookTest sends a test event to a saved webhook or to any URL, such as
+// a receiver being developed locally
+func runWebhookTest(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("webhook test", flag.ContinueOnError)
+	secret := flags.String("secret", "", "secret that signs the payload when sending to a URL")
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		return usageError("personal-ai-board webhook test WEBHOOK_ID|URL [--secret SECRET]")
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	target := &webhook.Webhook{URL: positional[0], Secret: *secret}
+	if !strings.Contains(positional[0], "://") {
+		if target, err = webhook.NewStorage(app.DB.DB).LoadWebhook(positional[0]); err != nil {
+			return reportError(err)
+		}
+	}
+
+	delivery, err := app.webhookDispatcher().Test(context.Background(), target)
+	if delivery == nil {
+		return argumentError(err)
+	}
+	if err != nil {
+		return reportError(err)
+	}
+
+	if code := printOutput(*format, delivery, func(tw *tabwriter.Writer) {
+		if delivery.Status == webhook.StatusDelivered {
+			fmt.Fprintf(tw, "✓ %s responded %d in %s\n", target.URL, delivery.StatusCode, delivery.Duration.Round(time.Millisecond))
+		} else {
+			fmt.Fprintf(tw, "✗ %s: %s\n", target.URL, delivery.Error)
+		}
+	}); code != exitOK {
+		return code
+	}
+	if delivery.Status != webhook.StatusDelivered {
+		return exitError
+	}
+	return exitOK
+}
+
+// runWebhookDeliveries prints the delivery log
+func runWebhookDeliveries(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("webhook deliveries", flag.ContinueOnError)
+	limit := flags.Int("limit", 20, "number of deliveries to show (0 for all)")
+	format := addOutputFlag(flags)
+	positional, err := parseCommandFlags(flags, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) > 1 {
+		return usageError("personal-ai-board webhook deliveries [WEBHOOK_ID] [--limit N]")
+	}
+	if err := checkOutputFormat(*format); err != nil {
+		return argumentError(err)
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	storage := webhook.NewStorage(app.DB.DB)
+	webhookID := ""
+	if len(positional) == 1 {
+		if _, err := storage.LoadWebhook(positional[0]); err != nil {
+			return reportError(err)
+		}
+		webhookID = positional[0]
+	}
+
+	deliveries, err := storage.ListDeliveries(webhookID, *limit)
+	if err != nil {
+		return reportError(err)
+	}
+
+	return printOutput(*format, deliveries, func(tw *tabwriter.Writer) {
+		tableRow(tw, "ID", "WEBHOOK", "EVENT", "STATUS", "ATTEMPTS", "RESPONSE", "CREATED", "NEXT ATTEMPT / ERROR")
+		for _, d := range deliveries {
+			response := "-"
+			if d.StatusCode > 0 {
+				response = fmt.Sprint(d.StatusCode)
+			}
+			detail := truncate(d.Error, 60)
+			if d.NextAttemptAt != nil {
+				detail = d.NextAttemptAt.Local().Format("2006-01-02 15:04:05") + "  " + detail
+			}
+			tableRow(tw, d.ID, d.WebhookID, d.EventType, d.Status, d.Attempts, response,
+				d.CreatedAt.Format("2006-01-02 15:04:05"), detail)
+		}
+	})
+}
+
+// runWebhookDeliver attempts the deliveries that are due, once
+func runWebhookDeliver(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("webhook deliver", flag.ContinueOnError)
+	if _, err := parseCommandFlags(flags, args); err != nil {
+		return exitUsage
+	}
+
+	app, err := openApp(cfg)
+	if err != nil {
+		return reportError(err)
+	}
+	defer app.Close()
+
+	attempted, err := app.webhookDispatcher().RunOnce(context.Background())
+	if err != nil {
+		return reportError(err)
+	}
+
+	fmt.Printf("✓ Attempted %d webhook deliveries\n", attempted)
+	return exitOK
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"personal-ai-board/internal/analysis"
+	"personal-ai-board/internal/api"
+	"personal-ai-board/internal/auth"
+	"personal-ai-board/internal/board"
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/db"
+	"personal-ai-board/internal/llm"
+	"personal-ai-board/internal/persona"
+	"personal-ai-board/internal/project"
+	"personal-ai-board/internal/search"
+	"personal-ai-board/internal/webhook"
+	"personal-ai-board/pkg/logger"
+)
+
+// shutdownTimeout is how long requests in flight get to finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
+func main() {
+	configPath := flag.String("config", "", "project configuration file, applied over the system and user files (default: .personal-ai-board.yaml in the current directory)")
+	host := flag.String("host", "", "host to listen on (default from config)")
+	port := flag.Int("port", 0, "port to listen on (default from config)")
+	traitsDir := flag.String("traits-dir", "config", "directory containing traits/base.json")
+	flag.Parse()
+
+	load := func() (*config.Config, error) {
+		cfg, err := config.Load(*configPath)
+		if err != nil {
+			return nil, fmt.Errorf("error loading configuration: %w", err)
+		}
+		if *host != "" {
+			cfg.Set("web.host", *host, config.FlagSource("host"))
+		}
+		if *port != 0 {
+			cfg.Set("web.port", strconv.Itoa(*port), config.FlagSource("port"))
+		}
+		return cfg, nil
+	}
+
+	cfg, err := load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	log := logger.NewWithWriter(cfg.Log.Level, cfg.Log.Format, os.Stderr)
+	reloader := newReloader(load, append(config.WatchPaths(*configPath), cfg.ModelCatalogPath()), *traitsDir, log)
+	if err := serve(cfg, reloader, log); err != nil {
+		log.Error("Server stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+// serve opens the database and serves the API until interrupted
+func serve(cfg *config.Config, reloader *reloader, log logger.Logger) error {
+	database, err := openDatabase(cfg, log)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	configureMemory(cfg)
+
+	// API keys are resolved as the providers are created; keep them out of the logs
+	cfg.SecretResolver().Observe(logger.Redact)
+	manager, errs := llm.NewManagerFromConfig(cfg, log)
+	for _, err := range errs {
+		log.Warn("LLM provider not available", "error", err)
+	}
+	enableReplay(cfg, manager, database, log)
+
+	webhooks := webhook.NewStorage(database.DB)
+	var engine *analysis.Engine
+	if len(manager.ListProviders()) > 0 {
+		engine = analysis.NewEngine(database.DB, llm.NewPersonaProvider(manager, "", ""), cfg.Analysis.MaxConcurrent, log)
+		engine.TrackPersonas(reloader.traits)
+		watcher := webhook.Watch(engine, database.DB, cfg.Webhooks.BudgetUSD, log)
+		defer watcher.Stop()
+	} else {
+		log.Warn("No LLM provider configured; analyses cannot be started")
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	workspaces := func(workspaceID string) api.Repositories {
+		return api.Repositories{
+			Personas: persona.NewWorkspaceStorage(database.DB, workspaceID),
+			Boards:   board.NewWorkspaceStorage(database.DB, workspaceID),
+			Projects: project.NewWorkspaceStorage(database.DB, workspaceID),
+			Analyses: analysis.NewWorkspaceStorage(database.DB, workspaceID),
+			Webhooks: webhook.NewWorkspaceStorage(database.DB, workspaceID),
+		}
+	}
+	handler := api.New(workspaces, auth.NewStorage(database.DB), engine, persona.NewTraitLoader(reloader.traitsPath), cfg.Analysis.DefaultMode, log)
+	defer handler.Close()
+
+	server := &http.Server{
+		Addr:         cfg.WebAddress(),
+		Handler:      handler,
+		ReadTimeout:  cfg.GetWebReadTimeout(),
+		WriteTimeout: cfg.GetWebWriteTimeout(),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	webhook.NewDispatcher(webhooks, webhook.Policy{
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		Backoff:     cfg.GetWebhookBackoff(),
+		Timeout:     cfg.GetWebhookTimeout(),
+	}, log).Start(ctx)
+	db.NewRetentionJob(database, cfg.Memory.RetentionDays, cfg.GetCleanupInterval(), log).Start(ctx)
+	if cfg.Backup.Enabled {
+		db.NewSnapshotJob(database, db.SnapshotPolicy{
+			Dir:        cfg.BackupDir(),
+			Interval:   cfg.GetBackupInterval(),
+			KeepDaily:  cfg.Backup.KeepDaily,
+			KeepWeekly: cfg.Backup.KeepWeekly,
+			Compress:   cfg.Backup.Compress,
+		}, log).Start(ctx)
+	}
+	reloader.start(ctx, database, manager)
+
+	served := make(chan error, 1)
+	go func() {
+		log.Info("API server listening", "address", "http://"+server.Addr+"/api/v1")
+		served <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-served:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to serve: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	log.Info("Shutting down API server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+	return nil
+}
+
+// configureMemory applies the configured memory tier limits to new personas
+func configureMemory(cfg *config.Config) {
+	persona.SetDefaultMemorySettings(persona.MemorySettings{
+		ShortTermLimit: cfg.Memory.ShortTermLimit,
+		LongTermLimit:  cfg.Memory.LongTermLimit,
+		DecayRate:      cfg.Memory.DecayRate,
+	})
+}
+
+// enableReplay has the mock provider replay recorded interactions, if cfg
+// asks for it
+func enableReplay(cfg *config.Config, manager *llm.Manager, database *db.Database, log logger.Logger) {
+	if !cfg.LLM.Mock.Enabled || !cfg.LLM.Mock.Replay {
+		return
+	}
+	if count, err := llm.EnableReplay(manager, database.DB); err != nil {
+		log.Warn("Mock provider cannot replay recorded interactions", "error", err)
+	} else {
+		log.Debug("Mock provider replaying recorded interactions", "count", count)
+	}
+}
+
+// openDatabase connects to the configured database, migrates it, prepares the
+// search index and applies the settings stored in it
+func openDatabase(cfg *config.Config, log logger.Logger) (*db.Database, error) {
+	dbConfig := db.DefaultConfig()
+	dbConfig.Path = cfg.Database.Path
+	dbConfig.MaxOpenConns = cfg.Database.MaxOpenConns
+	dbConfig.MaxIdleConns = cfg.Database.MaxIdleConns
+	dbConfig.ConnMaxLifetime = cfg.GetConnMaxLifetime()
+	dbConfig.ConnMaxIdleTime = cfg.GetConnMaxIdleTime()
+	dbConfig.EnableWAL = cfg.Database.EnableWAL
+	dbConfig.EnableForeignKeys = cfg.Database.EnableForeignKeys
+
+	database, err := db.Connect(dbConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	if err := database.Migrate(); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+	fullText, err := search.EnsureIndex(database.DB)
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
+	if !fullText {
+		log.Warn(search.FallbackNotice)
+	}
+
+	// Settings stored in the database take precedence over the file
+	values, err := database.GetAllSystemConfig()
+	if err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to load database settings: %w", err)
+	}
+	for _, err := range cfg.ApplySystemConfig(values) {
+		log.Warn("Ignoring invalid database setting", "error", err)
+	}
+
+	return database, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/db"
+	"personal-ai-board/internal/filewatch"
+	"personal-ai-board/internal/llm"
+	"personal-ai-board/internal/persona"
+	"personal-ai-board/pkg/logger"
+)
+
+// reloader applies changes to the configuration and trait files while the
+// server runs. The listen address, timeouts and database only change on a
+// restart.
+type reloader struct {
+	load        func() (*config.Config, error)
+	configFiles map[string]bool
+	traitsPath  string
+	traits      *persona.TraitReloader
+	logger      logger.Logger
+}
+
+// newReloader creates a reloader that loads the configuration with load when
+// one of configFiles changes, and rebuilds persona traits from traitsPath
+func newReloader(load func() (*config.Config, error), configFiles []string, traitsPath string, log logger.Logger) *reloader {
+	files := make(map[string]bool)
+	for _, path := range configFiles {
+		files[filepath.Clean(path)] = true
+	}
+	return &reloader{
+		load:        load,
+		configFiles: files,
+		traitsPath:  traitsPath,
+		traits:      persona.NewTraitReloader(traitsPath, log),
+		logger:      log,
+	}
+}
+
+// start watches the files until ctx is cancelled
+func (r *reloader) start(ctx context.Context, database *db.Database, manager *llm.Manager) {
+	watcher := filewatch.NewWatcher(filewatch.DefaultInterval, r.logger)
+	for path := range r.configFiles {
+		watcher.Add(path)
+	}
+	watcher.Add(r.traits.TraitsDir())
+
+	watcher.Start(ctx, func(changed []string) {
+		for _, path := range changed {
+			if r.configFiles[filepath.Clean(path)] {
+				r.reloadConfig(database, manager)
+				break
+			}
+		}
+		_, errs := r.traits.Reload(changed)
+		for _, err := range errs {
+			r.logger.Error("Persona traits not reloaded", "error", err)
+		}
+	})
+}
+
+// reloadConfig loads the configuration again and rebuilds the LLM providers
+// whose settings changed, keeping the running configuration when the new one
+// is invalid
+func (r *reloader) reloadConfig(database *db.Database, manager *llm.Manager) {
+	cfg, err := r.load()
+	if err == nil {
+		var values map[string]string
+		if values, err = database.GetAllSystemConfig(); err == nil {
+			for _, err := range cfg.ApplySystemConfig(values) {
+				r.logger.Warn("Ignoring invalid database setting", "error", err)
+			}
+			err = cfg.Validate()
+		}
+	}
+	if err != nil {
+		r.logger.Error("Configuration not reloaded", "error", err)
+		return
+	}
+
+	configureMemory(cfg)
+	cfg.SecretResolver().Observe(logger.Redact)
+	changed, errs := manager.ApplyConfig(cfg)
+	for _, err := range errs {
+		r.logger.Warn("LLM provider not available", "error", err)
+	}
+	enableReplay(cfg, manager, database, r.logger)
+	r.logger.Info("Configuration reloaded", "providers_changed", changed)
+}
@@ -0,0 +1,523 @@
+package analysis
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"personal-ai-board/internal/auth"
+	"personal-ai-board/internal/board"
+	"personal-ai-board/internal/persona"
+	"personal-ai-board/internal/project"
+)
+
+// maxInsights caps how many insights are kept on a result
+const maxInsights = 10
+
+// Modes lists the supported analysis modes
+var Modes = []string{"discussion", "simulation", "analysis", "comparison", "evaluation", "prediction"}
+
+// modeInstructions tells the board what each analysis mode asks of them
+var modeInstructions = map[string]string{
+	"discussion": "Discuss this topic with the rest of the board. Share your perspective and engage with the points others raise.",
+	"simulation": "Simulate how this scenario is likely to play out. Walk through the key events and their consequences.",
+	"analysis":   "Analyze this topic in depth. Break it into its components and assess each one.",
+	"comparison": "Compare the options involved. Weigh their strengths and weaknesses against each other.",
+	"evaluation": "Evaluate this proposal systematically. Judge its merits, risks and feasibility.",
+	"prediction": "Predict the likely outcomes. State how confident you are and what would change your mind.",
+}
+
+// Logger interface for structured logging
+type Logger interface {
+	Info(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+	Debug(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+}
+
+// PersonaTracker keeps the traits of personas up to date while they are in
+// use, such as a persona.TraitReloader
+type PersonaTracker interface {
+	Track(p *persona.Persona)
+	Untrack(p *persona.Persona)
+}
+
+// Engine runs analyses with a board of personas and reports their progress
+type Engine struct {
+	workspaces    Workspaces
+	provider      persona.LLMProvider
+	maxConcurrent int
+	logger        Logger
+	events        broadcaster
+	running       sync.WaitGroup
+	tracker       PersonaTracker
+}
+
+// NewEngine creates an analysis engine over a database. Each analysis reads
+// from and saves to the workspace of its request. maxConcurrent limits how
+// many personas respond at the same time.
+func NewEngine(db *sql.DB, provider persona.LLMProvider, maxConcurrent int, logger Logger) *Engine {
+	return NewEngineWithWorkspaces(func(workspaceID string) Repositories {
+		return Repositories{
+			Boards:   board.NewWorkspaceStorage(db, workspaceID),
+			Projects: project.NewWorkspaceStorage(db, workspaceID),
+			Personas: persona.NewWorkspaceStorage(db, workspaceID),
+			Analyses: NewWorkspaceStorage(db, workspaceID),
+		}
+	}, provider, maxConcurrent, logger)
+}
+
+// NewEngineWithRepositories creates an analysis engine that reads from and
+// saves to the given repositories, whatever the workspace of a request
+func NewEngineWithRepositories(repos Repositories, provider persona.LLMProvider, maxConcurrent int, logger Logger) *Engine {
+	return NewEngineWithWorkspaces(func(string) Repositories { return repos }, provider, maxConcurrent, logger)
+}
+
+// NewEngineWithWorkspaces creates an analysis engine that reads from and
+// saves to the repositories of each request's workspace
+func NewEngineWithWorkspaces(workspaces Workspaces, provider persona.LLMProvider, maxConcurrent int, logger Logger) *Engine {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+
+	return &Engine{
+		workspaces:    workspaces,
+		provider:      provider,
+		maxConcurrent: maxConcurrent,
+		logger:        logger,
+	}
+}
+
+// Provider returns the LLM provider the engine's personas respond with
+func (e *Engine) Provider() persona.LLMProvider {
+	return e.provider
+}
+
+// TrackPersonas has the personas of every analysis tracked while it runs, so
+// their traits follow changes to their trait files. Call it before starting
+// analyses.
+func (e *Engine) TrackPersonas(tracker PersonaTracker) {
+	e.tracker = tracker
+}
+
+// Track has the tracker set with TrackPersonas keep the traits of personas
+// the engine does not run itself up to date, until the returned function is
+// called
+func (e *Engine) Track(personas ...*persona.Persona) func() {
+	if e.tracker == nil {
+		return func() {}
+	}
+	for _, p := range personas {
+		e.tracker.Track(p)
+	}
+	return func() {
+		for _, p := range personas {
+			e.tracker.Untrack(p)
+		}
+	}
+}
+
+// ValidMode reports whether mode is a supported analysis mode
+func ValidMode(mode string) bool {
+	_, ok := modeInstructions[mode]
+	return ok
+}
+
+// Subscribe registers for progress events of every analysis the engine runs.
+// Call the returned function to stop receiving events. The channel is closed
+// then, or earlier if the subscriber falls so far behind that its buffer fills
+// up.
+func (e *Engine) Subscribe(buffer int) (<-chan Event, func()) {
+	return e.events.subscribe(buffer)
+}
+
+// run holds the state of one analysis while it executes
+type run struct {
+	req            *Request
+	repos          Repositories
+	result         *Result
+	model          persona.ModelInfo
+	board          *board.Board
+	personas       []*persona.Persona
+	projectContext map[string]interface{}
+
+	mu       sync.Mutex
+	insights []string
+
+	// publishing sends the run's events one at a time, in sequence
+	publishing sync.Mutex
+	seq        int64
+}
+
+// Run executes an analysis, saving the request and its result. Progress is
+// published to subscribers as it happens. Cancelling ctx stops the analysis
+// and saves what was produced so far.
+func (e *Engine) Run(ctx context.Context, req *Request) (*Result, error) {
+	r, err := e.prepare(req)
+	if err != nil {
+		return nil, err
+	}
+	return e.execute(ctx, r)
+}
+
+// Start begins an analysis in the background once the request is valid and
+// saved, and returns its result as it stands when the analysis is running.
+// Follow its progress with Subscribe, or load the result by its ID until its
+// status is no longer running. Cancelling ctx stops the analysis.
+func (e *Engine) Start(ctx context.Context, req *Request) (*Result, error) {
+	r, err := e.prepare(req)
+	if err != nil {
+		return nil, err
+	}
+
+	started := *r.result
+	started.Responses = make([]PersonaResponse, 0)
+
+	e.running.Add(1)
+	go func() {
+		defer e.running.Done()
+		// Failures are recorded on the result and logged by finish
+		e.execute(ctx, r)
+	}()
+
+	return &started, nil
+}
+
+// Wait blocks until every analysis begun with Start has finished
+func (e *Engine) Wait() {
+	e.running.Wait()
+}
+
+// prepare validates a request, loads the board, project and personas it
+// refers to, and saves the request with a running result
+func (e *Engine) prepare(req *Request) (*run, error) {
+	if e.provider == nil {
+		return nil, fmt.Errorf("no LLM provider configured")
+	}
+	if req.BoardID == "" || req.ProjectID == "" {
+		return nil, fmt.Errorf("board and project are required")
+	}
+	if strings.TrimSpace(req.Topic) == "" {
+		return nil, fmt.Errorf("topic is required")
+	}
+	if !ValidMode(req.Mode) {
+		return nil, fmt.Errorf("invalid analysis mode: %s (must be one of: %s)", req.Mode, strings.Join(Modes, ", "))
+	}
+	if req.Rounds < 1 {
+		req.Rounds = 1
+	}
+	if req.WorkspaceID == "" {
+		req.WorkspaceID = auth.DefaultWorkspaceID
+	}
+	repos := e.workspaces(req.WorkspaceID)
+
+	b, err := repos.Boards.LoadBoard(req.BoardID)
+	if err != nil {
+		return nil, err
+	}
+	if len(b.Members) == 0 {
+		return nil, fmt.Errorf("board %s has no members", b.Name)
+	}
+	proj, err := repos.Projects.LoadProject(req.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	personas := make([]*persona.Persona, 0, len(b.Members))
+	participants := make([]Participant, 0, len(b.Members))
+	for _, member := range b.Members {
+		p, err := repos.Personas.LoadPersona(member.PersonaID, e.provider, e.logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load board member %s: %w", member.PersonaID, err)
+		}
+		personas = append(personas, p)
+		participants = append(participants, Participant{PersonaID: p.ID, PersonaName: p.Name, Role: member.Role})
+	}
+
+	now := time.Now()
+	if req.ID == "" {
+		req.ID = fmt.Sprintf("request_%d", now.UnixNano())
+	}
+	if req.CreatedAt.IsZero() {
+		req.CreatedAt = now
+	}
+	if err := repos.Analyses.SaveRequest(req); err != nil {
+		return nil, err
+	}
+
+	r := &run{
+		req:   req,
+		repos: repos,
+		result: &Result{
+			ID:        fmt.Sprintf("result_%d", time.Now().UnixNano()),
+			RequestID: req.ID,
+			ProjectID: req.ProjectID,
+			BoardID:   req.BoardID,
+			Mode:      req.Mode,
+			Topic:     req.Topic,
+			Status:    StatusRunning,
+			Responses: make([]PersonaResponse, 0),
+			StartedAt: now,
+			CreatedAt: now,
+		},
+		model:    e.provider.GetModelInfo(),
+		board:    b,
+		personas: personas,
+		projectContext: map[string]interface{}{
+			"project":     proj.Name,
+			"description": proj.Description,
+		},
+	}
+	if len(proj.Ideas) > 0 {
+		ideas := make([]string, 0, len(proj.Ideas))
+		for _, idea := range proj.Ideas {
+			ideas = append(ideas, idea.Title)
+		}
+		r.projectContext["ideas"] = strings.Join(ideas, "; ")
+	}
+	for key, value := range req.Context {
+		r.projectContext[key] = value
+	}
+
+	if err := r.repos.Analyses.SaveResult(r.result); err != nil {
+		return nil, err
+	}
+
+	e.logger.Info("Analysis started", "result_id", r.result.ID, "board_id", b.ID, "mode", req.Mode, "rounds", req.Rounds)
+	e.publish(r, Event{Type: EventStarted, Rounds: req.Rounds, Participants: participants})
+
+	return r, nil
+}
+
+// execute runs the rounds of a prepared analysis and records its outcome
+func (e *Engine) execute(ctx context.Context, r *run) (*Result, error) {
+	defer e.Track(r.personas...)()
+
+	history := make([]persona.ConversationTurn, 0)
+	for round := 1; round <= r.req.Rounds && ctx.Err() == nil; round++ {
+		r.mu.Lock()
+		r.result.Metrics.Rounds = round
+		r.mu.Unlock()
+		e.publish(r, Event{Type: EventRoundStarted, Round: round})
+
+		responses := e.runRound(ctx, r, r.personas, round, persona.ThinkingContext{
+			Topic:               r.req.Topic,
+			ProjectContext:      r.projectContext,
+			BoardContext:        map[string]interface{}{"board": r.board.Name, "mode": r.req.Mode, "round": round},
+			ConversationHistory: history,
+		})
+
+		for _, resp := range responses {
+			if resp.Error == "" {
+				history = append(history, persona.ConversationTurn{
+					Speaker:   resp.PersonaName,
+					Content:   resp.Content,
+					Timestamp: resp.CreatedAt,
+				})
+			}
+		}
+		if len(history) == 0 {
+			// Nobody could respond, so later rounds would fail the same way
+			break
+		}
+	}
+
+	return e.finish(ctx, r)
+}
+
+// runRound asks every persona for its response to the topic, at most
+// maxConcurrent at a time, and returns the responses in board order
+func (e *Engine) runRound(ctx context.Context, r *run, personas []*persona.Persona, round int, thinking persona.ThinkingContext) []PersonaResponse {
+	prompt := buildPrompt(r.req, round)
+	responses := make([]PersonaResponse, len(personas))
+	slots := make(chan struct{}, e.maxConcurrent)
+
+	var wg sync.WaitGroup
+	for i, p := range personas {
+		wg.Add(1)
+		go func(i int, p *persona.Persona) {
+			defer wg.Done()
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				responses[i] = PersonaResponse{PersonaID: p.ID, PersonaName: p.Name, Round: round, Error: ctx.Err().Error(), CreatedAt: time.Now()}
+				return
+			}
+
+			responses[i] = e.respond(ctx, r, p, round, prompt, thinking)
+		}(i, p)
+	}
+	wg.Wait()
+
+	return responses
+}
+
+// respond gets one persona's response, streaming its text to subscribers
+func (e *Engine) respond(ctx context.Context, r *run, p *persona.Persona, round int, prompt string, thinking persona.ThinkingContext) PersonaResponse {
+	e.publish(r, Event{Type: EventPersonaStarted, Round: round, PersonaID: p.ID, PersonaName: p.Name})
+
+	resp := PersonaResponse{PersonaID: p.ID, PersonaName: p.Name, Round: round}
+	thought, err := p.ThinkStream(ctx, prompt, thinking, func(chunk string) {
+		e.publish(r, Event{Type: EventPersonaChunk, Round: round, PersonaID: p.ID, PersonaName: p.Name, Chunk: chunk})
+	})
+	resp.CreatedAt = time.Now()
+
+	eventType := EventPersonaCompleted
+	if err != nil {
+		e.logger.Warn("Persona failed to respond", "persona_id", p.ID, "round", round, "error", err)
+		resp.Error = err.Error()
+		eventType = EventPersonaFailed
+	} else {
+		resp.Content = thought.Response
+		resp.Confidence = thought.Confidence
+		resp.EmotionalTone = thought.EmotionalTone
+		resp.TokensUsed = thought.TokensUsed
+		resp.CostUSD = r.model.Cost(thought.TokensUsed, thought.PromptTokens, thought.CompletionTokens)
+	}
+
+	var insights []string
+	if err == nil {
+		insights = thought.KeyInsights
+	}
+
+	r.mu.Lock()
+	r.result.Responses = append(r.result.Responses, resp)
+	r.result.Metrics.Responses = len(r.result.Responses)
+	r.result.Metrics.TotalTokens += resp.TokensUsed
+	r.result.Metrics.CostUSD += resp.CostUSD
+	for _, insight := range insights {
+		r.insights = append(r.insights, fmt.Sprintf("%s: %s", p.Name, insight))
+	}
+	r.mu.Unlock()
+
+	event := Event{Type: eventType, Round: round, PersonaID: p.ID, PersonaName: p.Name, Response: &resp}
+	if err != nil {
+		event.Error = resp.Error
+	}
+	e.publish(r, event)
+
+	for _, insight := range insights {
+		e.publish(r, Event{Type: EventInsight, Round: round, PersonaID: p.ID, PersonaName: p.Name, Insight: insight})
+	}
+	if resp.TokensUsed > 0 {
+		e.publish(r, Event{Type: EventCostUpdated, Round: round, PersonaID: p.ID, PersonaName: p.Name})
+	}
+
+	return resp
+}
+
+// finish records the outcome of an analysis and publishes its final event
+func (e *Engine) finish(ctx context.Context, r *run) (*Result, error) {
+	result := r.result
+	completedAt := time.Now()
+	result.CompletedAt = &completedAt
+	result.Duration = completedAt.Sub(result.StartedAt)
+
+	succeeded := 0
+	var confidence float64
+	for _, resp := range result.Responses {
+		if resp.Error == "" {
+			succeeded++
+			confidence += resp.Confidence
+		}
+	}
+
+	var runErr error
+	eventType := EventCompleted
+	switch {
+	case ctx.Err() != nil:
+		result.Status = StatusCancelled
+		result.Error = "analysis cancelled"
+		runErr = ctx.Err()
+		eventType = EventCancelled
+	case succeeded == 0:
+		result.Status = StatusFailed
+		result.Error = "no persona produced a response"
+		for _, resp := range result.Responses {
+			if resp.Error != "" {
+				result.Error = resp.Error
+				break
+			}
+		}
+		runErr = errors.New(result.Error)
+		eventType = EventFailed
+	default:
+		result.Status = StatusCompleted
+	}
+
+	if succeeded > 0 {
+		result.Summary = fmt.Sprintf("%d response(s) from the board over %d round(s), average confidence %.0f%%",
+			succeeded, result.Metrics.Rounds, confidence/float64(succeeded)*100)
+	}
+	result.Insights = uniqueInsights(r.insights)
+
+	if err := r.repos.Analyses.SaveResult(result); err != nil {
+		e.logger.Error("Failed to save analysis result", "result_id", result.ID, "error", err)
+		if runErr == nil {
+			runErr = err
+		}
+	}
+
+	e.logger.Info("Analysis finished", "result_id", result.ID, "status", result.Status, "duration", result.Duration)
+	e.publish(r, Event{Type: eventType, Round: result.Metrics.Rounds, Error: result.Error})
+
+	return result, runErr
+}
+
+// publish stamps an event with the run's identifiers, sequence number and
+// metrics and sends it
+func (e *Engine) publish(r *run, event Event) {
+	r.publishing.Lock()
+	defer r.publishing.Unlock()
+
+	r.mu.Lock()
+	event.Metrics = r.result.Metrics
+	r.mu.Unlock()
+
+	r.seq++
+	event.Seq = r.seq
+
+	event.WorkspaceID = r.req.WorkspaceID
+	event.RequestID = r.req.ID
+	event.ResultID = r.result.ID
+	if event.Rounds == 0 {
+		event.Rounds = r.req.Rounds
+	}
+	event.Time = time.Now()
+	e.events.publish(event)
+}
+
+// buildPrompt builds the question put to the board in a given round
+func buildPrompt(req *Request, round int) string {
+	var s strings.Builder
+	s.WriteString(modeInstructions[req.Mode])
+	s.WriteString("\n\nTopic: ")
+	s.WriteString(req.Topic)
+	if round > 1 {
+		fmt.Fprintf(&s, "\n\nThis is round %d of %d. Respond to the points the other board members made in the conversation so far.", round, req.Rounds)
+	}
+	return s.String()
+}
+
+// uniqueInsights removes duplicate insights and caps their number
+func uniqueInsights(insights []string) []string {
+	seen := make(map[string]bool, len(insights))
+	unique := make([]string, 0, len(insights))
+	for _, insight := range insights {
+		if seen[insight] {
+			continue
+		}
+		seen[insight] = true
+		unique = append(unique, insight)
+		if len(unique) == maxInsights {
+			break
+		}
+	}
+	return unique
+}
@@ -0,0 +1,114 @@
+package analysis
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType identifies a kind of analysis progress event
+type EventType string
+
+// Analysis progress events
+const (
+	EventStarted          EventType = "started"
+	EventRoundStarted     EventType = "round_started"
+	EventPersonaStarted   EventType = "persona_started"
+	EventPersonaChunk     EventType = "persona_chunk"
+	EventPersonaCompleted EventType = "persona_completed"
+	EventPersonaFailed    EventType = "persona_failed"
+	EventInsight          EventType = "insight"
+	EventCostUpdated      EventType = "cost_updated"
+	EventCompleted        EventType = "completed"
+	EventFailed           EventType = "failed"
+	EventCancelled        EventType = "cancelled"
+)
+
+// Participant is a persona taking part in an analysis
+type Participant struct {
+	PersonaID   string `json:"persona_id"`
+	PersonaName string `json:"persona_name"`
+	Role        string `json:"role,omitempty"`
+}
+
+// Event reports progress of a running analysis. Seq numbers the events of an
+// analysis from 1 in the order they were published, so that a consumer can
+// tell which events it has already seen.
+type Event struct {
+	Seq          int64            `json:"seq"`
+	Type         EventType        `json:"type"`
+	WorkspaceID  string           `json:"workspace_id,omitempty"`
+	RequestID    string           `json:"request_id"`
+	ResultID     string           `json:"result_id"`
+	Round        int              `json:"round,omitempty"`
+	Rounds       int              `json:"rounds,omitempty"`
+	PersonaID    string           `json:"persona_id,omitempty"`
+	PersonaName  string           `json:"persona_name,omitempty"`
+	Chunk        string           `json:"chunk,omitempty"`
+	Insight      string           `json:"insight,omitempty"`
+	Response     *PersonaResponse `json:"response,omitempty"`
+	Participants []Participant    `json:"participants,omitempty"`
+	Metrics      Metrics          `json:"metrics"`
+	Error        string           `json:"error,omitempty"`
+	Time         time.Time        `json:"time"`
+}
+
+// Final reports whether the event ends an analysis
+func (e Event) Final() bool {
+	return e.Type == EventCompleted || e.Type == EventFailed || e.Type == EventCancelled
+}
+
+// broadcaster fans events out to subscribers
+type broadcaster struct {
+	mu          sync.Mutex
+	subscribers map[int]chan Event
+	nextID      int
+}
+
+// subscribe registers a receiver with the given buffer size. The returned
+// function unsubscribes and closes the event channel. A subscriber that falls
+// so far behind that its buffer fills up is dropped, and its channel closed.
+func (b *broadcaster) subscribe(buffer int) (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subscribers == nil {
+		b.subscribers = make(map[int]chan Event)
+	}
+	id := b.nextID
+	b.nextID++
+	events := make(chan Event, buffer)
+	b.subscribers[id] = events
+
+	return events, func() { b.drop(id) }
+}
+
+// drop unsubscribes a receiver, closing its event channel
+func (b *broadcaster) drop(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if events, ok := b.subscribers[id]; ok {
+		delete(b.subscribers, id)
+		close(events)
+	}
+}
+
+// publish delivers an event to every subscriber without waiting for any of
+// them. Text chunks are skipped for subscribers whose buffer is full; a
+// subscriber with no room for any other event is dropped, so that it finds
+// its channel closed rather than silently missing the event.
+func (b *broadcaster) publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for id, events := range b.subscribers {
+		select {
+		case events <- event:
+		default:
+			if event.Type != EventPersonaChunk {
+				delete(b.subscribers, id)
+				close(events)
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPublishDropsSlowSubscribers(t *testing.T) {
+	var b broadcaster
+	slow, _ := b.subscribe(1)
+	fast, unsubscribe := b.subscribe(8)
+	defer unsubscribe()
+
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		b.publish(Event{Seq: 1, Type: EventStarted})
+		b.publish(Event{Seq: 2, Type: EventPersonaChunk})
+		b.publish(Event{Seq: 3, Type: EventRoundStarted})
+		b.publish(Event{Seq: 4, Type: EventCompleted})
+	}()
+	select {
+	case <-published:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publish waited for a subscriber that is not reading")
+	}
+
+	// The slow subscriber missed a chunk, then was dropped for the next event
+	if event, ok := <-slow; !ok || event.Seq != 1 {
+		t.Errorf("slow subscriber got %+v, %v; want the first event", event, ok)
+	}
+	if event, ok := <-slow; ok {
+		t.Errorf("slow subscriber got %+v after falling behind, want its channel closed", event)
+	}
+
+	for seq := int64(1); seq <= 4; seq++ {
+		if event := <-fast; event.Seq != seq {
+			t.Errorf("fast subscriber got event %d, want %d", event.Seq, seq)
+		}
+	}
+	if len(b.subscribers) != 1 {
+		t.Errorf("%d subscribers, want only the fast one", len(b.subscribers))
+	}
+}
+
+func TestUnsubscribeClosesChannel(t *testing.T) {
+	var b broadcaster
+	events, unsubscribe := b.subscribe(1)
+	unsubscribe()
+	unsubscribe()
+
+	if _, ok := <-events; ok {
+		t.Error("received an event after unsubscribing")
+	}
+	b.publish(Event{Type: EventStarted})
+}
@@ -0,0 +1,203 @@
+package analysis
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"personal-ai-board/internal/board"
+	"personal-ai-board/internal/project"
+)
+
+// InMemoryStorage is a Repository that keeps analyses in memory. It behaves
+// like Storage, so that code running analyses can be tested without SQLite.
+type InMemoryStorage struct {
+	mu       sync.RWMutex
+	requests map[string]*Request
+	results  map[string]*Result
+	projects project.Repository
+	boards   board.Repository
+}
+
+// NewInMemoryStorage creates an empty in-memory analysis store. The names of
+// projects and boards in listed results are looked up in projects and boards,
+// either of which may be nil.
+func NewInMemoryStorage(projects project.Repository, boards board.Repository) *InMemoryStorage {
+	return &InMemoryStorage{
+		requests: make(map[string]*Request),
+		results:  make(map[string]*Result),
+		projects: projects,
+		boards:   boards,
+	}
+}
+
+// SaveRequest saves an analysis request
+func (s *InMemoryStorage) SaveRequest(req *Request) error {
+	stored := &Request{}
+	if err := copyJSON(req, stored); err != nil {
+		return fmt.Errorf("failed to save analysis request: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.requests[req.ID]; ok {
+		return fmt.Errorf("failed to save analysis request: %s already exists", req.ID)
+	}
+	s.requests[req.ID] = stored
+
+	return nil
+}
+
+// SaveResult inserts or updates an analysis result. An update only changes
+// the outcome, leaving the request, project, board, mode and start time as
+// first saved.
+func (s *InMemoryStorage) SaveResult(result *Result) error {
+	stored := &Result{}
+	if err := copyJSON(result, stored); err != nil {
+		return fmt.Errorf("failed to save analysis result: %w", err)
+	}
+	stored.Duration = result.Duration.Truncate(time.Millisecond)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.results[result.ID]
+	if !ok {
+		if _, ok := s.requests[result.RequestID]; !ok {
+			return fmt.Errorf("failed to save analysis result: request not found: %s", result.RequestID)
+		}
+		s.results[result.ID] = stored
+		return nil
+	}
+
+	existing.Status = stored.Status
+	existing.Summary = stored.Summary
+	existing.Insights = stored.Insights
+	existing.Responses = stored.Responses
+	existing.Metrics = stored.Metrics
+	existing.Topic = stored.Topic
+	existing.Error = stored.Error
+	existing.CompletedAt = stored.CompletedAt
+	existing.Duration = stored.Duration
+
+	return nil
+}
+
+// LoadResult loads an analysis result
+func (s *InMemoryStorage) LoadResult(id string) (*Result, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored, ok := s.results[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to load analysis result: %w", sql.ErrNoRows)
+	}
+
+	result := &Result{}
+	if err := copyJSON(stored, result); err != nil {
+		return nil, fmt.Errorf("failed to load analysis result: %w", err)
+	}
+	return result, nil
+}
+
+// ListResults returns the most recent analysis results. A limit of zero returns all results.
+func (s *InMemoryStorage) ListResults(limit int) ([]ResultInfo, error) {
+	return s.PageResults(limit, 0)
+}
+
+// PageResults returns a page of analysis results, most recent first
+func (s *InMemoryStorage) PageResults(limit, offset int) ([]ResultInfo, error) {
+	projectNames, boardNames, err := s.names()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := make([]ResultInfo, 0, len(s.results))
+	for _, result := range s.results {
+		results = append(results, ResultInfo{
+			ID:          result.ID,
+			ProjectID:   result.ProjectID,
+			ProjectName: projectNames[result.ProjectID],
+			BoardID:     result.BoardID,
+			BoardName:   boardNames[result.BoardID],
+			Mode:        result.Mode,
+			Topic:       result.Topic,
+			Status:      result.Status,
+			Summary:     result.Summary,
+			StartedAt:   result.StartedAt,
+			Duration:    result.Duration,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].StartedAt.After(results[j].StartedAt)
+	})
+	start, end := pageBounds(len(results), limit, offset)
+
+	return results[start:end], nil
+}
+
+// CountResults returns the number of analysis results
+func (s *InMemoryStorage) CountResults() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.results), nil
+}
+
+// names looks up the names of projects and boards by ID
+func (s *InMemoryStorage) names() (map[string]string, map[string]string, error) {
+	projectNames := make(map[string]string)
+	if s.projects != nil {
+		projects, err := s.projects.ListProjects()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to query analysis results: %w", err)
+		}
+		for _, info := range projects {
+			projectNames[info.ID] = info.Name
+		}
+	}
+
+	boardNames := make(map[string]string)
+	if s.boards != nil {
+		boards, err := s.boards.ListBoards()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to query analysis results: %w", err)
+		}
+		for _, info := range boards {
+			boardNames[info.ID] = info.Name
+		}
+	}
+
+	return projectNames, boardNames, nil
+}
+
+// copyJSON deep-copies a value through JSON, as saving it to the database and
+// loading it back would
+func copyJSON(from, to interface{}) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
+
+// pageBounds returns the range of indexes of a page of n items. A limit of
+// zero takes the rest of the items from offset.
+func pageBounds(n, limit, offset int) (int, int) {
+	start := offset
+	if start > n {
+		start = n
+	}
+	end := n
+	if limit > 0 && start+limit < n {
+		end = start + limit
+	}
+	return start, end
+}
@@ -0,0 +1,36 @@
+package analysis
+
+import (
+	"personal-ai-board/internal/board"
+	"personal-ai-board/internal/persona"
+	"personal-ai-board/internal/project"
+)
+
+// Repository stores analysis requests and their results. Saving a request
+// twice is an error, and a result can only be saved for a saved request.
+// LoadResult returns an error wrapping sql.ErrNoRows for an unknown result.
+type Repository interface {
+	SaveRequest(req *Request) error
+	// SaveResult inserts a result, or updates the outcome of one already saved
+	SaveResult(result *Result) error
+	LoadResult(id string) (*Result, error)
+	// ListResults returns the most recent results. A limit of zero returns
+	// them all.
+	ListResults(limit int) ([]ResultInfo, error)
+	// PageResults returns results in the order of ListResults, skipping
+	// offset of them. A limit of zero returns the rest.
+	PageResults(limit, offset int) ([]ResultInfo, error)
+	CountResults() (int, error)
+}
+
+// Repositories are the stores an Engine reads boards, projects and personas
+// from and saves analyses to
+type Repositories struct {
+	Boards   board.Repository
+	Projects project.Repository
+	Personas persona.PersonaRepository
+	Analyses Repository
+}
+
+// Workspaces opens the repositories of a workspace
+type Workspaces func(workspaceID string) Repositories
@@ -0,0 +1,82 @@
+package analysis
+
+import "time"
+
+// Analysis statuses
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// Request describes an analysis of a project by a board
+type Request struct {
+	ID          string                 `json:"id"`
+	WorkspaceID string                 `json:"workspace_id,omitempty"` // The default workspace when empty
+	ProjectID   string                 `json:"project_id"`
+	BoardID     string                 `json:"board_id"`
+	Mode        string                 `json:"mode"`
+	Topic       string                 `json:"topic"`
+	Rounds      int                    `json:"rounds"`
+	Context     map[string]interface{} `json:"context,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+}
+
+// PersonaResponse is one persona's contribution to a round of analysis
+type PersonaResponse struct {
+	PersonaID     string    `json:"persona_id"`
+	PersonaName   string    `json:"persona_name"`
+	Round         int       `json:"round"`
+	Content       string    `json:"content"`
+	Confidence    float64   `json:"confidence"`
+	EmotionalTone string    `json:"emotional_tone"`
+	TokensUsed    int       `json:"tokens_used"`
+	CostUSD       float64   `json:"cost_usd"`
+	Error         string    `json:"error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Metrics summarizes the cost of an analysis
+type Metrics struct {
+	Rounds      int     `json:"rounds"`
+	Responses   int     `json:"responses"`
+	TotalTokens int     `json:"total_tokens"`
+	CostUSD     float64 `json:"cost_usd"`
+}
+
+// Result is the outcome of an analysis
+type Result struct {
+	ID          string            `json:"id"`
+	RequestID   string            `json:"request_id"`
+	ProjectID   string            `json:"project_id"`
+	BoardID     string            `json:"board_id"`
+	Mode        string            `json:"mode"`
+	Topic       string            `json:"topic"`
+	Status      string            `json:"status"`
+	Summary     string            `json:"summary"`
+	Insights    []string          `json:"insights"`
+	Responses   []PersonaResponse `json:"responses"`
+	Metrics     Metrics           `json:"metrics"`
+	Error       string            `json:"error,omitempty"`
+	StartedAt   time.Time         `json:"started_at"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+	Duration    time.Duration     `json:"duration"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// ResultInfo contains summary information about an analysis result
+type ResultInfo struct {
+	ID          string        `json:"id"`
+	ProjectID   string        `json:"project_id"`
+	ProjectName string        `json:"project_name"`
+	BoardID     string        `json:"board_id"`
+	BoardName   string        `json:"board_name"`
+	Mode        string        `json:"mode"`
+	Topic       string        `json:"topic"`
+	Status      string        `json:"status"`
+	Summary     string        `json:"summary"`
+	StartedAt   time.Time     `json:"started_at"`
+	Duration    time.Duration `json:"duration"`
+}
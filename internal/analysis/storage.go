@@ -0,0 +1,241 @@
+package analysis
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"personal-ai-board/internal/auth"
+)
+
+// Storage handles database operations for the analysis requests and results
+// of one workspace
+type Storage struct {
+	db        *sql.DB
+	workspace string
+}
+
+// NewStorage creates a storage instance for the default workspace
+func NewStorage(db *sql.DB) *Storage {
+	return NewWorkspaceStorage(db, auth.DefaultWorkspaceID)
+}
+
+// NewWorkspaceStorage creates a storage instance for the analyses of a
+// workspace
+func NewWorkspaceStorage(db *sql.DB, workspaceID string) *Storage {
+	return &Storage{db: db, workspace: workspaceID}
+}
+
+// resultMetadata holds result fields that have no column of their own
+type resultMetadata struct {
+	Topic string `json:"topic,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// SaveRequest saves an analysis request to the database
+func (s *Storage) SaveRequest(req *Request) error {
+	config, err := json.Marshal(map[string]interface{}{
+		"topic":   req.Topic,
+		"rounds":  req.Rounds,
+		"context": req.Context,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to serialize request config: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO analysis_requests (id, workspace_id, project_id, board_id, mode, config, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, req.ID, s.workspace, req.ProjectID, req.BoardID, req.Mode, string(config), req.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save analysis request: %w", err)
+	}
+
+	return nil
+}
+
+// SaveResult inserts or updates an analysis result
+func (s *Storage) SaveResult(result *Result) error {
+	insights, err := json.Marshal(result.Insights)
+	if err != nil {
+		return fmt.Errorf("failed to serialize insights: %w", err)
+	}
+	responses, err := json.Marshal(result.Responses)
+	if err != nil {
+		return fmt.Errorf("failed to serialize responses: %w", err)
+	}
+	metrics, err := json.Marshal(result.Metrics)
+	if err != nil {
+		return fmt.Errorf("failed to serialize metrics: %w", err)
+	}
+	metadata, err := json.Marshal(resultMetadata{Topic: result.Topic, Error: result.Error})
+	if err != nil {
+		return fmt.Errorf("failed to serialize metadata: %w", err)
+	}
+
+	// A result of another workspace is left alone
+	res, err := s.db.Exec(`
+		INSERT INTO analysis_results (
+			id, workspace_id, request_id, project_id, board_id, mode, status, summary, insights,
+			responses, metrics, metadata, started_at, completed_at, duration_ms, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			status = excluded.status,
+			summary = excluded.summary,
+			insights = excluded.insights,
+			responses = excluded.responses,
+			metrics = excluded.metrics,
+			metadata = excluded.metadata,
+			completed_at = excluded.completed_at,
+			duration_ms = excluded.duration_ms
+		WHERE analysis_results.workspace_id = excluded.workspace_id
+	`, result.ID, s.workspace, result.RequestID, result.ProjectID, result.BoardID, result.Mode, result.Status,
+		result.Summary, string(insights), string(responses), string(metrics), string(metadata),
+		result.StartedAt, result.CompletedAt, result.Duration.Milliseconds(), result.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save analysis result: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return fmt.Errorf("failed to save analysis result %s: %w", result.ID, sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// LoadResult loads an analysis result from the database
+func (s *Storage) LoadResult(id string) (*Result, error) {
+	var result Result
+	var summary, insights, responses, metrics, metadata sql.NullString
+	var completedAt sql.NullTime
+	var durationMs int64
+
+	err := s.db.QueryRow(`
+		SELECT id, request_id, project_id, board_id, mode, status, summary, insights,
+		       responses, metrics, metadata, started_at, completed_at, duration_ms, created_at
+		FROM analysis_results WHERE id = ? AND workspace_id = ?
+	`, id, s.workspace).Scan(
+		&result.ID,
+		&result.RequestID,
+		&result.ProjectID,
+		&result.BoardID,
+		&result.Mode,
+		&result.Status,
+		&summary,
+		&insights,
+		&responses,
+		&metrics,
+		&metadata,
+		&result.StartedAt,
+		&completedAt,
+		&durationMs,
+		&result.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load analysis result: %w", err)
+	}
+
+	result.Summary = summary.String
+	result.Duration = time.Duration(durationMs) * time.Millisecond
+	if completedAt.Valid {
+		result.CompletedAt = &completedAt.Time
+	}
+
+	fields := []struct {
+		data   sql.NullString
+		target interface{}
+		name   string
+	}{
+		{insights, &result.Insights, "insights"},
+		{responses, &result.Responses, "responses"},
+		{metrics, &result.Metrics, "metrics"},
+	}
+	for _, field := range fields {
+		if !field.data.Valid || field.data.String == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(field.data.String), field.target); err != nil {
+			return nil, fmt.Errorf("failed to deserialize %s: %w", field.name, err)
+		}
+	}
+
+	if metadata.Valid && metadata.String != "" {
+		var meta resultMetadata
+		if err := json.Unmarshal([]byte(metadata.String), &meta); err == nil {
+			result.Topic = meta.Topic
+			result.Error = meta.Error
+		}
+	}
+
+	return &result, nil
+}
+
+// ListResults returns the most recent analysis results. A limit of zero returns all results.
+func (s *Storage) ListResults(limit int) ([]ResultInfo, error) {
+	return s.PageResults(limit, 0)
+}
+
+// PageResults returns a page of analysis results, most recent first
+func (s *Storage) PageResults(limit, offset int) ([]ResultInfo, error) {
+	if limit == 0 {
+		limit = -1 // SQLite reads a negative limit as no limit
+	}
+	rows, err := s.db.Query(`
+		SELECT r.id, r.project_id, COALESCE(p.name, ''), r.board_id, COALESCE(b.name, ''),
+		       r.mode, r.status, COALESCE(r.summary, ''), COALESCE(r.metadata, ''),
+		       r.started_at, r.duration_ms
+		FROM analysis_results r
+		LEFT JOIN projects p ON p.id = r.project_id
+		LEFT JOIN boards b ON b.id = r.board_id
+		WHERE r.workspace_id = ?
+		ORDER BY r.started_at DESC
+		LIMIT ? OFFSET ?
+	`, s.workspace, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query analysis results: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]ResultInfo, 0)
+	for rows.Next() {
+		var info ResultInfo
+		var metadata string
+		var durationMs int64
+		err := rows.Scan(
+			&info.ID,
+			&info.ProjectID,
+			&info.ProjectName,
+			&info.BoardID,
+			&info.BoardName,
+			&info.Mode,
+			&info.Status,
+			&info.Summary,
+			&metadata,
+			&info.StartedAt,
+			&durationMs,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan analysis result: %w", err)
+		}
+
+		info.Duration = time.Duration(durationMs) * time.Millisecond
+		if metadata != "" {
+			var meta resultMetadata
+			if err := json.Unmarshal([]byte(metadata), &meta); err == nil {
+				info.Topic = meta.Topic
+			}
+		}
+		results = append(results, info)
+	}
+
+	return results, rows.Err()
+}
+
+// CountResults returns the number of analysis results
+func (s *Storage) CountResults() (int, error) {
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM analysis_results WHERE workspace_id = ?", s.workspace).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count analysis results: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"personal-ai-board/internal/analysis"
+)
+
+// maxRounds limits the discussion rounds of an analysis started through the API
+const maxRounds = 10
+
+// analysisRequest is the body of a request to start an analysis
+type analysisRequest struct {
+	BoardID   string                 `json:"board_id"`
+	ProjectID string                 `json:"project_id"`
+	Mode      string                 `json:"mode"`
+	Topic     string                 `json:"topic"`
+	Rounds    int                    `json:"rounds"`
+	Context   map[string]interface{} `json:"context"`
+}
+
+// listAnalyses returns a page of analysis results, most recent first
+func (s *Server) listAnalyses(c *gin.Context) {
+	p, err := page(c)
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	repo := s.repos(c).Analyses
+	if p.Total, err = repo.CountResults(); err != nil {
+		s.fail(c, err)
+		return
+	}
+	results, err := repo.PageResults(p.Limit, p.Offset)
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	respondPage(c, p, results)
+}
+
+// startAnalysis starts an analysis in the background. The response is the
+// running result, whose ID is the session to poll or stream for the outcome.
+func (s *Server) startAnalysis(c *gin.Context) {
+	if s.engine == nil {
+		s.fail(c, &Error{Status: http.StatusServiceUnavailable, Code: CodeUnavailable,
+			Message: "analyses are unavailable because no LLM provider is configured"})
+		return
+	}
+
+	var req analysisRequest
+	if err := bind(c, &req); err != nil {
+		s.fail(c, err)
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = s.defaultMode
+	}
+	if req.Rounds == 0 {
+		req.Rounds = 1
+	}
+
+	switch {
+	case req.BoardID == "" || req.ProjectID == "":
+		s.fail(c, invalidf("board_id and project_id are required"))
+		return
+	case strings.TrimSpace(req.Topic) == "":
+		s.fail(c, invalidf("topic is required"))
+		return
+	case !analysis.ValidMode(req.Mode):
+		s.fail(c, invalidf("invalid analysis mode: %s (must be one of: %s)", req.Mode, strings.Join(analysis.Modes, ", ")))
+		return
+	case req.Rounds < 1 || req.Rounds > maxRounds:
+		s.fail(c, invalidf("rounds must be between 1 and %d", maxRounds))
+		return
+	}
+
+	b, err := s.loadBoard(c, req.BoardID)
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+	if len(b.Members) == 0 {
+		s.fail(c, invalidf("board %s has no members", b.ID))
+		return
+	}
+	if _, err := s.loadProject(c, req.ProjectID); err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	result, err := s.engine.Start(s.ctx, &analysis.Request{
+		BoardID:     req.BoardID,
+		ProjectID:   req.ProjectID,
+		WorkspaceID: workspaceID(c),
+		Mode:        req.Mode,
+		Topic:       req.Topic,
+		Rounds:      req.Rounds,
+		Context:     req.Context,
+	})
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+	s.feed.open(result.ID)
+
+	c.Header("Location", "/api/v1/analyses/"+result.ID)
+	respond(c, http.StatusAccepted, result)
+}
+
+// getAnalysis returns an analysis result. Its status is running until the
+// analysis has completed, failed or been cancelled.
+func (s *Server) getAnalysis(c *gin.Context) {
+	id := c.Param("id")
+	result, err := s.repos(c).Analyses.LoadResult(id)
+	if err != nil {
+		s.fail(c, lookupError("analysis", id, err))
+		return
+	}
+	respond(c, http.StatusOK, result)
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"personal-ai-board/internal/analysis"
+	"personal-ai-board/internal/auth"
+	"personal-ai-board/internal/board"
+	"personal-ai-board/internal/persona"
+	"personal-ai-board/internal/project"
+)
+
+// Logger interface for structured logging
+type Logger interface {
+	Info(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+	Debug(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+}
+
+// Repositories are the stores the API reads from and writes to
+type Repositories struct {
+	Personas persona.Repository
+	Boards   board.Repository
+	Projects project.Repository
+	Analyses analysis.Repository
+	Webhooks Notifier // May be nil, to send no webhook events
+}
+
+// Notifier queues webhook events
+type Notifier interface {
+	Notify(eventType string, data interface{}) (int, error)
+}
+
+// Workspaces opens the repositories of a workspace
+type Workspaces func(workspaceID string) Repositories
+
+// Authenticator identifies the user an API token belongs to. It returns
+// auth.ErrInvalidToken for a token it does not accept.
+type Authenticator interface {
+	Authenticate(token string) (*auth.User, error)
+}
+
+// Server serves the versioned JSON API under /api/v1. It is an http.Handler,
+// so it can be mounted in an http.Server or exercised with httptest.
+type Server struct {
+	workspaces  Workspaces
+	auth        Authenticator
+	engine      *analysis.Engine
+	traits      *persona.TraitLoader
+	defaultMode string
+	logger      Logger
+	router      *gin.Engine
+
+	// feed records the events of running analyses for their event streams
+	feed *feed
+
+	// personaLocks keeps completions from losing each other's memories
+	personaLocks personaLocks
+
+	// ctx is cancelled by Close to stop the analyses started through the API
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// New creates an API server over the repositories of each workspace.
+// authenticator identifies the user behind each request, whose workspace the
+// request acts on; it may be nil to serve every request, unauthenticated, in
+// the default workspace. engine runs the analyses started through the API,
+// and may be nil when no LLM provider is configured. traits resolves the
+// traits of personas created or edited through the API, and defaultMode is
+// the analysis mode used when a request names none.
+func New(workspaces Workspaces, authenticator Authenticator, engine *analysis.Engine, traits *persona.TraitLoader, defaultMode string, logger Logger) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		workspaces:  workspaces,
+		auth:        authenticator,
+		engine:      engine,
+		traits:      traits,
+		defaultMode: defaultMode,
+		logger:      logger,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	if engine != nil {
+		s.feed = newFeed(engine)
+	}
+	s.router = s.routes()
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
+// Close cancels the analyses still running, waits for them to save what they
+// produced and ends the event streams
+func (s *Server) Close() {
+	s.cancel()
+	if s.engine != nil {
+		s.engine.Wait()
+	}
+	if s.feed != nil {
+		s.feed.close()
+	}
+}
+
+// routes registers the API's endpoints
+func (s *Server) routes() *gin.Engine {
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	router.Use(s.logRequests(), gin.CustomRecovery(s.recovered))
+	router.NoRoute(func(c *gin.Context) {
+		s.fail(c, notFoundf("no such endpoint: %s %s", c.Request.Method, c.Request.URL.Path))
+	})
+	router.NoMethod(func(c *gin.Context) {
+		s.fail(c, &Error{Status: http.StatusMethodNotAllowed, Code: CodeMethodNotAllowed,
+			Message: c.Request.Method + " is not allowed on " + c.Request.URL.Path})
+	})
+
+	router.GET("/api/v1/health", s.health)
+
+	v1 := router.Group("/api/v1", s.authenticate(s.fail))
+	v1.GET("/me", s.me)
+
+	v1.GET("/personas", s.listPersonas)
+	v1.POST("/personas", s.createPersona)
+	v1.GET("/personas/:id", s.getPersona)
+	v1.PUT("/personas/:id", s.updatePersona)
+	v1.DELETE("/personas/:id", s.deletePersona)
+
+	v1.GET("/boards", s.listBoards)
+	v1.POST("/boards", s.createBoard)
+	v1.GET("/boards/:id", s.getBoard)
+	v1.PUT("/boards/:id", s.updateBoard)
+	v1.DELETE("/boards/:id", s.deleteBoard)
+
+	v1.GET("/projects", s.listProjects)
+	v1.POST("/projects", s.createProject)
+	v1.GET("/projects/:id", s.getProject)
+	v1.PUT("/projects/:id", s.updateProject)
+	v1.DELETE("/projects/:id", s.deleteProject)
+
+	v1.GET("/projects/:id/ideas", s.listIdeas)
+	v1.POST("/projects/:id/ideas", s.createIdea)
+	v1.GET("/projects/:id/ideas/:idea_id", s.getIdea)
+	v1.PUT("/projects/:id/ideas/:idea_id", s.updateIdea)
+	v1.DELETE("/projects/:id/ideas/:idea_id", s.deleteIdea)
+
+	v1.GET("/analyses", s.listAnalyses)
+	v1.POST("/analyses", s.startAnalysis)
+	v1.GET("/analyses/:id", s.getAnalysis)
+	v1.GET("/analyses/:id/events", s.streamAnalysis)
+
+	// OpenAI-compatible endpoints, where models are personas and boards
+	openai := router.Group("/v1", s.authenticate(s.failCompletion))
+	openai.GET("/models", s.listModels)
+	openai.POST("/chat/completions", s.createCompletion)
+
+	return router
+}
+
+// health reports that the server is up and whether it can run analyses
+func (s *Server) health(c *gin.Context) {
+	respond(c, http.StatusOK, gin.H{
+		"status":    "ok",
+		"analyses":  s.engine != nil,
+		"timestamp": time.Now(),
+	})
+}
+
+// logRequests logs every request with its status and duration
+func (s *Server) logRequests() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		s.logger.Info("API request",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", c.Writer.Status(),
+			"duration", time.Since(start))
+	}
+}
+
+// recovered turns a panic in a handler into an internal error response
+func (s *Server) recovered(c *gin.Context, recovered interface{}) {
+	s.logger.Error("API handler panicked", "path", c.Request.URL.Path, "panic", recovered)
+	s.fail(c, &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: "internal server error"})
+}
+
+// newID creates a unique identifier with the given prefix
+func newID(prefix string) string {
+	return fmt.Sprintf("%s_%d", prefix, time.Now().UnixNano())
+}
@@ -0,0 +1,380 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"personal-ai-board/internal/analysis"
+	"personal-ai-board/internal/auth"
+	"personal-ai-board/internal/llm"
+	"personal-ai-board/internal/llm/providers"
+	"personal-ai-board/internal/llm/types"
+	"personal-ai-board/internal/persona"
+	"personal-ai-board/internal/repotest"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Warn(string, ...interface{})  {}
+
+// tokenUsers authenticates each token as a user of the workspace of the same name
+type tokenUsers map[string]string
+
+func (t tokenUsers) Authenticate(token string) (*auth.User, error) {
+	workspace, ok := t[token]
+	if !ok {
+		return nil, auth.ErrInvalidToken
+	}
+	return &auth.User{ID: "user_" + workspace, Name: workspace, WorkspaceID: workspace}, nil
+}
+
+// testServer serves the API over in-memory repositories, one set for each
+// workspace, with analyses answered by the mock provider
+type testServer struct {
+	t       *testing.T
+	handler *Server
+	open    func(workspaceID string) repotest.Repositories
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var mu sync.Mutex
+	stores := make(map[string]repotest.Repositories)
+	open := func(workspaceID string) repotest.Repositories {
+		mu.Lock()
+		defer mu.Unlock()
+		repos, ok := stores[workspaceID]
+		if !ok {
+			repos = repotest.InMemory()
+			stores[workspaceID] = repos
+		}
+		return repos
+	}
+
+	// Questions asked concurrently take a while, so that their answers overlap
+	mock, err := providers.NewMockProvider(types.Config{Extra: map[string]interface{}{
+		"rules": []providers.MockRule{{Prompt: "concurrently", Response: "Noted.", Latency: "50ms"}},
+	}}, nopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager := llm.NewManager(nopLogger{})
+	if err := manager.RegisterProvider("mock", mock); err != nil {
+		t.Fatal(err)
+	}
+	engine := analysis.NewEngineWithWorkspaces(func(workspaceID string) analysis.Repositories {
+		return open(workspaceID).Engine()
+	}, llm.NewPersonaProvider(manager, "mock", ""), 2, nopLogger{})
+
+	workspaces := func(workspaceID string) Repositories {
+		repos := open(workspaceID)
+		return Repositories{Personas: repos.Personas, Boards: repos.Boards, Projects: repos.Projects, Analyses: repos.Analyses}
+	}
+	handler := New(workspaces, tokenUsers{"alice-token": "alice", "bob-token": "bob"}, engine,
+		persona.NewTraitLoader("../../config"), "discussion", nopLogger{})
+	t.Cleanup(handler.Close)
+
+	return &testServer{t: t, handler: handler, open: open}
+}
+
+// do sends a request with the token and decodes the response body into out,
+// returning the status
+func (s *testServer) do(method, path, token string, body, out interface{}) int {
+	s.t.Helper()
+	var reader bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reader).Encode(body); err != nil {
+			s.t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			s.t.Fatalf("%s %s: invalid response body: %v\n%s", method, path, err, rec.Body.String())
+		}
+	}
+	return rec.Code
+}
+
+// create posts body and returns the ID of what was created
+func (s *testServer) create(path, token string, body interface{}) string {
+	s.t.Helper()
+	var resp struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if status := s.do(http.MethodPost, path, token, body, &resp); status != http.StatusCreated {
+		s.t.Fatalf("POST %s: status %d", path, status)
+	}
+	return resp.Data.ID
+}
+
+// expectError checks that a request fails with the status and error code
+func (s *testServer) expectError(method, path, token string, body interface{}, status int, code string) {
+	s.t.Helper()
+	var resp ErrorResponse
+	got := s.do(method, path, token, body, &resp)
+	if got != status || resp.Error == nil || resp.Error.Code != code || resp.Error.Message == "" {
+		s.t.Errorf("%s %s: status %d, error %+v; want %d %s", method, path, got, resp.Error, status, code)
+	}
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	s := newTestServer(t)
+
+	s.expectError(http.MethodGet, "/api/v1/personas", "", nil, http.StatusUnauthorized, CodeUnauthorized)
+	s.expectError(http.MethodGet, "/api/v1/personas", "stolen", nil, http.StatusUnauthorized, CodeUnauthorized)
+	s.expectError(http.MethodGet, "/api/v1/nothing", "alice-token", nil, http.StatusNotFound, CodeNotFound)
+	s.expectError(http.MethodPatch, "/api/v1/personas", "alice-token", nil, http.StatusMethodNotAllowed, CodeMethodNotAllowed)
+	s.expectError(http.MethodPost, "/api/v1/personas", "alice-token", map[string]string{"name": " "}, http.StatusBadRequest, CodeInvalidRequest)
+	s.expectError(http.MethodPost, "/api/v1/analyses", "alice-token", map[string]string{"topic": "pricing"}, http.StatusBadRequest, CodeInvalidRequest)
+
+	// A body that is not JSON
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/projects", bytes.NewBufferString("{"))
+	req.Header.Set("Authorization", "Bearer alice-token")
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	var resp ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusBadRequest || resp.Error.Code != CodeInvalidRequest {
+		t.Errorf("invalid JSON: status %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	var health struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if status := s.do(http.MethodGet, "/api/v1/health", "", nil, &health); status != http.StatusOK || health.Data["status"] != "ok" {
+		t.Errorf("health: status %d, body %v", status, health.Data)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	s := newTestServer(t)
+	for i := 0; i < 5; i++ {
+		s.create("/api/v1/projects", "alice-token", map[string]string{"name": fmt.Sprintf("Project %d", i)})
+	}
+
+	for _, tc := range []struct {
+		query               string
+		limit, offset, size int
+	}{
+		{"", defaultPageSize, 0, 5},
+		{"?limit=2", 2, 0, 2},
+		{"?limit=2&offset=4", 2, 4, 1},
+		{"?offset=5", defaultPageSize, 5, 0},
+		{"?offset=50", defaultPageSize, 50, 0},
+		{fmt.Sprintf("?limit=%d", maxPageSize), maxPageSize, 0, 5},
+	} {
+		var resp struct {
+			Data       []map[string]interface{} `json:"data"`
+			Pagination Pagination               `json:"pagination"`
+		}
+		if status := s.do(http.MethodGet, "/api/v1/projects"+tc.query, "alice-token", nil, &resp); status != http.StatusOK {
+			t.Errorf("%s: status %d", tc.query, status)
+			continue
+		}
+		want := Pagination{Limit: tc.limit, Offset: tc.offset, Total: 5}
+		if resp.Pagination != want || len(resp.Data) != tc.size {
+			t.Errorf("%s: pagination %+v with %d items, want %+v with %d", tc.query, resp.Pagination, len(resp.Data), want, tc.size)
+		}
+		if resp.Data == nil {
+			t.Errorf("%s: data is null rather than a list", tc.query)
+		}
+	}
+
+	for _, query := range []string{"?limit=0", "?limit=-1", fmt.Sprintf("?limit=%d", maxPageSize+1), "?limit=ten", "?offset=-1", "?offset=x"} {
+		s.expectError(http.MethodGet, "/api/v1/projects"+query, "alice-token", nil, http.StatusBadRequest, CodeInvalidRequest)
+	}
+
+	// Ideas are paged within their project
+	projectID := s.create("/api/v1/projects", "alice-token", map[string]string{"name": "Ideas"})
+	for i := 0; i < 3; i++ {
+		s.create("/api/v1/projects/"+projectID+"/ideas", "alice-token", map[string]interface{}{"title": fmt.Sprintf("Idea %d", i), "priority": i})
+	}
+	var ideas struct {
+		Data []struct {
+			Title string `json:"title"`
+		} `json:"data"`
+		Pagination Pagination `json:"pagination"`
+	}
+	s.do(http.MethodGet, "/api/v1/projects/"+projectID+"/ideas?limit=1&offset=1", "alice-token", nil, &ideas)
+	if ideas.Pagination.Total != 3 || len(ideas.Data) != 1 || ideas.Data[0].Title != "Idea 1" {
+		t.Errorf("ideas: pagination %+v with %+v, want Idea 1 of 3", ideas.Pagination, ideas.Data)
+	}
+	emptyID := s.create("/api/v1/projects", "alice-token", map[string]string{"name": "Empty"})
+	if status := s.do(http.MethodGet, "/api/v1/projects/"+emptyID+"/ideas", "alice-token", nil, &ideas); status != http.StatusOK || ideas.Pagination.Total != 0 {
+		t.Errorf("project without ideas: status %d, pagination %+v", status, ideas.Pagination)
+	}
+	s.expectError(http.MethodGet, "/api/v1/projects/missing/ideas", "alice-token", nil, http.StatusNotFound, CodeNotFound)
+}
+
+func TestWorkspaces(t *testing.T) {
+	s := newTestServer(t)
+	personaID := s.create("/api/v1/personas", "alice-token", map[string]string{"name": "Alice's Advisor"})
+	projectID := s.create("/api/v1/projects", "alice-token", map[string]string{"name": "Alice's Project"})
+	boardID := s.create("/api/v1/boards", "alice-token", map[string]interface{}{
+		"name":    "Alice's Board",
+		"members": []map[string]string{{"persona_id": personaID}},
+	})
+
+	for _, path := range []string{
+		"/api/v1/personas/" + personaID,
+		"/api/v1/projects/" + projectID,
+		"/api/v1/boards/" + boardID,
+	} {
+		if status := s.do(http.MethodGet, path, "alice-token", nil, nil); status != http.StatusOK {
+			t.Errorf("GET %s in its own workspace: status %d", path, status)
+		}
+		s.expectError(http.MethodGet, path, "bob-token", nil, http.StatusNotFound, CodeNotFound)
+		s.expectError(http.MethodPut, path, "bob-token", map[string]string{"name": "Taken"}, http.StatusNotFound, CodeNotFound)
+		s.expectError(http.MethodDelete, path, "bob-token", nil, http.StatusNotFound, CodeNotFound)
+	}
+
+	var list struct {
+		Pagination Pagination `json:"pagination"`
+	}
+	s.do(http.MethodGet, "/api/v1/personas", "bob-token", nil, &list)
+	if list.Pagination.Total != 0 {
+		t.Errorf("bob sees %d of alice's personas", list.Pagination.Total)
+	}
+
+	// Bob cannot seat Alice's persona or analyze Alice's board and project
+	s.expectError(http.MethodPost, "/api/v1/boards", "bob-token", map[string]interface{}{
+		"name":    "Bob's Board",
+		"members": []map[string]string{{"persona_id": personaID}},
+	}, http.StatusBadRequest, CodeInvalidRequest)
+	s.expectError(http.MethodPost, "/api/v1/analyses", "bob-token", map[string]string{
+		"board_id": boardID, "project_id": projectID, "topic": "pricing",
+	}, http.StatusNotFound, CodeNotFound)
+}
+
+func TestAnalysis(t *testing.T) {
+	s := newTestServer(t)
+	first := s.create("/api/v1/personas", "alice-token", map[string]string{"name": "Skeptical CFO", "description": "a careful investor"})
+	second := s.create("/api/v1/personas", "alice-token", map[string]string{"name": "Optimist", "description": "a first-time founder"})
+	projectID := s.create("/api/v1/projects", "alice-token", map[string]string{"name": "Pricing"})
+	boardID := s.create("/api/v1/boards", "alice-token", map[string]interface{}{
+		"name":    "Advisors",
+		"members": []map[string]string{{"persona_id": first}, {"persona_id": second}},
+	})
+
+	var started struct {
+		Data analysis.Result `json:"data"`
+	}
+	status := s.do(http.MethodPost, "/api/v1/analyses", "alice-token", map[string]string{
+		"board_id": boardID, "project_id": projectID, "topic": "Should we raise prices?",
+	}, &started)
+	if status != http.StatusAccepted || started.Data.ID == "" {
+		t.Fatalf("start: status %d, result %+v", status, started.Data)
+	}
+
+	var polled struct {
+		Data analysis.Result `json:"data"`
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if status := s.do(http.MethodGet, "/api/v1/analyses/"+started.Data.ID, "alice-token", nil, &polled); status != http.StatusOK {
+			t.Fatalf("poll: status %d", status)
+		}
+		if polled.Data.Status != analysis.StatusRunning && polled.Data.Status != analysis.StatusPending {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("analysis still %s after 10s", polled.Data.Status)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if polled.Data.Status != analysis.StatusCompleted {
+		t.Fatalf("analysis %s: %s", polled.Data.Status, polled.Data.Error)
+	}
+	if len(polled.Data.Responses) < 2 || polled.Data.Topic != "Should we raise prices?" {
+		t.Errorf("%d responses about %q", len(polled.Data.Responses), polled.Data.Topic)
+	}
+
+	s.expectError(http.MethodGet, "/api/v1/analyses/"+started.Data.ID, "bob-token", nil, http.StatusNotFound, CodeNotFound)
+	s.expectError(http.MethodGet, "/api/v1/analyses/nope", "alice-token", nil, http.StatusNotFound, CodeNotFound)
+}
+
+func TestCompletionUsage(t *testing.T) {
+	s := newTestServer(t)
+	first := s.create("/api/v1/personas", "alice-token", map[string]string{"name": "Skeptical CFO", "description": "a careful investor"})
+	second := s.create("/api/v1/personas", "alice-token", map[string]string{"name": "Optimist", "description": "a first-time founder"})
+	boardID := s.create("/api/v1/boards", "alice-token", map[string]interface{}{
+		"name":    "Advisors",
+		"members": []map[string]string{{"persona_id": first}, {"persona_id": second}},
+	})
+
+	var personaUsage completionUsage
+	for _, model := range []string{personaModelPrefix + first, boardModelPrefix + boardID} {
+		var resp completion
+		status := s.do(http.MethodPost, "/v1/chat/completions", "alice-token", map[string]interface{}{
+			"model":    model,
+			"messages": []map[string]string{{"role": "user", "content": "Should we raise prices?"}},
+		}, &resp)
+		if status != http.StatusOK || resp.Usage == nil {
+			t.Fatalf("%s: status %d", model, status)
+		}
+		usage := *resp.Usage
+		if usage.PromptTokens == 0 || usage.CompletionTokens == 0 || usage.PromptTokens+usage.CompletionTokens != usage.TotalTokens {
+			t.Errorf("%s: usage %+v", model, usage)
+		}
+		if model == personaModelPrefix+first {
+			personaUsage = usage
+		} else if usage.TotalTokens <= personaUsage.TotalTokens {
+			t.Errorf("board used %d tokens, no more than one persona's %d", usage.TotalTokens, personaUsage.TotalTokens)
+		}
+	}
+}
+
+func TestConcurrentCompletionsKeepMemories(t *testing.T) {
+	s := newTestServer(t)
+	personaID := s.create("/api/v1/personas", "alice-token", map[string]string{"name": "Skeptical CFO", "description": "a careful investor"})
+
+	const questions = 4
+	var wg sync.WaitGroup
+	for i := 0; i < questions; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			status := s.do(http.MethodPost, "/v1/chat/completions", "alice-token", map[string]interface{}{
+				"model":    personaModelPrefix + personaID,
+				"messages": []map[string]string{{"role": "user", "content": fmt.Sprintf("Question %d, asked concurrently", i)}},
+			}, nil)
+			if status != http.StatusOK {
+				t.Errorf("question %d: status %d", i, status)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	memory, err := s.open("alice").Personas.LoadMemory(personaID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < questions; i++ {
+		if question := fmt.Sprintf("Question %d, asked concurrently", i); !bytes.Contains(memory, []byte(question)) {
+			t.Errorf("memory lost %q", question)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"personal-ai-board/internal/auth"
+)
+
+// Keys of what authenticate stores in the request context
+const (
+	userKey       = "user"
+	workspaceKey  = "workspace_id"
+	repositoryKey = "repositories"
+)
+
+// authenticate identifies the user behind a request from the API token in its
+// Authorization header, or in the access_token query parameter for clients
+// such as EventSource that cannot set headers, and serves the request in the
+// user's workspace. Requests without a valid token are refused with fail.
+// Without an authenticator every request is served in the default workspace.
+func (s *Server) authenticate(fail func(*gin.Context, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.auth == nil {
+			s.enter(c, nil, auth.DefaultWorkspaceID)
+			return
+		}
+
+		token := bearerToken(c)
+		if token == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="personal-ai-board"`)
+			fail(c, &Error{Status: http.StatusUnauthorized, Code: CodeUnauthorized, Message: "an API token is required"})
+			return
+		}
+
+		user, err := s.auth.Authenticate(token)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidToken) {
+				c.Header("WWW-Authenticate", `Bearer realm="personal-ai-board", error="invalid_token"`)
+				err = &Error{Status: http.StatusUnauthorized, Code: CodeUnauthorized, Message: err.Error()}
+			}
+			fail(c, err)
+			return
+		}
+		s.enter(c, user, user.WorkspaceID)
+	}
+}
+
+// enter serves the rest of a request as the user, in the workspace
+func (s *Server) enter(c *gin.Context, user *auth.User, workspaceID string) {
+	c.Set(userKey, user)
+	c.Set(workspaceKey, workspaceID)
+	c.Set(repositoryKey, s.workspaces(workspaceID))
+	c.Next()
+}
+
+// bearerToken returns the API token a request carries, if any
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if scheme, token, ok := strings.Cut(header, " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return c.Query("access_token")
+}
+
+// repos returns the repositories of the workspace a request is served in
+func (s *Server) repos(c *gin.Context) Repositories {
+	return c.MustGet(repositoryKey).(Repositories)
+}
+
+// workspaceID returns the workspace a request is served in
+func workspaceID(c *gin.Context) string {
+	return c.GetString(workspaceKey)
+}
+
+// me returns the user the request's token belongs to and their workspace
+func (s *Server) me(c *gin.Context) {
+	user, _ := c.Get(userKey)
+	respond(c, http.StatusOK, gin.H{
+		"user":         user,
+		"workspace_id": workspaceID(c),
+	})
+}
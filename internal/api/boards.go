@@ -0,0 +1,207 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"personal-ai-board/internal/board"
+)
+
+// memberRequest seats a persona on a board
+type memberRequest struct {
+	PersonaID string `json:"persona_id"`
+	Role      string `json:"role"`
+}
+
+// boardRequest is the body of a request to create a board. Members are
+// seated in the order given.
+type boardRequest struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	IsTemplate  bool                   `json:"is_template"`
+	Metadata    map[string]interface{} `json:"metadata"`
+	Members     []memberRequest        `json:"members"`
+}
+
+// boardUpdate is the body of a request to edit a board. Fields left out are
+// kept; members, when given, replace the board's members.
+type boardUpdate struct {
+	Name        *string                `json:"name"`
+	Description *string                `json:"description"`
+	IsTemplate  *bool                  `json:"is_template"`
+	Metadata    map[string]interface{} `json:"metadata"`
+	Members     *[]memberRequest       `json:"members"`
+}
+
+// listBoards returns a page of the saved boards
+func (s *Server) listBoards(c *gin.Context) {
+	p, err := page(c)
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	repo := s.repos(c).Boards
+	if p.Total, err = repo.CountBoards(); err != nil {
+		s.fail(c, err)
+		return
+	}
+	boards, err := repo.PageBoards(p.Limit, p.Offset)
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	respondPage(c, p, boards)
+}
+
+// createBoard saves a new board with its members
+func (s *Server) createBoard(c *gin.Context) {
+	var req boardRequest
+	if err := bind(c, &req); err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	b, err := board.New(newID("board"), req.Name, req.Description)
+	if err != nil {
+		s.fail(c, invalidf("%v", err))
+		return
+	}
+	b.IsTemplate = req.IsTemplate
+	if req.Metadata != nil {
+		b.Metadata = req.Metadata
+	}
+	if err := s.seatMembers(c, b, req.Members); err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	s.saveBoard(c, b, http.StatusCreated)
+}
+
+// getBoard returns a board with its members
+func (s *Server) getBoard(c *gin.Context) {
+	b, err := s.loadBoard(c, c.Param("id"))
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, b)
+}
+
+// updateBoard edits a board and its members
+func (s *Server) updateBoard(c *gin.Context) {
+	var req boardUpdate
+	if err := bind(c, &req); err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	b, err := s.loadBoard(c, c.Param("id"))
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			s.fail(c, invalidf("board name cannot be empty"))
+			return
+		}
+		b.Name = *req.Name
+	}
+	if req.Description != nil {
+		b.Description = *req.Description
+	}
+	if req.IsTemplate != nil {
+		b.IsTemplate = *req.IsTemplate
+	}
+	if req.Metadata != nil {
+		b.Metadata = req.Metadata
+	}
+	if req.Members != nil {
+		if err := s.seatMembers(c, b, *req.Members); err != nil {
+			s.fail(c, err)
+			return
+		}
+	}
+
+	s.saveBoard(c, b, http.StatusOK)
+}
+
+// deleteBoard removes a board
+func (s *Server) deleteBoard(c *gin.Context) {
+	b, err := s.loadBoard(c, c.Param("id"))
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+	if err := s.repos(c).Boards.DeleteBoard(b.ID); err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	s.logger.Info("Board deleted through the API", "board_id", b.ID)
+	c.Status(http.StatusNoContent)
+}
+
+// loadBoard loads a board with its members
+func (s *Server) loadBoard(c *gin.Context, id string) (*board.Board, error) {
+	b, err := s.repos(c).Boards.LoadBoard(id)
+	if err != nil {
+		return nil, lookupError("board", id, err)
+	}
+	return b, nil
+}
+
+// seatMembers replaces the members of a board. Personas that were already on
+// the board keep the time they were added.
+func (s *Server) seatMembers(c *gin.Context, b *board.Board, members []memberRequest) error {
+	personas, err := s.repos(c).Personas.ListPersonas()
+	if err != nil {
+		return err
+	}
+	saved := make(map[string]bool, len(personas))
+	for _, info := range personas {
+		saved[info.ID] = true
+	}
+
+	added := make(map[string]time.Time, len(b.Members))
+	for _, member := range b.Members {
+		added[member.PersonaID] = member.AddedAt
+	}
+
+	b.Members = make([]board.Member, 0, len(members))
+	for _, member := range members {
+		if !saved[member.PersonaID] {
+			return invalidf("persona %s not found", member.PersonaID)
+		}
+		if err := b.AddMember(member.PersonaID, member.Role); err != nil {
+			return invalidf("%v", err)
+		}
+		if addedAt, ok := added[member.PersonaID]; ok {
+			b.Members[len(b.Members)-1].AddedAt = addedAt
+		}
+	}
+	return nil
+}
+
+// saveBoard saves a board and responds with it as stored, member names
+// included
+func (s *Server) saveBoard(c *gin.Context, b *board.Board, status int) {
+	if err := s.repos(c).Boards.SaveBoard(b); err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	saved, err := s.repos(c).Boards.LoadBoard(b.ID)
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+	respond(c, status, saved)
+}
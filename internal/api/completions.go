@@ -0,0 +1,456 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"personal-ai-board/internal/chat"
+	"personal-ai-board/internal/persona"
+)
+
+// Prefixes of the model names that address a persona or a board
+const (
+	personaModelPrefix = "persona:"
+	boardModelPrefix   = "board:"
+)
+
+// completionRequest is the body of an OpenAI chat completion request. Sampling
+// parameters are accepted but ignored: a persona's traits decide them.
+type completionRequest struct {
+	Model    string              `json:"model"`
+	Messages []completionMessage `json:"messages"`
+	Stream   bool                `json:"stream"`
+}
+
+// completionMessage is one message of a chat completion request or response
+type completionMessage struct {
+	Role    string         `json:"role"`
+	Content messageContent `json:"content"`
+}
+
+// messageContent is the text of a message, sent either as a string or as a
+// list of parts of which only the text parts are read
+type messageContent string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (m *messageContent) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*m = ""
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*m = messageContent(text)
+		return nil
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return fmt.Errorf("message content must be a string or a list of parts")
+	}
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part.Type == "text" {
+			texts = append(texts, part.Text)
+		}
+	}
+	*m = messageContent(strings.Join(texts, "\n"))
+	return nil
+}
+
+// completion is the response to a chat completion request
+type completion struct {
+	ID      string             `json:"id"`
+	Object  string             `json:"object"`
+	Created int64              `json:"created"`
+	Model   string             `json:"model"`
+	Choices []completionChoice `json:"choices"`
+	Usage   *completionUsage   `json:"usage,omitempty"`
+}
+
+// completionChoice is the reply in a completion, or a piece of it in a chunk
+// of a streamed completion
+type completionChoice struct {
+	Index        int                `json:"index"`
+	Message      *completionMessage `json:"message,omitempty"`
+	Delta        *completionDelta   `json:"delta,omitempty"`
+	FinishReason *string            `json:"finish_reason"`
+}
+
+// completionDelta is the part of a reply sent in one chunk
+type completionDelta struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// completionUsage reports the tokens a completion used
+type completionUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// completionError is an error in the shape OpenAI clients expect
+type completionError struct {
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Param   *string `json:"param"`
+	Code    string  `json:"code"`
+}
+
+// model is an entry of the model list
+type model struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// conversation is a chat completion request turned into what a persona
+// thinks about: the question, the conversation before it and any system
+// instructions
+type conversation struct {
+	question string
+	thinking persona.ThinkingContext
+}
+
+// listModels lists the personas and boards as models, in the shape of
+// OpenAI's model list
+func (s *Server) listModels(c *gin.Context) {
+	personas, err := s.repos(c).Personas.ListPersonas()
+	if err != nil {
+		s.failCompletion(c, err)
+		return
+	}
+	boards, err := s.repos(c).Boards.ListBoards()
+	if err != nil {
+		s.failCompletion(c, err)
+		return
+	}
+
+	models := make([]model, 0, len(personas)+len(boards))
+	for _, p := range personas {
+		models = append(models, model{ID: personaModelPrefix + p.ID, Object: "model", Created: p.CreatedAt.Unix(), OwnedBy: p.Name})
+	}
+	for _, b := range boards {
+		if b.PersonaCount == 0 {
+			continue
+		}
+		models = append(models, model{ID: boardModelPrefix + b.ID, Object: "model", Created: b.CreatedAt.Unix(), OwnedBy: b.Name})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"object": "list", "data": models})
+}
+
+// createCompletion answers an OpenAI chat completion request. The model names
+// a persona, which answers the last message itself, or a board, which
+// discusses it and answers with a synthesis of the discussion. The personas'
+// memories are saved, as in a chat. With stream set, the answer is sent as
+// Server-Sent Events as it is generated.
+func (s *Server) createCompletion(c *gin.Context) {
+	if s.engine == nil {
+		s.failCompletion(c, &Error{Status: http.StatusServiceUnavailable, Code: CodeUnavailable,
+			Message: "chat completions are unavailable because no LLM provider is configured"})
+		return
+	}
+
+	var req completionRequest
+	if err := bind(c, &req); err != nil {
+		s.failCompletion(c, err)
+		return
+	}
+
+	var speaker string
+	var answer func(conv conversation, onChunk func(string)) (*persona.ThinkingResult, error)
+	switch {
+	case strings.HasPrefix(req.Model, personaModelPrefix):
+		personaID := strings.TrimPrefix(req.Model, personaModelPrefix)
+		defer s.personaLocks.lock(workspaceID(c), personaID)()
+		p, err := s.repos(c).Personas.LoadPersona(personaID, s.engine.Provider(), s.logger)
+		if err != nil {
+			s.failCompletion(c, lookupError("model", req.Model, err))
+			return
+		}
+		defer s.engine.Track(p)()
+		speaker = p.Name
+		answer = func(conv conversation, onChunk func(string)) (*persona.ThinkingResult, error) {
+			result, err := p.ThinkStream(c.Request.Context(), conv.question, conv.thinking, onChunk)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.repos(c).Personas.SavePersona(p); err != nil {
+				s.logger.Warn("Failed to save persona memory", "persona_id", p.ID, "error", err)
+			}
+			return result, nil
+		}
+
+	case strings.HasPrefix(req.Model, boardModelPrefix):
+		b, err := s.repos(c).Boards.LoadBoard(strings.TrimPrefix(req.Model, boardModelPrefix))
+		if err != nil {
+			s.failCompletion(c, lookupError("model", req.Model, err))
+			return
+		}
+		if len(b.Members) == 0 {
+			s.failCompletion(c, invalidf("board %s has no members", b.ID))
+			return
+		}
+		memberIDs := make([]string, 0, len(b.Members))
+		for _, member := range b.Members {
+			memberIDs = append(memberIDs, member.PersonaID)
+		}
+		defer s.personaLocks.lock(workspaceID(c), memberIDs...)()
+		members := make([]*persona.Persona, 0, len(b.Members))
+		for _, member := range b.Members {
+			p, err := s.repos(c).Personas.LoadPersona(member.PersonaID, s.engine.Provider(), s.logger)
+			if err != nil {
+				s.failCompletion(c, fmt.Errorf("failed to load board member %s: %w", member.PersonaID, err))
+				return
+			}
+			members = append(members, p)
+		}
+		defer s.engine.Track(members...)()
+		speaker = b.Name
+		answer = func(conv conversation, onChunk func(string)) (*persona.ThinkingResult, error) {
+			consultation, err := chat.Consult(c.Request.Context(), s.repos(c).Personas, b, members, conv.question, conv.thinking, onChunk, s.logger)
+			if err != nil {
+				return nil, err
+			}
+			// The synthesis is the answer, but every member's tokens count
+			consultation.Answer.TokensUsed = consultation.TokensUsed
+			consultation.Answer.PromptTokens = consultation.PromptTokens
+			consultation.Answer.CompletionTokens = consultation.CompletionTokens
+			return consultation.Answer, nil
+		}
+
+	default:
+		s.failCompletion(c, invalidf("model must be %s<id> or %s<id>, got %q", personaModelPrefix, boardModelPrefix, req.Model))
+		return
+	}
+
+	conv, err := readConversation(req.Messages, speaker)
+	if err != nil {
+		s.failCompletion(c, err)
+		return
+	}
+
+	id := newID("chatcmpl")
+	created := time.Now().Unix()
+	if req.Stream {
+		s.streamCompletion(c, id, created, req.Model, func(onChunk func(string)) error {
+			_, err := answer(conv, onChunk)
+			return err
+		})
+		return
+	}
+
+	result, err := answer(conv, nil)
+	if errors.Is(err, persona.ErrQuestionTooLong) {
+		s.failCompletion(c, invalidf("%v", err))
+		return
+	}
+	if err != nil {
+		s.failCompletion(c, &Error{Status: http.StatusBadGateway, Code: CodeUnavailable, Message: err.Error()})
+		return
+	}
+
+	stop := "stop"
+	c.JSON(http.StatusOK, completion{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: created,
+		Model:   req.Model,
+		Choices: []completionChoice{{
+			Message:      &completionMessage{Role: "assistant", Content: messageContent(result.Response)},
+			FinishReason: &stop,
+		}},
+		Usage: &completionUsage{
+			PromptTokens:     result.PromptTokens,
+			CompletionTokens: result.CompletionTokens,
+			TotalTokens:      result.TokensUsed,
+		},
+	})
+}
+
+// streamCompletion sends the answer generate produces as chunks of a
+// completion, ending the stream with [DONE]. A failure once the stream has
+// started is sent as an error event.
+func (s *Server) streamCompletion(c *gin.Context, id string, created int64, modelName string, generate func(onChunk func(string)) error) {
+	send := func(data interface{}) {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			s.logger.Error("Failed to marshal completion chunk", "error", err)
+			return
+		}
+		fmt.Fprintf(c.Writer, "data: %s\n\n", payload)
+		c.Writer.Flush()
+	}
+	chunk := func(delta completionDelta, finishReason *string) completion {
+		return completion{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   modelName,
+			Choices: []completionChoice{{Delta: &delta, FinishReason: finishReason}},
+		}
+	}
+
+	openStream(c)
+	send(chunk(completionDelta{Role: "assistant"}, nil))
+
+	err := generate(func(content string) {
+		send(chunk(completionDelta{Content: content}, nil))
+	})
+	if err != nil {
+		s.logger.Warn("Chat completion failed", "model", modelName, "error", err)
+		send(gin.H{"error": completionError{Message: err.Error(), Type: "server_error", Code: CodeUnavailable}})
+		return
+	}
+
+	stop := "stop"
+	send(chunk(completionDelta{}, &stop))
+	fmt.Fprint(c.Writer, "data: [DONE]\n\n")
+	c.Writer.Flush()
+}
+
+// readConversation turns the messages of a request into the question, which
+// is the last message and must come from the user, and what preceded it.
+// Replies from the assistant are attributed to speaker.
+func readConversation(messages []completionMessage, speaker string) (conversation, error) {
+	var conv conversation
+	if len(messages) == 0 {
+		return conv, invalidf("messages cannot be empty")
+	}
+
+	last := messages[len(messages)-1]
+	conv.question = strings.TrimSpace(string(last.Content))
+	if last.Role != "user" || conv.question == "" {
+		return conv, invalidf("the last message must be a non-empty message from the user")
+	}
+
+	var instructions []string
+	history := make([]persona.ConversationTurn, 0, len(messages)-1)
+	now := time.Now()
+	for i, message := range messages[:len(messages)-1] {
+		content := strings.TrimSpace(string(message.Content))
+		switch message.Role {
+		case "system", "developer":
+			if content != "" {
+				instructions = append(instructions, content)
+			}
+		case "user":
+			if conv.thinking.Topic == "" {
+				conv.thinking.Topic = content
+			}
+			history = append(history, persona.ConversationTurn{Speaker: chat.UserSpeaker, Content: content, Timestamp: now})
+		case "assistant":
+			history = append(history, persona.ConversationTurn{Speaker: speaker, Content: content, Timestamp: now})
+		default:
+			return conv, invalidf("messages[%d] has an unsupported role: %s", i, message.Role)
+		}
+	}
+
+	if conv.thinking.Topic == "" {
+		conv.thinking.Topic = conv.question
+	}
+	conv.thinking.ConversationHistory = history
+	if len(instructions) > 0 {
+		conv.thinking.ProjectContext = map[string]interface{}{"instructions": strings.Join(instructions, "\n\n")}
+	}
+	return conv, nil
+}
+
+// failCompletion writes an error response in the shape OpenAI clients expect.
+// Errors other than an *Error are reported as internal errors, and their
+// details are logged rather than returned.
+func (s *Server) failCompletion(c *gin.Context, err error) {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		s.logger.Error("API request failed", "method", c.Request.Method, "path", c.Request.URL.Path, "error", err)
+		apiErr = &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: "internal server error"}
+	}
+
+	errorType := "invalid_request_error"
+	if apiErr.Status >= http.StatusInternalServerError {
+		errorType = "server_error"
+	}
+	c.AbortWithStatusJSON(apiErr.Status, gin.H{"error": completionError{
+		Message: apiErr.Message,
+		Type:    errorType,
+		Code:    apiErr.Code,
+	}})
+}
+
+// personaLocks serialises the completions of each persona. A completion loads
+// a persona, waits for its answer and saves its memory with the exchange
+// added, so two at once would each save the memory without the other's.
+type personaLocks struct {
+	mu    sync.Mutex
+	locks map[string]*personaLock
+}
+
+// personaLock is the lock of one persona and the number of completions
+// holding or waiting for it
+type personaLock struct {
+	sync.Mutex
+	users int
+}
+
+// lock waits for the personas of a workspace to be free and returns the
+// function that frees them again. Personas are locked in order of their IDs,
+// so that boards sharing members cannot each wait for the other.
+func (l *personaLocks) lock(workspaceID string, personaIDs ...string) func() {
+	keys := make([]string, 0, len(personaIDs))
+	seen := make(map[string]bool)
+	for _, id := range personaIDs {
+		key := workspaceID + "/" + id
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*personaLock)
+	}
+	held := make([]*personaLock, len(keys))
+	for i, key := range keys {
+		lock, ok := l.locks[key]
+		if !ok {
+			lock = &personaLock{}
+			l.locks[key] = lock
+		}
+		lock.users++
+		held[i] = lock
+	}
+	l.mu.Unlock()
+
+	for _, lock := range held {
+		lock.Lock()
+	}
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for i, lock := range held {
+			lock.Unlock()
+			if lock.users--; lock.users == 0 {
+				delete(l.locks, keys[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"personal-ai-board/internal/analysis"
+)
+
+// keepaliveInterval is how often an idle event stream sends a comment, so
+// that proxies do not close it
+const keepaliveInterval = 15 * time.Second
+
+// streamAnalysis streams the progress events of an analysis as Server-Sent
+// Events. A client that reconnects with the Last-Event-ID header, or the
+// last_event_id query parameter, receives the events that followed it. The
+// stream ends after the event that finishes the analysis.
+func (s *Server) streamAnalysis(c *gin.Context) {
+	id := c.Param("id")
+	last, err := lastEventID(c)
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+	// The feed holds the analyses of every workspace
+	if _, err := s.repos(c).Analyses.LoadResult(id); err != nil {
+		s.fail(c, lookupError("analysis", id, err))
+		return
+	}
+
+	var events []analysis.Event
+	var done, ok bool
+	var changed <-chan struct{}
+	if s.feed != nil {
+		events, done, changed, ok = s.feed.since(id, last)
+	}
+	if !ok {
+		s.streamStored(c, id)
+		return
+	}
+	if done && len(events) == 0 {
+		// Nothing follows the last event seen; this also stops EventSource
+		// clients from reconnecting
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	openStream(c)
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		for _, event := range events {
+			if err := writeEvent(c, event); err != nil {
+				return
+			}
+			last = event.Seq
+			if event.Final() {
+				return
+			}
+		}
+		if done {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(c.Writer, ": keepalive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		case <-s.ctx.Done():
+			return
+		}
+		events, done, changed, _ = s.feed.since(id, last)
+	}
+}
+
+// streamStored answers a stream request for an analysis this server holds no
+// events for. A finished analysis gets a single event reporting its outcome.
+func (s *Server) streamStored(c *gin.Context, id string) {
+	result, err := s.repos(c).Analyses.LoadResult(id)
+	if err != nil {
+		s.fail(c, lookupError("analysis", id, err))
+		return
+	}
+
+	var eventType analysis.EventType
+	switch result.Status {
+	case analysis.StatusCompleted:
+		eventType = analysis.EventCompleted
+	case analysis.StatusFailed:
+		eventType = analysis.EventFailed
+	case analysis.StatusCancelled:
+		eventType = analysis.EventCancelled
+	default:
+		s.fail(c, &Error{Status: http.StatusServiceUnavailable, Code: CodeUnavailable,
+			Message: fmt.Sprintf("analysis %s is not running on this server", id)})
+		return
+	}
+
+	finished := result.StartedAt.Add(result.Duration)
+	if result.CompletedAt != nil {
+		finished = *result.CompletedAt
+	}
+
+	openStream(c)
+	writeEvent(c, analysis.Event{
+		Type:      eventType,
+		RequestID: result.RequestID,
+		ResultID:  result.ID,
+		Metrics:   result.Metrics,
+		Error:     result.Error,
+		Time:      finished,
+	})
+}
+
+// lastEventID returns the number of the last event a client has seen, or 0
+func lastEventID(c *gin.Context) (int64, error) {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	last, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || last < 0 {
+		return 0, invalidf("invalid last event ID: %s", value)
+	}
+	return last, nil
+}
+
+// openStream sends the headers of an event stream. The server's write timeout
+// would cut a long analysis short, so the stream has none.
+func openStream(c *gin.Context) {
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+}
+
+// writeEvent sends an event and flushes it to the client. Events without a
+// sequence number are sent without an ID.
+func writeEvent(c *gin.Context, event analysis.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	if event.Seq > 0 {
+		if _, err := fmt.Fprintf(c.Writer, "id: %d\n", event.Seq); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
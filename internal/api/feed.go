@@ -0,0 +1,132 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"personal-ai-board/internal/analysis"
+)
+
+const (
+	// feedBuffer is how many events may queue between the engine and the feed
+	feedBuffer = 1024
+
+	// feedRetention is how long the events of a finished analysis are kept
+	// for clients that reconnect
+	feedRetention = 15 * time.Minute
+)
+
+// feed keeps the events of the analyses the engine runs, so that clients can
+// follow an analysis from its start or resume after the last event they saw
+type feed struct {
+	mu       sync.Mutex
+	sessions map[string]*feedSession // By result ID
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// feedSession holds the events of one analysis
+type feedSession struct {
+	events   []analysis.Event
+	finished time.Time
+	// changed is closed and replaced whenever an event is added
+	changed chan struct{}
+}
+
+// newFeed starts recording the events of an engine's analyses
+func newFeed(engine *analysis.Engine) *feed {
+	f := &feed{
+		sessions: make(map[string]*feedSession),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	events, unsubscribe := engine.Subscribe(feedBuffer)
+	go func() {
+		defer close(f.done)
+		defer unsubscribe()
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				f.add(event)
+			case <-f.stop:
+				return
+			}
+		}
+	}()
+
+	return f
+}
+
+// close stops recording events
+func (f *feed) close() {
+	close(f.stop)
+	<-f.done
+}
+
+// open makes sure a session exists for an analysis that has started, so that
+// clients can wait for its events before the first one has been recorded
+func (f *feed) open(resultID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.session(resultID)
+}
+
+// add records an event, and forgets analyses that finished long ago
+func (f *feed) add(event analysis.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if event.Type == analysis.EventStarted {
+		for id, s := range f.sessions {
+			if !s.finished.IsZero() && time.Since(s.finished) > feedRetention {
+				delete(f.sessions, id)
+			}
+		}
+	}
+
+	s := f.session(event.ResultID)
+	s.events = append(s.events, event)
+	if event.Final() {
+		s.finished = time.Now()
+	}
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// session returns the session of an analysis, adding it if needed. The caller
+// holds f.mu.
+func (f *feed) session(resultID string) *feedSession {
+	s, ok := f.sessions[resultID]
+	if !ok {
+		s = &feedSession{changed: make(chan struct{})}
+		f.sessions[resultID] = s
+	}
+	return s
+}
+
+// since returns the events of an analysis that follow the event numbered
+// after, whether the analysis has finished, and a channel that is closed when
+// more events arrive. It reports false for an analysis the feed does not know.
+func (f *feed) since(resultID string, after int64) ([]analysis.Event, bool, <-chan struct{}, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.sessions[resultID]
+	if !ok {
+		return nil, false, nil, false
+	}
+
+	var events []analysis.Event
+	for i, event := range s.events {
+		if event.Seq > after {
+			events = append(events, s.events[i:]...)
+			break
+		}
+	}
+	return events, !s.finished.IsZero(), s.changed, true
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"personal-ai-board/internal/persona"
+	"personal-ai-board/internal/webhook"
+)
+
+// personaRequest is the body of a request to create a persona. Traits are a
+// personality configuration as found in config/traits, extending base.json
+// when they name no parent.
+type personaRequest struct {
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	Traits      *persona.PersonalityConfig `json:"traits"`
+}
+
+// personaUpdate is the body of a request to edit a persona. Fields left out
+// are kept.
+type personaUpdate struct {
+	Name        *string                    `json:"name"`
+	Description *string                    `json:"description"`
+	Traits      *persona.PersonalityConfig `json:"traits"`
+}
+
+// listPersonas returns a page of the saved personas
+func (s *Server) listPersonas(c *gin.Context) {
+	p, err := page(c)
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	repo := s.repos(c).Personas
+	if p.Total, err = repo.CountPersonas(); err != nil {
+		s.fail(c, err)
+		return
+	}
+	personas, err := repo.PagePersonas(p.Limit, p.Offset)
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	respondPage(c, p, personas)
+}
+
+// createPersona builds a persona from its traits and saves it
+func (s *Server) createPersona(c *gin.Context) {
+	var req personaRequest
+	if err := bind(c, &req); err != nil {
+		s.fail(c, err)
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		s.fail(c, invalidf("persona name cannot be empty"))
+		return
+	}
+
+	traits, err := s.buildTraits(req.Traits, req.Name, req.Description)
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	p, err := persona.NewWithTraits(newID("persona"), req.Name, req.Description, traits, s.repos(c).Personas, nil, s.logger)
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+	if err := s.repos(c).Personas.SavePersona(p); err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	s.logger.Info("Persona created through the API", "persona_id", p.ID)
+	if s.repos(c).Webhooks != nil {
+		if _, err := s.repos(c).Webhooks.Notify(webhook.EventPersonaCreated, webhook.NewPersonaData(p)); err != nil {
+			s.logger.Error("Failed to queue webhook event", "event", webhook.EventPersonaCreated, "error", err)
+		}
+	}
+	respond(c, http.StatusCreated, personaView(p))
+}
+
+// getPersona returns a persona's personality profile
+func (s *Server) getPersona(c *gin.Context) {
+	p, err := s.loadPersona(c, c.Param("id"))
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, personaView(p))
+}
+
+// updatePersona edits a persona's name, description or traits
+func (s *Server) updatePersona(c *gin.Context) {
+	var req personaUpdate
+	if err := bind(c, &req); err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	p, err := s.loadPersona(c, c.Param("id"))
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			s.fail(c, invalidf("persona name cannot be empty"))
+			return
+		}
+		p.Name = *req.Name
+	}
+	if req.Description != nil {
+		p.Description = *req.Description
+	}
+	if req.Traits != nil {
+		traits, err := s.buildTraits(req.Traits, p.Name, p.Description)
+		if err != nil {
+			s.fail(c, err)
+			return
+		}
+		p.Traits = traits
+	}
+
+	if err := s.repos(c).Personas.SavePersona(p); err != nil {
+		s.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, personaView(p))
+}
+
+// deletePersona removes a persona
+func (s *Server) deletePersona(c *gin.Context) {
+	p, err := s.loadPersona(c, c.Param("id"))
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+	if err := s.repos(c).Personas.DeletePersona(p.ID); err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	s.logger.Info("Persona deleted through the API", "persona_id", p.ID)
+	c.Status(http.StatusNoContent)
+}
+
+// loadPersona loads a persona without an LLM provider, since it is only shown
+// or edited
+func (s *Server) loadPersona(c *gin.Context, id string) (*persona.Persona, error) {
+	p, err := s.repos(c).Personas.LoadPersona(id, nil, s.logger)
+	if err != nil {
+		return nil, lookupError("persona", id, err)
+	}
+	return p, nil
+}
+
+// buildTraits resolves and validates a personality configuration. A missing
+// configuration gives the defaults of base.json.
+func (s *Server) buildTraits(config *persona.PersonalityConfig, name, description string) (*persona.PersonalityTraits, error) {
+	if _, err := s.traits.BaseConfig(); err != nil {
+		return nil, err
+	}
+
+	if config == nil {
+		config = &persona.PersonalityConfig{Extends: "base", PersonaType: "custom"}
+	}
+	if config.Extends == "" {
+		config.Extends = "base"
+	}
+	config.Name = name
+	config.Description = description
+
+	traits, err := s.traits.BuildTraits(config)
+	if err != nil {
+		return nil, invalidf("persona is not valid: %v", err)
+	}
+	return traits, nil
+}
+
+// personaView is how a persona is returned: its personality profile with the
+// configuration its traits were built from
+func personaView(p *persona.Persona) map[string]interface{} {
+	profile := p.GetPersonalityProfile()
+	profile["traits"] = p.Traits.Config
+	return profile
+}
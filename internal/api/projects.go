@@ -0,0 +1,324 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"personal-ai-board/internal/project"
+)
+
+// projectRequest is the body of a request to create a project
+type projectRequest struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+// projectUpdate is the body of a request to edit a project. Fields left out
+// are kept.
+type projectUpdate struct {
+	Name        *string                `json:"name"`
+	Description *string                `json:"description"`
+	Status      *string                `json:"status"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+// ideaRequest is the body of a request to create an idea
+type ideaRequest struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Content     string   `json:"content"`
+	Tags        []string `json:"tags"`
+	Priority    int      `json:"priority"`
+	Status      string   `json:"status"`
+}
+
+// ideaUpdate is the body of a request to edit an idea. Fields left out are
+// kept.
+type ideaUpdate struct {
+	Title       *string   `json:"title"`
+	Description *string   `json:"description"`
+	Content     *string   `json:"content"`
+	Tags        *[]string `json:"tags"`
+	Priority    *int      `json:"priority"`
+	Status      *string   `json:"status"`
+}
+
+// listProjects returns a page of the saved projects
+func (s *Server) listProjects(c *gin.Context) {
+	p, err := page(c)
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	repo := s.repos(c).Projects
+	if p.Total, err = repo.CountProjects(); err != nil {
+		s.fail(c, err)
+		return
+	}
+	projects, err := repo.PageProjects(p.Limit, p.Offset)
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	respondPage(c, p, projects)
+}
+
+// createProject saves a new active project
+func (s *Server) createProject(c *gin.Context) {
+	var req projectRequest
+	if err := bind(c, &req); err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	proj, err := project.New(newID("project"), req.Name, req.Description)
+	if err != nil {
+		s.fail(c, invalidf("%v", err))
+		return
+	}
+	if req.Metadata != nil {
+		proj.Metadata = req.Metadata
+	}
+
+	if err := s.repos(c).Projects.SaveProject(proj); err != nil {
+		s.fail(c, err)
+		return
+	}
+	respond(c, http.StatusCreated, proj)
+}
+
+// getProject returns a project with its ideas
+func (s *Server) getProject(c *gin.Context) {
+	proj, err := s.loadProject(c, c.Param("id"))
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, proj)
+}
+
+// updateProject edits a project
+func (s *Server) updateProject(c *gin.Context) {
+	var req projectUpdate
+	if err := bind(c, &req); err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	proj, err := s.loadProject(c, c.Param("id"))
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			s.fail(c, invalidf("project name cannot be empty"))
+			return
+		}
+		proj.Name = *req.Name
+	}
+	if req.Description != nil {
+		proj.Description = *req.Description
+	}
+	if req.Status != nil {
+		if *req.Status != project.StatusActive && *req.Status != project.StatusArchived {
+			s.fail(c, invalidf("invalid project status: %s (must be %s or %s)", *req.Status, project.StatusActive, project.StatusArchived))
+			return
+		}
+		proj.Status = *req.Status
+	}
+	if req.Metadata != nil {
+		proj.Metadata = req.Metadata
+	}
+	proj.UpdatedAt = time.Now()
+
+	if err := s.repos(c).Projects.SaveProject(proj); err != nil {
+		s.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, proj)
+}
+
+// deleteProject removes a project with its ideas and documents
+func (s *Server) deleteProject(c *gin.Context) {
+	proj, err := s.loadProject(c, c.Param("id"))
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+	if err := s.repos(c).Projects.DeleteProject(proj.ID); err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	s.logger.Info("Project deleted through the API", "project_id", proj.ID)
+	c.Status(http.StatusNoContent)
+}
+
+// listIdeas returns a page of a project's ideas, highest priority first
+func (s *Server) listIdeas(c *gin.Context) {
+	p, err := page(c)
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	id := c.Param("id")
+	repo := s.repos(c).Projects
+	if p.Total, err = repo.CountIdeas(id); err != nil {
+		s.fail(c, err)
+		return
+	}
+	if p.Total == 0 {
+		// Tell a project without ideas from one that does not exist
+		if _, err := s.loadProject(c, id); err != nil {
+			s.fail(c, err)
+			return
+		}
+	}
+	ideas, err := repo.PageIdeas(id, p.Limit, p.Offset)
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	respondPage(c, p, ideas)
+}
+
+// createIdea saves a new idea in a project
+func (s *Server) createIdea(c *gin.Context) {
+	var req ideaRequest
+	if err := bind(c, &req); err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	proj, err := s.loadProject(c, c.Param("id"))
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	idea, err := project.NewIdea(newID("idea"), proj.ID, req.Title, req.Content)
+	if err != nil {
+		s.fail(c, invalidf("%v", err))
+		return
+	}
+	idea.Description = req.Description
+	idea.Priority = req.Priority
+	if req.Tags != nil {
+		idea.Tags = req.Tags
+	}
+	if req.Status != "" {
+		idea.Status = req.Status
+	}
+
+	if err := s.repos(c).Projects.SaveIdea(idea); err != nil {
+		s.fail(c, err)
+		return
+	}
+	respond(c, http.StatusCreated, idea)
+}
+
+// getIdea returns an idea of a project
+func (s *Server) getIdea(c *gin.Context) {
+	idea, err := s.loadIdea(c, c.Param("id"), c.Param("idea_id"))
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, idea)
+}
+
+// updateIdea edits an idea of a project
+func (s *Server) updateIdea(c *gin.Context) {
+	var req ideaUpdate
+	if err := bind(c, &req); err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	idea, err := s.loadIdea(c, c.Param("id"), c.Param("idea_id"))
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+
+	if req.Title != nil {
+		if strings.TrimSpace(*req.Title) == "" {
+			s.fail(c, invalidf("idea title cannot be empty"))
+			return
+		}
+		idea.Title = *req.Title
+	}
+	if req.Description != nil {
+		idea.Description = *req.Description
+	}
+	if req.Content != nil {
+		idea.Content = *req.Content
+	}
+	if req.Tags != nil {
+		idea.Tags = *req.Tags
+	}
+	if req.Priority != nil {
+		idea.Priority = *req.Priority
+	}
+	if req.Status != nil {
+		if strings.TrimSpace(*req.Status) == "" {
+			s.fail(c, invalidf("idea status cannot be empty"))
+			return
+		}
+		idea.Status = *req.Status
+	}
+	idea.UpdatedAt = time.Now()
+
+	if err := s.repos(c).Projects.SaveIdea(idea); err != nil {
+		s.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, idea)
+}
+
+// deleteIdea removes an idea from a project
+func (s *Server) deleteIdea(c *gin.Context) {
+	idea, err := s.loadIdea(c, c.Param("id"), c.Param("idea_id"))
+	if err != nil {
+		s.fail(c, err)
+		return
+	}
+	if err := s.repos(c).Projects.DeleteIdea(idea.ID); err != nil {
+		s.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// loadProject loads a project with its ideas
+func (s *Server) loadProject(c *gin.Context, id string) (*project.Project, error) {
+	proj, err := s.repos(c).Projects.LoadProject(id)
+	if err != nil {
+		return nil, lookupError("project", id, err)
+	}
+	return proj, nil
+}
+
+// loadIdea finds an idea among the ideas of a project
+func (s *Server) loadIdea(c *gin.Context, projectID, ideaID string) (*project.Idea, error) {
+	proj, err := s.loadProject(c, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range proj.Ideas {
+		if proj.Ideas[i].ID == ideaID {
+			return &proj.Ideas[i], nil
+		}
+	}
+	return nil, notFoundf("idea %s not found in project %s", ideaID, projectID)
+}
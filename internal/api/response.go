@@ -0,0 +1,132 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error codes reported in error responses
+const (
+	CodeInvalidRequest   = "invalid_request"
+	CodeUnauthorized     = "unauthorized"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeUnavailable      = "unavailable"
+	CodeInternal         = "internal_error"
+)
+
+// Page sizes of list endpoints
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// Error is an API error. Every failed request is answered with an
+// ErrorResponse holding one.
+type Error struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ErrorResponse is the body of a failed request
+type ErrorResponse struct {
+	Error *Error `json:"error"`
+}
+
+// Response is the body of a successful request
+type Response struct {
+	Data interface{} `json:"data"`
+}
+
+// ListResponse is the body of a successful request to a list endpoint
+type ListResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+// Pagination describes the page of a list that was returned. Request other
+// pages with the limit and offset query parameters.
+type Pagination struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
+
+// invalidf creates an error for a request that cannot be served as made
+func invalidf(format string, args ...interface{}) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeInvalidRequest, Message: fmt.Sprintf(format, args...)}
+}
+
+// notFoundf creates an error for a request about something that does not exist
+func notFoundf(format string, args ...interface{}) *Error {
+	return &Error{Status: http.StatusNotFound, Code: CodeNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// lookupError reports a failure to load the kind of thing with the given ID,
+// as not found when it does not exist
+func lookupError(kind, id string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundf("%s %s not found", kind, id)
+	}
+	return err
+}
+
+// respond writes a successful response
+func respond(c *gin.Context, status int, data interface{}) {
+	c.JSON(status, Response{Data: data})
+}
+
+// fail writes an error response. Errors other than an *Error are reported as
+// internal errors, and their details are logged rather than returned.
+func (s *Server) fail(c *gin.Context, err error) {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		s.logger.Error("API request failed", "method", c.Request.Method, "path", c.Request.URL.Path, "error", err)
+		apiErr = &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: "internal server error"}
+	}
+	c.AbortWithStatusJSON(apiErr.Status, ErrorResponse{Error: apiErr})
+}
+
+// bind decodes a JSON request body
+func bind(c *gin.Context, body interface{}) error {
+	if err := c.ShouldBindJSON(body); err != nil {
+		return invalidf("invalid request body: %v", err)
+	}
+	return nil
+}
+
+// page reads the limit and offset query parameters of a list request
+func page(c *gin.Context) (Pagination, error) {
+	p := Pagination{Limit: defaultPageSize}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return p, invalidf("limit must be between 1 and %d", maxPageSize)
+		}
+		p.Limit = limit
+	}
+	if value := c.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return p, invalidf("offset cannot be negative")
+		}
+		p.Offset = offset
+	}
+	return p, nil
+}
+
+// respondPage writes one page of a list
+func respondPage(c *gin.Context, p Pagination, items interface{}) {
+	c.JSON(http.StatusOK, ListResponse{Data: items, Pagination: p})
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultWorkspaceID is the workspace that holds everything created before
+// workspaces existed, and everything the CLI creates
+const DefaultWorkspaceID = "workspace_default"
+
+// TokenPrefix starts every API token, so that leaked tokens are easy to spot
+const TokenPrefix = "pab_"
+
+// tokenBytes is how many random bytes an API token carries
+const tokenBytes = 32
+
+// displayedPrefix is how much of a token is kept to identify it in listings
+const displayedPrefix = len(TokenPrefix) + 8
+
+// ErrInvalidToken is returned for a token that is unknown or has expired
+var ErrInvalidToken = errors.New("invalid or expired API token")
+
+// Workspace holds the personas, boards, projects, analyses and webhooks of
+// the users who belong to it
+type Workspace struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// User is someone who calls the API with their tokens, acting on the data of
+// their workspace
+type User struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	WorkspaceID string    `json:"workspace_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Token is an API token of a user. The token itself is only known when it
+// is issued; what is stored is its hash.
+type Token struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name,omitempty"`
+	Prefix     string     `json:"prefix"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NewWorkspace creates a workspace
+func NewWorkspace(id, name string) (*Workspace, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("workspace name cannot be empty")
+	}
+
+	now := time.Now()
+	return &Workspace{ID: id, Name: name, CreatedAt: now, UpdatedAt: now}, nil
+}
+
+// NewUser creates a user belonging to a workspace
+func NewUser(id, name, workspaceID string) (*User, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("user name cannot be empty")
+	}
+	if workspaceID == "" {
+		return nil, fmt.Errorf("user must belong to a workspace")
+	}
+
+	now := time.Now()
+	return &User{ID: id, Name: name, WorkspaceID: workspaceID, CreatedAt: now, UpdatedAt: now}, nil
+}
+
+// Expired reports whether the token has expired
+func (t *Token) Expired() bool {
+	return t.ExpiresAt != nil && !time.Now().Before(*t.ExpiresAt)
+}
+
+// NewToken returns a random API token
+func NewToken() (string, error) {
+	buf := make([]byte, tokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+	return TokenPrefix + hex.EncodeToString(buf), nil
+}
+
+// HashToken returns the hash under which a token is stored. Tokens are long
+// and random, so a fast hash is enough to keep them out of the database.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,255 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Storage handles database operations for workspaces, users and API tokens
+type Storage struct {
+	db *sql.DB
+}
+
+// NewStorage creates a new storage instance
+func NewStorage(db *sql.DB) *Storage {
+	return &Storage{db: db}
+}
+
+// SaveWorkspace saves a workspace to the database
+func (s *Storage) SaveWorkspace(w *Workspace) error {
+	_, err := s.db.Exec(`
+		INSERT INTO workspaces (id, name, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			updated_at = excluded.updated_at
+	`, w.ID, w.Name, w.CreatedAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save workspace: %w", err)
+	}
+	return nil
+}
+
+// LoadWorkspace loads a workspace from the database
+func (s *Storage) LoadWorkspace(id string) (*Workspace, error) {
+	var w Workspace
+	err := s.db.QueryRow(`SELECT id, name, created_at, updated_at FROM workspaces WHERE id = ?`, id).
+		Scan(&w.ID, &w.Name, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workspace: %w", err)
+	}
+	return &w, nil
+}
+
+// ListWorkspaces returns every workspace, oldest first
+func (s *Storage) ListWorkspaces() ([]*Workspace, error) {
+	rows, err := s.db.Query(`SELECT id, name, created_at, updated_at FROM workspaces ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	var workspaces []*Workspace
+	for rows.Next() {
+		var w Workspace
+		if err := rows.Scan(&w.ID, &w.Name, &w.CreatedAt, &w.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace: %w", err)
+		}
+		workspaces = append(workspaces, &w)
+	}
+
+	return workspaces, rows.Err()
+}
+
+// SaveUser saves a user to the database. The user's workspace must exist.
+func (s *Storage) SaveUser(u *User) error {
+	_, err := s.db.Exec(`
+		INSERT INTO users (id, name, workspace_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			workspace_id = excluded.workspace_id,
+			updated_at = excluded.updated_at
+	`, u.ID, u.Name, u.WorkspaceID, u.CreatedAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save user: %w", err)
+	}
+	return nil
+}
+
+// LoadUser loads a user from the database
+func (s *Storage) LoadUser(id string) (*User, error) {
+	var u User
+	err := s.db.QueryRow(`SELECT id, name, workspace_id, created_at, updated_at FROM users WHERE id = ?`, id).
+		Scan(&u.ID, &u.Name, &u.WorkspaceID, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+	return &u, nil
+}
+
+// ListUsers returns every user, oldest first
+func (s *Storage) ListUsers() ([]*User, error) {
+	rows, err := s.db.Query(`SELECT id, name, workspace_id, created_at, updated_at FROM users ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*User
+	for rows.Next() {
+		var u User
+		if err := rows.Scan(&u.ID, &u.Name, &u.WorkspaceID, &u.CreatedAt, &u.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, &u)
+	}
+
+	return users, rows.Err()
+}
+
+// DeleteUser removes a user and their API tokens. Their workspace and its
+// data are kept.
+func (s *Storage) DeleteUser(id string) error {
+	result, err := s.db.Exec("DELETE FROM users WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("user not found: %s", id)
+	}
+	return nil
+}
+
+// IssueToken creates an API token for a user and returns it with its record.
+// The token is not stored and cannot be shown again. A nil expiry gives a
+// token that does not expire.
+func (s *Storage) IssueToken(userID, name string, expiresAt *time.Time) (string, *Token, error) {
+	if _, err := s.LoadUser(userID); err != nil {
+		return "", nil, err
+	}
+
+	token, err := NewToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	record := &Token{
+		ID:        fmt.Sprintf("token_%d", now.UnixNano()),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    token[:displayedPrefix],
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO api_tokens (id, user_id, name, prefix, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, record.ID, record.UserID, record.Name, record.Prefix, HashToken(token), record.ExpiresAt, record.CreatedAt)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to save API token: %w", err)
+	}
+
+	return token, record, nil
+}
+
+// ListTokens returns the tokens of a user, or of every user when userID is
+// empty, newest first
+func (s *Storage) ListTokens(userID string) ([]*Token, error) {
+	rows, err := s.db.Query(`
+		SELECT id, user_id, name, prefix, expires_at, last_used_at, created_at
+		FROM api_tokens
+		WHERE ? = '' OR user_id = ?
+		ORDER BY created_at DESC
+	`, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []*Token
+	for rows.Next() {
+		t, err := scanToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API token: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+
+	return tokens, rows.Err()
+}
+
+// RevokeToken deletes an API token, so that it is no longer accepted
+func (s *Storage) RevokeToken(id string) error {
+	result, err := s.db.Exec("DELETE FROM api_tokens WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API token: %w", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("API token not found: %s", id)
+	}
+	return nil
+}
+
+// Authenticate returns the user a token belongs to, recording that the token
+// was used. It returns ErrInvalidToken for a token that is unknown or has
+// expired.
+func (s *Storage) Authenticate(token string) (*User, error) {
+	row := s.db.QueryRow(`
+		SELECT id, user_id, name, prefix, expires_at, last_used_at, created_at
+		FROM api_tokens WHERE token_hash = ?
+	`, HashToken(token))
+
+	t, err := scanToken(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up API token: %w", err)
+	}
+	if t.Expired() {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.LoadUser(t.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.db.Exec("UPDATE api_tokens SET last_used_at = ? WHERE id = ?", time.Now(), t.ID); err != nil {
+		return nil, fmt.Errorf("failed to record API token use: %w", err)
+	}
+
+	return user, nil
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanToken reads an API token from a row
+func scanToken(row scanner) (*Token, error) {
+	var t Token
+	var name sql.NullString
+	var expiresAt, lastUsedAt sql.NullTime
+
+	if err := row.Scan(&t.ID, &t.UserID, &name, &t.Prefix, &expiresAt, &lastUsedAt, &t.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	t.Name = name.String
+	if expiresAt.Valid {
+		t.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		t.LastUsedAt = &lastUsedAt.Time
+	}
+	return &t, nil
+}
@@ -0,0 +1,110 @@
+package board
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Board is an advisory board made up of personas
+type Board struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	IsTemplate  bool                   `json:"is_template"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Members     []Member               `json:"members"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// Member is a persona seat on a board
+type Member struct {
+	PersonaID   string    `json:"persona_id"`
+	PersonaName string    `json:"persona_name,omitempty"`
+	Role        string    `json:"role,omitempty"`
+	Position    int       `json:"position"`
+	AddedAt     time.Time `json:"added_at"`
+}
+
+// BoardInfo contains summary information about a board
+type BoardInfo struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	IsTemplate   bool      `json:"is_template"`
+	PersonaCount int       `json:"persona_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// New creates a new empty board
+func New(id, name, description string) (*Board, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("board name cannot be empty")
+	}
+
+	now := time.Now()
+	return &Board{
+		ID:          id,
+		Name:        name,
+		Description: description,
+		Metadata:    make(map[string]interface{}),
+		Members:     make([]Member, 0),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// AddMember seats a persona on the board
+func (b *Board) AddMember(personaID, role string) error {
+	if b.HasMember(personaID) {
+		return fmt.Errorf("persona %s is already on board %s", personaID, b.ID)
+	}
+
+	b.Members = append(b.Members, Member{
+		PersonaID: personaID,
+		Role:      role,
+		Position:  len(b.Members),
+		AddedAt:   time.Now(),
+	})
+	b.UpdatedAt = time.Now()
+	return nil
+}
+
+// RemoveMember removes a persona from the board
+func (b *Board) RemoveMember(personaID string) error {
+	for i, member := range b.Members {
+		if member.PersonaID != personaID {
+			continue
+		}
+
+		b.Members = append(b.Members[:i], b.Members[i+1:]...)
+		for j := range b.Members {
+			b.Members[j].Position = j
+		}
+		b.UpdatedAt = time.Now()
+		return nil
+	}
+
+	return fmt.Errorf("persona %s is not on board %s", personaID, b.ID)
+}
+
+// HasMember reports whether a persona is on the board
+func (b *Board) HasMember(personaID string) bool {
+	for _, member := range b.Members {
+		if member.PersonaID == personaID {
+			return true
+		}
+	}
+	return false
+}
+
+// PersonaIDs returns the IDs of the board's personas in seating order
+func (b *Board) PersonaIDs() []string {
+	ids := make([]string, len(b.Members))
+	for i, member := range b.Members {
+		ids[i] = member.PersonaID
+	}
+	return ids
+}
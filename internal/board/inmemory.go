@@ -0,0 +1,172 @@
+package board
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"personal-ai-board/internal/persona"
+)
+
+// InMemoryStorage is a Repository that keeps boards in memory. It behaves
+// like Storage, so that code using boards can be tested without SQLite.
+type InMemoryStorage struct {
+	mu       sync.RWMutex
+	boards   map[string]*Board
+	personas persona.PersonaRepository
+}
+
+// NewInMemoryStorage creates an empty in-memory board store. Member names are
+// looked up in personas, which may be nil.
+func NewInMemoryStorage(personas persona.PersonaRepository) *InMemoryStorage {
+	return &InMemoryStorage{boards: make(map[string]*Board), personas: personas}
+}
+
+// SaveBoard saves a board and its members, keeping the creation time of one
+// already saved
+func (s *InMemoryStorage) SaveBoard(board *Board) error {
+	stored, err := copyBoard(board)
+	if err != nil {
+		return fmt.Errorf("failed to save board: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.boards[board.ID]; ok {
+		stored.CreatedAt = existing.CreatedAt
+	}
+	stored.UpdatedAt = time.Now()
+	for i := range stored.Members {
+		stored.Members[i].Position = i
+		stored.Members[i].PersonaName = ""
+		if stored.Members[i].AddedAt.IsZero() {
+			stored.Members[i].AddedAt = time.Now()
+		}
+	}
+	s.boards[board.ID] = stored
+
+	return nil
+}
+
+// LoadBoard loads a board and its members
+func (s *InMemoryStorage) LoadBoard(id string) (*Board, error) {
+	s.mu.RLock()
+	stored, ok := s.boards[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("failed to load board: %w", sql.ErrNoRows)
+	}
+
+	board, err := copyBoard(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load board: %w", err)
+	}
+	if s.personas != nil && len(board.Members) > 0 {
+		personas, err := s.personas.ListPersonas()
+		if err != nil {
+			return nil, fmt.Errorf("failed to query board members: %w", err)
+		}
+		names := make(map[string]string, len(personas))
+		for _, info := range personas {
+			names[info.ID] = info.Name
+		}
+		for i, member := range board.Members {
+			board.Members[i].PersonaName = names[member.PersonaID]
+		}
+	}
+
+	return board, nil
+}
+
+// ListBoards returns all boards, most recently updated first
+func (s *InMemoryStorage) ListBoards() ([]BoardInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	boards := make([]BoardInfo, 0, len(s.boards))
+	for _, board := range s.boards {
+		boards = append(boards, BoardInfo{
+			ID:           board.ID,
+			Name:         board.Name,
+			Description:  board.Description,
+			IsTemplate:   board.IsTemplate,
+			PersonaCount: len(board.Members),
+			CreatedAt:    board.CreatedAt,
+			UpdatedAt:    board.UpdatedAt,
+		})
+	}
+	sort.Slice(boards, func(i, j int) bool {
+		return boards[i].UpdatedAt.After(boards[j].UpdatedAt)
+	})
+
+	return boards, nil
+}
+
+// PageBoards returns a page of boards, most recently updated first
+func (s *InMemoryStorage) PageBoards(limit, offset int) ([]BoardInfo, error) {
+	boards, err := s.ListBoards()
+	if err != nil {
+		return nil, err
+	}
+	start, end := pageBounds(len(boards), limit, offset)
+	return boards[start:end], nil
+}
+
+// CountBoards returns the number of boards
+func (s *InMemoryStorage) CountBoards() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.boards), nil
+}
+
+// DeleteBoard removes a board and its member seats
+func (s *InMemoryStorage) DeleteBoard(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.boards[id]; !ok {
+		return fmt.Errorf("board not found: %s", id)
+	}
+	delete(s.boards, id)
+
+	return nil
+}
+
+// copyBoard deep-copies a board through JSON, as saving it to the database
+// and loading it back would
+func copyBoard(board *Board) (*Board, error) {
+	data, err := json.Marshal(board)
+	if err != nil {
+		return nil, err
+	}
+	var copied Board
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, err
+	}
+	if copied.Metadata == nil {
+		copied.Metadata = make(map[string]interface{})
+	}
+	if copied.Members == nil {
+		copied.Members = make([]Member, 0)
+	}
+	return &copied, nil
+}
+
+// pageBounds returns the range of indexes of a page of n items. A limit of
+// zero takes the rest of the items from offset.
+func pageBounds(n, limit, offset int) (int, int) {
+	start := offset
+	if start > n {
+		start = n
+	}
+	end := n
+	if limit > 0 && start+limit < n {
+		end = start + limit
+	}
+	return start, end
+}
@@ -0,0 +1,15 @@
+package board
+
+// Repository stores boards with their members. LoadBoard returns an error
+// wrapping sql.ErrNoRows for an unknown board, and DeleteBoard an error for
+// one that does not exist.
+type Repository interface {
+	SaveBoard(board *Board) error
+	LoadBoard(id string) (*Board, error)
+	ListBoards() ([]BoardInfo, error)
+	// PageBoards returns boards in the order of ListBoards, skipping offset
+	// of them. A limit of zero returns the rest.
+	PageBoards(limit, offset int) ([]BoardInfo, error)
+	CountBoards() (int, error)
+	DeleteBoard(id string) error
+}
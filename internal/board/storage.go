@@ -0,0 +1,221 @@
+package board
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"personal-ai-board/internal/auth"
+)
+
+// Storage handles database operations for the boards of one workspace
+type Storage struct {
+	db        *sql.DB
+	workspace string
+}
+
+// NewStorage creates a storage instance for the default workspace
+func NewStorage(db *sql.DB) *Storage {
+	return NewWorkspaceStorage(db, auth.DefaultWorkspaceID)
+}
+
+// NewWorkspaceStorage creates a storage instance for the boards of a workspace
+func NewWorkspaceStorage(db *sql.DB, workspaceID string) *Storage {
+	return &Storage{db: db, workspace: workspaceID}
+}
+
+// SaveBoard saves a board and its members to the database
+func (s *Storage) SaveBoard(board *Board) error {
+	metadata, err := json.Marshal(board.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to serialize board metadata: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Upsert rather than replace so that member rows are not cascade-deleted.
+	// A board of another workspace is left alone.
+	result, err := tx.Exec(`
+		INSERT INTO boards (id, workspace_id, name, description, is_template, metadata, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			description = excluded.description,
+			is_template = excluded.is_template,
+			metadata = excluded.metadata,
+			updated_at = excluded.updated_at
+		WHERE boards.workspace_id = excluded.workspace_id
+	`, board.ID, s.workspace, board.Name, board.Description, board.IsTemplate, string(metadata), board.CreatedAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save board: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("failed to save board %s: %w", board.ID, sql.ErrNoRows)
+	}
+
+	if _, err := tx.Exec("DELETE FROM board_personas WHERE board_id = ?", board.ID); err != nil {
+		return fmt.Errorf("failed to clear board members: %w", err)
+	}
+
+	for i, member := range board.Members {
+		addedAt := member.AddedAt
+		if addedAt.IsZero() {
+			addedAt = time.Now()
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO board_personas (board_id, persona_id, role, position, added_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, board.ID, member.PersonaID, member.Role, i, addedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save board member %s: %w", member.PersonaID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit board: %w", err)
+	}
+
+	return nil
+}
+
+// LoadBoard loads a board and its members from the database
+func (s *Storage) LoadBoard(id string) (*Board, error) {
+	var board Board
+	var description, metadata sql.NullString
+
+	err := s.db.QueryRow(`
+		SELECT id, name, description, is_template, metadata, created_at, updated_at
+		FROM boards WHERE id = ? AND workspace_id = ?
+	`, id, s.workspace).Scan(
+		&board.ID,
+		&board.Name,
+		&description,
+		&board.IsTemplate,
+		&metadata,
+		&board.CreatedAt,
+		&board.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load board: %w", err)
+	}
+
+	board.Description = description.String
+	board.Metadata = make(map[string]interface{})
+	if metadata.Valid && metadata.String != "" {
+		if err := json.Unmarshal([]byte(metadata.String), &board.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to deserialize board metadata: %w", err)
+		}
+	}
+
+	members, err := s.loadMembers(id)
+	if err != nil {
+		return nil, err
+	}
+	board.Members = members
+
+	return &board, nil
+}
+
+// loadMembers loads the personas seated on a board in order
+func (s *Storage) loadMembers(boardID string) ([]Member, error) {
+	rows, err := s.db.Query(`
+		SELECT bp.persona_id, COALESCE(p.name, ''), COALESCE(bp.role, ''), bp.position, bp.added_at
+		FROM board_personas bp
+		LEFT JOIN personas p ON p.id = bp.persona_id
+		WHERE bp.board_id = ?
+		ORDER BY bp.position
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query board members: %w", err)
+	}
+	defer rows.Close()
+
+	members := make([]Member, 0)
+	for rows.Next() {
+		var member Member
+		if err := rows.Scan(&member.PersonaID, &member.PersonaName, &member.Role, &member.Position, &member.AddedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan board member: %w", err)
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// ListBoards returns all boards
+func (s *Storage) ListBoards() ([]BoardInfo, error) {
+	return s.PageBoards(0, 0)
+}
+
+// PageBoards returns a page of boards, most recently updated first
+func (s *Storage) PageBoards(limit, offset int) ([]BoardInfo, error) {
+	if limit == 0 {
+		limit = -1 // SQLite reads a negative limit as no limit
+	}
+	rows, err := s.db.Query(`
+		SELECT b.id, b.name, COALESCE(b.description, ''), b.is_template,
+		       (SELECT COUNT(*) FROM board_personas bp WHERE bp.board_id = b.id),
+		       b.created_at, b.updated_at
+		FROM boards b
+		WHERE b.workspace_id = ?
+		ORDER BY b.updated_at DESC
+		LIMIT ? OFFSET ?
+	`, s.workspace, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query boards: %w", err)
+	}
+	defer rows.Close()
+
+	boards := make([]BoardInfo, 0)
+	for rows.Next() {
+		var info BoardInfo
+		err := rows.Scan(
+			&info.ID,
+			&info.Name,
+			&info.Description,
+			&info.IsTemplate,
+			&info.PersonaCount,
+			&info.CreatedAt,
+			&info.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan board: %w", err)
+		}
+		boards = append(boards, info)
+	}
+
+	return boards, rows.Err()
+}
+
+// CountBoards returns the number of boards
+func (s *Storage) CountBoards() (int, error) {
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM boards WHERE workspace_id = ?", s.workspace).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count boards: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteBoard removes a board and its member seats from the database
+func (s *Storage) DeleteBoard(id string) error {
+	result, err := s.db.Exec("DELETE FROM boards WHERE id = ? AND workspace_id = ?", id, s.workspace)
+	if err != nil {
+		return fmt.Errorf("failed to delete board: %w", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("board not found: %s", id)
+	}
+
+	if _, err := s.db.Exec("DELETE FROM board_personas WHERE board_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete board members: %w", err)
+	}
+
+	return nil
+}
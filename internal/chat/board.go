@@ -0,0 +1,132 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"personal-ai-board/internal/board"
+	"personal-ai-board/internal/persona"
+)
+
+// Contribution is one board member's answer during a consultation
+type Contribution struct {
+	PersonaID   string  `json:"persona_id"`
+	PersonaName string  `json:"persona_name"`
+	Role        string  `json:"role,omitempty"`
+	Response    string  `json:"response,omitempty"`
+	Confidence  float64 `json:"confidence,omitempty"`
+	TokensUsed  int     `json:"tokens_used"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// Consultation is the outcome of putting a question to a board
+type Consultation struct {
+	Contributions []Contribution          `json:"contributions"`
+	SynthesizedBy string                  `json:"synthesized_by"`
+	Answer        *persona.ThinkingResult `json:"answer"`
+	TokensUsed    int                     `json:"tokens_used"`
+	// Prompt and completion tokens, for the members and the synthesis alike,
+	// as far as their providers report them
+	PromptTokens     int `json:"prompt_tokens,omitempty"`
+	CompletionTokens int `json:"completion_tokens,omitempty"`
+}
+
+// Consult holds a short discussion of a question with a board. The members,
+// given in board order, answer in turn, each seeing the answers given before
+// theirs. The first member to answer then synthesizes the board's answer;
+// onChunk, if not nil, receives it as it is generated. The members' memories
+// are saved to personas unless it is nil.
+func Consult(ctx context.Context, personas persona.PersonaRepository, b *board.Board, members []*persona.Persona, question string, thinking persona.ThinkingContext, onChunk func(string), logger Logger) (*Consultation, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return nil, fmt.Errorf("question cannot be empty")
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("board %s has no members", b.Name)
+	}
+
+	roles := make(map[string]string, len(b.Members))
+	for _, member := range b.Members {
+		roles[member.PersonaID] = member.Role
+	}
+
+	if thinking.Topic == "" {
+		thinking.Topic = question
+	}
+	thinking.BoardContext = map[string]interface{}{"board": b.Name, "mode": "consultation"}
+	history := append([]persona.ConversationTurn(nil), thinking.ConversationHistory...)
+
+	consultation := &Consultation{Contributions: make([]Contribution, 0, len(members))}
+	var chair *persona.Persona
+	var lastErr error
+	for _, p := range members {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		memberThinking := thinking
+		memberThinking.ConversationHistory = history
+		result, err := p.Think(ctx, question, memberThinking)
+
+		contribution := Contribution{PersonaID: p.ID, PersonaName: p.Name, Role: roles[p.ID]}
+		if err != nil {
+			logger.Warn("Board member failed to answer", "board_id", b.ID, "persona_id", p.ID, "error", err)
+			contribution.Error = err.Error()
+			consultation.Contributions = append(consultation.Contributions, contribution)
+			lastErr = err
+			continue
+		}
+
+		contribution.Response = result.Response
+		contribution.Confidence = result.Confidence
+		contribution.TokensUsed = result.TokensUsed
+		consultation.Contributions = append(consultation.Contributions, contribution)
+		consultation.TokensUsed += result.TokensUsed
+		consultation.PromptTokens += result.PromptTokens
+		consultation.CompletionTokens += result.CompletionTokens
+		history = append(history, persona.ConversationTurn{Speaker: p.Name, Content: result.Response, Timestamp: time.Now()})
+		savePersona(personas, p, logger)
+
+		if chair == nil {
+			chair = p
+		}
+	}
+	if chair == nil {
+		return nil, fmt.Errorf("no board member could answer: %w", lastErr)
+	}
+
+	chairThinking := thinking
+	chairThinking.ConversationHistory = history
+	chairThinking.Focus = "synthesis"
+	answer, err := chair.ThinkStream(ctx, synthesisPrompt(b, question), chairThinking, onChunk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to synthesize the board's answer: %w", err)
+	}
+	savePersona(personas, chair, logger)
+
+	consultation.SynthesizedBy = chair.ID
+	consultation.Answer = answer
+	consultation.TokensUsed += answer.TokensUsed
+	consultation.PromptTokens += answer.PromptTokens
+	consultation.CompletionTokens += answer.CompletionTokens
+	return consultation, nil
+}
+
+// synthesisPrompt asks a board member to combine the board's answers
+func synthesisPrompt(b *board.Board, question string) string {
+	return fmt.Sprintf("The members of the %s board have each answered the question below, as shown in the conversation. "+
+		"Speaking for the whole board, combine their answers into one reply to the person who asked: "+
+		"say where the board agrees, where it differs and what it recommends.\n\nQuestion: %s", b.Name, question)
+}
+
+// savePersona saves a persona's memory, logging failures
+func savePersona(personas persona.PersonaRepository, p *persona.Persona, logger Logger) {
+	if personas == nil {
+		return
+	}
+	if err := personas.SavePersona(p); err != nil {
+		logger.Warn("Failed to save persona memory", "persona_id", p.ID, "error", err)
+	}
+}
@@ -0,0 +1,310 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"personal-ai-board/internal/persona"
+	"personal-ai-board/internal/project"
+)
+
+const (
+	// UserSpeaker is the speaker name given to the user's messages
+	UserSpeaker = "User"
+	// maxHistoryTurns caps how much of the conversation is sent with each message
+	maxHistoryTurns = 20
+	// maxTitleLength caps the length of a saved transcript's title
+	maxTitleLength = 60
+)
+
+// Logger interface for structured logging
+type Logger interface {
+	Info(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+	Debug(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+}
+
+// Turn is one message from the user and the persona's reply to it
+type Turn struct {
+	Message  string                  `json:"message"`
+	Result   *persona.ThinkingResult `json:"result,omitempty"`
+	Memories []string                `json:"memories,omitempty"`
+	Error    string                  `json:"error,omitempty"`
+	Time     time.Time               `json:"time"`
+}
+
+// Session is a one-on-one conversation with a single persona. The persona sees
+// the recent conversation with every message, and its memories are saved after
+// each reply.
+type Session struct {
+	ID        string           `json:"id"`
+	Persona   *persona.Persona `json:"-"`
+	Project   *project.Project `json:"-"`
+	Turns     []Turn           `json:"turns"`
+	StartedAt time.Time        `json:"started_at"`
+
+	personas persona.PersonaRepository
+	logger   Logger
+	history  []persona.ConversationTurn
+	mu       sync.Mutex
+}
+
+// NewSession starts a conversation with a persona. The project is optional and
+// gives the persona context about what is being discussed. The persona's
+// memories are saved to personas unless it is nil.
+func NewSession(personas persona.PersonaRepository, p *persona.Persona, proj *project.Project, logger Logger) *Session {
+	return &Session{
+		ID:        fmt.Sprintf("chat_%d", time.Now().UnixNano()),
+		Persona:   p,
+		Project:   proj,
+		Turns:     make([]Turn, 0),
+		StartedAt: time.Now(),
+		personas:  personas,
+		logger:    logger,
+		history:   make([]persona.ConversationTurn, 0),
+	}
+}
+
+// Send passes a message to the persona and returns its reply. onChunk, if not
+// nil, receives the reply as it is generated. Failed turns are kept in the
+// transcript but not in the conversation the persona sees.
+func (s *Session) Send(ctx context.Context, message string, onChunk func(string)) (*persona.ThinkingResult, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil, fmt.Errorf("message cannot be empty")
+	}
+
+	s.mu.Lock()
+	thinkingContext := persona.ThinkingContext{
+		Topic:               s.topic(message),
+		ProjectContext:      s.projectContext(),
+		BoardContext:        map[string]interface{}{"mode": "one-on-one chat"},
+		ConversationHistory: append([]persona.ConversationTurn(nil), s.history...),
+	}
+	s.mu.Unlock()
+
+	sentAt := time.Now()
+	result, err := s.Persona.ThinkStream(ctx, message, thinkingContext, onChunk)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	turn := Turn{Message: message, Result: result, Time: sentAt}
+	if err != nil {
+		turn.Error = err.Error()
+		s.Turns = append(s.Turns, turn)
+		return nil, err
+	}
+	turn.Memories = s.memories(result)
+	s.Turns = append(s.Turns, turn)
+
+	s.history = append(s.history,
+		persona.ConversationTurn{Speaker: UserSpeaker, Content: message, Timestamp: sentAt},
+		persona.ConversationTurn{Speaker: s.Persona.Name, Content: result.Response, Timestamp: time.Now()},
+	)
+	if len(s.history) > maxHistoryTurns {
+		s.history = s.history[len(s.history)-maxHistoryTurns:]
+	}
+
+	if s.personas != nil {
+		if err := s.personas.SavePersona(s.Persona); err != nil {
+			s.logger.Warn("Failed to save persona memory", "persona_id", s.Persona.ID, "error", err)
+		}
+	}
+
+	return result, nil
+}
+
+// topic returns the subject of the conversation, which is its first message
+func (s *Session) topic(message string) string {
+	for _, turn := range s.Turns {
+		if turn.Error == "" {
+			return turn.Message
+		}
+	}
+	return message
+}
+
+// projectContext describes the session's project to the persona
+func (s *Session) projectContext() map[string]interface{} {
+	if s.Project == nil {
+		return nil
+	}
+
+	projectContext := map[string]interface{}{
+		"project":     s.Project.Name,
+		"description": s.Project.Description,
+	}
+	if len(s.Project.Ideas) > 0 {
+		ideas := make([]string, 0, len(s.Project.Ideas))
+		for _, idea := range s.Project.Ideas {
+			ideas = append(ideas, idea.Title)
+		}
+		projectContext["ideas"] = strings.Join(ideas, "; ")
+	}
+	return projectContext
+}
+
+// History returns a copy of the turns taken so far
+func (s *Session) History() []Turn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Turn(nil), s.Turns...)
+}
+
+// LastReply returns the turn with the persona's most recent reply, or nil if
+// it has not replied yet
+func (s *Session) LastReply() *Turn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.Turns) - 1; i >= 0; i-- {
+		if s.Turns[i].Result != nil {
+			turn := s.Turns[i]
+			return &turn
+		}
+	}
+	return nil
+}
+
+// Title names the conversation after the persona and its first message
+func (s *Session) Title() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	title := "Chat with " + s.Persona.Name
+	if len(s.Turns) > 0 {
+		title += ": " + strings.Join(strings.Fields(s.Turns[0].Message), " ")
+	}
+	if runes := []rune(title); len(runes) > maxTitleLength {
+		title = string(runes[:maxTitleLength-1]) + "…"
+	}
+	return title
+}
+
+// Transcript formats the conversation as Markdown, including the questions,
+// recommendations and memories behind each reply
+func (s *Session) Transcript() string {
+	title := s.Title()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "- Persona: %s (%s)\n", s.Persona.Name, s.Persona.ID)
+	if s.Project != nil {
+		fmt.Fprintf(&b, "- Project: %s (%s)\n", s.Project.Name, s.Project.ID)
+	}
+	fmt.Fprintf(&b, "- Started: %s\n", s.StartedAt.Format("2006-01-02 15:04"))
+
+	for _, turn := range s.Turns {
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", UserSpeaker, turn.Message)
+		if turn.Error != "" {
+			fmt.Fprintf(&b, "\n## %s\n\n_No reply: %s_\n", s.Persona.Name, turn.Error)
+			continue
+		}
+
+		result := turn.Result
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", s.Persona.Name, strings.TrimSpace(result.Response))
+		fmt.Fprintf(&b, "\n_Confidence %.0f%%, %s_\n", result.Confidence*100, result.EmotionalTone)
+		writeList(&b, "Questions", result.Questions)
+		writeList(&b, "Recommendations", result.Recommendations)
+		writeList(&b, "Memories used", turn.Memories)
+	}
+
+	return b.String()
+}
+
+// memories describes the memories a reply drew on. It must be called from the
+// goroutine that ran Think, since persona memory is not safe for concurrent use.
+func (s *Session) memories(result *persona.ThinkingResult) []string {
+	descriptions := make([]string, 0, len(result.MemoriesUsed))
+	for _, id := range result.MemoriesUsed {
+		if memory, ok := s.Persona.Memory(id); ok {
+			descriptions = append(descriptions, memory.Content)
+		} else {
+			descriptions = append(descriptions, id)
+		}
+	}
+	return descriptions
+}
+
+// writeList writes a titled bullet list, skipping empty lists
+func writeList(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n**%s**\n\n", title)
+	for _, item := range items {
+		fmt.Fprintf(b, "- %s\n", item)
+	}
+}
+
+// SaveAsIdea stores the transcript as an idea of a project
+func (s *Session) SaveAsIdea(storage project.Repository, projectID string) (*project.Idea, error) {
+	if !s.hasReplies() {
+		return nil, fmt.Errorf("there is nothing to save yet")
+	}
+
+	idea, err := project.NewIdea(fmt.Sprintf("idea_%d", time.Now().UnixNano()), projectID, s.Title(), s.Transcript())
+	if err != nil {
+		return nil, err
+	}
+	idea.Description = fmt.Sprintf("Conversation with %s", s.Persona.Name)
+	idea.Tags = []string{"chat", s.Persona.ID}
+
+	if err := storage.SaveIdea(idea); err != nil {
+		return nil, err
+	}
+	return idea, nil
+}
+
+// SaveAsDocument writes the transcript to a Markdown file in dir and attaches
+// it to a project as a document
+func (s *Session) SaveAsDocument(storage project.Repository, projectID, dir string) (*project.Document, error) {
+	if !s.hasReplies() {
+		return nil, fmt.Errorf("there is nothing to save yet")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create documents directory: %w", err)
+	}
+
+	content := s.Transcript()
+	id := fmt.Sprintf("document_%d", time.Now().UnixNano())
+	path := filepath.Join(dir, id+".md")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write transcript: %w", err)
+	}
+
+	now := time.Now()
+	doc := &project.Document{
+		ID:          id,
+		ProjectID:   projectID,
+		Name:        s.Title() + ".md",
+		FilePath:    path,
+		ContentType: "text/markdown",
+		Size:        int64(len(content)),
+		Metadata:    map[string]interface{}{"source": "chat", "persona_id": s.Persona.ID, "session_id": s.ID},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := storage.SaveDocument(doc); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return doc, nil
+}
+
+// hasReplies reports whether the persona has replied at least once
+func (s *Session) hasReplies() bool {
+	return s.LastReply() != nil
+}
@@ -1,17 +1,36 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 	"gopkg.in/yaml.v3"
+
+	"personal-ai-board/internal/secrets"
 )
 
+// DefaultFilePath is where settings are saved when no configuration file was loaded
+const DefaultFilePath = ".personal-ai-board.yaml"
+
+// DefaultKeystoreFile is the keystore's file name in the home directory
+const DefaultKeystoreFile = ".personal-ai-board.keys"
+
+// DefaultModelCatalogFile is the model catalog override file's name in the
+// home directory
+const DefaultModelCatalogFile = ".personal-ai-board.models.json"
+
+// KeystorePassphraseEnv is the environment variable holding the keystore's
+// passphrase when no key file is configured
+const KeystorePassphraseEnv = "PAB_KEYSTORE_PASSPHRASE"
+
 // Config represents the application configuration
 type Config struct {
 	Database DatabaseConfig `yaml:"database"`
@@ -19,6 +38,15 @@ type Config struct {
 	Log      LogConfig      `yaml:"log"`
 	Analysis AnalysisConfig `yaml:"analysis"`
 	Memory   MemoryConfig   `yaml:"memory"`
+	Backup   BackupConfig   `yaml:"backup"`
+	Web      WebConfig      `yaml:"web"`
+	Webhooks WebhookConfig  `yaml:"webhooks"`
+	Secrets  SecretsConfig  `yaml:"secrets"`
+
+	path     string            // Most specific file the configuration was loaded from
+	files    []Source          // Files loaded, in the order they were applied
+	origins  map[string]Source // Where each value that is not a default was set
+	resolver *secrets.Resolver // Resolves the API key references
 }
 
 // DatabaseConfig represents database configuration
@@ -26,6 +54,8 @@ type DatabaseConfig struct {
 	Path              string `yaml:"path"`
 	MaxOpenConns      int    `yaml:"max_open_conns"`
 	MaxIdleConns      int    `yaml:"max_idle_conns"`
+	ConnMaxLifetime   string `yaml:"conn_max_lifetime"`  // How long a connection is reused
+	ConnMaxIdleTime   string `yaml:"conn_max_idle_time"` // How long an idle connection is kept
 	EnableWAL         bool   `yaml:"enable_wal"`
 	EnableForeignKeys bool   `yaml:"enable_foreign_keys"`
 }
@@ -40,22 +70,37 @@ type LLMConfig struct {
 	OpenAI          ProviderConfig         `yaml:"openai"`
 	Anthropic       ProviderConfig         `yaml:"anthropic"`
 	Google          ProviderConfig         `yaml:"google"`
+	Mock            MockConfig             `yaml:"mock"`
+	ModelCatalog    string                 `yaml:"model_catalog"` // Overrides for the built-in model catalog; defaults to ~/.personal-ai-board.models.json
 	Providers       map[string]interface{} `yaml:"providers"`
 }
 
 // ProviderConfig represents a specific provider configuration
 type ProviderConfig struct {
-	APIKey      string  `yaml:"api_key"`
+	APIKey      string  `yaml:"api_key"` // The key, or a reference such as keystore:openai
 	BaseURL     string  `yaml:"base_url"`
 	Model       string  `yaml:"model"`
 	Temperature float64 `yaml:"temperature"`
 	MaxTokens   int     `yaml:"max_tokens"`
 }
 
+// MockConfig represents the mock provider, which answers without a network
+// connection for development and tests
+type MockConfig struct {
+	Enabled   bool    `yaml:"enabled"`
+	Model     string  `yaml:"model"`
+	Script    string  `yaml:"script"`     // YAML file of scripted responses
+	Replay    bool    `yaml:"replay"`     // Replay responses recorded in the interaction log
+	Latency   string  `yaml:"latency"`    // Simulated response time, e.g. 500ms
+	ErrorRate float64 `yaml:"error_rate"` // Fraction of requests that fail, between 0 and 1
+	Seed      int64   `yaml:"seed"`       // Varies the generated responses
+}
+
 // LogConfig represents logging configuration
 type LogConfig struct {
 	Level  string `yaml:"level"`
 	Format string `yaml:"format"`
+	File   string `yaml:"file"` // Log file used while the interactive UI owns the terminal
 }
 
 // AnalysisConfig represents analysis configuration
@@ -66,9 +111,44 @@ type AnalysisConfig struct {
 
 // MemoryConfig represents memory configuration
 type MemoryConfig struct {
-	RetentionDays  int `yaml:"retention_days"`
-	ShortTermLimit int `yaml:"short_term_limit"`
-	LongTermLimit  int `yaml:"long_term_limit"`
+	RetentionDays   int     `yaml:"retention_days"`
+	ShortTermLimit  int     `yaml:"short_term_limit"`
+	LongTermLimit   int     `yaml:"long_term_limit"`
+	DecayRate       float64 `yaml:"decay_rate"`
+	CleanupInterval string  `yaml:"cleanup_interval"`
+}
+
+// BackupConfig represents scheduled database snapshot configuration
+type BackupConfig struct {
+	Enabled    bool   `yaml:"enabled"`     // Take snapshots while the interactive UI runs
+	Dir        string `yaml:"dir"`         // Defaults to a backups directory next to the database
+	Interval   string `yaml:"interval"`    // Time between snapshots
+	KeepDaily  int    `yaml:"keep_daily"`  // Days to keep the newest snapshot of
+	KeepWeekly int    `yaml:"keep_weekly"` // Weeks to keep the newest snapshot of
+	Compress   bool   `yaml:"compress"`    // Compress snapshots with gzip
+}
+
+// WebConfig represents the HTTP API server configuration
+type WebConfig struct {
+	Host         string `yaml:"host"`
+	Port         int    `yaml:"port"`
+	ReadTimeout  string `yaml:"read_timeout"`
+	WriteTimeout string `yaml:"write_timeout"`
+}
+
+// WebhookConfig represents how webhook deliveries are attempted
+type WebhookConfig struct {
+	MaxAttempts int     `yaml:"max_attempts"` // Attempts before a delivery fails for good
+	Backoff     string  `yaml:"backoff"`      // Wait before the first retry, doubled after each one
+	Timeout     string  `yaml:"timeout"`      // Time allowed for each request
+	BudgetUSD   float64 `yaml:"budget_usd"`   // Analysis cost that triggers budget.threshold; 0 disables it
+}
+
+// SecretsConfig represents where the secrets that API keys refer to are kept
+type SecretsConfig struct {
+	Keystore string `yaml:"keystore"` // Encrypted keystore for keystore: references; defaults to ~/.personal-ai-board.keys
+	KeyFile  string `yaml:"key_file"` // File holding the keystore's passphrase, instead of PAB_KEYSTORE_PASSPHRASE
+	Command  string `yaml:"command"`  // Helper run for command: references, given the secret's name
 }
 
 // DefaultConfig returns a configuration with default values
@@ -78,6 +158,8 @@ func DefaultConfig() *Config {
 			Path:              "personal_ai_board.db",
 			MaxOpenConns:      25,
 			MaxIdleConns:      25,
+			ConnMaxLifetime:   "1h",
+			ConnMaxIdleTime:   "5m",
 			EnableWAL:         true,
 			EnableForeignKeys: true,
 		},
@@ -100,86 +182,72 @@ func DefaultConfig() *Config {
 		Log: LogConfig{
 			Level:  "info",
 			Format: "text",
+			File:   "./personal_ai_board.log",
 		},
 		Analysis: AnalysisConfig{
 			MaxConcurrent: 5,
 			DefaultMode:   "discussion",
 		},
 		Memory: MemoryConfig{
-			RetentionDays:  90,
-			ShortTermLimit: 50,
-			LongTermLimit:  200,
+			RetentionDays:   90,
+			ShortTermLimit:  50,
+			LongTermLimit:   200,
+			DecayRate:       0.95,
+			CleanupInterval: "24h",
+		},
+		Backup: BackupConfig{
+			Interval:   "24h",
+			KeepDaily:  7,
+			KeepWeekly: 4,
+			Compress:   true,
+		},
+		Web: WebConfig{
+			Host:         "localhost",
+			Port:         8080,
+			ReadTimeout:  "10s",
+			WriteTimeout: "10s",
+		},
+		Webhooks: WebhookConfig{
+			MaxAttempts: 5,
+			Backoff:     "30s",
+			Timeout:     "10s",
 		},
 	}
 }
 
-// Load loads configuration from file and environment variables
+// Load loads the configuration in layers: the defaults, then the system file,
+// the user's file and the project file, then environment variables. The
+// project file is configPath when one is given, or else
+// .personal-ai-board.yaml in the current directory. Command line flags are
+// applied on top with Set.
 func Load(configPath string) (*Config, error) {
 	config := DefaultConfig()
 
 	// Load .env file first (if it exists)
 	loadDotEnv()
 
-	// Load from file if provided
-	if configPath != "" {
-		if err := loadFromFile(config, configPath); err != nil {
-			return nil, fmt.Errorf("failed to load config file: %w", err)
+	for _, layer := range fileLayers(configPath) {
+		if err := loadFile(config, layer.Name, layer.Kind); err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", layer, err)
 		}
-	}
-
-	// Override with environment variables
-	loadFromEnv(config)
-
-	return config, nil
-}
-
-// LoadDefault loads configuration from default locations
-func LoadDefault() (*Config, error) {
-	config := DefaultConfig()
-
-	// Load .env file first (if it exists)
-	loadDotEnv()
-
-	// Try to load from default locations
-	configPaths := []string{
-		".personal-ai-board.yaml",
-		".personal-ai-board.yml",
-	}
-
-	// Add home directory paths
-	if home, err := os.UserHomeDir(); err == nil {
-		configPaths = append(configPaths,
-			filepath.Join(home, ".personal-ai-board.yaml"),
-			filepath.Join(home, ".personal-ai-board.yml"),
-		)
-	}
-
-	// Try each path
-	for _, path := range configPaths {
-		if _, err := os.Stat(path); err == nil {
-			if err := loadFromFile(config, path); err != nil {
-				return nil, fmt.Errorf("failed to load config from %s: %w", path, err)
-			}
-			break
+		config.files = append(config.files, layer)
+		if layer.Kind != SourceSystem {
+			config.path = layer.Name
 		}
 	}
 
 	// Override with environment variables
-	loadFromEnv(config)
+	if err := loadFromEnv(config); err != nil {
+		return nil, err
+	}
+	config.resolver = newResolver(config)
 
 	return config, nil
 }
 
-// loadFromFile loads configuration from a YAML file
-func loadFromFile(config *Config, path string) error {
-	file, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	decoder := yaml.NewDecoder(file)
-	return decoder.Decode(config)
+// LoadDefault loads configuration from the default locations
+func LoadDefault() (*Config, error) {
+	return Load("")
 }
 
 // loadDotEnv loads environment variables from .env file
@@ -207,179 +275,322 @@ func loadDotEnv() {
 	// If no .env file found, that's okay - we'll use system environment variables
 }
 
-// loadFromEnv loads configuration from environment variables
-func loadFromEnv(config *Config) {
-	// Database configuration
-	if path := os.Getenv("PAB_DATABASE_PATH"); path != "" {
-		config.Database.Path = path
-	}
-	if val := os.Getenv("PAB_DATABASE_MAX_OPEN_CONNS"); val != "" {
-		if i, err := strconv.Atoi(val); err == nil {
-			config.Database.MaxOpenConns = i
-		}
-	}
-	if val := os.Getenv("PAB_DATABASE_MAX_IDLE_CONNS"); val != "" {
-		if i, err := strconv.Atoi(val); err == nil {
-			config.Database.MaxIdleConns = i
+// loadFromEnv overrides configuration values with environment variables. Each
+// value has one, named after its key: PAB_LLM_MOCK_ENABLED sets
+// llm.mock.enabled.
+func loadFromEnv(config *Config) error {
+	for _, key := range config.Keys() {
+		// API keys are only referred to, below
+		if strings.HasSuffix(key, ".api_key") {
+			continue
 		}
-	}
-	if val := os.Getenv("PAB_DATABASE_ENABLE_WAL"); val != "" {
-		if b, err := strconv.ParseBool(val); err == nil {
-			config.Database.EnableWAL = b
+		name := envVar(key)
+		if value := os.Getenv(name); value != "" {
+			if err := config.Set(key, value, Source{Kind: SourceEnv, Name: name}); err != nil {
+				return err
+			}
 		}
 	}
 
-	// LLM configuration
-	if provider := os.Getenv("PAB_LLM_DEFAULT_PROVIDER"); provider != "" {
-		config.LLM.DefaultProvider = provider
-	}
-	if model := os.Getenv("PAB_LLM_DEFAULT_MODEL"); model != "" {
-		config.LLM.DefaultModel = model
-	}
-	if val := os.Getenv("PAB_LLM_TEMPERATURE"); val != "" {
-		if f, err := strconv.ParseFloat(val, 64); err == nil {
-			config.LLM.Temperature = f
-		}
-	}
-	if val := os.Getenv("PAB_LLM_MAX_TOKENS"); val != "" {
-		if i, err := strconv.Atoi(val); err == nil {
-			config.LLM.MaxTokens = i
+	// Provider API keys from standard environment variables, then PAB
+	// prefixed ones. Only a reference is kept; the key is read when the
+	// provider is created.
+	for _, provider := range []string{"openai", "anthropic", "google"} {
+		key := "llm." + provider + ".api_key"
+		for _, name := range []string{strings.ToUpper(provider) + "_API_KEY", envVar(key)} {
+			if os.Getenv(name) != "" {
+				config.Set(key, secrets.SchemeEnv+":"+name, Source{Kind: SourceEnv, Name: name})
+			}
 		}
 	}
+	return nil
+}
+
+// newResolver creates the resolver of the configuration's API key references,
+// with a backend for every scheme
+func newResolver(c *Config) *secrets.Resolver {
+	resolver := secrets.NewResolver()
+	resolver.Register(secrets.SchemeEnv, secrets.NewEnv())
+	resolver.Register(secrets.SchemeKeystore, c.Keystore())
+	resolver.Register(secrets.SchemeCommand, secrets.NewCommand(c.Secrets.Command))
+	return resolver
+}
 
-	// Provider API keys from standard environment variables
-	if key := os.Getenv("OPENAI_API_KEY"); key != "" {
-		config.LLM.OpenAI.APIKey = key
+// SecretResolver returns the resolver of the configuration's API key
+// references
+func (c *Config) SecretResolver() *secrets.Resolver {
+	if c.resolver == nil {
+		c.resolver = newResolver(c)
 	}
-	if key := os.Getenv("ANTHROPIC_API_KEY"); key != "" {
-		config.LLM.Anthropic.APIKey = key
+	return c.resolver
+}
+
+// ResolveSecret returns the secret a value such as an API key refers to. A
+// value that is not a reference is returned as it is.
+func (c *Config) ResolveSecret(value string) (string, error) {
+	return c.SecretResolver().Resolve(value)
+}
+
+// KeystorePath returns the file the encrypted keystore is kept in
+func (c *Config) KeystorePath() string {
+	if c.Secrets.Keystore != "" {
+		return c.Secrets.Keystore
 	}
-	if key := os.Getenv("GOOGLE_API_KEY"); key != "" {
-		config.LLM.Google.APIKey = key
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, DefaultKeystoreFile)
 	}
+	return DefaultKeystoreFile
+}
 
-	// Provider API keys from PAB prefixed environment variables
-	if key := os.Getenv("PAB_LLM_OPENAI_API_KEY"); key != "" {
-		config.LLM.OpenAI.APIKey = key
+// ModelCatalogPath returns the file that overrides and extends the built-in
+// model catalog
+func (c *Config) ModelCatalogPath() string {
+	if c.LLM.ModelCatalog != "" {
+		return c.LLM.ModelCatalog
 	}
-	if key := os.Getenv("PAB_LLM_ANTHROPIC_API_KEY"); key != "" {
-		config.LLM.Anthropic.APIKey = key
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, DefaultModelCatalogFile)
 	}
-	if key := os.Getenv("PAB_LLM_GOOGLE_API_KEY"); key != "" {
-		config.LLM.Google.APIKey = key
+	return DefaultModelCatalogFile
+}
+
+// Keystore returns the encrypted keystore that keystore: references are read
+// from, unlocked with the key file or PAB_KEYSTORE_PASSPHRASE
+func (c *Config) Keystore() *secrets.Keystore {
+	return secrets.NewKeystore(c.KeystorePath(), secrets.Passphrase(c.Secrets.KeyFile, KeystorePassphraseEnv))
+}
+
+// GetTimeout parses the timeout string and returns a time.Duration
+func (c *Config) GetTimeout() time.Duration {
+	if duration, err := time.ParseDuration(c.LLM.Timeout); err == nil {
+		return duration
 	}
+	return 30 * time.Second // Default timeout
+}
 
-	// Log configuration
-	if level := os.Getenv("PAB_LOG_LEVEL"); level != "" {
-		config.Log.Level = level
+// GetCleanupInterval parses the memory cleanup interval and returns a time.Duration
+func (c *Config) GetCleanupInterval() time.Duration {
+	if duration, err := time.ParseDuration(c.Memory.CleanupInterval); err == nil && duration > 0 {
+		return duration
 	}
-	if format := os.Getenv("PAB_LOG_FORMAT"); format != "" {
-		config.Log.Format = format
+	return 24 * time.Hour // Default cleanup interval
+}
+
+// GetBackupInterval parses the backup interval and returns a time.Duration
+func (c *Config) GetBackupInterval() time.Duration {
+	if duration, err := time.ParseDuration(c.Backup.Interval); err == nil && duration > 0 {
+		return duration
 	}
+	return 24 * time.Hour // Default backup interval
+}
 
-	// Analysis configuration
-	if val := os.Getenv("PAB_ANALYSIS_MAX_CONCURRENT"); val != "" {
-		if i, err := strconv.Atoi(val); err == nil {
-			config.Analysis.MaxConcurrent = i
-		}
+// GetConnMaxLifetime parses how long a database connection is reused
+func (c *Config) GetConnMaxLifetime() time.Duration {
+	if duration, err := time.ParseDuration(c.Database.ConnMaxLifetime); err == nil && duration >= 0 {
+		return duration
 	}
-	if mode := os.Getenv("PAB_ANALYSIS_DEFAULT_MODE"); mode != "" {
-		config.Analysis.DefaultMode = mode
+	return time.Hour // Default connection lifetime
+}
+
+// GetConnMaxIdleTime parses how long an idle database connection is kept
+func (c *Config) GetConnMaxIdleTime() time.Duration {
+	if duration, err := time.ParseDuration(c.Database.ConnMaxIdleTime); err == nil && duration >= 0 {
+		return duration
 	}
+	return 5 * time.Minute // Default idle time
+}
 
-	// Memory configuration
-	if val := os.Getenv("PAB_MEMORY_RETENTION_DAYS"); val != "" {
-		if i, err := strconv.Atoi(val); err == nil {
-			config.Memory.RetentionDays = i
-		}
+// GetMockLatency parses the mock provider's simulated response time
+func (c *Config) GetMockLatency() time.Duration {
+	if duration, err := time.ParseDuration(c.LLM.Mock.Latency); err == nil && duration > 0 {
+		return duration
 	}
-	if val := os.Getenv("PAB_MEMORY_SHORT_TERM_LIMIT"); val != "" {
-		if i, err := strconv.Atoi(val); err == nil {
-			config.Memory.ShortTermLimit = i
-		}
+	return 0
+}
+
+// GetWebReadTimeout parses the API server's request read timeout
+func (c *Config) GetWebReadTimeout() time.Duration {
+	if duration, err := time.ParseDuration(c.Web.ReadTimeout); err == nil && duration > 0 {
+		return duration
 	}
-	if val := os.Getenv("PAB_MEMORY_LONG_TERM_LIMIT"); val != "" {
-		if i, err := strconv.Atoi(val); err == nil {
-			config.Memory.LongTermLimit = i
-		}
+	return 10 * time.Second // Default read timeout
+}
+
+// GetWebWriteTimeout parses the API server's response write timeout
+func (c *Config) GetWebWriteTimeout() time.Duration {
+	if duration, err := time.ParseDuration(c.Web.WriteTimeout); err == nil && duration > 0 {
+		return duration
 	}
+	return 10 * time.Second // Default write timeout
 }
 
-// GetString returns a string value, handling environment variable expansion
-func (c *Config) GetString(value string) string {
-	if strings.HasPrefix(value, "${") && strings.HasSuffix(value, "}") {
-		envVar := value[2 : len(value)-1]
-		if envValue := os.Getenv(envVar); envValue != "" {
-			return envValue
-		}
+// WebAddress returns the host and port the API server listens on
+func (c *Config) WebAddress() string {
+	return net.JoinHostPort(c.Web.Host, strconv.Itoa(c.Web.Port))
+}
+
+// GetWebhookBackoff parses the wait before the first retry of a webhook delivery
+func (c *Config) GetWebhookBackoff() time.Duration {
+	if duration, err := time.ParseDuration(c.Webhooks.Backoff); err == nil && duration > 0 {
+		return duration
 	}
-	return value
+	return 30 * time.Second // Default backoff
 }
 
-// GetTimeout parses the timeout string and returns a time.Duration
-func (c *Config) GetTimeout() time.Duration {
-	if duration, err := time.ParseDuration(c.LLM.Timeout); err == nil {
+// GetWebhookTimeout parses the time allowed for each webhook request
+func (c *Config) GetWebhookTimeout() time.Duration {
+	if duration, err := time.ParseDuration(c.Webhooks.Timeout); err == nil && duration > 0 {
 		return duration
 	}
-	return 30 * time.Second // Default timeout
+	return 10 * time.Second // Default webhook timeout
+}
+
+// BackupDir returns the directory snapshots are written to
+func (c *Config) BackupDir() string {
+	if c.Backup.Dir != "" {
+		return c.Backup.Dir
+	}
+	return filepath.Join(filepath.Dir(c.Database.Path), "backups")
 }
 
-// Validate validates the configuration
+// Validate validates the configuration. The error for an invalid value is a
+// *ValidationError naming the key and where it was set.
 func (c *Config) Validate() error {
-	// Validate database path
+	// Validate database configuration
 	if c.Database.Path == "" {
-		return fmt.Errorf("database path cannot be empty")
+		return c.invalid("database.path", "cannot be empty")
+	}
+
+	if d, err := time.ParseDuration(c.Database.ConnMaxLifetime); err != nil || d < 0 {
+		return c.invalid("database.conn_max_lifetime", "must be a duration such as 1h")
+	}
+
+	if d, err := time.ParseDuration(c.Database.ConnMaxIdleTime); err != nil || d < 0 {
+		return c.invalid("database.conn_max_idle_time", "must be a duration such as 5m")
 	}
 
 	// Validate log level
 	validLogLevels := []string{"debug", "info", "warn", "error"}
-	validLevel := false
-	for _, level := range validLogLevels {
-		if c.Log.Level == level {
-			validLevel = true
-			break
-		}
-	}
-	if !validLevel {
-		return fmt.Errorf("invalid log level: %s (must be one of: %s)",
-			c.Log.Level, strings.Join(validLogLevels, ", "))
+	if !contains(validLogLevels, c.Log.Level) {
+		return c.invalid("log.level", "is %q, must be one of: %s", c.Log.Level, strings.Join(validLogLevels, ", "))
 	}
 
 	// Validate LLM configuration
 	if c.LLM.Temperature < 0 || c.LLM.Temperature > 2 {
-		return fmt.Errorf("LLM temperature must be between 0 and 2")
+		return c.invalid("llm.temperature", "must be between 0 and 2")
 	}
 
 	if c.LLM.MaxTokens <= 0 {
-		return fmt.Errorf("LLM max tokens must be positive")
+		return c.invalid("llm.max_tokens", "must be positive")
 	}
 
-	// Validate analysis mode
-	validModes := []string{"discussion", "simulation", "analysis", "comparison", "evaluation", "prediction"}
-	validMode := false
-	for _, mode := range validModes {
-		if c.Analysis.DefaultMode == mode {
-			validMode = true
-			break
+	if timeout, err := time.ParseDuration(c.LLM.Timeout); err != nil || timeout <= 0 {
+		return c.invalid("llm.timeout", "must be a positive duration such as 30s")
+	}
+
+	if c.LLM.Mock.Latency != "" {
+		if latency, err := time.ParseDuration(c.LLM.Mock.Latency); err != nil || latency < 0 {
+			return c.invalid("llm.mock.latency", "must be a duration such as 500ms")
 		}
 	}
-	if !validMode {
-		return fmt.Errorf("invalid analysis mode: %s (must be one of: %s)",
-			c.Analysis.DefaultMode, strings.Join(validModes, ", "))
+
+	if c.LLM.Mock.ErrorRate < 0 || c.LLM.Mock.ErrorRate > 1 {
+		return c.invalid("llm.mock.error_rate", "must be between 0 and 1")
+	}
+
+	if c.Analysis.MaxConcurrent <= 0 {
+		return c.invalid("analysis.max_concurrent", "must be positive")
+	}
+
+	// Validate memory configuration
+	if c.Memory.RetentionDays <= 0 {
+		return c.invalid("memory.retention_days", "must be positive")
+	}
+
+	if c.Memory.ShortTermLimit <= 0 {
+		return c.invalid("memory.short_term_limit", "must be positive")
+	}
+
+	if c.Memory.LongTermLimit <= 0 {
+		return c.invalid("memory.long_term_limit", "must be positive")
+	}
+
+	if c.Memory.DecayRate <= 0 || c.Memory.DecayRate > 1 {
+		return c.invalid("memory.decay_rate", "must be greater than 0 and at most 1")
+	}
+
+	if interval, err := time.ParseDuration(c.Memory.CleanupInterval); err != nil || interval <= 0 {
+		return c.invalid("memory.cleanup_interval", "must be a positive duration such as 24h")
+	}
+
+	// Validate backup configuration
+	if interval, err := time.ParseDuration(c.Backup.Interval); err != nil || interval <= 0 {
+		return c.invalid("backup.interval", "must be a positive duration such as 24h")
+	}
+
+	if c.Backup.KeepDaily < 0 {
+		return c.invalid("backup.keep_daily", "cannot be negative")
+	}
+
+	if c.Backup.KeepWeekly < 0 {
+		return c.invalid("backup.keep_weekly", "cannot be negative")
+	}
+
+	if c.Backup.Enabled && c.Backup.KeepDaily == 0 && c.Backup.KeepWeekly == 0 {
+		return c.invalid("backup.enabled", "needs keep_daily or keep_weekly to be positive")
+	}
+
+	// Validate web configuration
+	if c.Web.Port <= 0 || c.Web.Port > 65535 {
+		return c.invalid("web.port", "must be between 1 and 65535")
+	}
+
+	if duration, err := time.ParseDuration(c.Web.ReadTimeout); err != nil || duration <= 0 {
+		return c.invalid("web.read_timeout", "must be a positive duration such as 10s")
+	}
+
+	if duration, err := time.ParseDuration(c.Web.WriteTimeout); err != nil || duration <= 0 {
+		return c.invalid("web.write_timeout", "must be a positive duration such as 10s")
+	}
+
+	// Validate webhook configuration
+	if c.Webhooks.MaxAttempts <= 0 {
+		return c.invalid("webhooks.max_attempts", "must be positive")
+	}
+
+	if d, err := time.ParseDuration(c.Webhooks.Backoff); err != nil || d <= 0 {
+		return c.invalid("webhooks.backoff", "must be a positive duration such as 30s")
+	}
+
+	if d, err := time.ParseDuration(c.Webhooks.Timeout); err != nil || d <= 0 {
+		return c.invalid("webhooks.timeout", "must be a positive duration such as 10s")
+	}
+
+	if c.Webhooks.BudgetUSD < 0 {
+		return c.invalid("webhooks.budget_usd", "cannot be negative")
+	}
+
+	// Validate analysis mode
+	validModes := []string{"discussion", "simulation", "analysis", "comparison", "evaluation", "prediction"}
+	if !contains(validModes, c.Analysis.DefaultMode) {
+		return c.invalid("analysis.default_mode", "is %q, must be one of: %s", c.Analysis.DefaultMode, strings.Join(validModes, ", "))
 	}
 
 	return nil
 }
 
-// HasProvider checks if a provider is configured with an API key
+// HasProvider checks if a provider is configured with an API key, or for the
+// mock provider, enabled. References are not resolved.
 func (c *Config) HasProvider(provider string) bool {
 	switch strings.ToLower(provider) {
 	case "openai":
-		return c.GetString(c.LLM.OpenAI.APIKey) != ""
+		return c.LLM.OpenAI.APIKey != ""
 	case "anthropic":
-		return c.GetString(c.LLM.Anthropic.APIKey) != ""
+		return c.LLM.Anthropic.APIKey != ""
 	case "google", "gemini":
-		return c.GetString(c.LLM.Google.APIKey) != ""
+		return c.LLM.Google.APIKey != ""
+	case "mock":
+		return c.LLM.Mock.Enabled
 	default:
 		return false
 	}
@@ -394,14 +605,33 @@ func (c *Config) GetProviderConfig(provider string) (ProviderConfig, bool) {
 		return c.LLM.Anthropic, true
 	case "google", "gemini":
 		return c.LLM.Google, true
+	case "mock":
+		return ProviderConfig{Model: c.LLM.Mock.Model}, true
 	default:
 		return ProviderConfig{}, false
 	}
 }
 
-// Save saves the configuration to a file
+// FilePath returns the file the configuration was loaded from, or
+// DefaultFilePath if it was not loaded from a file
+func (c *Config) FilePath() string {
+	if c.path != "" {
+		return c.path
+	}
+	return DefaultFilePath
+}
+
+// Save saves the configuration to a file. API keys are only written as
+// references such as keystore:openai; keys given in clear text are left out.
 func (c *Config) Save(path string) error {
-	file, err := os.Create(path)
+	saved := *c
+	for _, provider := range []*ProviderConfig{&saved.LLM.OpenAI, &saved.LLM.Anthropic, &saved.LLM.Google} {
+		if !secrets.IsReference(provider.APIKey) {
+			provider.APIKey = ""
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
 	if err != nil {
 		return err
 	}
@@ -410,5 +640,83 @@ func (c *Config) Save(path string) error {
 	encoder := yaml.NewEncoder(file)
 	defer encoder.Close()
 
-	return encoder.Encode(c)
+	return encoder.Encode(&saved)
+}
+
+// UpdateFile sets key to value in a YAML configuration file, creating the
+// file if needed. Only that key is added or changed: the keys the file already
+// sets, and its comments, are kept, and values from other layers never end up
+// in it.
+func UpdateFile(path, key, value string) error {
+	var document yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config from %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("failed to load config from %s: %w", path, err)
+	}
+	if len(document.Content) == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	// Parse the value as the key's type, and write it back as text
+	parsed := DefaultConfig()
+	if err := parsed.Set(key, value, Source{Kind: SourceProject, Name: path}); err != nil {
+		return err
+	}
+	field, _ := parsed.field(key)
+	text, _ := parsed.Get(key)
+
+	node := document.Content[0]
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to load config from %s: not a mapping of keys to values", path)
+	}
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		last := i == len(parts)-1
+		child := mappingValue(node, part)
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, child)
+		}
+		if last {
+			*child = yaml.Node{Kind: yaml.ScalarNode, Tag: scalarTags[field.Kind()], Value: text,
+				LineComment: child.LineComment, HeadComment: child.HeadComment, FootComment: child.FootComment}
+		} else if child.Kind != yaml.MappingNode {
+			*child = yaml.Node{Kind: yaml.MappingNode}
+		}
+		node = child
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return fmt.Errorf("failed to save config to %s: %w", path, err)
+	}
+	encoder.Close()
+	if err := os.WriteFile(path, out.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to save config to %s: %w", path, err)
+	}
+	return nil
+}
+
+// scalarTags are the YAML tags of the kinds of configuration values
+var scalarTags = map[reflect.Kind]string{
+	reflect.String:  "!!str",
+	reflect.Int:     "!!int",
+	reflect.Int64:   "!!int",
+	reflect.Float64: "!!float",
+	reflect.Bool:    "!!bool",
+}
+
+// mappingValue returns the value of key in a YAML mapping, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
 }
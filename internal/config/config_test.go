@@ -0,0 +1,177 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpdateFileKeepsOtherKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := `# Project settings
+llm:
+  temperature: 0.3 # Keep answers focused
+  openai:
+    model: gpt-4o
+web:
+  port: 9090
+`
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := UpdateFile(path, "llm.max_tokens", "1500"); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateFile(path, "llm.temperature", "0.9"); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateFile(path, "log.level", "warn"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, want := range []string{"# Project settings", "# Keep answers focused", "model: gpt-4o", "port: 9090", "max_tokens: 1500", "temperature: 0.9", "level: warn"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("saved file lacks %q:\n%s", want, text)
+		}
+	}
+	// Defaults of keys the file did not set are not written
+	for _, unwanted := range []string{"database", "retention_days", "default_mode"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("saved file sets %s:\n%s", unwanted, text)
+		}
+	}
+
+	config := DefaultConfig()
+	if err := loadFile(config, path, SourceProject); err != nil {
+		t.Fatal(err)
+	}
+	if config.LLM.Temperature != 0.9 || config.LLM.MaxTokens != 1500 || config.Web.Port != 9090 || config.Log.Level != "warn" {
+		t.Errorf("reloaded temperature %v, max tokens %d, port %d, log level %s", config.LLM.Temperature, config.LLM.MaxTokens, config.Web.Port, config.Log.Level)
+	}
+}
+
+func TestUpdateFileCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.yaml")
+	// A string that reads as another type is quoted
+	if err := UpdateFile(path, "llm.default_model", "true"); err != nil {
+		t.Fatal(err)
+	}
+
+	config := DefaultConfig()
+	if err := loadFile(config, path, SourceProject); err != nil {
+		t.Fatal(err)
+	}
+	if config.LLM.DefaultModel != "true" {
+		t.Errorf("default model = %q", config.LLM.DefaultModel)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("file mode: %v, %v", info, err)
+	}
+}
+
+func TestUpdateFileRejects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("web:\n  port: 9090\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, value := range map[string]string{"llm.nothing": "1", "llm.max_tokens": "many", "web": "x"} {
+		if err := UpdateFile(path, key, value); err == nil {
+			t.Errorf("%s = %s saved", key, value)
+		}
+	}
+	if data, _ := os.ReadFile(path); string(data) != "web:\n  port: 9090\n" {
+		t.Errorf("file changed:\n%s", data)
+	}
+}
+
+func TestLoadReportsSource(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("llm:\n  temperature: 5\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = config.Validate()
+	invalid, ok := err.(*ValidationError)
+	if !ok || invalid.Key != "llm.temperature" || invalid.Source.Name != path || invalid.Source.Line != 2 {
+		t.Errorf("err = %v, want llm.temperature invalid at %s:2", err, path)
+	}
+}
+
+func TestAPIKeyPrecedence(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	for _, name := range []string{"OPENAI_API_KEY", "PAB_LLM_OPENAI_API_KEY", "ANTHROPIC_API_KEY", "PAB_LLM_ANTHROPIC_API_KEY"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv(KeystorePassphraseEnv, "correct horse battery")
+
+	dir := t.TempDir()
+	helper := filepath.Join(dir, "helper")
+	if err := os.WriteFile(helper, []byte("#!/bin/sh\necho \"$1-from-command\"\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	file := "llm:\n  openai:\n    api_key: keystore:openai\n  anthropic:\n    api_key: command:anthropic\n" +
+		"secrets:\n  keystore: " + filepath.Join(dir, "keys") + "\n  command: " + helper + "\n"
+	if err := os.WriteFile(path, []byte(file), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	resolve := func(provider string) string {
+		t.Helper()
+		config, err := Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		providerConfig, _ := config.GetProviderConfig(provider)
+		key, err := config.ResolveSecret(providerConfig.APIKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Keystore().Set("openai", "openai-from-keystore"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file's references, to the keystore and the helper command
+	if key := resolve("openai"); key != "openai-from-keystore" {
+		t.Errorf("openai key %q, want the keystore's", key)
+	}
+	if key := resolve("anthropic"); key != "anthropic-from-command" {
+		t.Errorf("anthropic key %q, want the helper command's", key)
+	}
+
+	// Environment variables come before either
+	t.Setenv("OPENAI_API_KEY", "openai-from-env")
+	t.Setenv("ANTHROPIC_API_KEY", "anthropic-from-env")
+	if key := resolve("openai"); key != "openai-from-env" {
+		t.Errorf("openai key %q, want OPENAI_API_KEY", key)
+	}
+	if key := resolve("anthropic"); key != "anthropic-from-env" {
+		t.Errorf("anthropic key %q, want ANTHROPIC_API_KEY", key)
+	}
+
+	// and the PAB prefixed variable before the standard one
+	t.Setenv("PAB_LLM_OPENAI_API_KEY", "openai-from-pab-env")
+	if key := resolve("openai"); key != "openai-from-pab-env" {
+		t.Errorf("openai key %q, want PAB_LLM_OPENAI_API_KEY", key)
+	}
+}
@@ -0,0 +1,271 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"personal-ai-board/internal/secrets"
+)
+
+// SettingStore is where an editable setting is persisted
+type SettingStore string
+
+const (
+	// StoreFile settings are saved in the YAML configuration file
+	StoreFile SettingStore = "file"
+	// StoreDatabase settings are saved in the database's system_config table.
+	// They describe how the data in one database is analyzed and retained, so
+	// they travel with the database and take precedence over the file.
+	StoreDatabase SettingStore = "database"
+)
+
+// Setting describes a configuration value that can be edited at runtime.
+// Values are layered: built-in defaults, then the YAML files, then the
+// system_config table for database settings, then environment variables and
+// flags.
+type Setting struct {
+	Key         string       // Dotted YAML path, e.g. "llm.temperature"
+	Label       string       // Short human-readable name
+	Description string       // One-line explanation
+	Store       SettingStore // Where the value is saved
+	SystemKey   string       // system_config key for StoreDatabase settings
+	EnvVars     []string     // Environment variables that override the value
+	Options     []string     // Allowed values, if the setting is a choice
+	Secret      bool         // Whether the value must be masked when shown
+	SecretName  string       // Keystore entry a secret typed in is stored under
+	Restart     bool         // Whether a change only applies after a restart
+
+	get func(c *Config) string
+	set func(c *Config, value string) error
+}
+
+// Settings returns every editable setting in display order
+func Settings() []Setting {
+	return []Setting{
+		{
+			Key: "llm.default_provider", Label: "Default provider", Store: StoreDatabase,
+			Description: "LLM provider used when none is chosen",
+			SystemKey:   "llm_default_provider", EnvVars: []string{"PAB_LLM_DEFAULT_PROVIDER"},
+			Options: []string{"openai", "anthropic", "google", "mock"},
+			get:     func(c *Config) string { return c.LLM.DefaultProvider },
+			set:     func(c *Config, v string) error { c.LLM.DefaultProvider = v; return nil },
+		},
+		{
+			Key: "llm.default_model", Label: "Default model", Store: StoreDatabase,
+			Description: "Model used by the default provider when it sets none",
+			SystemKey:   "llm_default_model", EnvVars: []string{"PAB_LLM_DEFAULT_MODEL"},
+			get: func(c *Config) string { return c.LLM.DefaultModel },
+			set: func(c *Config, v string) error { c.LLM.DefaultModel = v; return nil },
+		},
+		{
+			Key: "llm.temperature", Label: "Temperature", Store: StoreFile,
+			Description: "Sampling temperature between 0 and 2",
+			EnvVars:     []string{"PAB_LLM_TEMPERATURE"},
+			get:         func(c *Config) string { return formatFloat(c.LLM.Temperature) },
+			set:         func(c *Config, v string) error { return parseFloat(v, &c.LLM.Temperature) },
+		},
+		{
+			Key: "llm.max_tokens", Label: "Max tokens", Store: StoreFile,
+			Description: "Largest response a provider may generate",
+			EnvVars:     []string{"PAB_LLM_MAX_TOKENS"},
+			get:         func(c *Config) string { return strconv.Itoa(c.LLM.MaxTokens) },
+			set:         func(c *Config, v string) error { return parseInt(v, &c.LLM.MaxTokens) },
+		},
+		{
+			Key: "llm.timeout", Label: "Request timeout", Store: StoreFile,
+			Description: "How long to wait for a provider, e.g. 30s or 2m",
+			get:         func(c *Config) string { return c.LLM.Timeout },
+			set:         func(c *Config, v string) error { c.LLM.Timeout = v; return nil },
+		},
+		{
+			Key: "llm.openai.api_key", Label: "OpenAI API key", Store: StoreFile,
+			Description: "Key for the OpenAI provider, kept in the keystore, or a reference such as env:OPENAI_API_KEY",
+			EnvVars:     []string{"OPENAI_API_KEY", "PAB_LLM_OPENAI_API_KEY"}, Secret: true, SecretName: "openai",
+			get: func(c *Config) string { return c.LLM.OpenAI.APIKey },
+			set: func(c *Config, v string) error { c.LLM.OpenAI.APIKey = v; return nil },
+		},
+		{
+			Key: "llm.anthropic.api_key", Label: "Anthropic API key", Store: StoreFile,
+			Description: "Key for the Anthropic provider, kept in the keystore, or a reference such as env:ANTHROPIC_API_KEY",
+			EnvVars:     []string{"ANTHROPIC_API_KEY", "PAB_LLM_ANTHROPIC_API_KEY"}, Secret: true, SecretName: "anthropic",
+			get: func(c *Config) string { return c.LLM.Anthropic.APIKey },
+			set: func(c *Config, v string) error { c.LLM.Anthropic.APIKey = v; return nil },
+		},
+		{
+			Key: "llm.google.api_key", Label: "Google API key", Store: StoreFile,
+			Description: "Key for the Google provider, kept in the keystore, or a reference such as env:GOOGLE_API_KEY",
+			EnvVars:     []string{"GOOGLE_API_KEY", "PAB_LLM_GOOGLE_API_KEY"}, Secret: true, SecretName: "google",
+			get: func(c *Config) string { return c.LLM.Google.APIKey },
+			set: func(c *Config, v string) error { c.LLM.Google.APIKey = v; return nil },
+		},
+		{
+			Key: "analysis.max_concurrent", Label: "Concurrent personas", Store: StoreDatabase,
+			Description: "How many personas may respond at the same time during an analysis",
+			SystemKey:   "analysis_max_concurrent", EnvVars: []string{"PAB_ANALYSIS_MAX_CONCURRENT"},
+			get: func(c *Config) string { return strconv.Itoa(c.Analysis.MaxConcurrent) },
+			set: func(c *Config, v string) error { return parseInt(v, &c.Analysis.MaxConcurrent) },
+		},
+		{
+			Key: "memory.short_term_limit", Label: "Short-term memories", Store: StoreFile,
+			Description: "Recent memories a persona keeps before consolidating them",
+			EnvVars:     []string{"PAB_MEMORY_SHORT_TERM_LIMIT"},
+			get:         func(c *Config) string { return strconv.Itoa(c.Memory.ShortTermLimit) },
+			set:         func(c *Config, v string) error { return parseInt(v, &c.Memory.ShortTermLimit) },
+		},
+		{
+			Key: "memory.long_term_limit", Label: "Long-term memories", Store: StoreFile,
+			Description: "Consolidated memories a persona keeps",
+			EnvVars:     []string{"PAB_MEMORY_LONG_TERM_LIMIT"},
+			get:         func(c *Config) string { return strconv.Itoa(c.Memory.LongTermLimit) },
+			set:         func(c *Config, v string) error { return parseInt(v, &c.Memory.LongTermLimit) },
+		},
+		{
+			Key: "memory.decay_rate", Label: "Memory decay rate", Store: StoreFile,
+			Description: "How much of a memory's weight survives each decay, above 0 and at most 1",
+			EnvVars:     []string{"PAB_MEMORY_DECAY_RATE"},
+			get:         func(c *Config) string { return formatFloat(c.Memory.DecayRate) },
+			set:         func(c *Config, v string) error { return parseFloat(v, &c.Memory.DecayRate) },
+		},
+		{
+			Key: "memory.retention_days", Label: "Log retention days", Store: StoreDatabase,
+			Description: "Days to keep LLM interaction logs",
+			SystemKey:   "memory_retention_days", EnvVars: []string{"PAB_MEMORY_RETENTION_DAYS"},
+			get: func(c *Config) string { return strconv.Itoa(c.Memory.RetentionDays) },
+			set: func(c *Config, v string) error { return parseInt(v, &c.Memory.RetentionDays) },
+		},
+	}
+}
+
+// LookupSetting returns the editable setting with the given key
+func LookupSetting(key string) (Setting, bool) {
+	for _, setting := range Settings() {
+		if setting.Key == key {
+			return setting, true
+		}
+	}
+	return Setting{}, false
+}
+
+// Value returns the setting's current value in c
+func (s Setting) Value(c *Config) string {
+	return s.get(c)
+}
+
+// Display returns the setting's value in c, masked if it is secret
+func (s Setting) Display(c *Config) string {
+	value := s.get(c)
+	if s.Secret {
+		return MaskSecret(value)
+	}
+	return value
+}
+
+// Set parses value and assigns it to the setting in c. It does not validate
+// the resulting configuration; use Config.Validate for that.
+func (s Setting) Set(c *Config, value string) error {
+	value = strings.TrimSpace(value)
+	if len(s.Options) > 0 && !contains(s.Options, value) {
+		return fmt.Errorf("%s must be one of: %s", s.Label, strings.Join(s.Options, ", "))
+	}
+	if err := s.set(c, value); err != nil {
+		return fmt.Errorf("%s: %w", s.Label, err)
+	}
+	return nil
+}
+
+// OverriddenBy returns the environment variable that currently overrides the
+// setting, or an empty string if none does
+func (s Setting) OverriddenBy() string {
+	for _, name := range s.EnvVars {
+		if os.Getenv(name) != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// Overridden returns the environment variable or flag that sets the setting
+// in c, when there is one; a value saved for it would not take effect
+func (s Setting) Overridden(c *Config) (Source, bool) {
+	if name := s.OverriddenBy(); name != "" {
+		return Source{Kind: SourceEnv, Name: name}, true
+	}
+	if source := c.Origin(s.Key); source.Kind == SourceFlag {
+		return source, true
+	}
+	return Source{}, false
+}
+
+// ApplySystemConfig applies database settings stored in the system_config
+// table. Settings set by an environment variable or a flag keep their value,
+// and values that cannot be parsed are skipped and reported.
+func (c *Config) ApplySystemConfig(values map[string]string) []error {
+	var errs []error
+	for _, setting := range Settings() {
+		if setting.Store != StoreDatabase {
+			continue
+		}
+		if kind := c.Origin(setting.Key).Kind; kind == SourceEnv || kind == SourceFlag {
+			continue
+		}
+		value, ok := values[setting.SystemKey]
+		if !ok {
+			continue
+		}
+		if err := setting.Set(c, value); err != nil {
+			errs = append(errs, fmt.Errorf("system_config %s: %w", setting.SystemKey, err))
+			continue
+		}
+		c.setOrigin(setting.Key, Source{Kind: SourceDatabase, Name: "system_config." + setting.SystemKey})
+	}
+	return errs
+}
+
+// MaskSecret hides all but the last four characters of a secret. References
+// such as keystore:openai or ${OPENAI_API_KEY} are shown as they are.
+func MaskSecret(value string) string {
+	if value == "" || secrets.IsReference(value) {
+		return value
+	}
+	if len(value) <= 8 {
+		return strings.Repeat("•", 8)
+	}
+	return strings.Repeat("•", 8) + value[len(value)-4:]
+}
+
+// formatFloat formats a float without trailing zeros
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// parseFloat parses value into target
+func parseFloat(value string, target *float64) error {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", value)
+	}
+	*target = f
+	return nil
+}
+
+// parseInt parses value into target
+func parseInt(value string, target *int) error {
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%q is not a whole number", value)
+	}
+	*target = i
+	return nil
+}
+
+// contains reports whether values contains value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,319 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SystemFilePath is the configuration file shared by every user of the machine
+const SystemFilePath = "/etc/personal-ai-board/config.yaml"
+
+// EnvPrefix starts the environment variable of every configuration value;
+// llm.mock.enabled is set by PAB_LLM_MOCK_ENABLED
+const EnvPrefix = "PAB_"
+
+// SourceKind is the kind of layer a configuration value comes from
+type SourceKind string
+
+// Layers in the order they are applied; each one overrides the ones before
+const (
+	SourceDefault  SourceKind = "default"
+	SourceSystem   SourceKind = "system file"
+	SourceUser     SourceKind = "user file"
+	SourceProject  SourceKind = "project file"
+	SourceEnv      SourceKind = "environment"
+	SourceFlag     SourceKind = "flag"
+	SourceDatabase SourceKind = "database"
+)
+
+// Source is where a configuration value was set
+type Source struct {
+	Kind SourceKind `json:"kind" yaml:"kind"`
+	Name string     `json:"name,omitempty" yaml:"name,omitempty"` // File, variable, flag or system_config key
+	Line int        `json:"line,omitempty" yaml:"line,omitempty"` // Line in a file
+}
+
+// String describes the source, e.g. "user file /home/me/.personal-ai-board.yaml:12"
+func (s Source) String() string {
+	switch {
+	case s.Name == "":
+		return string(s.Kind)
+	case s.Line > 0:
+		return fmt.Sprintf("%s %s:%d", s.Kind, s.Name, s.Line)
+	default:
+		return fmt.Sprintf("%s %s", s.Kind, s.Name)
+	}
+}
+
+// FlagSource is the source of a value given by a command line flag
+func FlagSource(name string) Source {
+	return Source{Kind: SourceFlag, Name: "--" + name}
+}
+
+// ValidationError is an invalid configuration value and where it was set
+type ValidationError struct {
+	Key     string
+	Message string
+	Source  Source
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s %s (%s)", e.Key, e.Message, e.Source)
+}
+
+// invalid reports that the value of key is invalid
+func (c *Config) invalid(key, format string, args ...interface{}) error {
+	return &ValidationError{Key: key, Message: fmt.Sprintf(format, args...), Source: c.Origin(key)}
+}
+
+// Origin returns where the value of key was set
+func (c *Config) Origin(key string) Source {
+	if source, ok := c.origins[key]; ok {
+		return source
+	}
+	return Source{Kind: SourceDefault}
+}
+
+// setOrigin records where the value of key was set
+func (c *Config) setOrigin(key string, source Source) {
+	if c.origins == nil {
+		c.origins = make(map[string]Source)
+	}
+	c.origins[key] = source
+}
+
+// Files returns the configuration files that were loaded, in the order they
+// were applied
+func (c *Config) Files() []Source {
+	return c.files
+}
+
+// Keys returns the dotted key of every configuration value in file order
+func (c *Config) Keys() []string {
+	return fieldKeys(reflect.TypeOf(*c), "")
+}
+
+// Get returns the value of key formatted as text
+func (c *Config) Get(key string) (string, bool) {
+	field, ok := c.field(key)
+	if !ok {
+		return "", false
+	}
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), true
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), true
+	case reflect.Float64:
+		return formatFloat(field.Float()), true
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool()), true
+	}
+	return "", false
+}
+
+// Set parses value into key and records source as where it came from
+func (c *Config) Set(key, value string, source Source) error {
+	field, ok := c.field(key)
+	if !ok {
+		return fmt.Errorf("unknown configuration key %s (%s)", key, source)
+	}
+
+	var err error
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(value, 10, 64); err != nil {
+			err = fmt.Errorf("%q is not a whole number", value)
+		} else {
+			field.SetInt(i)
+		}
+	case reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(value, 64); err != nil {
+			err = fmt.Errorf("%q is not a number", value)
+		} else {
+			field.SetFloat(f)
+		}
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(value); err != nil {
+			err = fmt.Errorf("%q is not true or false", value)
+		} else {
+			field.SetBool(b)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s (%s): %w", key, source, err)
+	}
+
+	c.setOrigin(key, source)
+	return nil
+}
+
+// field returns the settable struct field a dotted key names
+func (c *Config) field(key string) (reflect.Value, bool) {
+	value := reflect.ValueOf(c).Elem()
+	for _, part := range strings.Split(key, ".") {
+		if value.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		found := false
+		for i := 0; i < value.NumField(); i++ {
+			if value.Type().Field(i).IsExported() && yamlName(value.Type().Field(i)) == part {
+				value, found = value.Field(i), true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, false
+		}
+	}
+	switch value.Kind() {
+	case reflect.String, reflect.Int, reflect.Int64, reflect.Float64, reflect.Bool:
+		return value, true
+	}
+	return reflect.Value{}, false
+}
+
+// fieldKeys lists the dotted keys of the scalar fields of a struct type
+func fieldKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key := prefix + yamlName(field)
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			keys = append(keys, fieldKeys(field.Type, key+".")...)
+		case reflect.String, reflect.Int, reflect.Int64, reflect.Float64, reflect.Bool:
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// yamlName returns the name a struct field has in the YAML file
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// envVar returns the environment variable that sets key
+func envVar(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// fileLayers returns the configuration files applied on top of the defaults:
+// the system file, the user's file in their home directory, and the project
+// file, which is path when one is given or else the file in the current
+// directory. Files that do not exist are left out, except for path.
+func fileLayers(path string) []Source {
+	var layers []Source
+	add := func(kind SourceKind, candidates ...string) {
+		for _, candidate := range candidates {
+			if _, err := os.Stat(candidate); err != nil {
+				continue
+			}
+			for _, layer := range layers {
+				if sameFile(layer.Name, candidate) {
+					return
+				}
+			}
+			layers = append(layers, Source{Kind: kind, Name: candidate})
+			return
+		}
+	}
+
+	add(SourceSystem, SystemFilePath)
+	if home, err := os.UserHomeDir(); err == nil {
+		add(SourceUser, filepath.Join(home, ".personal-ai-board.yaml"), filepath.Join(home, ".personal-ai-board.yml"))
+	}
+	if path != "" {
+		// A file given explicitly is the project file, even when it is
+		// also the user's file
+		kept := layers[:0]
+		for _, layer := range layers {
+			if !sameFile(layer.Name, path) {
+				kept = append(kept, layer)
+			}
+		}
+		layers = append(kept, Source{Kind: SourceProject, Name: path})
+	} else {
+		add(SourceProject, ".personal-ai-board.yaml", ".personal-ai-board.yml")
+	}
+	return layers
+}
+
+// WatchPaths returns every file that could hold a configuration layer for
+// path, whether or not it exists yet, so that changes to them can be noticed
+func WatchPaths(path string) []string {
+	paths := []string{SystemFilePath}
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".personal-ai-board.yaml"), filepath.Join(home, ".personal-ai-board.yml"))
+	}
+	if path != "" {
+		return append(paths, path)
+	}
+	return append(paths, ".personal-ai-board.yaml", ".personal-ai-board.yml")
+}
+
+// sameFile reports whether two paths name the same file
+func sameFile(a, b string) bool {
+	aInfo, aErr := os.Stat(a)
+	bInfo, bErr := os.Stat(b)
+	return aErr == nil && bErr == nil && os.SameFile(aInfo, bInfo)
+}
+
+// loadFile applies a YAML file on top of the configuration and records the
+// line each of its values was set on
+func loadFile(config *Config, path string, kind SourceKind) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return err
+	}
+	if len(document.Content) == 0 {
+		return nil
+	}
+	if err := document.Content[0].Decode(config); err != nil {
+		return err
+	}
+
+	recordOrigins(config, document.Content[0], "", Source{Kind: kind, Name: path})
+	return nil
+}
+
+// recordOrigins records the file and line of every value in a YAML mapping
+func recordOrigins(config *Config, node *yaml.Node, prefix string, source Source) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		path := prefix + key.Value
+		if value.Kind == yaml.MappingNode {
+			recordOrigins(config, value, path+".", source)
+			continue
+		}
+		source.Line = value.Line
+		config.setOrigin(path, source)
+	}
+}
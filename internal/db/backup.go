@@ -0,0 +1,579 @@
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	// snapshotTimeFormat is the UTC timestamp in a snapshot's file name
+	snapshotTimeFormat = "20060102T150405Z"
+	// compressedSuffix is added to the name of compressed snapshots
+	compressedSuffix = ".gz"
+)
+
+// gzipMagic starts every gzip-compressed file
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// ErrDatabaseInUse is returned when a database cannot be restored because
+// another process has it open
+var ErrDatabaseInUse = errors.New("database is in use")
+
+// Snapshot is a verified, timestamped copy of the database
+type Snapshot struct {
+	Path          string    `json:"path"`
+	CreatedAt     time.Time `json:"created_at"`
+	Size          int64     `json:"size"`
+	Compressed    bool      `json:"compressed"`
+	SchemaVersion int       `json:"schema_version,omitempty"`
+}
+
+// SnapshotPolicy describes where snapshots are written and how many are kept.
+// The newest snapshot of each of the last KeepDaily days and of each of the
+// last KeepWeekly weeks is kept; the rest are pruned.
+type SnapshotPolicy struct {
+	Dir        string
+	Interval   time.Duration
+	KeepDaily  int
+	KeepWeekly int
+	Compress   bool
+}
+
+// RestoreResult describes a completed restore
+type RestoreResult struct {
+	Snapshot        string `json:"snapshot"`
+	Database        string `json:"database"`
+	SchemaVersion   int    `json:"schema_version"`
+	PreviousVersion int    `json:"previous_version"`
+	LatestVersion   int    `json:"latest_version"`
+	SafetyCopy      string `json:"safety_copy,omitempty"`
+}
+
+// Backup writes a consistent copy of the live database to destPath, which
+// must not exist
+func (db *Database) Backup(destPath string) error {
+	if _, err := db.Exec("VACUUM INTO ?", destPath); err != nil {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+	return nil
+}
+
+// Snapshot writes a timestamped copy of the database to dir, verifies it and
+// optionally compresses it with gzip
+func (db *Database) Snapshot(dir string, compress bool) (*Snapshot, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	name := snapshotPrefix(db.config.Path) + time.Now().UTC().Format(snapshotTimeFormat) + ".db"
+	if compress {
+		name += compressedSuffix
+	}
+	return db.BackupFile(filepath.Join(dir, name), compress)
+}
+
+// BackupFile writes a verified copy of the database to path, compressed with
+// gzip if compress is set. The copy is written under a temporary name first
+// so that path never holds a partial backup.
+func (db *Database) BackupFile(path string, compress bool) (*Snapshot, error) {
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	os.Remove(tmp)
+	defer os.Remove(tmp)
+
+	if err := db.Backup(tmp); err != nil {
+		return nil, err
+	}
+	version, err := VerifyFile(tmp)
+	if err != nil {
+		return nil, fmt.Errorf("backup failed verification: %w", err)
+	}
+
+	if compress {
+		if err := gzipFile(tmp, path); err != nil {
+			return nil, err
+		}
+	} else if err := os.Rename(tmp, path); err != nil {
+		return nil, fmt.Errorf("failed to save backup: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{
+		Path:          path,
+		CreatedAt:     createdAt,
+		Size:          info.Size(),
+		Compressed:    compress,
+		SchemaVersion: version,
+	}, nil
+}
+
+// snapshotPrefix is the start of the file name of every snapshot of the
+// database at dbPath
+func snapshotPrefix(dbPath string) string {
+	base := filepath.Base(dbPath)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + "-"
+}
+
+// ListSnapshots returns the snapshots of the database at dbPath found in dir,
+// newest first
+func ListSnapshots(dir, dbPath string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	prefix := snapshotPrefix(dbPath)
+	snapshots := make([]Snapshot, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		compressed := strings.HasSuffix(name, compressedSuffix)
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), compressedSuffix)
+		if !strings.HasSuffix(stamp, ".db") {
+			continue
+		}
+		createdAt, err := time.Parse(snapshotTimeFormat, strings.TrimSuffix(stamp, ".db"))
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, Snapshot{
+			Path:       filepath.Join(dir, name),
+			CreatedAt:  createdAt,
+			Size:       info.Size(),
+			Compressed: compressed,
+		})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt) })
+	return snapshots, nil
+}
+
+// ExpiredSnapshots returns the snapshots that fall outside the retention
+// policy. snapshots must be ordered newest first.
+func ExpiredSnapshots(snapshots []Snapshot, keepDaily, keepWeekly int) []Snapshot {
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+	expired := make([]Snapshot, 0)
+
+	for _, snapshot := range snapshots {
+		created := snapshot.CreatedAt.Local()
+		keep := false
+
+		day := created.Format("2006-01-02")
+		if !days[day] && len(days) < keepDaily {
+			days[day] = true
+			keep = true
+		}
+
+		year, week := created.ISOWeek()
+		weekKey := fmt.Sprintf("%d-W%02d", year, week)
+		if !weeks[weekKey] && len(weeks) < keepWeekly {
+			weeks[weekKey] = true
+			keep = true
+		}
+
+		if !keep {
+			expired = append(expired, snapshot)
+		}
+	}
+
+	return expired
+}
+
+// PruneSnapshots deletes the snapshots of the database in dir that fall
+// outside the retention policy and returns them
+func (db *Database) PruneSnapshots(dir string, keepDaily, keepWeekly int) ([]Snapshot, error) {
+	snapshots, err := ListSnapshots(dir, db.config.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	expired := ExpiredSnapshots(snapshots, keepDaily, keepWeekly)
+	for i, snapshot := range expired {
+		if err := os.Remove(snapshot.Path); err != nil {
+			return expired[:i], fmt.Errorf("failed to remove snapshot: %w", err)
+		}
+	}
+	return expired, nil
+}
+
+// VerifyFile checks the integrity of a database file or gzip-compressed
+// snapshot and returns its schema version. Compressed files are recognized by
+// their contents, whatever their name.
+func VerifyFile(path string) (int, error) {
+	compressed, err := isCompressed(path)
+	if err != nil {
+		return 0, err
+	}
+	if compressed {
+		tmp, err := os.CreateTemp("", "pab-verify-*.db")
+		if err != nil {
+			return 0, err
+		}
+		tmp.Close()
+		defer os.Remove(tmp.Name())
+
+		if err := gunzipFile(path, tmp.Name()); err != nil {
+			return 0, err
+		}
+		path = tmp.Name()
+	}
+
+	conn, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer conn.Close()
+
+	problems, err := queryNames(conn, "PRAGMA integrity_check")
+	if err != nil {
+		return 0, fmt.Errorf("integrity check failed: %w", err)
+	}
+	if len(problems) != 1 || problems[0] != "ok" {
+		return 0, fmt.Errorf("integrity check failed: %s", strings.Join(problems, "; "))
+	}
+
+	return fileSchemaVersion(conn)
+}
+
+// fileSchemaVersion reads the schema version of a database without changing
+// it, which also works on databases migrated before migrations were
+// checksummed
+func fileSchemaVersion(conn *sql.DB) (int, error) {
+	columns, err := tableColumns(conn, "schema_migrations")
+	if err != nil {
+		return 0, err
+	}
+	if len(columns) == 0 {
+		return 0, nil
+	}
+
+	query := "SELECT COALESCE(MAX(version), 0) FROM schema_migrations"
+	if _, ok := columns["dirty"]; ok {
+		query += " WHERE dirty = 0"
+	}
+	var version int
+	if err := conn.QueryRow(query).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// LatestVersion returns the version of the newest migration this build knows
+func LatestVersion() (int, error) {
+	migrations, err := NewMigrator(nil).GetMigrations()
+	if err != nil {
+		return 0, err
+	}
+	if len(migrations) == 0 {
+		return 0, nil
+	}
+	return migrations[len(migrations)-1].Version, nil
+}
+
+// isCompressed reports whether the file at path is gzip-compressed
+func isCompressed(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	header := make([]byte, len(gzipMagic))
+	if _, err := io.ReadFull(file, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	return bytes.Equal(header, gzipMagic), nil
+}
+
+// RestoreSnapshot replaces the database at dbPath with a snapshot. The
+// snapshot is verified first and refused if its schema is newer than this
+// build knows; an older schema is migrated the next time the database is
+// opened. The current database is kept next to it as a safety copy.
+//
+// The database must not be in use. It is locked exclusively while the safety
+// copy is made, and the restore fails with ErrDatabaseInUse if another
+// connection, such as the interactive UI or the API server, has it open.
+func RestoreSnapshot(snapshotPath, dbPath string) (*RestoreResult, error) {
+	version, err := VerifyFile(snapshotPath)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s is not usable: %w", snapshotPath, err)
+	}
+	latest, err := LatestVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version > latest {
+		return nil, fmt.Errorf("snapshot has schema version %d, but this version of the application only knows up to %d", version, latest)
+	}
+
+	result := &RestoreResult{
+		Snapshot:      snapshotPath,
+		Database:      dbPath,
+		SchemaVersion: version,
+		LatestVersion: latest,
+	}
+
+	// Stage the snapshot next to the database so the final swap is a rename
+	staged := dbPath + ".restore"
+	os.Remove(staged)
+	defer os.Remove(staged)
+	compressed, err := isCompressed(snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+	if compressed {
+		err = gunzipFile(snapshotPath, staged)
+	} else {
+		err = copyFile(snapshotPath, staged)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(dbPath); err == nil {
+		lock, err := lockDatabase(dbPath)
+		if err != nil {
+			return nil, err
+		}
+		result.SafetyCopy = unusedPath(fmt.Sprintf("%s.pre-restore-%s", dbPath, time.Now().UTC().Format(snapshotTimeFormat)))
+		result.PreviousVersion, err = saveSafetyCopy(lock, result.SafetyCopy)
+		// Closing the last connection checkpoints the write-ahead log
+		if closeErr := lock.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The safety copy holds everything in the write-ahead log, which must not
+	// be replayed onto the restored file
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove %s: %w", dbPath+suffix, err)
+		}
+	}
+	if err := os.Rename(staged, dbPath); err != nil {
+		return nil, fmt.Errorf("failed to replace database: %w", err)
+	}
+
+	return result, nil
+}
+
+// unusedPath returns path, or path with a numeric suffix if it already exists
+func unusedPath(path string) string {
+	candidate := path
+	for i := 2; ; i++ {
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", path, i)
+	}
+}
+
+// lockDatabase opens the database at path and locks it exclusively until the
+// connection is closed. Connections to a database in WAL mode hold a shared
+// lock for as long as they are open, so this fails with ErrDatabaseInUse
+// while anything else has the database open.
+func lockDatabase(path string) (*sql.DB, error) {
+	conn, err := sql.Open("sqlite3", "file:"+path+"?_locking_mode=EXCLUSIVE&_busy_timeout=0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open current database: %w", err)
+	}
+	conn.SetMaxOpenConns(1)
+
+	// In exclusive locking mode the lock is kept after the transaction ends
+	if _, err := conn.Exec("BEGIN EXCLUSIVE; COMMIT"); err != nil {
+		conn.Close()
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked) {
+			return nil, fmt.Errorf("%w: close the interactive UI and stop the API server using %s before restoring", ErrDatabaseInUse, path)
+		}
+		return nil, fmt.Errorf("failed to lock current database: %w", err)
+	}
+	return conn, nil
+}
+
+// saveSafetyCopy copies the database about to be replaced and returns its
+// schema version
+func saveSafetyCopy(conn *sql.DB, dest string) (int, error) {
+	version, err := fileSchemaVersion(conn)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := conn.Exec("VACUUM INTO ?", dest); err != nil {
+		return 0, fmt.Errorf("failed to copy current database: %w", err)
+	}
+	return version, nil
+}
+
+// gzipFile writes a gzip-compressed copy of src to dest
+func gzipFile(src, dest string) error {
+	return transformFile(src, dest, func(w io.Writer, r io.Reader) error {
+		zw := gzip.NewWriter(w)
+		if _, err := io.Copy(zw, r); err != nil {
+			return err
+		}
+		return zw.Close()
+	})
+}
+
+// gunzipFile writes the decompressed contents of src to dest
+func gunzipFile(src, dest string) error {
+	return transformFile(src, dest, func(w io.Writer, r io.Reader) error {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		_, err = io.Copy(w, zr)
+		return err
+	})
+}
+
+// copyFile copies src to dest
+func copyFile(src, dest string) error {
+	return transformFile(src, dest, func(w io.Writer, r io.Reader) error {
+		_, err := io.Copy(w, r)
+		return err
+	})
+}
+
+// transformFile writes src to dest through transform, removing dest if
+// anything fails
+func transformFile(src, dest string, transform func(w io.Writer, r io.Reader) error) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	err = transform(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dest)
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	return nil
+}
+
+// SnapshotJob takes snapshots on a schedule and prunes old ones
+type SnapshotJob struct {
+	db     *Database
+	policy SnapshotPolicy
+	logger Logger
+
+	mu       sync.Mutex
+	lastRun  time.Time
+	lastErr  error
+	lastSnap *Snapshot
+}
+
+// NewSnapshotJob creates a job that snapshots db according to policy
+func NewSnapshotJob(db *Database, policy SnapshotPolicy, logger Logger) *SnapshotJob {
+	if policy.Interval <= 0 {
+		policy.Interval = 24 * time.Hour
+	}
+
+	return &SnapshotJob{db: db, policy: policy, logger: logger}
+}
+
+// Start takes a snapshot whenever the newest one is older than the interval,
+// until ctx is cancelled
+func (j *SnapshotJob) Start(ctx context.Context) {
+	go func() {
+		for {
+			wait := j.untilDue()
+			if wait <= 0 {
+				if _, err := j.RunOnce(); err != nil {
+					j.logger.Error("Snapshot job failed", "error", err)
+				}
+				wait = j.policy.Interval
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+	}()
+}
+
+// untilDue returns how long until the next snapshot is due
+func (j *SnapshotJob) untilDue() time.Duration {
+	snapshots, err := ListSnapshots(j.policy.Dir, j.db.config.Path)
+	if err != nil || len(snapshots) == 0 {
+		return 0
+	}
+	return time.Until(snapshots[0].CreatedAt.Add(j.policy.Interval))
+}
+
+// RunOnce takes a snapshot and prunes those outside the retention policy
+func (j *SnapshotJob) RunOnce() (*Snapshot, error) {
+	snapshot, err := j.db.Snapshot(j.policy.Dir, j.policy.Compress)
+	if err == nil {
+		j.logger.Info("Database snapshot saved", "path", snapshot.Path, "size", snapshot.Size)
+
+		var pruned []Snapshot
+		pruned, err = j.db.PruneSnapshots(j.policy.Dir, j.policy.KeepDaily, j.policy.KeepWeekly)
+		if len(pruned) > 0 {
+			j.logger.Info("Pruned old database snapshots", "count", len(pruned))
+		}
+	}
+
+	j.mu.Lock()
+	j.lastRun = time.Now()
+	j.lastErr = err
+	if snapshot != nil {
+		j.lastSnap = snapshot
+	}
+	j.mu.Unlock()
+
+	return snapshot, err
+}
+
+// LastRun returns when the job last ran, the snapshot it took and the error
+// it returned, if any
+func (j *SnapshotJob) LastRun() (time.Time, *Snapshot, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.lastRun, j.lastSnap, j.lastErr
+}
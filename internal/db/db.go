@@ -145,6 +145,18 @@ func (db *Database) Migrate() error {
 	return migrator.RunMigrations()
 }
 
+// MigrationStatus returns the status of every known migration
+func (db *Database) MigrationStatus() ([]MigrationStatus, error) {
+	migrator := NewMigrator(db.DB)
+	return migrator.GetMigrationStatus()
+}
+
+// Rollback rolls the schema back to the given version
+func (db *Database) Rollback(targetVersion int) error {
+	migrator := NewMigrator(db.DB)
+	return migrator.RollbackMigration(targetVersion)
+}
+
 // Reset drops all tables and recreates the schema
 func (db *Database) Reset() error {
 	migrator := NewMigrator(db.DB)
@@ -157,6 +169,20 @@ func (db *Database) ValidateSchema() error {
 	return migrator.ValidateSchema()
 }
 
+// SchemaDifferences lists how the schema differs from what its migrations
+// should have produced
+func (db *Database) SchemaDifferences() ([]string, error) {
+	migrator := NewMigrator(db.DB)
+	return migrator.SchemaDifferences()
+}
+
+// ClearDirtyMigrations forgets migrations that failed or were interrupted and
+// returns how many there were
+func (db *Database) ClearDirtyMigrations() (int, error) {
+	migrator := NewMigrator(db.DB)
+	return migrator.ClearDirty()
+}
+
 // GetConfig returns the database configuration
 func (db *Database) GetConfig() *Config {
 	return db.config
@@ -234,35 +260,6 @@ func (db *Database) GetStats() (map[string]interface{}, error) {
 	return stats, nil
 }
 
-// Backup creates a backup of the database
-func (db *Database) Backup(destPath string) error {
-	// For SQLite, we can use the backup API
-	destDB, err := sql.Open("sqlite3", destPath)
-	if err != nil {
-		return fmt.Errorf("failed to open destination database: %w", err)
-	}
-	defer destDB.Close()
-
-	// Simple backup by copying all data
-	// In a production environment, you'd use sqlite3_backup_init/step/finish
-	// For now, we'll use a simple approach
-	
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// This is a simplified backup - in production you'd use the SQLite backup API
-	query := "VACUUM INTO ?"
-	_, err = db.Exec(query, destPath)
-	if err != nil {
-		return fmt.Errorf("backup failed: %w", err)
-	}
-
-	return nil
-}
-
 // Vacuum optimizes the database by rebuilding it
 func (db *Database) Vacuum() error {
 	_, err := db.Exec("VACUUM")
@@ -282,23 +279,25 @@ func (db *Database) Analyze() error {
 }
 
 // CleanupOldLogs removes old LLM interaction logs based on retention policy
-func (db *Database) CleanupOldLogs(retentionDays int) error {
+// and returns the number of rows removed
+func (db *Database) CleanupOldLogs(retentionDays int) (int64, error) {
+	if retentionDays <= 0 {
+		return 0, fmt.Errorf("retention days must be positive, got %d", retentionDays)
+	}
+
+	// created_at holds both SQLite's UTC CURRENT_TIMESTAMP text and Go times
+	// with a zone offset, so both sides are compared as UTC datetimes
 	query := `
 		DELETE FROM llm_interaction_logs 
-		WHERE created_at < datetime('now', '-' || ? || ' days')
+		WHERE datetime(created_at) < datetime('now', ?)
 	`
-	result, err := db.Exec(query, retentionDays)
+	result, err := db.Exec(query, fmt.Sprintf("-%d days", retentionDays))
 	if err != nil {
-		return fmt.Errorf("failed to cleanup old logs: %w", err)
+		return 0, fmt.Errorf("failed to cleanup old logs: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected > 0 {
-		// Log the cleanup operation
-		fmt.Printf("Cleaned up %d old LLM interaction logs\n", rowsAffected)
-	}
-
-	return nil
+	return rowsAffected, nil
 }
 
 // GetSystemConfig retrieves a system configuration value
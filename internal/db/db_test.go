@@ -0,0 +1,89 @@
+package db
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// testDatabase returns a migrated database in a temporary directory
+func testDatabase(t *testing.T) *Database {
+	t.Helper()
+	config := DefaultConfig()
+	config.Path = filepath.Join(t.TempDir(), "test.db")
+	database, err := Connect(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return database
+}
+
+func TestCleanupOldLogs(t *testing.T) {
+	database := testDatabase(t)
+
+	// A zone far from UTC, so comparing local and UTC text would go wrong
+	zone := time.FixedZone("UTC+14", 14*60*60)
+	now := time.Now()
+	for id, createdAt := range map[string]interface{}{
+		"old-utc-text":   now.Add(-31 * 24 * time.Hour).UTC().Format("2006-01-02 15:04:05"),
+		"new-utc-text":   now.Add(-29 * 24 * time.Hour).UTC().Format("2006-01-02 15:04:05"),
+		"old-local-time": now.Add(-30*24*time.Hour - time.Hour).In(zone),
+		"new-local-time": now.Add(-30*24*time.Hour + time.Hour).In(zone),
+		"just-now":       now,
+	} {
+		_, err := database.Exec(`
+			INSERT INTO llm_interaction_logs (id, prompt, response, model_name, created_at)
+			VALUES (?, 'prompt', 'response', 'mock', ?)
+		`, id, createdAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := database.Exec(`
+		INSERT INTO llm_interaction_logs (id, prompt, response, model_name, created_at)
+		VALUES ('current-timestamp', 'prompt', 'response', 'mock', CURRENT_TIMESTAMP)
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := database.CleanupOldLogs(30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Errorf("removed %d logs, want 2", removed)
+	}
+
+	rows, err := database.Query("SELECT id FROM llm_interaction_logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var kept []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		kept = append(kept, id)
+	}
+	sort.Strings(kept)
+	want := []string{"current-timestamp", "just-now", "new-local-time", "new-utc-text"}
+	if len(kept) != len(want) {
+		t.Fatalf("kept %v, want %v", kept, want)
+	}
+	for i := range want {
+		if kept[i] != want[i] {
+			t.Fatalf("kept %v, want %v", kept, want)
+		}
+	}
+
+	if _, err := database.CleanupOldLogs(0); err == nil {
+		t.Error("cleaned up with a retention of 0 days")
+	}
+}
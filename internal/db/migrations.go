@@ -1,21 +1,43 @@
 package db
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"embed"
+	"encoding/hex"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// migrationFiles holds the SQL of every migration, named
+// NNNN_name.up.sql and NNNN_name.down.sql
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationFileName matches the name of a migration file
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
 // Migration represents a database migration
 type Migration struct {
-	Version int
-	Name    string
-	Up      string
-	Down    string
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
 }
 
 // Migrator handles database migrations
 type Migrator struct {
-	db *sql.DB
+	db    *sql.DB
+	files fs.FS // Migration files; the embedded ones when nil
 }
 
 // NewMigrator creates a new migrator instance
@@ -24,436 +46,104 @@ func NewMigrator(db *sql.DB) *Migrator {
 }
 
 // GetMigrations returns all available migrations in order
-func (m *Migrator) GetMigrations() []Migration {
-	return []Migration{
-		{
-			Version: 1,
-			Name:    "create_personas_table",
-			Up: `
-				CREATE TABLE IF NOT EXISTS personas (
-					id TEXT PRIMARY KEY,
-					name TEXT NOT NULL,
-					description TEXT,
-					traits_config TEXT NOT NULL,
-					memory_data TEXT,
-					created_at DATETIME NOT NULL,
-					updated_at DATETIME NOT NULL
-				);
-
-				CREATE INDEX IF NOT EXISTS idx_personas_updated_at ON personas(updated_at);
-				CREATE INDEX IF NOT EXISTS idx_personas_name ON personas(name);
-			`,
-			Down: `DROP TABLE IF EXISTS personas;`,
-		},
-		{
-			Version: 2,
-			Name:    "create_boards_table",
-			Up: `
-				CREATE TABLE IF NOT EXISTS boards (
-					id TEXT PRIMARY KEY,
-					name TEXT NOT NULL,
-					description TEXT,
-					is_template BOOLEAN DEFAULT FALSE,
-					metadata TEXT,
-					created_at DATETIME NOT NULL,
-					updated_at DATETIME NOT NULL
-				);
-
-				CREATE INDEX IF NOT EXISTS idx_boards_updated_at ON boards(updated_at);
-				CREATE INDEX IF NOT EXISTS idx_boards_is_template ON boards(is_template);
-				CREATE INDEX IF NOT EXISTS idx_boards_name ON boards(name);
-			`,
-			Down: `DROP TABLE IF EXISTS boards;`,
-		},
-		{
-			Version: 3,
-			Name:    "create_board_personas_table",
-			Up: `
-				CREATE TABLE IF NOT EXISTS board_personas (
-					board_id TEXT NOT NULL,
-					persona_id TEXT NOT NULL,
-					role TEXT,
-					position INTEGER DEFAULT 0,
-					added_at DATETIME NOT NULL,
-					PRIMARY KEY (board_id, persona_id),
-					FOREIGN KEY (board_id) REFERENCES boards(id) ON DELETE CASCADE,
-					FOREIGN KEY (persona_id) REFERENCES personas(id) ON DELETE CASCADE
-				);
-
-				CREATE INDEX IF NOT EXISTS idx_board_personas_board_id ON board_personas(board_id);
-				CREATE INDEX IF NOT EXISTS idx_board_personas_persona_id ON board_personas(persona_id);
-			`,
-			Down: `DROP TABLE IF EXISTS board_personas;`,
-		},
-		{
-			Version: 4,
-			Name:    "create_projects_table",
-			Up: `
-				CREATE TABLE IF NOT EXISTS projects (
-					id TEXT PRIMARY KEY,
-					name TEXT NOT NULL,
-					description TEXT,
-					metadata TEXT,
-					status TEXT DEFAULT 'active',
-					created_at DATETIME NOT NULL,
-					updated_at DATETIME NOT NULL
-				);
-
-				CREATE INDEX IF NOT EXISTS idx_projects_updated_at ON projects(updated_at);
-				CREATE INDEX IF NOT EXISTS idx_projects_status ON projects(status);
-				CREATE INDEX IF NOT EXISTS idx_projects_name ON projects(name);
-			`,
-			Down: `DROP TABLE IF EXISTS projects;`,
-		},
-		{
-			Version: 5,
-			Name:    "create_ideas_table",
-			Up: `
-				CREATE TABLE IF NOT EXISTS ideas (
-					id TEXT PRIMARY KEY,
-					project_id TEXT NOT NULL,
-					title TEXT NOT NULL,
-					content TEXT,
-					metadata TEXT,
-					status TEXT DEFAULT 'draft',
-					created_at DATETIME NOT NULL,
-					updated_at DATETIME NOT NULL,
-					FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
-				);
-
-				CREATE INDEX IF NOT EXISTS idx_ideas_project_id ON ideas(project_id);
-				CREATE INDEX IF NOT EXISTS idx_ideas_updated_at ON ideas(updated_at);
-				CREATE INDEX IF NOT EXISTS idx_ideas_status ON ideas(status);
-				CREATE INDEX IF NOT EXISTS idx_ideas_title ON ideas(title);
-			`,
-			Down: `DROP TABLE IF EXISTS ideas;`,
-		},
-		{
-			Version: 6,
-			Name:    "create_analysis_sessions_table",
-			Up: `
-				CREATE TABLE IF NOT EXISTS analysis_sessions (
-					id TEXT PRIMARY KEY,
-					project_id TEXT NOT NULL,
-					board_id TEXT NOT NULL,
-					mode TEXT NOT NULL,
-					status TEXT DEFAULT 'pending',
-					context_data TEXT,
-					results_data TEXT,
-					created_at DATETIME NOT NULL,
-					started_at DATETIME,
-					completed_at DATETIME,
-					FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
-					FOREIGN KEY (board_id) REFERENCES boards(id) ON DELETE CASCADE
-				);
-
-				CREATE INDEX IF NOT EXISTS idx_analysis_sessions_project_id ON analysis_sessions(project_id);
-				CREATE INDEX IF NOT EXISTS idx_analysis_sessions_board_id ON analysis_sessions(board_id);
-				CREATE INDEX IF NOT EXISTS idx_analysis_sessions_status ON analysis_sessions(status);
-				CREATE INDEX IF NOT EXISTS idx_analysis_sessions_created_at ON analysis_sessions(created_at);
-				CREATE INDEX IF NOT EXISTS idx_analysis_sessions_mode ON analysis_sessions(mode);
-			`,
-			Down: `DROP TABLE IF EXISTS analysis_sessions;`,
-		},
-		{
-			Version: 7,
-			Name:    "create_analysis_responses_table",
-			Up: `
-				CREATE TABLE IF NOT EXISTS analysis_responses (
-					id TEXT PRIMARY KEY,
-					session_id TEXT NOT NULL,
-					persona_id TEXT NOT NULL,
-					response_content TEXT NOT NULL,
-					reasoning TEXT,
-					confidence REAL DEFAULT 0.5,
-					emotional_tone TEXT,
-					response_order INTEGER DEFAULT 0,
-					created_at DATETIME NOT NULL,
-					FOREIGN KEY (session_id) REFERENCES analysis_sessions(id) ON DELETE CASCADE,
-					FOREIGN KEY (persona_id) REFERENCES personas(id) ON DELETE CASCADE
-				);
-
-				CREATE INDEX IF NOT EXISTS idx_analysis_responses_session_id ON analysis_responses(session_id);
-				CREATE INDEX IF NOT EXISTS idx_analysis_responses_persona_id ON analysis_responses(persona_id);
-				CREATE INDEX IF NOT EXISTS idx_analysis_responses_created_at ON analysis_responses(created_at);
-				CREATE INDEX IF NOT EXISTS idx_analysis_responses_order ON analysis_responses(response_order);
-			`,
-			Down: `DROP TABLE IF EXISTS analysis_responses;`,
-		},
-		{
-			Version: 8,
-			Name:    "create_llm_interaction_logs_table",
-			Up: `
-				CREATE TABLE IF NOT EXISTS llm_interaction_logs (
-					id TEXT PRIMARY KEY,
-					persona_id TEXT,
-					session_id TEXT,
-					prompt TEXT NOT NULL,
-					system_message TEXT,
-					response TEXT NOT NULL,
-					model_name TEXT NOT NULL,
-					temperature REAL,
-					max_tokens INTEGER,
-					tokens_used INTEGER,
-					duration_ms INTEGER,
-					context_data TEXT,
-					created_at DATETIME NOT NULL
-				);
-
-				CREATE INDEX IF NOT EXISTS idx_llm_logs_persona_id ON llm_interaction_logs(persona_id);
-				CREATE INDEX IF NOT EXISTS idx_llm_logs_session_id ON llm_interaction_logs(session_id);
-				CREATE INDEX IF NOT EXISTS idx_llm_logs_created_at ON llm_interaction_logs(created_at);
-				CREATE INDEX IF NOT EXISTS idx_llm_logs_model_name ON llm_interaction_logs(model_name);
-			`,
-			Down: `DROP TABLE IF EXISTS llm_interaction_logs;`,
-		},
-		{
-			Version: 9,
-			Name:    "create_documents_table",
-			Up: `
-				CREATE TABLE IF NOT EXISTS documents (
-					id TEXT PRIMARY KEY,
-					project_id TEXT NOT NULL,
-					filename TEXT NOT NULL,
-					file_path TEXT NOT NULL,
-					file_type TEXT NOT NULL,
-					file_size INTEGER NOT NULL,
-					content_hash TEXT,
-					processed_content TEXT,
-					metadata TEXT,
-					status TEXT DEFAULT 'pending',
-					created_at DATETIME NOT NULL,
-					processed_at DATETIME,
-					FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
-				);
-
-				CREATE INDEX IF NOT EXISTS idx_documents_project_id ON documents(project_id);
-				CREATE INDEX IF NOT EXISTS idx_documents_status ON documents(status);
-				CREATE INDEX IF NOT EXISTS idx_documents_file_type ON documents(file_type);
-				CREATE INDEX IF NOT EXISTS idx_documents_created_at ON documents(created_at);
-				CREATE INDEX IF NOT EXISTS idx_documents_content_hash ON documents(content_hash);
-			`,
-			Down: `DROP TABLE IF EXISTS documents;`,
-		},
-		{
-			Version: 10,
-			Name:    "create_migrations_table",
-			Up: `
-				CREATE TABLE IF NOT EXISTS schema_migrations (
-					version INTEGER PRIMARY KEY,
-					name TEXT NOT NULL,
-					applied_at DATETIME NOT NULL
-				);
-			`,
-			Down: `DROP TABLE IF EXISTS schema_migrations;`,
-		},
-		{
-			Version: 11,
-			Name:    "create_system_config_table",
-			Up: `
-				CREATE TABLE IF NOT EXISTS system_config (
-					key TEXT PRIMARY KEY,
-					value TEXT NOT NULL,
-					description TEXT,
-					updated_at DATETIME NOT NULL
-				);
-
-				-- Insert default configuration values
-				INSERT OR IGNORE INTO system_config (key, value, description, updated_at) VALUES
-				('schema_version', '11', 'Current database schema version', datetime('now')),
-				('llm_default_provider', 'openai', 'Default LLM provider', datetime('now')),
-				('llm_default_model', 'gpt-4', 'Default LLM model', datetime('now')),
-				('analysis_max_concurrent', '5', 'Maximum concurrent analysis sessions', datetime('now')),
-				('memory_retention_days', '90', 'Days to retain interaction logs', datetime('now'));
-			`,
-			Down: `DROP TABLE IF EXISTS system_config;`,
-		},
-		{
-			Version: 12,
-			Name:    "add_persona_statistics",
-			Up: `
-				-- Add columns for persona usage statistics
-				ALTER TABLE personas ADD COLUMN total_interactions INTEGER DEFAULT 0;
-				ALTER TABLE personas ADD COLUMN last_interaction_at DATETIME;
-				ALTER TABLE personas ADD COLUMN average_confidence REAL DEFAULT 0.5;
-
-				CREATE INDEX IF NOT EXISTS idx_personas_last_interaction ON personas(last_interaction_at);
-				CREATE INDEX IF NOT EXISTS idx_personas_total_interactions ON personas(total_interactions);
-			`,
-			Down: `
-				-- SQLite doesn't support DROP COLUMN, so we recreate the table
-				CREATE TABLE personas_backup AS SELECT
-					id, name, description, traits_config, memory_data, created_at, updated_at
-				FROM personas;
-
-				DROP TABLE personas;
-
-				CREATE TABLE personas (
-					id TEXT PRIMARY KEY,
-					name TEXT NOT NULL,
-					description TEXT,
-					traits_config TEXT NOT NULL,
-					memory_data TEXT,
-					created_at DATETIME NOT NULL,
-					updated_at DATETIME NOT NULL
-				);
-
-				INSERT INTO personas SELECT * FROM personas_backup;
-				DROP TABLE personas_backup;
-
-				CREATE INDEX idx_personas_updated_at ON personas(updated_at);
-				CREATE INDEX idx_personas_name ON personas(name);
-			`,
-		},
-		{
-			Version: 13,
-			Name:    "add_analysis_insights_table",
-			Up: `
-				CREATE TABLE IF NOT EXISTS analysis_insights (
-					id TEXT PRIMARY KEY,
-					session_id TEXT NOT NULL,
-					insight_text TEXT NOT NULL,
-					insight_type TEXT NOT NULL,
-					confidence REAL DEFAULT 0.5,
-					persona_id TEXT,
-					created_at DATETIME NOT NULL,
-					FOREIGN KEY (session_id) REFERENCES analysis_sessions(id) ON DELETE CASCADE,
-					FOREIGN KEY (persona_id) REFERENCES personas(id) ON DELETE SET NULL
-				);
-
-				CREATE INDEX IF NOT EXISTS idx_insights_session_id ON analysis_insights(session_id);
-				CREATE INDEX IF NOT EXISTS idx_insights_type ON analysis_insights(insight_type);
-				CREATE INDEX IF NOT EXISTS idx_insights_persona_id ON analysis_insights(persona_id);
-				CREATE INDEX IF NOT EXISTS idx_insights_created_at ON analysis_insights(created_at);
-			`,
-			Down: `DROP TABLE IF EXISTS analysis_insights;`,
-		},
-		{
-			Version: 14,
-			Name:    "create_project_ideas_table",
-			Up: `
-				CREATE TABLE IF NOT EXISTS project_ideas (
-					id TEXT PRIMARY KEY,
-					project_id TEXT NOT NULL,
-					title TEXT NOT NULL,
-					description TEXT,
-					content TEXT,
-					tags TEXT,
-					priority INTEGER DEFAULT 0,
-					status TEXT DEFAULT 'draft',
-					metadata TEXT,
-					created_at DATETIME NOT NULL,
-					updated_at DATETIME NOT NULL,
-					FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
-				);
-
-				CREATE INDEX IF NOT EXISTS idx_project_ideas_project_id ON project_ideas(project_id);
-				CREATE INDEX IF NOT EXISTS idx_project_ideas_updated_at ON project_ideas(updated_at);
-				CREATE INDEX IF NOT EXISTS idx_project_ideas_status ON project_ideas(status);
-				CREATE INDEX IF NOT EXISTS idx_project_ideas_priority ON project_ideas(priority);
-			`,
-			Down: `DROP TABLE IF EXISTS project_ideas;`,
-		},
-		{
-			Version: 15,
-			Name:    "create_project_documents_table",
-			Up: `
-				CREATE TABLE IF NOT EXISTS project_documents (
-					id TEXT PRIMARY KEY,
-					project_id TEXT NOT NULL,
-					name TEXT NOT NULL,
-					file_path TEXT NOT NULL,
-					content_type TEXT NOT NULL,
-					size INTEGER NOT NULL,
-					processed_at DATETIME,
-					knowledge_id TEXT,
-					metadata TEXT,
-					created_at DATETIME NOT NULL,
-					updated_at DATETIME NOT NULL,
-					FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
-				);
-
-				CREATE INDEX IF NOT EXISTS idx_project_documents_project_id ON project_documents(project_id);
-				CREATE INDEX IF NOT EXISTS idx_project_documents_created_at ON project_documents(created_at);
-				CREATE INDEX IF NOT EXISTS idx_project_documents_content_type ON project_documents(content_type);
-			`,
-			Down: `DROP TABLE IF EXISTS project_documents;`,
-		},
-		{
-			Version: 16,
-			Name:    "create_analysis_requests_table",
-			Up: `
-				CREATE TABLE IF NOT EXISTS analysis_requests (
-					id TEXT PRIMARY KEY,
-					project_id TEXT NOT NULL,
-					board_id TEXT NOT NULL,
-					mode TEXT NOT NULL,
-					config TEXT,
-					created_at DATETIME NOT NULL,
-					FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
-					FOREIGN KEY (board_id) REFERENCES boards(id) ON DELETE CASCADE
-				);
-
-				CREATE INDEX IF NOT EXISTS idx_analysis_requests_project_id ON analysis_requests(project_id);
-				CREATE INDEX IF NOT EXISTS idx_analysis_requests_board_id ON analysis_requests(board_id);
-				CREATE INDEX IF NOT EXISTS idx_analysis_requests_mode ON analysis_requests(mode);
-				CREATE INDEX IF NOT EXISTS idx_analysis_requests_created_at ON analysis_requests(created_at);
-			`,
-			Down: `DROP TABLE IF EXISTS analysis_requests;`,
-		},
-		{
-			Version: 17,
-			Name:    "create_analysis_results_table",
-			Up: `
-				CREATE TABLE IF NOT EXISTS analysis_results (
-					id TEXT PRIMARY KEY,
-					request_id TEXT NOT NULL,
-					project_id TEXT NOT NULL,
-					board_id TEXT NOT NULL,
-					mode TEXT NOT NULL,
-					status TEXT DEFAULT 'pending',
-					summary TEXT,
-					insights TEXT,
-					responses TEXT,
-					metrics TEXT,
-					metadata TEXT,
-					started_at DATETIME NOT NULL,
-					completed_at DATETIME,
-					duration_ms INTEGER DEFAULT 0,
-					created_at DATETIME NOT NULL,
-					FOREIGN KEY (request_id) REFERENCES analysis_requests(id) ON DELETE CASCADE,
-					FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
-					FOREIGN KEY (board_id) REFERENCES boards(id) ON DELETE CASCADE
-				);
-
-				CREATE INDEX IF NOT EXISTS idx_analysis_results_request_id ON analysis_results(request_id);
-				CREATE INDEX IF NOT EXISTS idx_analysis_results_project_id ON analysis_results(project_id);
-				CREATE INDEX IF NOT EXISTS idx_analysis_results_board_id ON analysis_results(board_id);
-				CREATE INDEX IF NOT EXISTS idx_analysis_results_status ON analysis_results(status);
-				CREATE INDEX IF NOT EXISTS idx_analysis_results_mode ON analysis_results(mode);
-				CREATE INDEX IF NOT EXISTS idx_analysis_results_created_at ON analysis_results(created_at);
-			`,
-			Down: `DROP TABLE IF EXISTS analysis_results;`,
-		},
+func (m *Migrator) GetMigrations() ([]Migration, error) {
+	if m.files != nil {
+		return LoadMigrations(m.files)
+	}
+	sub, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
 	}
+	return LoadMigrations(sub)
 }
 
-// RunMigrations executes all pending migrations
+// LoadMigrations reads the up and down SQL files of every migration in fsys.
+// Each version must have exactly one name and both an up and a down file.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s (expected NNNN_name.up.sql or NNNN_name.down.sql)", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Version == 0 {
+			return nil, fmt.Errorf("migration %s has version 0; versions start at 1", migration.Name)
+		}
+		if strings.TrimSpace(migration.Up) == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d (%s) needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migration.Checksum = checksum(migration.Up)
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// checksum fingerprints the SQL of a migration
+func checksum(sql string) string {
+	sum := sha256.Sum256([]byte(sql))
+	return hex.EncodeToString(sum[:])
+}
+
+// RunMigrations executes all pending migrations, each in its own transaction.
+// It refuses to run while a migration is marked dirty or while an applied
+// migration's SQL no longer matches the checksum recorded when it ran.
 func (m *Migrator) RunMigrations() error {
 	// Create migrations table if it doesn't exist
 	if err := m.createMigrationsTable(); err != nil {
 		return fmt.Errorf("failed to create migrations table: %w", err)
 	}
 
+	migrations, err := m.GetMigrations()
+	if err != nil {
+		return err
+	}
+	if err := m.checkApplied(migrations); err != nil {
+		return err
+	}
+	if err := m.checkSkipped(migrations); err != nil {
+		return err
+	}
+
 	// Get current schema version
 	currentVersion, err := m.getCurrentVersion()
 	if err != nil {
 		return fmt.Errorf("failed to get current version: %w", err)
 	}
 
-	// Get all migrations
-	migrations := m.GetMigrations()
-
 	// Run pending migrations
 	for _, migration := range migrations {
 		if migration.Version > currentVersion {
@@ -466,8 +156,86 @@ func (m *Migrator) RunMigrations() error {
 	return nil
 }
 
+// checkApplied verifies that no migration is dirty and that every applied
+// migration still has the SQL it was applied with
+func (m *Migrator) checkApplied(migrations []Migration) error {
+	records, err := m.appliedMigrations()
+	if err != nil {
+		return err
+	}
+
+	known := make(map[int]Migration, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = migration
+	}
+
+	for _, record := range records {
+		if record.Dirty {
+			return fmt.Errorf("migration %d (%s) is dirty: it failed or was interrupted (%s); fix the schema by hand, then run 'db migrate --force'",
+				record.Version, record.Name, record.Error)
+		}
+		migration, ok := known[record.Version]
+		if !ok {
+			return fmt.Errorf("database has migration %d (%s), which this version does not know about", record.Version, record.Name)
+		}
+		if record.Checksum != migration.Checksum {
+			return fmt.Errorf("migration %d (%s) was modified after it was applied; restore its original SQL and add a new migration instead",
+				record.Version, record.Name)
+		}
+	}
+
+	return nil
+}
+
+// checkSkipped verifies that every migration numbered below the latest applied
+// one has been applied too. Such a migration, added after a later one ran,
+// would otherwise never run.
+func (m *Migrator) checkSkipped(migrations []Migration) error {
+	records, err := m.appliedMigrations()
+	if err != nil {
+		return err
+	}
+
+	applied := make(map[int]bool, len(records))
+	latest := 0
+	for _, record := range records {
+		applied[record.Version] = true
+		if !record.Dirty && record.Version > latest {
+			latest = record.Version
+		}
+	}
+
+	for _, migration := range migrations {
+		if migration.Version < latest && !applied[migration.Version] {
+			return fmt.Errorf("migration %d (%s) was never applied, but the later migration %d was; renumber it after %d, or roll back to version %d with 'db rollback' and migrate again",
+				migration.Version, migration.Name, latest, latest, migration.Version-1)
+		}
+	}
+
+	return nil
+}
+
+// ClearDirty forgets migrations that failed or were interrupted, so that they
+// run again with the next migration. Use it once the schema has been repaired.
+func (m *Migrator) ClearDirty() (int, error) {
+	if err := m.createMigrationsTable(); err != nil {
+		return 0, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	result, err := m.db.Exec("DELETE FROM schema_migrations WHERE dirty = 1")
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear dirty migrations: %w", err)
+	}
+	cleared, err := result.RowsAffected()
+	return int(cleared), err
+}
+
 // RollbackMigration rolls back to the specified version
 func (m *Migrator) RollbackMigration(targetVersion int) error {
+	if err := m.createMigrationsTable(); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
 	currentVersion, err := m.getCurrentVersion()
 	if err != nil {
 		return fmt.Errorf("failed to get current version: %w", err)
@@ -477,12 +245,26 @@ func (m *Migrator) RollbackMigration(targetVersion int) error {
 		return fmt.Errorf("target version %d is not less than current version %d", targetVersion, currentVersion)
 	}
 
-	migrations := m.GetMigrations()
+	migrations, err := m.GetMigrations()
+	if err != nil {
+		return err
+	}
+	if err := m.checkApplied(migrations); err != nil {
+		return err
+	}
+	records, err := m.appliedMigrations()
+	if err != nil {
+		return err
+	}
+	applied := make(map[int]bool, len(records))
+	for _, record := range records {
+		applied[record.Version] = true
+	}
 
-	// Run rollbacks in reverse order
+	// Run rollbacks in reverse order, skipping migrations that never ran
 	for i := len(migrations) - 1; i >= 0; i-- {
 		migration := migrations[i]
-		if migration.Version > targetVersion && migration.Version <= currentVersion {
+		if migration.Version > targetVersion && migration.Version <= currentVersion && applied[migration.Version] {
 			if err := m.rollbackMigration(migration); err != nil {
 				return fmt.Errorf("failed to rollback migration %d (%s): %w", migration.Version, migration.Name, err)
 			}
@@ -492,31 +274,152 @@ func (m *Migrator) RollbackMigration(targetVersion int) error {
 	return nil
 }
 
-// createMigrationsTable creates the schema_migrations table
+// createMigrationsTable creates the schema_migrations table and upgrades one
+// created before migrations were checksummed
 func (m *Migrator) createMigrationsTable() error {
 	query := `
 		CREATE TABLE IF NOT EXISTS schema_migrations (
 			version INTEGER PRIMARY KEY,
 			name TEXT NOT NULL,
-			applied_at DATETIME NOT NULL
+			applied_at DATETIME NOT NULL,
+			checksum TEXT NOT NULL DEFAULT '',
+			dirty INTEGER NOT NULL DEFAULT 0,
+			error TEXT
 		)
 	`
-	_, err := m.db.Exec(query)
-	return err
+	if _, err := m.db.Exec(query); err != nil {
+		return err
+	}
+
+	columns, err := tableColumns(m.db, "schema_migrations")
+	if err != nil {
+		return err
+	}
+	added := map[string]string{
+		"checksum": "checksum TEXT NOT NULL DEFAULT ''",
+		"dirty":    "dirty INTEGER NOT NULL DEFAULT 0",
+		"error":    "error TEXT",
+	}
+	for _, name := range []string{"checksum", "dirty", "error"} {
+		if _, ok := columns[name]; ok {
+			continue
+		}
+		if _, err := m.db.Exec("ALTER TABLE schema_migrations ADD COLUMN " + added[name]); err != nil {
+			return fmt.Errorf("failed to add %s to schema_migrations: %w", name, err)
+		}
+	}
+
+	// Migrations used to be recorded with the literal text datetime('now')
+	if _, err := m.db.Exec(
+		"UPDATE schema_migrations SET applied_at = ? WHERE applied_at = 'datetime(''now'')'",
+		time.Now(),
+	); err != nil {
+		return fmt.Errorf("failed to repair migration times: %w", err)
+	}
+
+	return m.backfillChecksums()
 }
 
-// getCurrentVersion gets the current schema version
+// backfillChecksums records the checksum of migrations applied before
+// checksums were stored, trusting that their SQL has not changed since
+func (m *Migrator) backfillChecksums() error {
+	var missing int
+	if err := m.db.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE checksum = ''").Scan(&missing); err != nil {
+		return err
+	}
+	if missing == 0 {
+		return nil
+	}
+
+	migrations, err := m.GetMigrations()
+	if err != nil {
+		return err
+	}
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(
+			"UPDATE schema_migrations SET checksum = ? WHERE version = ? AND checksum = ''",
+			migration.Checksum, migration.Version,
+		); err != nil {
+			return fmt.Errorf("failed to record checksum of migration %d: %w", migration.Version, err)
+		}
+	}
+	return nil
+}
+
+// getCurrentVersion gets the current schema version, ignoring migrations
+// that did not complete
 func (m *Migrator) getCurrentVersion() (int, error) {
 	var version int
-	err := m.db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	err := m.db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations WHERE dirty = 0").Scan(&version)
 	if err != nil {
 		return 0, err
 	}
 	return version, nil
 }
 
-// runMigration executes a single migration
+// appliedMigration is a row of schema_migrations
+type appliedMigration struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+	Checksum  string
+	Dirty     bool
+	Error     string
+}
+
+// appliedMigrations reads every migration recorded in schema_migrations
+func (m *Migrator) appliedMigrations() ([]appliedMigration, error) {
+	rows, err := m.db.Query("SELECT version, name, applied_at, checksum, dirty, COALESCE(error, '') FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var records []appliedMigration
+	for rows.Next() {
+		var record appliedMigration
+		if err := rows.Scan(&record.Version, &record.Name, &record.AppliedAt, &record.Checksum, &record.Dirty, &record.Error); err != nil {
+			return nil, fmt.Errorf("failed to read applied migration: %w", err)
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// runMigration executes a single migration in a transaction. The migration is
+// marked dirty before it starts, so a failure or a crash part-way through
+// leaves a record that blocks further migrations until it is looked at.
 func (m *Migrator) runMigration(migration Migration) error {
+	if _, err := m.db.Exec(`
+		INSERT INTO schema_migrations (version, name, applied_at, checksum, dirty, error)
+		VALUES (?, ?, ?, ?, 1, NULL)
+		ON CONFLICT(version) DO UPDATE SET
+			name = excluded.name,
+			applied_at = excluded.applied_at,
+			checksum = excluded.checksum,
+			dirty = 1,
+			error = NULL`,
+		migration.Version, migration.Name, time.Now(), migration.Checksum,
+	); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	if err := m.applyMigration(migration); err != nil {
+		if _, markErr := m.db.Exec(
+			"UPDATE schema_migrations SET error = ? WHERE version = ?",
+			err.Error(), migration.Version,
+		); markErr != nil {
+			return fmt.Errorf("%w (and failed to record the error: %v)", err, markErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// applyMigration runs the migration SQL and clears its dirty flag in a single
+// transaction
+func (m *Migrator) applyMigration(migration Migration) error {
 	tx, err := m.db.Begin()
 	if err != nil {
 		return err
@@ -528,10 +431,9 @@ func (m *Migrator) runMigration(migration Migration) error {
 		return fmt.Errorf("failed to execute migration SQL: %w", err)
 	}
 
-	// Record the migration
 	if _, err := tx.Exec(
-		"INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
-		migration.Version, migration.Name, "datetime('now')",
+		"UPDATE schema_migrations SET dirty = 0, applied_at = ? WHERE version = ?",
+		time.Now(), migration.Version,
 	); err != nil {
 		return fmt.Errorf("failed to record migration: %w", err)
 	}
@@ -565,19 +467,37 @@ func (m *Migrator) rollbackMigration(migration Migration) error {
 
 // GetMigrationStatus returns the status of all migrations
 func (m *Migrator) GetMigrationStatus() ([]MigrationStatus, error) {
-	currentVersion, err := m.getCurrentVersion()
+	if err := m.createMigrationsTable(); err != nil {
+		return nil, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	migrations, err := m.GetMigrations()
 	if err != nil {
 		return nil, err
 	}
+	records, err := m.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[int]appliedMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
 
-	migrations := m.GetMigrations()
 	status := make([]MigrationStatus, len(migrations))
-
 	for i, migration := range migrations {
 		status[i] = MigrationStatus{
-			Version: migration.Version,
-			Name:    migration.Name,
-			Applied: migration.Version <= currentVersion,
+			Version:  migration.Version,
+			Name:     migration.Name,
+			Checksum: migration.Checksum,
+		}
+		if record, ok := applied[migration.Version]; ok {
+			status[i].Applied = !record.Dirty
+			status[i].Dirty = record.Dirty
+			status[i].Modified = !record.Dirty && record.Checksum != migration.Checksum
+			status[i].Error = record.Error
+			appliedAt := record.AppliedAt
+			status[i].AppliedAt = &appliedAt
 		}
 	}
 
@@ -586,9 +506,60 @@ func (m *Migrator) GetMigrationStatus() ([]MigrationStatus, error) {
 
 // MigrationStatus represents the status of a migration
 type MigrationStatus struct {
-	Version int    `json:"version"`
-	Name    string `json:"name"`
-	Applied bool   `json:"applied"`
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	Checksum  string     `json:"checksum"`
+	Dirty     bool       `json:"dirty"`
+	Modified  bool       `json:"modified"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// CreateMigration writes an empty up/down pair for a new migration to dir,
+// numbered after the highest version already there, and returns their paths
+func CreateMigration(dir, name string) (string, string, error) {
+	name = strings.Trim(regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", "", fmt.Errorf("migration name must contain letters or digits")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+	version := 0
+	for _, entry := range entries {
+		if match := migrationFileName.FindStringSubmatch(entry.Name()); match != nil {
+			if v, _ := strconv.Atoi(match[1]); v > version {
+				version = v
+			}
+		}
+	}
+	version++
+
+	base := fmt.Sprintf("%04d_%s", version, name)
+	up := filepath.Join(dir, base+".up.sql")
+	down := filepath.Join(dir, base+".down.sql")
+	files := map[string]string{
+		up:   fmt.Sprintf("-- Migration %d: %s\n", version, name),
+		down: fmt.Sprintf("-- Undo migration %d: %s\n", version, name),
+	}
+	for _, file := range []string{up, down} {
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to create %s: %w", filepath.Base(file), err)
+		}
+		_, err = f.WriteString(files[file])
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("failed to write %s: %w", filepath.Base(file), err)
+		}
+	}
+
+	return up, down, nil
 }
 
 // ResetDatabase drops all tables and recreates the schema
@@ -619,28 +590,3 @@ func (m *Migrator) ResetDatabase() error {
 	// Run all migrations
 	return m.RunMigrations()
 }
-
-// ValidateSchema validates that the database schema matches the expected structure
-func (m *Migrator) ValidateSchema() error {
-	expectedTables := []string{
-		"personas", "boards", "board_personas", "projects", "ideas",
-		"analysis_sessions", "analysis_responses", "llm_interaction_logs",
-		"documents", "schema_migrations", "system_config", "analysis_insights",
-	}
-
-	for _, tableName := range expectedTables {
-		var count int
-		err := m.db.QueryRow(
-			"SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?",
-			tableName,
-		).Scan(&count)
-		if err != nil {
-			return fmt.Errorf("failed to check table %s: %w", tableName, err)
-		}
-		if count == 0 {
-			return fmt.Errorf("missing required table: %s", tableName)
-		}
-	}
-
-	return nil
-}
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS personas (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	description TEXT,
+	traits_config TEXT NOT NULL,
+	memory_data TEXT,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_personas_updated_at ON personas(updated_at);
+CREATE INDEX IF NOT EXISTS idx_personas_name ON personas(name);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS boards (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	description TEXT,
+	is_template BOOLEAN DEFAULT FALSE,
+	metadata TEXT,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_boards_updated_at ON boards(updated_at);
+CREATE INDEX IF NOT EXISTS idx_boards_is_template ON boards(is_template);
+CREATE INDEX IF NOT EXISTS idx_boards_name ON boards(name);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS board_personas (
+	board_id TEXT NOT NULL,
+	persona_id TEXT NOT NULL,
+	role TEXT,
+	position INTEGER DEFAULT 0,
+	added_at DATETIME NOT NULL,
+	PRIMARY KEY (board_id, persona_id),
+	FOREIGN KEY (board_id) REFERENCES boards(id) ON DELETE CASCADE,
+	FOREIGN KEY (persona_id) REFERENCES personas(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_board_personas_board_id ON board_personas(board_id);
+CREATE INDEX IF NOT EXISTS idx_board_personas_persona_id ON board_personas(persona_id);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS ideas (
+	id TEXT PRIMARY KEY,
+	project_id TEXT NOT NULL,
+	title TEXT NOT NULL,
+	content TEXT,
+	metadata TEXT,
+	status TEXT DEFAULT 'draft',
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_ideas_project_id ON ideas(project_id);
+CREATE INDEX IF NOT EXISTS idx_ideas_updated_at ON ideas(updated_at);
+CREATE INDEX IF NOT EXISTS idx_ideas_status ON ideas(status);
+CREATE INDEX IF NOT EXISTS idx_ideas_title ON ideas(title);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS analysis_sessions (
+	id TEXT PRIMARY KEY,
+	project_id TEXT NOT NULL,
+	board_id TEXT NOT NULL,
+	mode TEXT NOT NULL,
+	status TEXT DEFAULT 'pending',
+	context_data TEXT,
+	results_data TEXT,
+	created_at DATETIME NOT NULL,
+	started_at DATETIME,
+	completed_at DATETIME,
+	FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+	FOREIGN KEY (board_id) REFERENCES boards(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_analysis_sessions_project_id ON analysis_sessions(project_id);
+CREATE INDEX IF NOT EXISTS idx_analysis_sessions_board_id ON analysis_sessions(board_id);
+CREATE INDEX IF NOT EXISTS idx_analysis_sessions_status ON analysis_sessions(status);
+CREATE INDEX IF NOT EXISTS idx_analysis_sessions_created_at ON analysis_sessions(created_at);
+CREATE INDEX IF NOT EXISTS idx_analysis_sessions_mode ON analysis_sessions(mode);
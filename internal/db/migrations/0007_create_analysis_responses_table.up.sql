@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS analysis_responses (
+	id TEXT PRIMARY KEY,
+	session_id TEXT NOT NULL,
+	persona_id TEXT NOT NULL,
+	response_content TEXT NOT NULL,
+	reasoning TEXT,
+	confidence REAL DEFAULT 0.5,
+	emotional_tone TEXT,
+	response_order INTEGER DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	FOREIGN KEY (session_id) REFERENCES analysis_sessions(id) ON DELETE CASCADE,
+	FOREIGN KEY (persona_id) REFERENCES personas(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_analysis_responses_session_id ON analysis_responses(session_id);
+CREATE INDEX IF NOT EXISTS idx_analysis_responses_persona_id ON analysis_responses(persona_id);
+CREATE INDEX IF NOT EXISTS idx_analysis_responses_created_at ON analysis_responses(created_at);
+CREATE INDEX IF NOT EXISTS idx_analysis_responses_order ON analysis_responses(response_order);
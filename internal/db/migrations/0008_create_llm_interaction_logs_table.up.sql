@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS llm_interaction_logs (
+	id TEXT PRIMARY KEY,
+	persona_id TEXT,
+	session_id TEXT,
+	prompt TEXT NOT NULL,
+	system_message TEXT,
+	response TEXT NOT NULL,
+	model_name TEXT NOT NULL,
+	temperature REAL,
+	max_tokens INTEGER,
+	tokens_used INTEGER,
+	duration_ms INTEGER,
+	context_data TEXT,
+	created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_logs_persona_id ON llm_interaction_logs(persona_id);
+CREATE INDEX IF NOT EXISTS idx_llm_logs_session_id ON llm_interaction_logs(session_id);
+CREATE INDEX IF NOT EXISTS idx_llm_logs_created_at ON llm_interaction_logs(created_at);
+CREATE INDEX IF NOT EXISTS idx_llm_logs_model_name ON llm_interaction_logs(model_name);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS documents (
+	id TEXT PRIMARY KEY,
+	project_id TEXT NOT NULL,
+	filename TEXT NOT NULL,
+	file_path TEXT NOT NULL,
+	file_type TEXT NOT NULL,
+	file_size INTEGER NOT NULL,
+	content_hash TEXT,
+	processed_content TEXT,
+	metadata TEXT,
+	status TEXT DEFAULT 'pending',
+	created_at DATETIME NOT NULL,
+	processed_at DATETIME,
+	FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_documents_project_id ON documents(project_id);
+CREATE INDEX IF NOT EXISTS idx_documents_status ON documents(status);
+CREATE INDEX IF NOT EXISTS idx_documents_file_type ON documents(file_type);
+CREATE INDEX IF NOT EXISTS idx_documents_created_at ON documents(created_at);
+CREATE INDEX IF NOT EXISTS idx_documents_content_hash ON documents(content_hash);
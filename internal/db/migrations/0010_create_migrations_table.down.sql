@@ -0,0 +1,2 @@
+-- Nothing to undo: schema_migrations belongs to the migrator and must
+-- survive rollbacks.
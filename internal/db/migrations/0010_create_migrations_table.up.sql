@@ -0,0 +1,3 @@
+-- schema_migrations is created by the migrator itself before any migration
+-- runs. This migration used to create it again and is kept as a no-op so
+-- that the version numbers of existing databases stay the same.
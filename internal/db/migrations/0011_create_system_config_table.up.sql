@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS system_config (
+	key TEXT PRIMARY KEY,
+	value TEXT NOT NULL,
+	description TEXT,
+	updated_at DATETIME NOT NULL
+);
+
+-- Insert default configuration values
+INSERT OR IGNORE INTO system_config (key, value, description, updated_at) VALUES
+('schema_version', '11', 'Current database schema version', datetime('now')),
+('llm_default_provider', 'openai', 'Default LLM provider', datetime('now')),
+('llm_default_model', 'gpt-4', 'Default LLM model', datetime('now')),
+('analysis_max_concurrent', '5', 'Maximum concurrent analysis sessions', datetime('now')),
+('memory_retention_days', '90', 'Days to retain interaction logs', datetime('now'));
@@ -0,0 +1,22 @@
+-- SQLite doesn't support DROP COLUMN, so we recreate the table
+CREATE TABLE personas_backup AS SELECT
+	id, name, description, traits_config, memory_data, created_at, updated_at
+FROM personas;
+
+DROP TABLE personas;
+
+CREATE TABLE personas (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	description TEXT,
+	traits_config TEXT NOT NULL,
+	memory_data TEXT,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+
+INSERT INTO personas SELECT * FROM personas_backup;
+DROP TABLE personas_backup;
+
+CREATE INDEX idx_personas_updated_at ON personas(updated_at);
+CREATE INDEX idx_personas_name ON personas(name);
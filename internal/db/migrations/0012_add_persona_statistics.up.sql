@@ -0,0 +1,7 @@
+-- Add columns for persona usage statistics
+ALTER TABLE personas ADD COLUMN total_interactions INTEGER DEFAULT 0;
+ALTER TABLE personas ADD COLUMN last_interaction_at DATETIME;
+ALTER TABLE personas ADD COLUMN average_confidence REAL DEFAULT 0.5;
+
+CREATE INDEX IF NOT EXISTS idx_personas_last_interaction ON personas(last_interaction_at);
+CREATE INDEX IF NOT EXISTS idx_personas_total_interactions ON personas(total_interactions);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS analysis_insights (
+	id TEXT PRIMARY KEY,
+	session_id TEXT NOT NULL,
+	insight_text TEXT NOT NULL,
+	insight_type TEXT NOT NULL,
+	confidence REAL DEFAULT 0.5,
+	persona_id TEXT,
+	created_at DATETIME NOT NULL,
+	FOREIGN KEY (session_id) REFERENCES analysis_sessions(id) ON DELETE CASCADE,
+	FOREIGN KEY (persona_id) REFERENCES personas(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_insights_session_id ON analysis_insights(session_id);
+CREATE INDEX IF NOT EXISTS idx_insights_type ON analysis_insights(insight_type);
+CREATE INDEX IF NOT EXISTS idx_insights_persona_id ON analysis_insights(persona_id);
+CREATE INDEX IF NOT EXISTS idx_insights_created_at ON analysis_insights(created_at);
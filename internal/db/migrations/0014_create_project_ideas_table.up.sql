@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS project_ideas (
+	id TEXT PRIMARY KEY,
+	project_id TEXT NOT NULL,
+	title TEXT NOT NULL,
+	description TEXT,
+	content TEXT,
+	tags TEXT,
+	priority INTEGER DEFAULT 0,
+	status TEXT DEFAULT 'draft',
+	metadata TEXT,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_ideas_project_id ON project_ideas(project_id);
+CREATE INDEX IF NOT EXISTS idx_project_ideas_updated_at ON project_ideas(updated_at);
+CREATE INDEX IF NOT EXISTS idx_project_ideas_status ON project_ideas(status);
+CREATE INDEX IF NOT EXISTS idx_project_ideas_priority ON project_ideas(priority);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS project_documents (
+	id TEXT PRIMARY KEY,
+	project_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	file_path TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	processed_at DATETIME,
+	knowledge_id TEXT,
+	metadata TEXT,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_documents_project_id ON project_documents(project_id);
+CREATE INDEX IF NOT EXISTS idx_project_documents_created_at ON project_documents(created_at);
+CREATE INDEX IF NOT EXISTS idx_project_documents_content_type ON project_documents(content_type);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS analysis_requests (
+	id TEXT PRIMARY KEY,
+	project_id TEXT NOT NULL,
+	board_id TEXT NOT NULL,
+	mode TEXT NOT NULL,
+	config TEXT,
+	created_at DATETIME NOT NULL,
+	FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+	FOREIGN KEY (board_id) REFERENCES boards(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_analysis_requests_project_id ON analysis_requests(project_id);
+CREATE INDEX IF NOT EXISTS idx_analysis_requests_board_id ON analysis_requests(board_id);
+CREATE INDEX IF NOT EXISTS idx_analysis_requests_mode ON analysis_requests(mode);
+CREATE INDEX IF NOT EXISTS idx_analysis_requests_created_at ON analysis_requests(created_at);
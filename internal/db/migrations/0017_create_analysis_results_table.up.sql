@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS analysis_results (
+	id TEXT PRIMARY KEY,
+	request_id TEXT NOT NULL,
+	project_id TEXT NOT NULL,
+	board_id TEXT NOT NULL,
+	mode TEXT NOT NULL,
+	status TEXT DEFAULT 'pending',
+	summary TEXT,
+	insights TEXT,
+	responses TEXT,
+	metrics TEXT,
+	metadata TEXT,
+	started_at DATETIME NOT NULL,
+	completed_at DATETIME,
+	duration_ms INTEGER DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	FOREIGN KEY (request_id) REFERENCES analysis_requests(id) ON DELETE CASCADE,
+	FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+	FOREIGN KEY (board_id) REFERENCES boards(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_analysis_results_request_id ON analysis_results(request_id);
+CREATE INDEX IF NOT EXISTS idx_analysis_results_project_id ON analysis_results(project_id);
+CREATE INDEX IF NOT EXISTS idx_analysis_results_board_id ON analysis_results(board_id);
+CREATE INDEX IF NOT EXISTS idx_analysis_results_status ON analysis_results(status);
+CREATE INDEX IF NOT EXISTS idx_analysis_results_mode ON analysis_results(mode);
+CREATE INDEX IF NOT EXISTS idx_analysis_results_created_at ON analysis_results(created_at);
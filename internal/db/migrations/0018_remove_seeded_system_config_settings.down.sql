@@ -0,0 +1,5 @@
+INSERT OR IGNORE INTO system_config (key, value, description, updated_at) VALUES
+('llm_default_provider', 'openai', 'Default LLM provider', datetime('now')),
+('llm_default_model', 'gpt-4', 'Default LLM model', datetime('now')),
+('analysis_max_concurrent', '5', 'Maximum concurrent analysis sessions', datetime('now')),
+('memory_retention_days', '90', 'Days to retain interaction logs', datetime('now'));
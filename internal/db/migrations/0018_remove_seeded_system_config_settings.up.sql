@@ -0,0 +1,8 @@
+-- Settings in system_config now override the configuration file.
+-- Drop the seeded defaults that were never changed so that they do
+-- not mask the values in the file.
+DELETE FROM system_config WHERE
+	(key = 'llm_default_provider' AND value = 'openai') OR
+	(key = 'llm_default_model' AND value = 'gpt-4') OR
+	(key = 'analysis_max_concurrent' AND value = '5') OR
+	(key = 'memory_retention_days' AND value = '90');
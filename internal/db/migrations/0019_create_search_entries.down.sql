@@ -0,0 +1,19 @@
+DROP TRIGGER IF EXISTS search_personas_insert;
+DROP TRIGGER IF EXISTS search_personas_update;
+DROP TRIGGER IF EXISTS search_personas_delete;
+DROP TRIGGER IF EXISTS search_ideas_insert;
+DROP TRIGGER IF EXISTS search_ideas_update;
+DROP TRIGGER IF EXISTS search_ideas_delete;
+DROP TRIGGER IF EXISTS search_documents_insert;
+DROP TRIGGER IF EXISTS search_documents_update;
+DROP TRIGGER IF EXISTS search_documents_delete;
+DROP TRIGGER IF EXISTS search_responses_insert;
+DROP TRIGGER IF EXISTS search_responses_update;
+DROP TRIGGER IF EXISTS search_responses_delete;
+DROP TRIGGER IF EXISTS search_insights_insert;
+DROP TRIGGER IF EXISTS search_insights_update;
+DROP TRIGGER IF EXISTS search_insights_delete;
+DROP TRIGGER IF EXISTS search_results_insert;
+DROP TRIGGER IF EXISTS search_results_update;
+DROP TRIGGER IF EXISTS search_results_delete;
+DROP TABLE IF EXISTS search_entries;
@@ -0,0 +1,169 @@
+-- search_entries holds the searchable text of personas, ideas, documents
+-- and analysis output. The triggers below keep it in sync with the tables it
+-- is drawn from. The full-text index over it (search_fts) is created at
+-- runtime, since it needs SQLite built with FTS5.
+CREATE TABLE IF NOT EXISTS search_entries (
+	id INTEGER PRIMARY KEY,
+	source TEXT NOT NULL,
+	ref_id TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	title TEXT NOT NULL DEFAULT '',
+	body TEXT NOT NULL DEFAULT '',
+	project_id TEXT,
+	board_id TEXT,
+	persona_id TEXT,
+	persona_name TEXT,
+	created_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_entries_source ON search_entries(source, ref_id);
+CREATE INDEX IF NOT EXISTS idx_search_entries_created_at ON search_entries(created_at);
+
+CREATE TRIGGER IF NOT EXISTS search_personas_insert AFTER INSERT ON personas BEGIN
+	INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+	SELECT 'personas', NEW.id, 'persona', NEW.name, COALESCE(NEW.description, ''), NULL, NULL, NEW.id, NEW.name, datetime(NEW.created_at);
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_personas_update AFTER UPDATE OF name, description ON personas BEGIN
+	DELETE FROM search_entries WHERE source = 'personas' AND ref_id = OLD.id;
+	INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+	SELECT 'personas', NEW.id, 'persona', NEW.name, COALESCE(NEW.description, ''), NULL, NULL, NEW.id, NEW.name, datetime(NEW.created_at);
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_personas_delete AFTER DELETE ON personas BEGIN
+	DELETE FROM search_entries WHERE source = 'personas' AND ref_id = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_ideas_insert AFTER INSERT ON project_ideas BEGIN
+	INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+	SELECT 'project_ideas', NEW.id, 'idea', NEW.title, trim(COALESCE(NEW.description, '') || char(10) || COALESCE(NEW.content, '')), NEW.project_id, NULL, NULL, NULL, datetime(NEW.created_at);
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_ideas_update AFTER UPDATE OF title, description, content, project_id ON project_ideas BEGIN
+	DELETE FROM search_entries WHERE source = 'project_ideas' AND ref_id = OLD.id;
+	INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+	SELECT 'project_ideas', NEW.id, 'idea', NEW.title, trim(COALESCE(NEW.description, '') || char(10) || COALESCE(NEW.content, '')), NEW.project_id, NULL, NULL, NULL, datetime(NEW.created_at);
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_ideas_delete AFTER DELETE ON project_ideas BEGIN
+	DELETE FROM search_entries WHERE source = 'project_ideas' AND ref_id = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_documents_insert AFTER INSERT ON documents BEGIN
+	INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+	SELECT 'documents', NEW.id, 'document', NEW.filename, COALESCE(NEW.processed_content, ''), NEW.project_id, NULL, NULL, NULL, datetime(NEW.created_at);
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_documents_update AFTER UPDATE OF filename, processed_content, project_id ON documents BEGIN
+	DELETE FROM search_entries WHERE source = 'documents' AND ref_id = OLD.id;
+	INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+	SELECT 'documents', NEW.id, 'document', NEW.filename, COALESCE(NEW.processed_content, ''), NEW.project_id, NULL, NULL, NULL, datetime(NEW.created_at);
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_documents_delete AFTER DELETE ON documents BEGIN
+	DELETE FROM search_entries WHERE source = 'documents' AND ref_id = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_responses_insert AFTER INSERT ON analysis_responses BEGIN
+	INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+	SELECT 'analysis_responses', NEW.id, 'response', COALESCE(CASE WHEN json_valid(s.context_data) THEN json_extract(s.context_data, '$.topic') END, ''), NEW.response_content, s.project_id, s.board_id, NEW.persona_id, p.name, datetime(NEW.created_at)
+	FROM (SELECT 1)
+	LEFT JOIN analysis_sessions s ON s.id = NEW.session_id
+	LEFT JOIN personas p ON p.id = NEW.persona_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_responses_update AFTER UPDATE OF response_content, persona_id, session_id ON analysis_responses BEGIN
+	DELETE FROM search_entries WHERE source = 'analysis_responses' AND ref_id = OLD.id;
+	INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+	SELECT 'analysis_responses', NEW.id, 'response', COALESCE(CASE WHEN json_valid(s.context_data) THEN json_extract(s.context_data, '$.topic') END, ''), NEW.response_content, s.project_id, s.board_id, NEW.persona_id, p.name, datetime(NEW.created_at)
+	FROM (SELECT 1)
+	LEFT JOIN analysis_sessions s ON s.id = NEW.session_id
+	LEFT JOIN personas p ON p.id = NEW.persona_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_responses_delete AFTER DELETE ON analysis_responses BEGIN
+	DELETE FROM search_entries WHERE source = 'analysis_responses' AND ref_id = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_insights_insert AFTER INSERT ON analysis_insights BEGIN
+	INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+	SELECT 'analysis_insights', NEW.id, 'insight', COALESCE(CASE WHEN json_valid(s.context_data) THEN json_extract(s.context_data, '$.topic') END, ''), NEW.insight_text, s.project_id, s.board_id, NEW.persona_id, p.name, datetime(NEW.created_at)
+	FROM (SELECT 1)
+	LEFT JOIN analysis_sessions s ON s.id = NEW.session_id
+	LEFT JOIN personas p ON p.id = NEW.persona_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_insights_update AFTER UPDATE OF insight_text, persona_id, session_id ON analysis_insights BEGIN
+	DELETE FROM search_entries WHERE source = 'analysis_insights' AND ref_id = OLD.id;
+	INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+	SELECT 'analysis_insights', NEW.id, 'insight', COALESCE(CASE WHEN json_valid(s.context_data) THEN json_extract(s.context_data, '$.topic') END, ''), NEW.insight_text, s.project_id, s.board_id, NEW.persona_id, p.name, datetime(NEW.created_at)
+	FROM (SELECT 1)
+	LEFT JOIN analysis_sessions s ON s.id = NEW.session_id
+	LEFT JOIN personas p ON p.id = NEW.persona_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_insights_delete AFTER DELETE ON analysis_insights BEGIN
+	DELETE FROM search_entries WHERE source = 'analysis_insights' AND ref_id = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_results_insert AFTER INSERT ON analysis_results BEGIN
+	INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+	SELECT 'analysis_results', NEW.id, 'analysis', COALESCE(CASE WHEN json_valid(NEW.metadata) THEN json_extract(NEW.metadata, '$.topic') END, ''), COALESCE(NEW.summary, ''), NEW.project_id, NEW.board_id, NULL, NULL, datetime(NEW.created_at);
+	INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+	SELECT 'analysis_results', NEW.id, 'response', COALESCE(CASE WHEN json_valid(NEW.metadata) THEN json_extract(NEW.metadata, '$.topic') END, ''), json_extract(j.value, '$.content'), NEW.project_id, NEW.board_id,
+		NULLIF(json_extract(j.value, '$.persona_id'), ''), json_extract(j.value, '$.persona_name'), datetime(NEW.created_at)
+	FROM json_each(CASE WHEN json_valid(NEW.responses) THEN NEW.responses ELSE '[]' END) j
+	WHERE COALESCE(json_extract(j.value, '$.content'), '') != '';
+	INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+	SELECT 'analysis_results', NEW.id, 'insight', COALESCE(CASE WHEN json_valid(NEW.metadata) THEN json_extract(NEW.metadata, '$.topic') END, ''), j.value, NEW.project_id, NEW.board_id, NULL, NULL, datetime(NEW.created_at)
+	FROM json_each(CASE WHEN json_valid(NEW.insights) THEN NEW.insights ELSE '[]' END) j
+	WHERE j.type = 'text';
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_results_update AFTER UPDATE OF summary, insights, responses, metadata, project_id, board_id ON analysis_results BEGIN
+	DELETE FROM search_entries WHERE source = 'analysis_results' AND ref_id = OLD.id;
+	INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+	SELECT 'analysis_results', NEW.id, 'analysis', COALESCE(CASE WHEN json_valid(NEW.metadata) THEN json_extract(NEW.metadata, '$.topic') END, ''), COALESCE(NEW.summary, ''), NEW.project_id, NEW.board_id, NULL, NULL, datetime(NEW.created_at);
+	INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+	SELECT 'analysis_results', NEW.id, 'response', COALESCE(CASE WHEN json_valid(NEW.metadata) THEN json_extract(NEW.metadata, '$.topic') END, ''), json_extract(j.value, '$.content'), NEW.project_id, NEW.board_id,
+		NULLIF(json_extract(j.value, '$.persona_id'), ''), json_extract(j.value, '$.persona_name'), datetime(NEW.created_at)
+	FROM json_each(CASE WHEN json_valid(NEW.responses) THEN NEW.responses ELSE '[]' END) j
+	WHERE COALESCE(json_extract(j.value, '$.content'), '') != '';
+	INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+	SELECT 'analysis_results', NEW.id, 'insight', COALESCE(CASE WHEN json_valid(NEW.metadata) THEN json_extract(NEW.metadata, '$.topic') END, ''), j.value, NEW.project_id, NEW.board_id, NULL, NULL, datetime(NEW.created_at)
+	FROM json_each(CASE WHEN json_valid(NEW.insights) THEN NEW.insights ELSE '[]' END) j
+	WHERE j.type = 'text';
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_results_delete AFTER DELETE ON analysis_results BEGIN
+	DELETE FROM search_entries WHERE source = 'analysis_results' AND ref_id = OLD.id;
+END;
+
+-- Index what is already stored
+INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+SELECT 'personas', x.id, 'persona', x.name, COALESCE(x.description, ''), NULL, NULL, x.id, x.name, datetime(x.created_at) FROM personas x;
+INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+SELECT 'project_ideas', x.id, 'idea', x.title, trim(COALESCE(x.description, '') || char(10) || COALESCE(x.content, '')), x.project_id, NULL, NULL, NULL, datetime(x.created_at) FROM project_ideas x;
+INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+SELECT 'documents', x.id, 'document', x.filename, COALESCE(x.processed_content, ''), x.project_id, NULL, NULL, NULL, datetime(x.created_at) FROM documents x;
+INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+SELECT 'analysis_responses', x.id, 'response', COALESCE(CASE WHEN json_valid(s.context_data) THEN json_extract(s.context_data, '$.topic') END, ''), x.response_content, s.project_id, s.board_id, x.persona_id, p.name, datetime(x.created_at)
+FROM analysis_responses x
+	LEFT JOIN analysis_sessions s ON s.id = x.session_id
+	LEFT JOIN personas p ON p.id = x.persona_id;
+INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+SELECT 'analysis_insights', x.id, 'insight', COALESCE(CASE WHEN json_valid(s.context_data) THEN json_extract(s.context_data, '$.topic') END, ''), x.insight_text, s.project_id, s.board_id, x.persona_id, p.name, datetime(x.created_at)
+FROM analysis_insights x
+	LEFT JOIN analysis_sessions s ON s.id = x.session_id
+	LEFT JOIN personas p ON p.id = x.persona_id;
+INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+SELECT 'analysis_results', x.id, 'analysis', COALESCE(CASE WHEN json_valid(x.metadata) THEN json_extract(x.metadata, '$.topic') END, ''), COALESCE(x.summary, ''), x.project_id, x.board_id, NULL, NULL, datetime(x.created_at) FROM analysis_results x;
+INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+SELECT 'analysis_results', x.id, 'response', COALESCE(CASE WHEN json_valid(x.metadata) THEN json_extract(x.metadata, '$.topic') END, ''), json_extract(j.value, '$.content'), x.project_id, x.board_id,
+	NULLIF(json_extract(j.value, '$.persona_id'), ''), json_extract(j.value, '$.persona_name'), datetime(x.created_at)
+FROM analysis_results x, json_each(CASE WHEN json_valid(x.responses) THEN x.responses ELSE '[]' END) j
+WHERE COALESCE(json_extract(j.value, '$.content'), '') != '';
+INSERT INTO search_entries (source, ref_id, kind, title, body, project_id, board_id, persona_id, persona_name, created_at)
+SELECT 'analysis_results', x.id, 'insight', COALESCE(CASE WHEN json_valid(x.metadata) THEN json_extract(x.metadata, '$.topic') END, ''), j.value, x.project_id, x.board_id, NULL, NULL, datetime(x.created_at)
+FROM analysis_results x, json_each(CASE WHEN json_valid(x.insights) THEN x.insights ELSE '[]' END) j
+WHERE j.type = 'text';
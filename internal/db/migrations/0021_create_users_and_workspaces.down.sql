@@ -0,0 +1,18 @@
+-- Rolling back merges every workspace's data back into one
+DROP INDEX IF EXISTS idx_personas_workspace_id;
+DROP INDEX IF EXISTS idx_boards_workspace_id;
+DROP INDEX IF EXISTS idx_projects_workspace_id;
+DROP INDEX IF EXISTS idx_analysis_requests_workspace_id;
+DROP INDEX IF EXISTS idx_analysis_results_workspace_id;
+DROP INDEX IF EXISTS idx_webhooks_workspace_id;
+
+ALTER TABLE personas DROP COLUMN workspace_id;
+ALTER TABLE boards DROP COLUMN workspace_id;
+ALTER TABLE projects DROP COLUMN workspace_id;
+ALTER TABLE analysis_requests DROP COLUMN workspace_id;
+ALTER TABLE analysis_results DROP COLUMN workspace_id;
+ALTER TABLE webhooks DROP COLUMN workspace_id;
+
+DROP TABLE IF EXISTS api_tokens;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,51 @@
+-- Personas, boards, projects, analyses and webhooks belong to a workspace.
+-- Everything created before workspaces existed moves into the default one.
+CREATE TABLE IF NOT EXISTS workspaces (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+
+INSERT OR IGNORE INTO workspaces (id, name, created_at, updated_at)
+VALUES ('workspace_default', 'Default', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+CREATE TABLE IF NOT EXISTS users (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	workspace_id TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (workspace_id) REFERENCES workspaces(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_workspace_id ON users(workspace_id);
+
+-- Only a hash of each token is kept; prefix identifies it in listings
+CREATE TABLE IF NOT EXISTS api_tokens (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL,
+	name TEXT,
+	prefix TEXT NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	expires_at DATETIME,
+	last_used_at DATETIME,
+	created_at DATETIME NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id ON api_tokens(user_id);
+
+ALTER TABLE personas ADD COLUMN workspace_id TEXT NOT NULL DEFAULT 'workspace_default';
+ALTER TABLE boards ADD COLUMN workspace_id TEXT NOT NULL DEFAULT 'workspace_default';
+ALTER TABLE projects ADD COLUMN workspace_id TEXT NOT NULL DEFAULT 'workspace_default';
+ALTER TABLE analysis_requests ADD COLUMN workspace_id TEXT NOT NULL DEFAULT 'workspace_default';
+ALTER TABLE analysis_results ADD COLUMN workspace_id TEXT NOT NULL DEFAULT 'workspace_default';
+ALTER TABLE webhooks ADD COLUMN workspace_id TEXT NOT NULL DEFAULT 'workspace_default';
+
+CREATE INDEX IF NOT EXISTS idx_personas_workspace_id ON personas(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_boards_workspace_id ON boards(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_projects_workspace_id ON projects(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_analysis_requests_workspace_id ON analysis_requests(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_analysis_results_workspace_id ON analysis_results(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_webhooks_workspace_id ON webhooks(workspace_id);
@@ -0,0 +1,206 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// migrationFS holds up and down files for each version given as name: up SQL
+func migrationFS(migrations map[string]string) fstest.MapFS {
+	files := make(fstest.MapFS)
+	for name, up := range migrations {
+		table := name[strings.Index(name, "_create_")+len("_create_"):]
+		files[name+".up.sql"] = &fstest.MapFile{Data: []byte(up)}
+		files[name+".down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE " + table + ";")}
+	}
+	return files
+}
+
+// testMigrator returns a migrator for files on an empty temporary database
+func testMigrator(t *testing.T, files fstest.MapFS) *Migrator {
+	t.Helper()
+	config := DefaultConfig()
+	config.Path = filepath.Join(t.TempDir(), "migrations.db")
+	database, err := Connect(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return &Migrator{db: database.DB, files: files}
+}
+
+// hasTable reports whether the migrator's database has a table
+func hasTable(t *testing.T, m *Migrator, table string) bool {
+	t.Helper()
+	var count int
+	if err := m.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return count == 1
+}
+
+func TestRunMigrationsRejectsModified(t *testing.T) {
+	files := migrationFS(map[string]string{
+		"0001_create_one": "CREATE TABLE one (id INTEGER);",
+		"0002_create_two": "CREATE TABLE two (id INTEGER);",
+	})
+	m := testMigrator(t, files)
+	if err := m.RunMigrations(); err != nil {
+		t.Fatal(err)
+	}
+
+	files["0001_create_one.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE one (id INTEGER, name TEXT);")}
+	if err := m.RunMigrations(); err == nil || !strings.Contains(err.Error(), "migration 1 (create_one) was modified") {
+		t.Errorf("err = %v, want migration 1 modified", err)
+	}
+	status, err := m.GetMigrationStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status[0].Modified || status[1].Modified {
+		t.Errorf("status %+v", status)
+	}
+}
+
+func TestDirtyMigration(t *testing.T) {
+	files := migrationFS(map[string]string{
+		"0001_create_one": "CREATE TABLE one (id INTEGER);",
+		"0002_create_two": "CREATE TABLE two (id INTEGER); INSERT INTO missing VALUES (1);",
+	})
+	m := testMigrator(t, files)
+	if err := m.RunMigrations(); err == nil {
+		t.Fatal("failing migration ran")
+	}
+	if hasTable(t, m, "two") {
+		t.Error("failed migration was not rolled back")
+	}
+
+	status, err := m.GetMigrationStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status[0].Applied || status[1].Applied || !status[1].Dirty || !strings.Contains(status[1].Error, "missing") {
+		t.Errorf("status %+v", status)
+	}
+
+	// Repairing the migration is not enough while it is marked dirty
+	files["0002_create_two.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE two (id INTEGER);")}
+	if err := m.RunMigrations(); err == nil || !strings.Contains(err.Error(), "migration 2 (create_two) is dirty") {
+		t.Fatalf("err = %v, want migration 2 dirty", err)
+	}
+
+	cleared, err := m.ClearDirty()
+	if err != nil || cleared != 1 {
+		t.Fatalf("cleared %d: %v", cleared, err)
+	}
+	if err := m.RunMigrations(); err != nil {
+		t.Fatal(err)
+	}
+	if !hasTable(t, m, "two") {
+		t.Error("migration 2 did not run again")
+	}
+	if cleared, err := m.ClearDirty(); err != nil || cleared != 0 {
+		t.Errorf("cleared %d: %v", cleared, err)
+	}
+}
+
+func TestRollbackMigration(t *testing.T) {
+	m := testMigrator(t, migrationFS(map[string]string{
+		"0001_create_one":   "CREATE TABLE one (id INTEGER);",
+		"0002_create_two":   "CREATE TABLE two (id INTEGER);",
+		"0003_create_three": "CREATE TABLE three (id INTEGER);",
+	}))
+	if err := m.RunMigrations(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.RollbackMigration(1); err != nil {
+		t.Fatal(err)
+	}
+	if !hasTable(t, m, "one") || hasTable(t, m, "two") || hasTable(t, m, "three") {
+		t.Error("rollback to 1 left the wrong tables")
+	}
+	if version, err := m.getCurrentVersion(); err != nil || version != 1 {
+		t.Errorf("version %d: %v", version, err)
+	}
+	if err := m.RollbackMigration(1); err == nil {
+		t.Error("rolled back to the current version")
+	}
+
+	if err := m.RunMigrations(); err != nil {
+		t.Fatal(err)
+	}
+	if !hasTable(t, m, "three") {
+		t.Error("migrations did not run again after the rollback")
+	}
+}
+
+func TestRunMigrationsRejectsSkipped(t *testing.T) {
+	files := migrationFS(map[string]string{
+		"0001_create_one":   "CREATE TABLE one (id INTEGER);",
+		"0003_create_three": "CREATE TABLE three (id INTEGER);",
+	})
+	m := testMigrator(t, files)
+	if err := m.RunMigrations(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A migration numbered below one already applied, as from a merged branch
+	for name, file := range migrationFS(map[string]string{"0002_create_two": "CREATE TABLE two (id INTEGER);"}) {
+		files[name] = file
+	}
+	if err := m.RunMigrations(); err == nil || !strings.Contains(err.Error(), "migration 2 (create_two) was never applied") {
+		t.Fatalf("err = %v, want migration 2 never applied", err)
+	}
+	if hasTable(t, m, "two") {
+		t.Error("skipped migration ran")
+	}
+
+	// Rolling back to before it does not undo the migration that never ran
+	if err := m.RollbackMigration(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RunMigrations(); err != nil {
+		t.Fatal(err)
+	}
+	if !hasTable(t, m, "two") || !hasTable(t, m, "three") {
+		t.Error("migrations did not run in order after the rollback")
+	}
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"0001_create_one.up.sql", "0001_create_one.down.sql", "0007_add_index.up.sql", "0007_add_index.down.sql", "README.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1;"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	up, down, err := CreateMigration(dir, "Add Widgets table!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(up) != "0008_add_widgets_table.up.sql" || filepath.Base(down) != "0008_add_widgets_table.down.sql" {
+		t.Errorf("created %s and %s", up, down)
+	}
+
+	up, _, err = CreateMigration(dir, "second")
+	if err != nil || filepath.Base(up) != "0009_second.up.sql" {
+		t.Errorf("created %s: %v", up, err)
+	}
+
+	migrations, err := LoadMigrations(os.DirFS(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 4 || migrations[2].Name != "add_widgets_table" || migrations[3].Version != 9 {
+		t.Errorf("loaded %+v", migrations)
+	}
+
+	if _, _, err := CreateMigration(dir, "!!!"); err == nil {
+		t.Error("created a migration without a name")
+	}
+}
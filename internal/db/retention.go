@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Logger interface for background database jobs
+type Logger interface {
+	Info(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+	Debug(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+}
+
+// RetentionJob periodically removes data that is older than the retention policy
+type RetentionJob struct {
+	db            *Database
+	retentionDays int
+	interval      time.Duration
+	logger        Logger
+
+	mu      sync.Mutex
+	lastRun time.Time
+	lastErr error
+}
+
+// RetentionResult describes a single retention run
+type RetentionResult struct {
+	RetentionDays int       `json:"retention_days"`
+	LogsRemoved   int64     `json:"logs_removed"`
+	RanAt         time.Time `json:"ran_at"`
+}
+
+// NewRetentionJob creates a retention job. retentionDays is used when the
+// system_config table has no memory_retention_days entry.
+func NewRetentionJob(db *Database, retentionDays int, interval time.Duration, logger Logger) *RetentionJob {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	return &RetentionJob{
+		db:            db,
+		retentionDays: retentionDays,
+		interval:      interval,
+		logger:        logger,
+	}
+}
+
+// Start runs the job immediately and then on every interval until ctx is cancelled
+func (j *RetentionJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := j.RunOnce(); err != nil {
+				j.logger.Error("Retention job failed", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce applies the retention policy a single time
+func (j *RetentionJob) RunOnce() (*RetentionResult, error) {
+	result := &RetentionResult{
+		RetentionDays: j.effectiveRetentionDays(),
+		RanAt:         time.Now(),
+	}
+
+	removed, err := j.db.CleanupOldLogs(result.RetentionDays)
+
+	j.mu.Lock()
+	j.lastRun = result.RanAt
+	j.lastErr = err
+	j.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	result.LogsRemoved = removed
+	if removed > 0 {
+		j.logger.Info("Removed old LLM interaction logs",
+			"count", removed,
+			"retention_days", result.RetentionDays,
+		)
+	}
+
+	return result, nil
+}
+
+// LastRun returns when the job last ran and the error it returned, if any
+func (j *RetentionJob) LastRun() (time.Time, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.lastRun, j.lastErr
+}
+
+// effectiveRetentionDays reads memory_retention_days from system_config,
+// falling back to the configured value
+func (j *RetentionJob) effectiveRetentionDays() int {
+	value, err := j.db.GetSystemConfig("memory_retention_days")
+	if err != nil {
+		return j.retentionDays
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days <= 0 {
+		j.logger.Warn("Ignoring invalid memory_retention_days", "value", value)
+		return j.retentionDays
+	}
+
+	return days
+}
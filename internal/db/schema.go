@@ -0,0 +1,309 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// column describes a table column as reported by PRAGMA table_info
+type column struct {
+	Type       string
+	NotNull    bool
+	Default    string
+	PrimaryKey int
+}
+
+// String describes the column the way it would be declared
+func (c column) String() string {
+	parts := []string{c.Type}
+	if c.NotNull {
+		parts = append(parts, "NOT NULL")
+	}
+	if c.Default != "" {
+		parts = append(parts, "DEFAULT "+c.Default)
+	}
+	if c.PrimaryKey > 0 {
+		parts = append(parts, "PRIMARY KEY")
+	}
+	return strings.Join(parts, " ")
+}
+
+// index describes an index and the columns it covers
+type index struct {
+	Table   string
+	Columns []string
+	Unique  bool
+}
+
+// String describes the index the way it would be declared
+func (i index) String() string {
+	unique := ""
+	if i.Unique {
+		unique = "UNIQUE "
+	}
+	return fmt.Sprintf("%sON %s(%s)", unique, i.Table, strings.Join(i.Columns, ", "))
+}
+
+// schema is the tables, columns and indexes of a database
+type schema struct {
+	Tables  map[string]map[string]column
+	Indexes map[string]index
+}
+
+// SchemaDifferences compares the live schema with the one the applied
+// migrations should have produced, built by running them on an empty
+// in-memory database. It returns one line per difference.
+func (m *Migrator) SchemaDifferences() ([]string, error) {
+	if err := m.createMigrationsTable(); err != nil {
+		return nil, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	version, err := m.getCurrentVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current version: %w", err)
+	}
+
+	expected, err := m.expectedSchema(version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build expected schema: %w", err)
+	}
+	actual, err := readSchema(m.db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	return compareSchemas(expected, actual), nil
+}
+
+// expectedSchema runs the migrations up to version on an empty in-memory
+// database and reads the schema they produce
+func (m *Migrator) expectedSchema(version int) (*schema, error) {
+	scratch, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	defer scratch.Close()
+	// Every connection to :memory: is a separate database
+	scratch.SetMaxOpenConns(1)
+
+	migrations, err := m.GetMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	migrator := &Migrator{db: scratch, files: m.files}
+	if err := migrator.createMigrationsTable(); err != nil {
+		return nil, err
+	}
+	for _, migration := range migrations {
+		if migration.Version > version {
+			break
+		}
+		if err := migrator.runMigration(migration); err != nil {
+			return nil, fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	return readSchema(scratch)
+}
+
+// ValidateSchema validates that the database schema matches the one its
+// applied migrations should have produced
+func (m *Migrator) ValidateSchema() error {
+	differences, err := m.SchemaDifferences()
+	if err != nil {
+		return err
+	}
+	if len(differences) > 0 {
+		return fmt.Errorf("schema does not match its migrations:\n  %s", strings.Join(differences, "\n  "))
+	}
+	return nil
+}
+
+// readSchema reads the tables, columns and indexes of a database
+func readSchema(db *sql.DB) (*schema, error) {
+	s := &schema{
+		Tables:  make(map[string]map[string]column),
+		Indexes: make(map[string]index),
+	}
+
+	tables, err := queryNames(db, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		return nil, err
+	}
+	// Virtual tables, such as the full-text search index, are created outside
+	// the migrations along with the shadow tables that store them
+	virtual, err := queryNames(db, "SELECT name FROM sqlite_master WHERE type = 'table' AND sql LIKE 'CREATE VIRTUAL TABLE%'")
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range tables {
+		if isVirtual(table, virtual) {
+			continue
+		}
+		columns, err := tableColumns(db, table)
+		if err != nil {
+			return nil, err
+		}
+		s.Tables[table] = columns
+
+		indexes, err := tableIndexes(db, table)
+		if err != nil {
+			return nil, err
+		}
+		for name, idx := range indexes {
+			s.Indexes[name] = idx
+		}
+	}
+
+	return s, nil
+}
+
+// isVirtual reports whether table is one of the virtual tables or a shadow
+// table of one
+func isVirtual(table string, virtual []string) bool {
+	for _, name := range virtual {
+		if table == name || strings.HasPrefix(table, name+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+// queryNames runs a query that returns a single text column
+func queryNames(db *sql.DB, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// tableColumns reads the columns of a table
+func tableColumns(db *sql.DB, table string) (map[string]column, error) {
+	rows, err := db.Query("SELECT name, type, \"notnull\", COALESCE(dflt_value, ''), pk FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]column)
+	for rows.Next() {
+		var name string
+		var c column
+		if err := rows.Scan(&name, &c.Type, &c.NotNull, &c.Default, &c.PrimaryKey); err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+		}
+		c.Type = strings.ToUpper(c.Type)
+		columns[name] = c
+	}
+	return columns, rows.Err()
+}
+
+// tableIndexes reads the named indexes of a table, skipping the ones SQLite
+// creates for primary keys and unique constraints
+func tableIndexes(db *sql.DB, table string) (map[string]index, error) {
+	rows, err := db.Query("SELECT name, \"unique\" FROM pragma_index_list(?) WHERE origin = 'c'", table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexes of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	indexes := make(map[string]index)
+	for rows.Next() {
+		var name string
+		idx := index{Table: table}
+		if err := rows.Scan(&name, &idx.Unique); err != nil {
+			return nil, fmt.Errorf("failed to read indexes of %s: %w", table, err)
+		}
+		indexes[name] = idx
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for name, idx := range indexes {
+		columns, err := queryNames(db, "SELECT COALESCE(name, '<expression>') FROM pragma_index_info(?) ORDER BY seqno", name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read columns of index %s: %w", name, err)
+		}
+		idx.Columns = columns
+		indexes[name] = idx
+	}
+	return indexes, nil
+}
+
+// compareSchemas lists how actual differs from expected, in a stable order
+func compareSchemas(expected, actual *schema) []string {
+	var differences []string
+
+	for _, table := range sortedKeys(expected.Tables) {
+		actualColumns, ok := actual.Tables[table]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("missing table %s", table))
+			continue
+		}
+		expectedColumns := expected.Tables[table]
+		for _, name := range sortedKeys(expectedColumns) {
+			want := expectedColumns[name]
+			got, ok := actualColumns[name]
+			switch {
+			case !ok:
+				differences = append(differences, fmt.Sprintf("table %s: missing column %s", table, name))
+			case got != want:
+				differences = append(differences, fmt.Sprintf("table %s: column %s is %s, expected %s", table, name, got, want))
+			}
+		}
+		for _, name := range sortedKeys(actualColumns) {
+			if _, ok := expectedColumns[name]; !ok {
+				differences = append(differences, fmt.Sprintf("table %s: unexpected column %s", table, name))
+			}
+		}
+	}
+	for _, table := range sortedKeys(actual.Tables) {
+		if _, ok := expected.Tables[table]; !ok {
+			differences = append(differences, fmt.Sprintf("unexpected table %s", table))
+		}
+	}
+
+	for _, name := range sortedKeys(expected.Indexes) {
+		want := expected.Indexes[name]
+		got, ok := actual.Indexes[name]
+		switch {
+		case !ok:
+			differences = append(differences, fmt.Sprintf("missing index %s %s", name, want))
+		case got.String() != want.String():
+			differences = append(differences, fmt.Sprintf("index %s is %s, expected %s", name, got, want))
+		}
+	}
+	for _, name := range sortedKeys(actual.Indexes) {
+		if _, ok := expected.Indexes[name]; !ok {
+			differences = append(differences, fmt.Sprintf("unexpected index %s %s", name, actual.Indexes[name]))
+		}
+	}
+
+	return differences
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSchema(t *testing.T) {
+	database := testDatabase(t)
+	if err := database.ValidateSchema(); err != nil {
+		t.Fatalf("freshly migrated database: %v", err)
+	}
+}
+
+func TestSchemaDifferences(t *testing.T) {
+	m := testMigrator(t, migrationFS(map[string]string{
+		"0001_create_widgets": `
+			CREATE TABLE widgets (id TEXT PRIMARY KEY, name TEXT NOT NULL, size INTEGER DEFAULT 0);
+			CREATE INDEX idx_widgets_name ON widgets(name);
+		`,
+		"0002_create_gadgets": "CREATE TABLE gadgets (id TEXT PRIMARY KEY);",
+	}))
+	if err := m.RunMigrations(); err != nil {
+		t.Fatal(err)
+	}
+	if differences, err := m.SchemaDifferences(); err != nil || len(differences) != 0 {
+		t.Fatalf("differences %q: %v", differences, err)
+	}
+
+	for _, statement := range []string{
+		"ALTER TABLE widgets ADD COLUMN color TEXT",
+		"DROP INDEX idx_widgets_name",
+		"CREATE INDEX idx_widgets_size ON widgets(size)",
+		"DROP TABLE gadgets",
+		"CREATE TABLE scratch (id INTEGER)",
+	} {
+		if _, err := m.db.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	differences, err := m.SchemaDifferences()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"missing table gadgets",
+		"table widgets: unexpected column color",
+		"unexpected table scratch",
+		"missing index idx_widgets_name ON widgets(name)",
+		"unexpected index idx_widgets_size ON widgets(size)",
+	}
+	if strings.Join(differences, "\n") != strings.Join(want, "\n") {
+		t.Errorf("differences:\n%s\nwant:\n%s", strings.Join(differences, "\n"), strings.Join(want, "\n"))
+	}
+
+	err = m.ValidateSchema()
+	if err == nil || !strings.Contains(err.Error(), "unexpected table scratch") {
+		t.Errorf("err = %v", err)
+	}
+}
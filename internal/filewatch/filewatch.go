@@ -0,0 +1,139 @@
+package filewatch
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultInterval is how often files are checked for changes
+const DefaultInterval = 2 * time.Second
+
+// Logger interface for file watching
+type Logger interface {
+	Debug(msg string, args ...interface{})
+}
+
+// fileState is what a file looked like when it was last checked
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watcher polls files and directories for changes, comparing their size and
+// modification time. Polling works the same on every platform and file system.
+type Watcher struct {
+	interval time.Duration
+	logger   Logger
+
+	mu    sync.Mutex
+	paths []string
+	state map[string]fileState
+}
+
+// NewWatcher creates a watcher that checks for changes every interval
+func NewWatcher(interval time.Duration, logger Logger) *Watcher {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Watcher{
+		interval: interval,
+		logger:   logger,
+		state:    make(map[string]fileState),
+	}
+}
+
+// Add watches files, or every file under directories. Paths that do not exist
+// yet are reported when they are created.
+func (w *Watcher) Add(paths ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, path := range paths {
+		w.paths = append(w.paths, path)
+		for file, state := range scan(path) {
+			w.state[file] = state
+		}
+	}
+}
+
+// Start checks for changes every interval until ctx is cancelled, calling
+// onChange with the files that were created, modified or removed since the
+// last check
+func (w *Watcher) Start(ctx context.Context, onChange func(changed []string)) {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if changed := w.Check(); len(changed) > 0 {
+				w.logger.Debug("Watched files changed", "files", changed)
+				onChange(changed)
+			}
+		}
+	}()
+}
+
+// Check returns the files that were created, modified or removed since the
+// last check, in order
+func (w *Watcher) Check() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	current := make(map[string]fileState)
+	for _, path := range w.paths {
+		for file, state := range scan(path) {
+			current[file] = state
+		}
+	}
+
+	var changed []string
+	for file, state := range current {
+		if previous, ok := w.state[file]; !ok || previous != state {
+			changed = append(changed, file)
+		}
+	}
+	for file := range w.state {
+		if _, ok := current[file]; !ok {
+			changed = append(changed, file)
+		}
+	}
+	sort.Strings(changed)
+
+	w.state = current
+	return changed
+}
+
+// scan returns the state of a file, or of every file under a directory
+func scan(path string) map[string]fileState {
+	files := make(map[string]fileState)
+	info, err := os.Stat(path)
+	if err != nil {
+		return files
+	}
+	if !info.IsDir() {
+		files[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return files
+	}
+
+	filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			files[file] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+		return nil
+	})
+	return files
+}
@@ -0,0 +1,342 @@
+package catalog
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"personal-ai-board/internal/llm/types"
+)
+
+// builtin is the catalog shipped with the application
+//
+//go:embed models.json
+var builtin []byte
+
+// Fallback is the name of a provider's entry for models the catalog does not
+// list
+const Fallback = "*"
+
+// Capabilities a model can have besides chat, completion and system messages
+const (
+	CapabilityStreaming = "streaming"
+	CapabilityTools     = "tools"
+	CapabilityJSONMode  = "json_mode"
+	CapabilityVision    = "vision"
+)
+
+// Model describes what a model can do and what it costs
+type Model struct {
+	Provider      string   `json:"provider"`
+	Name          string   `json:"name"`
+	Aliases       []string `json:"aliases,omitempty"` // Other names of the same model, such as dated snapshots
+	ContextWindow int      `json:"context_window"`    // Tokens of prompt and response together
+	MaxOutput     int      `json:"max_output"`        // Tokens the model can generate in one response
+	InputPer1K    float64  `json:"input_per_1k"`      // USD per 1000 prompt tokens
+	OutputPer1K   float64  `json:"output_per_1k"`     // USD per 1000 generated tokens
+	Streaming     bool     `json:"streaming"`
+	Tools         bool     `json:"tools"`
+	JSONMode      bool     `json:"json_mode"`
+	Vision        bool     `json:"vision"`
+}
+
+// Capabilities lists what the model supports
+func (m Model) Capabilities() []string {
+	return append([]string{"chat", "completion", "system_messages"}, m.Features()...)
+}
+
+// Features lists the optional capabilities the model supports
+func (m Model) Features() []string {
+	var features []string
+	if m.Streaming {
+		features = append(features, CapabilityStreaming)
+	}
+	if m.Tools {
+		features = append(features, CapabilityTools)
+	}
+	if m.JSONMode {
+		features = append(features, CapabilityJSONMode)
+	}
+	if m.Vision {
+		features = append(features, CapabilityVision)
+	}
+	return features
+}
+
+// Cost returns the price in USD of a request with the given token counts
+func (m Model) Cost(promptTokens, completionTokens int) float64 {
+	return float64(promptTokens)/1000*m.InputPer1K + float64(completionTokens)/1000*m.OutputPer1K
+}
+
+// Info describes the model as providers report it
+func (m Model) Info() types.ModelInfo {
+	return types.ModelInfo{
+		Name:            m.Name,
+		Provider:        m.Provider,
+		MaxTokens:       m.MaxOutput,
+		ContextSize:     m.ContextWindow,
+		CostPer1K:       (m.InputPer1K + m.OutputPer1K) / 2,
+		InputCostPer1K:  m.InputPer1K,
+		OutputCostPer1K: m.OutputPer1K,
+		Capabilities:    m.Capabilities(),
+	}
+}
+
+// file is the layout of the built-in catalog and of override files
+type file struct {
+	Models []json.RawMessage `json:"models"`
+}
+
+// Catalog lists the models of every provider. It is safe for concurrent use.
+type Catalog struct {
+	mu     sync.RWMutex
+	models []Model
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultCatalog = mustBuiltin()
+)
+
+// Default returns the catalog providers look their models up in
+func Default() *Catalog {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultCatalog
+}
+
+// SetDefault replaces the catalog providers look their models up in
+func SetDefault(c *Catalog) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultCatalog = c
+}
+
+// mustBuiltin parses the built-in catalog, which the package's tests check
+func mustBuiltin() *Catalog {
+	c := &Catalog{}
+	if err := c.merge(builtin); err != nil {
+		panic(fmt.Sprintf("invalid built-in model catalog: %v", err))
+	}
+	return c
+}
+
+// Load returns the built-in catalog with the entries of the override file at
+// path applied on top. An entry overrides only the fields it sets of the
+// model with the same provider and name, and adds the model if there is none.
+// A missing override file is not an error.
+func Load(path string) (*Catalog, error) {
+	c := mustBuiltin()
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model catalog: %w", err)
+	}
+	if err := c.merge(data); err != nil {
+		return nil, fmt.Errorf("invalid model catalog %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// merge applies the entries of a catalog file
+func (c *Catalog) merge(data []byte) error {
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+
+	for i, raw := range f.Models {
+		var key struct {
+			Provider string `json:"provider"`
+			Name     string `json:"name"`
+		}
+		if err := json.Unmarshal(raw, &key); err != nil {
+			return fmt.Errorf("model %d: %w", i+1, err)
+		}
+		if key.Provider == "" || key.Name == "" {
+			return fmt.Errorf("model %d: provider and name are required", i+1)
+		}
+
+		index := c.index(key.Provider, key.Name)
+		var model Model
+		if index >= 0 {
+			model = c.models[index]
+		}
+		if err := json.Unmarshal(raw, &model); err != nil {
+			return fmt.Errorf("model %s/%s: %w", key.Provider, key.Name, err)
+		}
+		if model.ContextWindow < 0 || model.MaxOutput < 0 || model.InputPer1K < 0 || model.OutputPer1K < 0 {
+			return fmt.Errorf("model %s/%s: sizes and prices cannot be negative", key.Provider, key.Name)
+		}
+
+		if index >= 0 {
+			c.models[index] = model
+		} else {
+			c.models = append(c.models, model)
+		}
+	}
+	return nil
+}
+
+// index returns the position of the entry named exactly name, or -1
+func (c *Catalog) index(provider, name string) int {
+	for i, model := range c.models {
+		if model.Provider == provider && model.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Lookup returns what the catalog knows about a provider's model: the entry
+// with that name or alias, or else the entry whose name is the longest prefix
+// of it, so that gpt-4o-2024-08-06 is described by gpt-4o. It reports false
+// when no entry matches and the provider's fallback entry was used instead.
+// The returned model always has the name asked for.
+func (c *Catalog) Lookup(provider, name string) (Model, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lookup(provider, name)
+}
+
+// lookup is Lookup for callers holding the lock
+func (c *Catalog) lookup(provider, name string) (Model, bool) {
+	var fallback, prefix Model
+	for _, model := range c.models {
+		if model.Provider != provider {
+			continue
+		}
+		switch {
+		case model.Name == Fallback:
+			fallback = model
+		case model.Name == name || contains(model.Aliases, name):
+			model.Name = name
+			return model, true
+		case strings.HasPrefix(name, model.Name+"-") && len(model.Name) > len(prefix.Name):
+			prefix = model
+		}
+	}
+
+	if prefix.Name != "" {
+		prefix.Name = name
+		return prefix, true
+	}
+	fallback.Provider = provider
+	fallback.Name = name
+	return fallback, false
+}
+
+// Models returns a provider's models, or every model if provider is empty,
+// ordered by provider and name. Fallback entries are left out.
+func (c *Catalog) Models(provider string) []Model {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	models := make([]Model, 0, len(c.models))
+	for _, model := range c.models {
+		if model.Name != Fallback && (provider == "" || model.Provider == provider) {
+			models = append(models, model)
+		}
+	}
+	sort.Slice(models, func(i, j int) bool {
+		if models[i].Provider != models[j].Provider {
+			return models[i].Provider < models[j].Provider
+		}
+		return models[i].Name < models[j].Name
+	})
+	return models
+}
+
+// Refresh adds the models a provider reports as available that the catalog
+// only knows through a prefix of their name, copying what it knows. It
+// returns the models it added, and the names it has no entry for at all,
+// which are left for the override file to describe.
+func (c *Catalog) Refresh(provider string, names []string) ([]Model, []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var added []Model
+	var unknown []string
+	for _, name := range names {
+		if c.index(provider, name) >= 0 {
+			continue
+		}
+
+		model, known := c.lookup(provider, name)
+		if !known {
+			unknown = append(unknown, name)
+			continue
+		}
+		if contains(model.Aliases, name) {
+			continue
+		}
+		model.Aliases = nil
+
+		c.models = append(c.models, model)
+		added = append(added, model)
+	}
+	sort.Strings(unknown)
+	return added, unknown
+}
+
+// AppendFile adds models to the override file at path, creating it if needed.
+// Entries already in the file for the same models are replaced.
+func AppendFile(path string, models []Model) error {
+	var f file
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &f); err != nil {
+			return fmt.Errorf("invalid model catalog %s: %w", path, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("failed to read model catalog: %w", err)
+	}
+
+	for _, model := range models {
+		raw, err := json.Marshal(model)
+		if err != nil {
+			return err
+		}
+		replaced := false
+		for i, existing := range f.Models {
+			var key Model
+			if json.Unmarshal(existing, &key) == nil && key.Provider == model.Provider && key.Name == model.Name {
+				f.Models[i], replaced = raw, true
+			}
+		}
+		if !replaced {
+			f.Models = append(f.Models, raw)
+		}
+	}
+
+	data, err = json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write model catalog: %w", err)
+	}
+	return nil
+}
+
+// contains reports whether values includes value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package catalog
+
+import "testing"
+
+func TestBuiltinFallbacks(t *testing.T) {
+	c := &Catalog{}
+	if err := c.merge(builtin); err != nil {
+		t.Fatalf("invalid built-in model catalog: %v", err)
+	}
+
+	// Every provider that looks its models up, and every provider listed
+	providers := map[string]bool{"openai": true, "anthropic": true, "google": true, "mock": true}
+	for _, model := range c.models {
+		providers[model.Provider] = true
+	}
+
+	for provider := range providers {
+		fallbacks := 0
+		for _, model := range c.models {
+			if model.Provider != provider || model.Name != Fallback {
+				continue
+			}
+			fallbacks++
+			if model.ContextWindow <= 0 || model.MaxOutput <= 0 || model.MaxOutput > model.ContextWindow {
+				t.Errorf("%s fallback: context window %d, max output %d", provider, model.ContextWindow, model.MaxOutput)
+			}
+		}
+		if fallbacks != 1 {
+			t.Errorf("%s has %d %q entries, want 1", provider, fallbacks, Fallback)
+		}
+
+		model, known := c.Lookup(provider, "not-a-real-model")
+		if known || model.Provider != provider || model.Name != "not-a-real-model" || model.ContextWindow <= 0 {
+			t.Errorf("%s: unknown model looked up as %+v, known %v", provider, model, known)
+		}
+	}
+}
+
+func TestBuiltinEntries(t *testing.T) {
+	c := mustBuiltin()
+	seen := make(map[string]bool)
+	for _, model := range c.models {
+		for _, name := range append([]string{model.Name}, model.Aliases...) {
+			key := model.Provider + "/" + name
+			if seen[key] {
+				t.Errorf("%s is listed twice", key)
+			}
+			seen[key] = true
+		}
+		if model.Name != Fallback && model.ContextWindow <= 0 {
+			t.Errorf("%s/%s has no context window", model.Provider, model.Name)
+		}
+	}
+}
@@ -4,22 +4,36 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	"personal-ai-board/internal/llm/catalog"
 	"personal-ai-board/internal/llm/types"
+	"personal-ai-board/internal/tokens"
 )
 
-// Manager manages multiple LLM providers
+// Manager manages multiple LLM providers. Providers can be replaced while
+// requests are running; those requests finish with the provider they started
+// with.
 type Manager struct {
+	mu              sync.RWMutex
 	providers       map[string]types.Provider
+	configs         map[string]types.Config // Configuration each provider was created from
 	defaultProvider string
 	logger          types.Logger
 }
 
+// CatalogRefresher is implemented by providers that can list the models
+// available to them and add them to the model catalog
+type CatalogRefresher interface {
+	RefreshCatalog(ctx context.Context, c *catalog.Catalog) ([]catalog.Model, []string, error)
+}
+
 // NewManager creates a new LLM manager
 func NewManager(logger types.Logger) *Manager {
 	return &Manager{
 		providers: make(map[string]types.Provider),
+		configs:   make(map[string]types.Config),
 		logger:    logger,
 	}
 }
@@ -30,6 +44,9 @@ func (m *Manager) RegisterProvider(name string, provider types.Provider) error {
 		return fmt.Errorf("provider validation failed: %w", err)
 	}
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.providers[name] = provider
 
 	// Set as default if it's the first provider
@@ -43,6 +60,9 @@ func (m *Manager) RegisterProvider(name string, provider types.Provider) error {
 
 // GetProvider returns a provider by name
 func (m *Manager) GetProvider(name string) (types.Provider, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	if name == "" {
 		name = m.defaultProvider
 	}
@@ -57,6 +77,9 @@ func (m *Manager) GetProvider(name string) (types.Provider, error) {
 
 // SetDefaultProvider sets the default provider
 func (m *Manager) SetDefaultProvider(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if _, exists := m.providers[name]; !exists {
 		return fmt.Errorf("provider not found: %s", name)
 	}
@@ -66,8 +89,25 @@ func (m *Manager) SetDefaultProvider(name string) error {
 	return nil
 }
 
+// RemoveProvider unregisters a provider. If it was the default, no provider
+// is the default until another is set.
+func (m *Manager) RemoveProvider(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.providers, name)
+	delete(m.configs, name)
+	if m.defaultProvider == name {
+		m.defaultProvider = ""
+	}
+	m.logger.Info("LLM provider removed", "provider", name)
+}
+
 // ListProviders returns all registered provider names
 func (m *Manager) ListProviders() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	names := make([]string, 0, len(m.providers))
 	for name := range m.providers {
 		names = append(names, name)
@@ -116,6 +156,39 @@ func (m *Manager) GenerateResponse(ctx context.Context, providerName string, req
 	return resp, nil
 }
 
+// GenerateResponseStream generates a response, passing content to onChunk as it
+// arrives. Providers that cannot stream deliver the whole response in one chunk.
+func (m *Manager) GenerateResponseStream(ctx context.Context, providerName string, req types.Request, onChunk func(string)) (*types.Response, error) {
+	provider, err := m.GetProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	streaming, ok := provider.(types.StreamingProvider)
+	if !ok {
+		resp, err := m.GenerateResponse(ctx, providerName, req)
+		if err != nil {
+			return nil, err
+		}
+		onChunk(resp.Content)
+		return resp, nil
+	}
+
+	startTime := time.Now()
+	resp, err := streaming.GenerateResponseStream(ctx, req, onChunk)
+	if err != nil {
+		m.logger.Error("LLM streaming failed",
+			"provider", providerName,
+			"error", err,
+			"duration", time.Since(startTime),
+		)
+		return nil, fmt.Errorf("generation failed: %w", err)
+	}
+	resp.Duration = time.Since(startTime)
+
+	return resp, nil
+}
+
 // ProviderFactory creates providers based on configuration
 type ProviderFactory struct {
 	logger types.Logger
@@ -137,6 +210,8 @@ func (f *ProviderFactory) CreateProvider(config types.Config) (types.Provider, e
 		return NewGoogleProvider(config, f.logger)
 	case "ollama":
 		return NewOllamaProvider(config, f.logger)
+	case "mock":
+		return NewMockProvider(config, f.logger)
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", config.Provider)
 	}
@@ -145,11 +220,10 @@ func (f *ProviderFactory) CreateProvider(config types.Config) (types.Provider, e
 // TokenCounter provides token counting utilities
 type TokenCounter struct{}
 
-// EstimateTokens provides a rough estimate of token count
-// This is a simplified implementation - in production you'd use tiktoken or similar
+// EstimateTokens estimates the token count of text as the providers' BPE
+// tokenizers split it
 func (tc *TokenCounter) EstimateTokens(text string) int {
-	// Very rough approximation: ~4 characters per token for English
-	return len(text) / 4
+	return tokens.Estimate(text)
 }
 
 // EstimateRequestTokens estimates the total tokens for a request
@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"context"
+
+	"personal-ai-board/internal/llm/catalog"
+	"personal-ai-board/internal/llm/types"
+	"personal-ai-board/internal/persona"
+	"personal-ai-board/internal/tokens"
+)
+
+// PersonaProvider adapts a Manager provider to the persona.LLMProvider interface
+type PersonaProvider struct {
+	manager      *Manager
+	providerName string
+	model        string
+}
+
+// NewPersonaProvider creates a persona LLM provider backed by the named manager
+// provider. An empty provider name uses the manager's default provider.
+func NewPersonaProvider(manager *Manager, providerName, model string) *PersonaProvider {
+	return &PersonaProvider{
+		manager:      manager,
+		providerName: providerName,
+		model:        model,
+	}
+}
+
+// GenerateResponse implements persona.LLMProvider
+func (p *PersonaProvider) GenerateResponse(ctx context.Context, req persona.LLMRequest) (*persona.LLMResponse, error) {
+	resp, err := p.manager.GenerateResponse(ctx, p.providerName, p.request(req))
+	if err != nil {
+		return nil, err
+	}
+	return toPersonaResponse(resp), nil
+}
+
+// GenerateResponseStream implements persona.StreamingLLMProvider
+func (p *PersonaProvider) GenerateResponseStream(ctx context.Context, req persona.LLMRequest, onChunk func(string)) (*persona.LLMResponse, error) {
+	resp, err := p.manager.GenerateResponseStream(ctx, p.providerName, p.request(req), onChunk)
+	if err != nil {
+		return nil, err
+	}
+	return toPersonaResponse(resp), nil
+}
+
+// request converts a persona request to a provider request
+func (p *PersonaProvider) request(req persona.LLMRequest) types.Request {
+	return types.Request{
+		Prompt:      req.Prompt,
+		SystemMsg:   req.SystemMsg,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+		Context:     req.Context,
+		Model:       p.model,
+	}
+}
+
+// toPersonaResponse converts a provider response to a persona response
+func toPersonaResponse(resp *types.Response) *persona.LLMResponse {
+	converted := &persona.LLMResponse{
+		Content:      resp.Content,
+		TokensUsed:   resp.TokensUsed,
+		Model:        resp.Model,
+		Duration:     resp.Duration,
+		FinishReason: resp.FinishReason,
+	}
+	if resp.Usage != nil {
+		converted.PromptTokens = resp.Usage.PromptTokens
+		converted.CompletionTokens = resp.Usage.CompletionTokens
+	}
+	return converted
+}
+
+// GetModelInfo implements persona.LLMProvider
+func (p *PersonaProvider) GetModelInfo() persona.ModelInfo {
+	provider, err := p.manager.GetProvider(p.providerName)
+	if err != nil {
+		return persona.ModelInfo{Name: p.model, Provider: p.providerName}
+	}
+
+	info := provider.GetModelInfo()
+	if p.model != "" && p.model != info.Name {
+		// The provider describes its own model; look up the one asked for
+		model, _ := catalog.Default().Lookup(info.Provider, p.model)
+		info = model.Info()
+	}
+
+	return persona.ModelInfo{
+		Name:            info.Name,
+		Provider:        info.Provider,
+		MaxTokens:       info.MaxTokens,
+		ContextSize:     info.ContextSize,
+		CostPer1K:       info.CostPer1K,
+		InputCostPer1K:  info.InputCostPer1K,
+		OutputCostPer1K: info.OutputCostPer1K,
+	}
+}
+
+// EstimateTokens implements persona.TokenEstimator, counting with the
+// provider's tokenizer when it has one
+func (p *PersonaProvider) EstimateTokens(text string) int {
+	provider, err := p.manager.GetProvider(p.providerName)
+	if err == nil {
+		if estimator, ok := provider.(types.TokenEstimator); ok {
+			return estimator.EstimateTokens(text)
+		}
+	}
+	return tokens.Estimate(text)
+}
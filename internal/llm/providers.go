@@ -21,6 +21,12 @@ func NewGoogleProvider(config types.Config, logger types.Logger) (types.Provider
 	return providers.NewGoogleProvider(config, logger)
 }
 
+// NewMockProvider creates a mock provider that answers without a network
+// connection
+func NewMockProvider(config types.Config, logger types.Logger) (types.Provider, error) {
+	return providers.NewMockProvider(config, logger)
+}
+
 // NewOllamaProvider creates a new Ollama provider (placeholder)
 func NewOllamaProvider(config types.Config, logger types.Logger) (types.Provider, error) {
 	// TODO: Implement Ollama provider
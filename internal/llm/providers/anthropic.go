@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"time"
 
+	"personal-ai-board/internal/llm/catalog"
 	"personal-ai-board/internal/llm/types"
 )
 
@@ -251,45 +252,11 @@ func (p *AnthropicProvider) convertResponse(anthropicResp *AnthropicResponse, du
 	return response
 }
 
-// GetModelInfo implements the Provider interface
+// GetModelInfo implements the Provider interface. The model is described by
+// the model catalog.
 func (p *AnthropicProvider) GetModelInfo() types.ModelInfo {
-	// Model information based on the configured model
-	modelInfo := types.ModelInfo{
-		Provider:     "anthropic",
-		Name:         p.config.Model,
-		Capabilities: []string{"chat", "completion", "system_messages"},
-	}
-
-	// Set model-specific information
-	switch p.config.Model {
-	case "claude-3-opus-20240229":
-		modelInfo.MaxTokens = 4096
-		modelInfo.ContextSize = 200000
-		modelInfo.CostPer1K = 0.015 // Input cost, output is higher
-	case "claude-3-sonnet-20240229":
-		modelInfo.MaxTokens = 4096
-		modelInfo.ContextSize = 200000
-		modelInfo.CostPer1K = 0.003 // Input cost
-	case "claude-3-haiku-20240307":
-		modelInfo.MaxTokens = 4096
-		modelInfo.ContextSize = 200000
-		modelInfo.CostPer1K = 0.00025 // Input cost
-	case "claude-2.1", "claude-2.0":
-		modelInfo.MaxTokens = 4096
-		modelInfo.ContextSize = 200000
-		modelInfo.CostPer1K = 0.008 // Input cost
-	case "claude-instant-1.2":
-		modelInfo.MaxTokens = 4096
-		modelInfo.ContextSize = 100000
-		modelInfo.CostPer1K = 0.0008 // Input cost
-	default:
-		// Default values for unknown models
-		modelInfo.MaxTokens = 4096
-		modelInfo.ContextSize = 200000
-		modelInfo.CostPer1K = 0.003
-	}
-
-	return modelInfo
+	model, _ := catalog.Default().Lookup("anthropic", p.config.Model)
+	return model.Info()
 }
 
 // ValidateConfig implements the Provider interface
@@ -306,26 +273,8 @@ func (p *AnthropicProvider) ValidateConfig() error {
 		return fmt.Errorf("model is required")
 	}
 
-	// Validate model name
-	validModels := []string{
-		"claude-3-opus-20240229",
-		"claude-3-sonnet-20240229",
-		"claude-3-haiku-20240307",
-		"claude-2.1",
-		"claude-2.0",
-		"claude-instant-1.2",
-	}
-
-	isValid := false
-	for _, validModel := range validModels {
-		if p.config.Model == validModel {
-			isValid = true
-			break
-		}
-	}
-
-	if !isValid {
-		p.logger.Warn("Unknown Anthropic model", "model", p.config.Model)
+	if _, known := catalog.Default().Lookup("anthropic", p.config.Model); !known {
+		p.logger.Warn("Unknown Anthropic model; add it to the model catalog", "model", p.config.Model)
 	}
 
 	// Validate max tokens
@@ -357,28 +306,9 @@ func (p *AnthropicProvider) TestConnection(ctx context.Context) error {
 	return err
 }
 
-// EstimateTokens provides a rough estimate of tokens for Anthropic models
-func (p *AnthropicProvider) EstimateTokens(text string) int {
-	// Anthropic models typically use ~4 characters per token for English
-	// This is a rough approximation
-	baseEstimate := len(text) / 4
-
-	// Add some overhead for special tokens
-	return baseEstimate + 10
-}
-
-// CalculateCost estimates the cost of a request
+// CalculateCost estimates the cost of a request from the prices in the model
+// catalog
 func (p *AnthropicProvider) CalculateCost(usage *types.TokenUsage) float64 {
-	modelInfo := p.GetModelInfo()
-
-	// Anthropic has different pricing for input and output tokens
-	// For simplicity, we'll use the input token cost as base
-	// In production, you'd want separate input/output pricing
-	inputCost := (float64(usage.PromptTokens) / 1000.0) * modelInfo.CostPer1K
-
-	// Output tokens typically cost more (e.g., 3x for Claude-3)
-	outputCostMultiplier := 3.0
-	outputCost := (float64(usage.CompletionTokens) / 1000.0) * modelInfo.CostPer1K * outputCostMultiplier
-
-	return inputCost + outputCost
+	model, _ := catalog.Default().Lookup("anthropic", p.config.Model)
+	return model.Cost(usage.PromptTokens, usage.CompletionTokens)
 }
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"personal-ai-board/internal/llm/catalog"
 	"personal-ai-board/internal/llm/types"
 )
 
@@ -326,41 +327,11 @@ func (p *GoogleProvider) convertResponse(googleResp *GoogleResponse, duration ti
 	return response
 }
 
-// GetModelInfo implements the Provider interface
+// GetModelInfo implements the Provider interface. The model is described by
+// the model catalog.
 func (p *GoogleProvider) GetModelInfo() types.ModelInfo {
-	// Model information based on the configured model
-	modelInfo := types.ModelInfo{
-		Provider:     "google",
-		Name:         p.config.Model,
-		Capabilities: []string{"chat", "completion", "system_messages", "multimodal"},
-	}
-
-	// Set model-specific information
-	switch p.config.Model {
-	case "gemini-1.5-pro", "gemini-1.5-pro-latest":
-		modelInfo.MaxTokens = 8192
-		modelInfo.ContextSize = 2097152 // 2M tokens
-		modelInfo.CostPer1K = 0.0035    // Input cost per 1K tokens
-	case "gemini-1.5-flash", "gemini-1.5-flash-latest":
-		modelInfo.MaxTokens = 8192
-		modelInfo.ContextSize = 1048576 // 1M tokens
-		modelInfo.CostPer1K = 0.00035   // Input cost per 1K tokens
-	case "gemini-1.0-pro", "gemini-1.0-pro-latest":
-		modelInfo.MaxTokens = 2048
-		modelInfo.ContextSize = 32768 // 32K tokens
-		modelInfo.CostPer1K = 0.0005  // Input cost per 1K tokens
-	case "gemini-1.0-pro-vision":
-		modelInfo.MaxTokens = 2048
-		modelInfo.ContextSize = 16384 // 16K tokens
-		modelInfo.CostPer1K = 0.0025  // Input cost per 1K tokens
-	default:
-		// Default values for unknown models
-		modelInfo.MaxTokens = 2048
-		modelInfo.ContextSize = 32768
-		modelInfo.CostPer1K = 0.0035
-	}
-
-	return modelInfo
+	model, _ := catalog.Default().Lookup("google", p.config.Model)
+	return model.Info()
 }
 
 // ValidateConfig implements the Provider interface
@@ -377,27 +348,8 @@ func (p *GoogleProvider) ValidateConfig() error {
 		return fmt.Errorf("model is required")
 	}
 
-	// Validate model name
-	validModels := []string{
-		"gemini-1.5-pro",
-		"gemini-1.5-pro-latest",
-		"gemini-1.5-flash",
-		"gemini-1.5-flash-latest",
-		"gemini-1.0-pro",
-		"gemini-1.0-pro-latest",
-		"gemini-1.0-pro-vision",
-	}
-
-	isValid := false
-	for _, validModel := range validModels {
-		if p.config.Model == validModel {
-			isValid = true
-			break
-		}
-	}
-
-	if !isValid {
-		p.logger.Warn("Unknown Google model", "model", p.config.Model)
+	if _, known := catalog.Default().Lookup("google", p.config.Model); !known {
+		p.logger.Warn("Unknown Google model; add it to the model catalog", "model", p.config.Model)
 	}
 
 	// Validate temperature
@@ -435,27 +387,9 @@ func (p *GoogleProvider) TestConnection(ctx context.Context) error {
 	return err
 }
 
-// EstimateTokens provides a rough estimate of tokens for Google models
-func (p *GoogleProvider) EstimateTokens(text string) int {
-	// Google models typically use ~4 characters per token for English
-	// This is a rough approximation
-	baseEstimate := len(text) / 4
-
-	// Add some overhead for special tokens
-	return baseEstimate + 10
-}
-
-// CalculateCost estimates the cost of a request
+// CalculateCost estimates the cost of a request from the prices in the model
+// catalog
 func (p *GoogleProvider) CalculateCost(usage *types.TokenUsage) float64 {
-	modelInfo := p.GetModelInfo()
-
-	// Google has different pricing for input and output tokens
-	// Input tokens
-	inputCost := (float64(usage.PromptTokens) / 1000.0) * modelInfo.CostPer1K
-
-	// Output tokens typically cost more (e.g., 2x for Gemini)
-	outputCostMultiplier := 2.0
-	outputCost := (float64(usage.CompletionTokens) / 1000.0) * modelInfo.CostPer1K * outputCostMultiplier
-
-	return inputCost + outputCost
+	model, _ := catalog.Default().Lookup("google", p.config.Model)
+	return model.Cost(usage.PromptTokens, usage.CompletionTokens)
 }
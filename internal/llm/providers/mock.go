@@ -0,0 +1,377 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"personal-ai-board/internal/llm/catalog"
+	"personal-ai-board/internal/llm/types"
+	"personal-ai-board/internal/tokens"
+)
+
+// MockProvider answers requests without a network connection. Responses come
+// from scripted rules, then from recorded interactions, and otherwise are
+// filler text in the voice of the persona described by the system message.
+// The same request always gets the same response, and the same sequence of
+// requests the same simulated failures.
+type MockProvider struct {
+	config    types.Config
+	logger    types.Logger
+	rules     []MockRule
+	latency   time.Duration
+	errorRate float64
+	seed      int64
+
+	mu         sync.RWMutex
+	recordings []MockRecording
+	attempts   map[uint64]int64
+}
+
+// MockRule scripts the response to requests whose prompt and system message
+// match its regular expressions. An empty expression matches anything.
+type MockRule struct {
+	Prompt   string `yaml:"prompt"`
+	System   string `yaml:"system"`
+	Response string `yaml:"response"` // May refer to submatches of Prompt as $1, ${name}
+	Error    string `yaml:"error"`    // Fail with this error instead of responding
+	Tokens   int    `yaml:"tokens"`   // Completion tokens to report instead of an estimate
+	Latency  string `yaml:"latency"`  // Delay before responding, e.g. 2s
+
+	prompt  *regexp.Regexp
+	system  *regexp.Regexp
+	latency time.Duration
+}
+
+// MockScript is the file format of scripted rules
+type MockScript struct {
+	Rules []MockRule `yaml:"rules"`
+}
+
+// MockRecording is a recorded response to replay
+type MockRecording struct {
+	Prompt    string
+	SystemMsg string
+	Response  string
+}
+
+// NewMockProvider creates a mock provider. Options are read from config.Extra:
+// "rules" ([]MockRule), "script" (a YAML file of rules), "latency"
+// (time.Duration), "error_rate" (float64 between 0 and 1) and "seed" (int64).
+func NewMockProvider(config types.Config, logger types.Logger) (*MockProvider, error) {
+	if config.Model == "" {
+		config.Model = "mock"
+	}
+	if config.MaxTokens == 0 {
+		config.MaxTokens = 1000
+	}
+
+	p := &MockProvider{config: config, logger: logger, attempts: make(map[uint64]int64)}
+
+	if rules, ok := config.Extra["rules"].([]MockRule); ok {
+		p.rules = append(p.rules, rules...)
+	}
+	if path, ok := config.Extra["script"].(string); ok && path != "" {
+		rules, err := LoadMockScript(path)
+		if err != nil {
+			return nil, err
+		}
+		p.rules = append(p.rules, rules...)
+	}
+	for i := range p.rules {
+		if err := p.rules[i].compile(); err != nil {
+			return nil, fmt.Errorf("mock rule %d: %w", i+1, err)
+		}
+	}
+
+	if latency, ok := config.Extra["latency"].(time.Duration); ok {
+		p.latency = latency
+	}
+	if rate, ok := config.Extra["error_rate"].(float64); ok {
+		p.errorRate = rate
+	}
+	if seed, ok := config.Extra["seed"].(int64); ok {
+		p.seed = seed
+	}
+
+	return p, nil
+}
+
+// LoadMockScript reads scripted rules from a YAML file
+func LoadMockScript(path string) ([]MockRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mock script: %w", err)
+	}
+
+	var script MockScript
+	if err := yaml.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("failed to parse mock script %s: %w", path, err)
+	}
+	return script.Rules, nil
+}
+
+// compile prepares a rule's expressions and latency
+func (r *MockRule) compile() error {
+	var err error
+	if r.Prompt != "" {
+		if r.prompt, err = regexp.Compile(r.Prompt); err != nil {
+			return fmt.Errorf("invalid prompt pattern: %w", err)
+		}
+	}
+	if r.System != "" {
+		if r.system, err = regexp.Compile(r.System); err != nil {
+			return fmt.Errorf("invalid system pattern: %w", err)
+		}
+	}
+	if r.Latency != "" {
+		if r.latency, err = time.ParseDuration(r.Latency); err != nil {
+			return fmt.Errorf("invalid latency: %w", err)
+		}
+	}
+	if r.Response == "" && r.Error == "" {
+		return fmt.Errorf("a response or an error is required")
+	}
+	return nil
+}
+
+// match reports whether the rule applies to a request and returns its response
+func (r *MockRule) match(req types.Request) (string, bool) {
+	if r.system != nil && !r.system.MatchString(req.SystemMsg) {
+		return "", false
+	}
+	if r.prompt == nil {
+		return r.Response, true
+	}
+
+	submatches := r.prompt.FindStringSubmatchIndex(req.Prompt)
+	if submatches == nil {
+		return "", false
+	}
+	return string(r.prompt.ExpandString(nil, r.Response, req.Prompt, submatches)), true
+}
+
+// AddRecordings adds recorded responses to replay. A request is answered with
+// a recording of the same prompt and system message, or failing that with one
+// of the recordings of the same system message, that is of the same persona.
+func (p *MockProvider) AddRecordings(recordings []MockRecording) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recordings = append(p.recordings, recordings...)
+}
+
+// GenerateResponse implements the Provider interface
+func (p *MockProvider) GenerateResponse(ctx context.Context, req types.Request) (*types.Response, error) {
+	return p.GenerateResponseStream(ctx, req, nil)
+}
+
+// GenerateResponseStream implements the StreamingProvider interface. The
+// response is delivered a few words at a time over the simulated latency.
+func (p *MockProvider) GenerateResponseStream(ctx context.Context, req types.Request, onChunk func(string)) (*types.Response, error) {
+	if err := types.ValidateRequest(req); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	startTime := time.Now()
+	key := p.key(req)
+	rng := rand.New(rand.NewSource(int64(key)))
+	latency := p.jitter(p.latency, rng)
+	failed := p.fails(key)
+
+	content, source, completionTokens, err := p.respond(req, rng, &latency)
+	if err == nil && failed {
+		err = fmt.Errorf("mock: simulated failure (503 service unavailable)")
+	}
+	if err != nil {
+		if waitErr := wait(ctx, latency); waitErr != nil {
+			return nil, waitErr
+		}
+		return nil, err
+	}
+
+	chunks := splitChunks(content)
+	for _, chunk := range chunks {
+		if err := wait(ctx, latency/time.Duration(len(chunks))); err != nil {
+			return nil, err
+		}
+		if onChunk != nil {
+			onChunk(chunk)
+		}
+	}
+
+	promptTokens := tokens.Estimate(req.SystemMsg) + tokens.Estimate(req.Prompt)
+	if completionTokens == 0 {
+		completionTokens = tokens.Estimate(content)
+	}
+
+	model := req.Model
+	if model == "" {
+		model = p.config.Model
+	}
+
+	p.logger.Debug("Mock response generated", "source", source, "tokens", completionTokens)
+
+	return &types.Response{
+		Content:      content,
+		TokensUsed:   promptTokens + completionTokens,
+		Model:        model,
+		Duration:     time.Since(startTime),
+		FinishReason: "stop",
+		Usage: &types.TokenUsage{
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+			TotalTokens:      promptTokens + completionTokens,
+		},
+		Metadata: map[string]interface{}{"source": source},
+	}, nil
+}
+
+// respond picks the response to a request and reports where it came from.
+// A matching rule may change the latency.
+func (p *MockProvider) respond(req types.Request, rng *rand.Rand, latency *time.Duration) (string, string, int, error) {
+	for i := range p.rules {
+		rule := &p.rules[i]
+		response, ok := rule.match(req)
+		if !ok {
+			continue
+		}
+		if rule.latency > 0 {
+			*latency = p.jitter(rule.latency, rng)
+		}
+		if rule.Error != "" {
+			return "", "rule", 0, fmt.Errorf("mock: %s", rule.Error)
+		}
+		return response, "rule", rule.Tokens, nil
+	}
+
+	if recording, ok := p.replay(req, rng); ok {
+		return recording.Response, "replay", 0, nil
+	}
+
+	return filler(req, rng), "filler", 0, nil
+}
+
+// replay finds a recorded response to a request
+func (p *MockProvider) replay(req types.Request, rng *rand.Rand) (MockRecording, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var samePersona []MockRecording
+	for _, recording := range p.recordings {
+		if recording.SystemMsg != req.SystemMsg {
+			continue
+		}
+		if recording.Prompt == req.Prompt {
+			return recording, true
+		}
+		samePersona = append(samePersona, recording)
+	}
+	if len(samePersona) == 0 {
+		return MockRecording{}, false
+	}
+	return samePersona[rng.Intn(len(samePersona))], true
+}
+
+// key identifies a request, so that the same request gets the same response
+func (p *MockProvider) key(req types.Request) uint64 {
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%d\x00%s\x00%s", p.seed, req.SystemMsg, req.Prompt)
+	return hash.Sum64()
+}
+
+// fails decides whether to simulate a failure. Each attempt at the same
+// request decides afresh, so that retries can succeed.
+func (p *MockProvider) fails(key uint64) bool {
+	if p.errorRate <= 0 {
+		return false
+	}
+
+	p.mu.Lock()
+	attempt := p.attempts[key]
+	p.attempts[key]++
+	p.mu.Unlock()
+
+	return rand.New(rand.NewSource(int64(key)+attempt)).Float64() < p.errorRate
+}
+
+// jitter varies a latency by up to half either way
+func (p *MockProvider) jitter(latency time.Duration, rng *rand.Rand) time.Duration {
+	if latency <= 0 {
+		return 0
+	}
+	return time.Duration(float64(latency) * (0.5 + rng.Float64()))
+}
+
+// wait sleeps for d unless ctx is cancelled first
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// splitChunks splits content into chunks of a few words, keeping whitespace
+func splitChunks(content string) []string {
+	const wordsPerChunk = 4
+
+	var chunks []string
+	var chunk strings.Builder
+	words := 0
+	for _, r := range content {
+		chunk.WriteRune(r)
+		if r == ' ' || r == '\n' {
+			words++
+		}
+		if words == wordsPerChunk {
+			chunks = append(chunks, chunk.String())
+			chunk.Reset()
+			words = 0
+		}
+	}
+	if chunk.Len() > 0 {
+		chunks = append(chunks, chunk.String())
+	}
+	if len(chunks) == 0 {
+		chunks = []string{content}
+	}
+	return chunks
+}
+
+// GetModelInfo implements the Provider interface. The model is described by
+// the model catalog.
+func (p *MockProvider) GetModelInfo() types.ModelInfo {
+	model, _ := catalog.Default().Lookup("mock", p.config.Model)
+	return model.Info()
+}
+
+// ValidateConfig implements the Provider interface. A mock provider needs no
+// API key.
+func (p *MockProvider) ValidateConfig() error {
+	if p.errorRate < 0 || p.errorRate > 1 {
+		return fmt.Errorf("error_rate must be between 0 and 1")
+	}
+	if p.latency < 0 {
+		return fmt.Errorf("latency cannot be negative")
+	}
+	return nil
+}
+
+// Name implements the Provider interface
+func (p *MockProvider) Name() string {
+	return "mock"
+}
@@ -0,0 +1,184 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"personal-ai-board/internal/llm/types"
+)
+
+var (
+	// fillerIdentity finds the persona's name and description in a system message
+	fillerIdentity = regexp.MustCompile(`(?m)^You are ([^,\n]+), ([^\n]*?)\.?$`)
+	// fillerExpertise finds the persona's expertise in a system message
+	fillerExpertise = regexp.MustCompile(`(?m)^- Your expertise: (.+)$`)
+	// fillerPhrases finds the persona's common phrases in a system message
+	fillerPhrases = regexp.MustCompile(`(?m)^- You often say things like: (.+)$`)
+	// fillerTopic finds the topic of an analysis in a prompt
+	fillerTopic = regexp.MustCompile(`(?m)^Topic: (.+)$`)
+	// fillerJSON finds a system message asking for a JSON object
+	fillerJSON = regexp.MustCompile(`(?i)\bJSON object\b`)
+	// fillerAdvisor finds the description of the advisor to design in a prompt
+	fillerAdvisor = regexp.MustCompile(`(?s)advisor:\n\n(.+?)(?:\n\n|$)`)
+	// fillerCategory finds a trait category heading in a prompt
+	fillerCategory = regexp.MustCompile(`^([a-z_]+):$`)
+	// fillerScale finds a scale trait and its range in a prompt
+	fillerScale = regexp.MustCompile(`^- ([a-z_]+) \(integer (\d+)-(\d+)\)`)
+	// fillerEnum finds an enum trait and its options in a prompt
+	fillerEnum = regexp.MustCompile(`^- ([a-z_]+) \(one of ([^)]+)\)`)
+)
+
+var fillerOpenings = []string{
+	"Looking at %s, my first reaction comes from %s.",
+	"When I think about %s, I can't help seeing it through %s.",
+	"Let me approach %s from the angle of %s.",
+}
+
+var fillerInsights = []string{
+	"Key insight: the success of %s depends less on the idea itself than on how quickly it can be tested with real users.",
+	"Key insight: the biggest risk in %s is an assumption nobody has written down yet.",
+	"Key insight: %s is important enough to deserve a small, focused experiment before a big commitment.",
+}
+
+var fillerRecommendations = []string{
+	"I recommend starting with the smallest version of %s that can prove or disprove the core assumption.",
+	"I suggest we set a clear budget and a deadline for %s before going further.",
+	"We should talk to five potential customers about %s this week.",
+}
+
+var fillerQuestions = []string{
+	"What would have to be true for %s to fail?",
+	"Who is the first person that would pay for %s, and why?",
+	"How will we know in a month whether %s is working?",
+}
+
+// filler writes a deterministic response in the voice of the persona the
+// system message describes, phrased so that insights, recommendations and
+// questions can be extracted from it
+func filler(req types.Request, rng *rand.Rand) string {
+	if fillerJSON.MatchString(req.SystemMsg) {
+		return fillerProfile(req.Prompt, rng)
+	}
+
+	name := "an advisor"
+	if match := fillerIdentity.FindStringSubmatch(req.SystemMsg); match != nil {
+		name = match[1]
+	}
+	expertise := "experience"
+	if match := fillerExpertise.FindStringSubmatch(req.SystemMsg); match != nil {
+		areas := strings.Split(match[1], ", ")
+		expertise = "my background in " + areas[rng.Intn(len(areas))]
+	}
+	topic := fillerTopicOf(req.Prompt)
+
+	var s strings.Builder
+	if match := fillerPhrases.FindStringSubmatch(req.SystemMsg); match != nil {
+		phrases := strings.Split(match[1], ", ")
+		phrase := strings.TrimSpace(phrases[rng.Intn(len(phrases))])
+		if phrase != "" && !strings.HasSuffix(phrase, ".") && !strings.HasSuffix(phrase, "!") && !strings.HasSuffix(phrase, "?") {
+			phrase += "."
+		}
+		if phrase != "" {
+			s.WriteString(phrase + " ")
+		}
+	}
+	fmt.Fprintf(&s, fillerOpenings[rng.Intn(len(fillerOpenings))], topic, expertise)
+	fmt.Fprintf(&s, " Speaking as %s, this is how I see it.\n\n", name)
+	fmt.Fprintf(&s, fillerInsights[rng.Intn(len(fillerInsights))], topic)
+	s.WriteString("\n\n")
+	fmt.Fprintf(&s, fillerRecommendations[rng.Intn(len(fillerRecommendations))], topic)
+	s.WriteString("\n\n")
+	fmt.Fprintf(&s, fillerQuestions[rng.Intn(len(fillerQuestions))], topic)
+
+	return s.String()
+}
+
+// fillerTopicOf returns what a prompt asks about
+func fillerTopicOf(prompt string) string {
+	var topic string
+	if match := fillerTopic.FindStringSubmatch(prompt); match != nil {
+		topic = match[1]
+	} else if _, question, ok := strings.Cut(prompt, "## Current Question/Topic:\n"); ok {
+		topic, _, _ = strings.Cut(question, "\n")
+	} else {
+		for _, line := range strings.Split(prompt, "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				topic = line
+				break
+			}
+		}
+	}
+
+	topic = strings.TrimRight(strings.TrimSpace(topic), ".?!")
+	if runes := []rune(topic); len(runes) > 80 {
+		topic = string(runes[:77]) + "..."
+	}
+	if topic == "" {
+		return "this"
+	}
+	return `"` + topic + `"`
+}
+
+// fillerProfile answers a request for a JSON object with a personality
+// profile: the advisor described by the prompt, with a value in range for
+// every scale and enum trait the prompt lists under its category
+func fillerProfile(prompt string, rng *rand.Rand) string {
+	description := "A pragmatic advisor"
+	if match := fillerAdvisor.FindStringSubmatch(prompt); match != nil {
+		description = strings.Join(strings.Fields(match[1]), " ")
+	}
+	words := strings.Fields(strings.ToLower(description))
+	if len(words) > 3 {
+		words = words[:3]
+	}
+	for i, word := range words {
+		words[i] = strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, word)
+	}
+
+	profile := map[string]interface{}{
+		"persona_type":    strings.Join(append([]string{"mock"}, words...), "_"),
+		"name":            "Mock Advisor",
+		"description":     description,
+		"expertise_areas": []string{"strategy", "execution"},
+		"speaking_patterns": map[string][]string{
+			"common_phrases": {"Let's test that first"},
+		},
+	}
+
+	var traits map[string]interface{}
+	for _, line := range strings.Split(prompt, "\n") {
+		if match := fillerCategory.FindStringSubmatch(line); match != nil {
+			traits = make(map[string]interface{})
+			profile[match[1]] = traits
+			continue
+		}
+		if traits == nil {
+			continue
+		}
+		if match := fillerScale.FindStringSubmatch(line); match != nil {
+			low, _ := strconv.Atoi(match[2])
+			high, _ := strconv.Atoi(match[3])
+			if high >= low {
+				traits[match[1]] = low + rng.Intn(high-low+1)
+			}
+		} else if match := fillerEnum.FindStringSubmatch(line); match != nil {
+			options := strings.Split(match[2], ", ")
+			traits[match[1]] = options[rng.Intn(len(options))]
+		}
+	}
+
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
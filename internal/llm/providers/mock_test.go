@@ -0,0 +1,274 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"personal-ai-board/internal/llm/types"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Warn(string, ...interface{})  {}
+
+func newMock(t *testing.T, extra map[string]interface{}) *MockProvider {
+	t.Helper()
+	p, err := NewMockProvider(types.Config{Extra: extra}, nopLogger{})
+	if err != nil {
+		t.Fatalf("NewMockProvider: %v", err)
+	}
+	if err := p.ValidateConfig(); err != nil {
+		t.Fatalf("ValidateConfig: %v", err)
+	}
+	return p
+}
+
+func request(system, prompt string) types.Request {
+	return types.Request{SystemMsg: system, Prompt: prompt, MaxTokens: 100}
+}
+
+func TestMockRules(t *testing.T) {
+	p := newMock(t, map[string]interface{}{"rules": []MockRule{
+		{Prompt: "Topic: (?P<topic>.*pricing.*)", System: "Skeptical CFO", Response: "Show me the unit economics for ${topic} first.", Tokens: 40},
+		{Prompt: "outage", Error: "rate limit exceeded (429)"},
+		{System: "Optimist", Response: "Ship it."},
+	}})
+	ctx := context.Background()
+
+	resp, err := p.GenerateResponse(ctx, request("You are Skeptical CFO, a careful investor.", "Topic: usage pricing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "Show me the unit economics for usage pricing first." {
+		t.Errorf("content = %q", resp.Content)
+	}
+	if resp.Usage.CompletionTokens != 40 || resp.Metadata["source"] != "rule" {
+		t.Errorf("completion tokens = %d, source = %v", resp.Usage.CompletionTokens, resp.Metadata["source"])
+	}
+
+	// The first rule's system pattern does not match, and the third has no
+	// prompt pattern
+	resp, err = p.GenerateResponse(ctx, request("You are Optimist, a founder.", "Topic: usage pricing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "Ship it." {
+		t.Errorf("content = %q", resp.Content)
+	}
+
+	_, err = p.GenerateResponse(ctx, request("You are Optimist, a founder.", "Plan for an outage"))
+	if err == nil || !strings.Contains(err.Error(), "rate limit exceeded") {
+		t.Fatalf("err = %v, want the rule's error", err)
+	}
+	if !types.IsRetryableError(err) {
+		t.Errorf("rule error %q is not retryable", err)
+	}
+
+	resp, err = p.GenerateResponse(ctx, request("You are Realist, an engineer.", "Topic: hiring"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Metadata["source"] != "filler" || !strings.Contains(resp.Content, `"hiring"`) {
+		t.Errorf("source = %v, content = %q", resp.Metadata["source"], resp.Content)
+	}
+}
+
+func TestMockRuleValidation(t *testing.T) {
+	for _, rule := range []MockRule{
+		{Prompt: "(", Response: "x"},
+		{System: "[", Response: "x"},
+		{Prompt: "x", Latency: "soon", Response: "x"},
+		{Prompt: "x"},
+	} {
+		if _, err := NewMockProvider(types.Config{Extra: map[string]interface{}{"rules": []MockRule{rule}}}, nopLogger{}); err == nil {
+			t.Errorf("rule %+v accepted", rule)
+		}
+	}
+}
+
+func TestMockDeterministic(t *testing.T) {
+	req := request("You are Realist, an engineer.", "Topic: a new onboarding flow")
+
+	first, err := newMock(t, nil).GenerateResponse(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := newMock(t, nil).GenerateResponse(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Content != second.Content {
+		t.Errorf("responses differ:\n%s\n%s", first.Content, second.Content)
+	}
+}
+
+func TestMockErrorRate(t *testing.T) {
+	const attempts = 200
+	outcomes := func(seed int64) []bool {
+		p := newMock(t, map[string]interface{}{"error_rate": 0.3, "seed": seed})
+		failed := make([]bool, attempts)
+		for i := range failed {
+			_, err := p.GenerateResponse(context.Background(), request("", "Topic: retries"))
+			if err != nil && !strings.Contains(err.Error(), "simulated failure") {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			failed[i] = err != nil
+		}
+		return failed
+	}
+
+	first := outcomes(1)
+	failures, run := 0, 0
+	for i, failed := range first {
+		if !failed {
+			run = 0
+			continue
+		}
+		failures++
+		if run++; run == 8 {
+			t.Fatalf("attempts %d to %d all failed", i-7, i)
+		}
+	}
+	if failures < attempts/10 || failures > attempts/2 {
+		t.Errorf("%d of %d attempts failed at an error rate of 0.3", failures, attempts)
+	}
+
+	again := outcomes(1)
+	for i := range first {
+		if first[i] != again[i] {
+			t.Fatalf("attempt %d: failures differ for the same seed", i)
+		}
+	}
+
+	p, err := NewMockProvider(types.Config{Extra: map[string]interface{}{"error_rate": 1.5}}, nopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ValidateConfig() == nil {
+		t.Error("error_rate 1.5 accepted")
+	}
+}
+
+func TestMockRetriesSucceed(t *testing.T) {
+	p := newMock(t, map[string]interface{}{"error_rate": 0.5, "seed": int64(7)})
+	for i := 0; i < 20; i++ {
+		req := request("", "Topic: attempt "+strings.Repeat("x", i))
+		var err error
+		for attempt := 0; attempt < 20; attempt++ {
+			if _, err = p.GenerateResponse(context.Background(), req); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			t.Fatalf("request %d still failing after 20 attempts: %v", i, err)
+		}
+	}
+}
+
+func TestMockLatency(t *testing.T) {
+	p := newMock(t, map[string]interface{}{
+		"latency": 40 * time.Millisecond,
+		"rules":   []MockRule{{Prompt: "slow", Response: "finally", Latency: "200ms"}},
+	})
+
+	start := time.Now()
+	var chunks []string
+	resp, err := p.GenerateResponseStream(context.Background(), request("", "Topic: one two three four five six seven eight nine"), func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Latency is jittered by up to half either way
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("responded after %v, want at least 20ms", elapsed)
+	}
+	if len(chunks) < 2 || strings.Join(chunks, "") != resp.Content {
+		t.Errorf("streamed %d chunks that do not add up to the response", len(chunks))
+	}
+
+	start = time.Now()
+	if _, err := p.GenerateResponse(context.Background(), request("", "slow")); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("rule latency: responded after %v, want at least 100ms", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.GenerateResponse(ctx, request("", "slow")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the context's deadline", err)
+	}
+}
+
+func TestMockReplay(t *testing.T) {
+	p := newMock(t, nil)
+	p.AddRecordings([]MockRecording{
+		{SystemMsg: "You are Skeptical CFO, a careful investor.", Prompt: "Topic: pricing", Response: "Recorded pricing answer"},
+		{SystemMsg: "You are Skeptical CFO, a careful investor.", Prompt: "Topic: hiring", Response: "Recorded hiring answer"},
+		{SystemMsg: "You are Optimist, a founder.", Prompt: "Topic: pricing", Response: "Optimist's pricing answer"},
+	})
+	ctx := context.Background()
+
+	resp, err := p.GenerateResponse(ctx, request("You are Skeptical CFO, a careful investor.", "Topic: pricing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "Recorded pricing answer" || resp.Metadata["source"] != "replay" {
+		t.Errorf("content = %q, source = %v", resp.Content, resp.Metadata["source"])
+	}
+
+	// Another prompt gets one of the same persona's recordings
+	resp, err = p.GenerateResponse(ctx, request("You are Skeptical CFO, a careful investor.", "Topic: a new office"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "Recorded pricing answer" && resp.Content != "Recorded hiring answer" {
+		t.Errorf("content = %q, want a recording of the same persona", resp.Content)
+	}
+
+	resp, err = p.GenerateResponse(ctx, request("You are Realist, an engineer.", "Topic: pricing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Metadata["source"] != "filler" {
+		t.Errorf("source = %v, want filler for a persona without recordings", resp.Metadata["source"])
+	}
+}
+
+func TestMockProfile(t *testing.T) {
+	prompt := "Create a personality profile for this advisor:\n\na frugal CFO\n\n## Traits to fill in\n\n" +
+		"core_dimensions:\n- risk_tolerance (integer 1-10): appetite for risk\n\n" +
+		"communication_style:\n- formality (one of casual, formal): register\n"
+	resp, err := newMock(t, nil).GenerateResponse(context.Background(), request("You answer with a single JSON object and nothing else.", prompt))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var profile struct {
+		PersonaType        string            `json:"persona_type"`
+		Description        string            `json:"description"`
+		CoreDimensions     map[string]int    `json:"core_dimensions"`
+		CommunicationStyle map[string]string `json:"communication_style"`
+	}
+	if err := json.Unmarshal([]byte(resp.Content), &profile); err != nil {
+		t.Fatalf("response is not a JSON object: %v\n%s", err, resp.Content)
+	}
+	if profile.PersonaType == "" || profile.Description != "a frugal CFO" {
+		t.Errorf("persona_type = %q, description = %q", profile.PersonaType, profile.Description)
+	}
+	if risk := profile.CoreDimensions["risk_tolerance"]; risk < 1 || risk > 10 {
+		t.Errorf("risk_tolerance = %d, want 1-10", risk)
+	}
+	if formality := profile.CommunicationStyle["formality"]; formality != "casual" && formality != "formal" {
+		t.Errorf("formality = %q", formality)
+	}
+}
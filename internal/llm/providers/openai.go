@@ -7,9 +7,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 	"time"
 
+	"personal-ai-board/internal/llm/catalog"
 	"personal-ai-board/internal/llm/types"
 )
 
@@ -254,50 +254,11 @@ func (p *OpenAIProvider) convertResponse(openaiResp *OpenAIResponse, duration ti
 	return response
 }
 
-// GetModelInfo implements the Provider interface
+// GetModelInfo implements the Provider interface. The model is described by
+// the model catalog.
 func (p *OpenAIProvider) GetModelInfo() types.ModelInfo {
-	// Model information based on the configured model
-	modelInfo := types.ModelInfo{
-		Provider:     "openai",
-		Name:         p.config.Model,
-		Capabilities: []string{"chat", "completion", "system_messages"},
-	}
-
-	// Set model-specific information
-	switch p.config.Model {
-	case "gpt-4", "gpt-4-0613", "gpt-4-32k", "gpt-4-32k-0613":
-		modelInfo.MaxTokens = 8192
-		modelInfo.ContextSize = 8192
-		modelInfo.CostPer1K = 0.03
-		if strings.Contains(p.config.Model, "32k") {
-			modelInfo.MaxTokens = 32768
-			modelInfo.ContextSize = 32768
-			modelInfo.CostPer1K = 0.06
-		}
-	case "gpt-4-turbo", "gpt-4-turbo-preview", "gpt-4-0125-preview", "gpt-4-1106-preview":
-		modelInfo.MaxTokens = 4096
-		modelInfo.ContextSize = 128000
-		modelInfo.CostPer1K = 0.01
-	case "gpt-4o", "gpt-4o-2024-05-13":
-		modelInfo.MaxTokens = 4096
-		modelInfo.ContextSize = 128000
-		modelInfo.CostPer1K = 0.005
-	case "gpt-3.5-turbo", "gpt-3.5-turbo-0125", "gpt-3.5-turbo-1106":
-		modelInfo.MaxTokens = 4096
-		modelInfo.ContextSize = 16385
-		modelInfo.CostPer1K = 0.0005
-	case "gpt-3.5-turbo-16k", "gpt-3.5-turbo-16k-0613":
-		modelInfo.MaxTokens = 4096
-		modelInfo.ContextSize = 16385
-		modelInfo.CostPer1K = 0.001
-	default:
-		// Default values for unknown models
-		modelInfo.MaxTokens = 4096
-		modelInfo.ContextSize = 8192
-		modelInfo.CostPer1K = 0.02
-	}
-
-	return modelInfo
+	model, _ := catalog.Default().Lookup("openai", p.config.Model)
+	return model.Info()
 }
 
 // ValidateConfig implements the Provider interface
@@ -314,25 +275,8 @@ func (p *OpenAIProvider) ValidateConfig() error {
 		return fmt.Errorf("model is required")
 	}
 
-	// Validate model name
-	validModels := []string{
-		"gpt-4", "gpt-4-0613", "gpt-4-32k", "gpt-4-32k-0613",
-		"gpt-4-turbo", "gpt-4-turbo-preview", "gpt-4-0125-preview", "gpt-4-1106-preview",
-		"gpt-4o", "gpt-4o-2024-05-13",
-		"gpt-3.5-turbo", "gpt-3.5-turbo-0125", "gpt-3.5-turbo-1106",
-		"gpt-3.5-turbo-16k", "gpt-3.5-turbo-16k-0613",
-	}
-
-	isValid := false
-	for _, validModel := range validModels {
-		if p.config.Model == validModel {
-			isValid = true
-			break
-		}
-	}
-
-	if !isValid {
-		p.logger.Warn("Unknown OpenAI model", "model", p.config.Model)
+	if _, known := catalog.Default().Lookup("openai", p.config.Model); !known {
+		p.logger.Warn("Unknown OpenAI model; add it to the model catalog", "model", p.config.Model)
 	}
 
 	// Validate temperature
@@ -415,25 +359,21 @@ func (p *OpenAIProvider) GetAvailableModels(ctx context.Context) ([]string, erro
 	return models, nil
 }
 
-// EstimateTokens provides a rough estimate of tokens for OpenAI models
-func (p *OpenAIProvider) EstimateTokens(text string) int {
-	// Very rough approximation for GPT models
-	// In production, you'd use tiktoken library
-
-	// GPT models typically use ~4 characters per token for English
-	baseEstimate := len(text) / 4
-
-	// Add some overhead for special tokens
-	return baseEstimate + 10
+// RefreshCatalog adds the models the account can use to the model catalog,
+// when the catalog knows their family. It returns the models it added and the
+// names the catalog has no entry for.
+func (p *OpenAIProvider) RefreshCatalog(ctx context.Context, c *catalog.Catalog) ([]catalog.Model, []string, error) {
+	names, err := p.GetAvailableModels(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	added, unknown := c.Refresh("openai", names)
+	return added, unknown, nil
 }
 
-// CalculateCost estimates the cost of a request
+// CalculateCost estimates the cost of a request from the prices in the model
+// catalog
 func (p *OpenAIProvider) CalculateCost(usage *types.TokenUsage) float64 {
-	modelInfo := p.GetModelInfo()
-
-	// Calculate cost based on total tokens and model pricing
-	totalTokens := float64(usage.TotalTokens)
-	costPer1K := modelInfo.CostPer1K
-
-	return (totalTokens / 1000.0) * costPer1K
+	model, _ := catalog.Default().Lookup("openai", p.config.Model)
+	return model.Cost(usage.PromptTokens, usage.CompletionTokens)
 }
@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"database/sql"
+	"fmt"
+
+	"personal-ai-board/internal/llm/providers"
+)
+
+// LoadRecordings reads the responses recorded in the interaction log, newest
+// first, leaving out those the mock provider itself generated. A limit of
+// zero reads them all.
+func LoadRecordings(db *sql.DB, mockModel string, limit int) ([]providers.MockRecording, error) {
+	query := `
+		SELECT prompt, COALESCE(system_message, ''), response
+		FROM llm_interaction_logs
+		WHERE model_name != ?
+		ORDER BY created_at DESC
+	`
+	args := []interface{}{mockModel}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recorded interactions: %w", err)
+	}
+	defer rows.Close()
+
+	recordings := make([]providers.MockRecording, 0)
+	for rows.Next() {
+		var recording providers.MockRecording
+		if err := rows.Scan(&recording.Prompt, &recording.SystemMsg, &recording.Response); err != nil {
+			return nil, fmt.Errorf("failed to scan recorded interaction: %w", err)
+		}
+		recordings = append(recordings, recording)
+	}
+
+	return recordings, rows.Err()
+}
+
+// EnableReplay loads the recorded interactions into the manager's mock
+// provider and returns how many were loaded
+func EnableReplay(manager *Manager, db *sql.DB) (int, error) {
+	provider, err := manager.GetProvider("mock")
+	if err != nil {
+		return 0, err
+	}
+	mock, ok := provider.(*providers.MockProvider)
+	if !ok {
+		return 0, fmt.Errorf("provider mock cannot replay recordings")
+	}
+
+	recordings, err := LoadRecordings(db, mock.GetModelInfo().Name, 0)
+	if err != nil {
+		return 0, err
+	}
+	mock.AddRecordings(recordings)
+
+	return len(recordings), nil
+}
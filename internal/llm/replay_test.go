@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"personal-ai-board/internal/db"
+	"personal-ai-board/internal/llm/providers"
+	"personal-ai-board/internal/llm/types"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Warn(string, ...interface{})  {}
+
+func TestEnableReplay(t *testing.T) {
+	config := db.DefaultConfig()
+	config.Path = filepath.Join(t.TempDir(), "replay.db")
+	database, err := db.Connect(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	const system = "You are Skeptical CFO, a careful investor."
+	for i, row := range []struct{ prompt, response, model string }{
+		{"Topic: pricing", "Recorded pricing answer", "gpt-4o"},
+		{"Topic: hiring", "Recorded hiring answer", "claude-3-5-sonnet"},
+		{"Topic: office", "Answer the mock made up", "mock"},
+	} {
+		_, err := database.Exec(`
+			INSERT INTO llm_interaction_logs (id, prompt, system_message, response, model_name, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, i, row.prompt, system, row.response, row.model, time.Now().Add(time.Duration(i)*time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mock, err := providers.NewMockProvider(types.Config{}, nopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager := NewManager(nopLogger{})
+	if err := manager.RegisterProvider("mock", mock); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := EnableReplay(manager, database.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded != 2 {
+		t.Fatalf("loaded %d recordings, want the 2 not made by the mock", loaded)
+	}
+
+	req := types.Request{SystemMsg: system, Prompt: "Topic: hiring", MaxTokens: 100}
+	resp, err := manager.GenerateResponse(context.Background(), "mock", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "Recorded hiring answer" || resp.Metadata["source"] != "replay" {
+		t.Errorf("content = %q, source = %v", resp.Content, resp.Metadata["source"])
+	}
+
+	req.Prompt = "Topic: office"
+	resp, err = manager.GenerateResponse(context.Background(), "mock", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content == "Answer the mock made up" {
+		t.Error("replayed a response the mock generated")
+	}
+}
@@ -0,0 +1,183 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"personal-ai-board/internal/config"
+	"personal-ai-board/internal/llm/catalog"
+	"personal-ai-board/internal/llm/types"
+)
+
+// ProviderConfigs builds provider configurations for every provider that has
+// an API key in the application configuration, and for the mock provider if it
+// is enabled. API keys are resolved here, so a provider whose key cannot be
+// read is skipped and reported in the returned errors.
+func ProviderConfigs(cfg *config.Config) ([]types.Config, []error) {
+	configs := make([]types.Config, 0)
+	var errs []error
+
+	for _, name := range []string{"openai", "anthropic", "google", "mock"} {
+		if !cfg.HasProvider(name) {
+			continue
+		}
+
+		providerCfg, _ := cfg.GetProviderConfig(name)
+		apiKey, err := cfg.ResolveSecret(providerCfg.APIKey)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+
+		model := providerCfg.Model
+		if model == "" && name == cfg.LLM.DefaultProvider && name != "mock" {
+			model = cfg.LLM.DefaultModel
+		}
+
+		temperature := providerCfg.Temperature
+		if temperature == 0 {
+			temperature = cfg.LLM.Temperature
+		}
+
+		maxTokens := providerCfg.MaxTokens
+		if maxTokens == 0 {
+			maxTokens = cfg.LLM.MaxTokens
+		}
+
+		providerConfig := types.Config{
+			Provider:    name,
+			APIKey:      apiKey,
+			BaseURL:     providerCfg.BaseURL,
+			Model:       model,
+			Temperature: temperature,
+			MaxTokens:   maxTokens,
+			Timeout:     cfg.GetTimeout(),
+		}
+		if name == "mock" {
+			providerConfig.Extra = map[string]interface{}{
+				"script":     cfg.LLM.Mock.Script,
+				"latency":    cfg.GetMockLatency(),
+				"error_rate": cfg.LLM.Mock.ErrorRate,
+				"seed":       cfg.LLM.Mock.Seed,
+			}
+		}
+		configs = append(configs, providerConfig)
+	}
+
+	return configs, errs
+}
+
+// NewManagerFromConfig creates a manager with every configured provider registered.
+// Providers that fail to initialize are skipped and reported in the returned errors.
+func NewManagerFromConfig(cfg *config.Config, logger types.Logger) (*Manager, []error) {
+	manager := NewManager(logger)
+	_, errs := manager.ApplyConfig(cfg)
+	return manager, errs
+}
+
+// ApplyConfig brings the registered providers in line with the application
+// configuration. Providers whose settings changed are rebuilt, providers that
+// are no longer configured are removed, and the rest are left as they are.
+// It returns the names of the providers that were added, rebuilt or removed.
+// A provider that fails to rebuild keeps running with its old settings.
+func (m *Manager) ApplyConfig(cfg *config.Config) ([]string, []error) {
+	factory := NewProviderFactory(m.logger)
+	configs, errs := ProviderConfigs(cfg)
+
+	// Providers describe their models from the catalog, so load it first
+	if models, err := catalog.Load(cfg.ModelCatalogPath()); err != nil {
+		errs = append(errs, err)
+	} else {
+		catalog.SetDefault(models)
+	}
+
+	// A provider whose key cannot be resolved any more is left alone, rather
+	// than removed because it looks unconfigured
+	wanted := make(map[string]bool)
+	for _, name := range []string{"openai", "anthropic", "google", "mock"} {
+		wanted[name] = cfg.HasProvider(name)
+	}
+
+	var changed []string
+	for _, providerCfg := range configs {
+		name := providerCfg.Provider
+		m.mu.RLock()
+		current, exists := m.configs[name]
+		m.mu.RUnlock()
+		if exists && reflect.DeepEqual(current, providerCfg) {
+			continue
+		}
+
+		provider, err := factory.CreateProvider(providerCfg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		if err := m.RegisterProvider(name, provider); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+
+		m.mu.Lock()
+		m.configs[name] = providerCfg
+		m.mu.Unlock()
+		changed = append(changed, name)
+	}
+
+	for _, name := range m.ListProviders() {
+		if configured, known := wanted[name]; known && !configured {
+			m.RemoveProvider(name)
+			changed = append(changed, name)
+		}
+	}
+
+	if cfg.LLM.DefaultProvider != "" {
+		if _, err := m.GetProvider(cfg.LLM.DefaultProvider); err == nil {
+			m.SetDefaultProvider(cfg.LLM.DefaultProvider)
+		}
+	}
+	if _, err := m.GetProvider(""); err != nil {
+		// The default was removed; fall back to any provider that is left
+		if names := m.ListProviders(); len(names) > 0 {
+			sort.Strings(names)
+			m.SetDefaultProvider(names[0])
+		}
+	}
+	sort.Strings(changed)
+
+	return changed, errs
+}
+
+// RefreshCatalog asks every registered provider that can list its models for
+// them, and adds those the catalog knows the family of. It returns the models
+// added and, by provider, the names the catalog has no entry for.
+func (m *Manager) RefreshCatalog(ctx context.Context, c *catalog.Catalog) ([]catalog.Model, map[string][]string, []error) {
+	names := m.ListProviders()
+	sort.Strings(names)
+
+	var added []catalog.Model
+	unknown := make(map[string][]string)
+	var errs []error
+	for _, name := range names {
+		provider, err := m.GetProvider(name)
+		if err != nil {
+			continue
+		}
+		refresher, ok := provider.(CatalogRefresher)
+		if !ok {
+			continue
+		}
+		models, names, err := refresher.RefreshCatalog(ctx, c)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		added = append(added, models...)
+		if len(names) > 0 {
+			unknown[name] = names
+		}
+	}
+	return added, unknown, errs
+}
@@ -13,6 +13,19 @@ type Provider interface {
 	Name() string
 }
 
+// StreamingProvider is implemented by providers that can stream a response.
+// onChunk receives each piece of content as it arrives.
+type StreamingProvider interface {
+	Provider
+	GenerateResponseStream(ctx context.Context, req Request, onChunk func(string)) (*Response, error)
+}
+
+// TokenEstimator is implemented by providers that can count the tokens of text
+// the way their model does
+type TokenEstimator interface {
+	EstimateTokens(text string) int
+}
+
 // Request represents a request to an LLM provider
 type Request struct {
 	Prompt      string                 `json:"prompt"`
@@ -43,12 +56,14 @@ type TokenUsage struct {
 
 // ModelInfo contains information about the LLM model
 type ModelInfo struct {
-	Name         string   `json:"name"`
-	Provider     string   `json:"provider"`
-	MaxTokens    int      `json:"max_tokens"`
-	ContextSize  int      `json:"context_size"`
-	CostPer1K    float64  `json:"cost_per_1k"`
-	Capabilities []string `json:"capabilities"`
+	Name            string   `json:"name"`
+	Provider        string   `json:"provider"`
+	MaxTokens       int      `json:"max_tokens"`
+	ContextSize     int      `json:"context_size"`
+	CostPer1K       float64  `json:"cost_per_1k"` // Average of the input and output prices, for when usage is not split
+	InputCostPer1K  float64  `json:"input_cost_per_1k"`
+	OutputCostPer1K float64  `json:"output_cost_per_1k"`
+	Capabilities    []string `json:"capabilities"`
 }
 
 // Config represents provider configuration
@@ -0,0 +1,397 @@
+package persona
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TraitCategories lists the configurable trait categories in authoring order
+var TraitCategories = []string{
+	"core_dimensions",
+	"communication_style",
+	"biases_and_tendencies",
+	"response_patterns",
+	"decision_making",
+	"temporal_orientation",
+	"learning_style",
+}
+
+// TraitField describes a single trait a persona author can set
+type TraitField struct {
+	Category   string          `json:"category"`
+	Name       string          `json:"name"`
+	Definition TraitDefinition `json:"definition"`
+}
+
+// LintIssue describes a problem found in a personality trait file
+type LintIssue struct {
+	File     string `json:"file"`
+	Severity string `json:"severity"` // "error" or "warning"
+	Category string `json:"category,omitempty"`
+	Trait    string `json:"trait,omitempty"`
+	Message  string `json:"message"`
+}
+
+// BaseConfig returns the base trait configuration, loading it if needed
+func (tl *TraitLoader) BaseConfig() (*BaseTraitConfig, error) {
+	if tl.baseConfig == nil {
+		if err := tl.LoadBaseConfig(); err != nil {
+			return nil, err
+		}
+	}
+	return tl.baseConfig, nil
+}
+
+// TraitFields returns every trait defined in the base configuration,
+// grouped by category and sorted by name within each category
+func (tl *TraitLoader) TraitFields() ([]TraitField, error) {
+	base, err := tl.BaseConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]TraitField, 0)
+	for _, category := range TraitCategories {
+		defs := base.definitions(category)
+
+		names := make([]string, 0, len(defs))
+		for name := range defs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fields = append(fields, TraitField{
+				Category:   category,
+				Name:       name,
+				Definition: defs[name],
+			})
+		}
+	}
+
+	return fields, nil
+}
+
+// ParseTraitInput converts user input into a trait value and validates it
+// against the field's definition. Empty input selects the default value.
+func (tl *TraitLoader) ParseTraitInput(field TraitField, input string) (TraitValue, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return normalizeTraitValue(field.Definition.Default), nil
+	}
+
+	var value TraitValue
+	switch field.Definition.Type {
+	case "scale":
+		number, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return nil, fmt.Errorf("trait %s expects a number in range %v", field.Name, field.Definition.Range)
+		}
+		value = number
+	case "enum":
+		// Allow picking an option by its 1-based position
+		if index, err := strconv.Atoi(input); err == nil && index >= 1 && index <= len(field.Definition.Options) {
+			value = field.Definition.Options[index-1]
+		} else {
+			value = input
+		}
+	default:
+		value = input
+	}
+
+	if err := tl.validateTraitValue(value, field.Definition, field.Name); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// CheckConstraints evaluates every constraint rule against the traits and
+// returns all violations. Rules that reference traits without values are skipped
+// so it can be used while a persona is only partially filled in.
+func (tl *TraitLoader) CheckConstraints(traits *PersonalityTraits) []error {
+	base, err := tl.BaseConfig()
+	if err != nil {
+		return []error{err}
+	}
+
+	violations := make([]error, 0)
+	for _, rule := range base.Constraints.TraitSumLimits.Rules {
+		complete := true
+		for _, traitName := range rule.Traits {
+			if _, exists := traits.CoreDimensions[traitName]; !exists {
+				complete = false
+				break
+			}
+		}
+		if !complete {
+			continue
+		}
+
+		if err := validateConstraintRule(traits, rule); err != nil {
+			violations = append(violations, err)
+		}
+	}
+
+	return violations
+}
+
+// BuildTraits resolves a personality configuration's parents and mixins,
+// merges it with the base configuration and validates the result
+func (tl *TraitLoader) BuildTraits(config *PersonalityConfig) (*PersonalityTraits, error) {
+	if _, err := tl.BaseConfig(); err != nil {
+		return nil, fmt.Errorf("failed to load base config: %w", err)
+	}
+
+	resolved, err := tl.resolve(config, inlineTraitsSource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve inheritance: %w", err)
+	}
+
+	traits, err := tl.buildTraits(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge traits: %w", err)
+	}
+
+	if err := tl.validateTraits(traits); err != nil {
+		return nil, fmt.Errorf("trait validation failed: %w", err)
+	}
+
+	return traits, nil
+}
+
+// LintFile checks a single personality file in the traits directory and
+// reports every problem instead of stopping at the first one
+func (tl *TraitLoader) LintFile(filename string) ([]LintIssue, error) {
+	base, err := tl.BaseConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load base config: %w", err)
+	}
+
+	issues := make([]LintIssue, 0)
+	addIssue := func(severity, category, trait, message string) {
+		issues = append(issues, LintIssue{
+			File:     filename,
+			Severity: severity,
+			Category: category,
+			Trait:    trait,
+			Message:  message,
+		})
+	}
+
+	data, err := os.ReadFile(filepath.Join(tl.configPath, "traits", filename))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read personality config: %w", err)
+	}
+
+	var config PersonalityConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		addIssue("error", "", "", fmt.Sprintf("invalid JSON: %v", err))
+		return issues, nil
+	}
+
+	if strings.TrimSpace(config.Name) == "" {
+		addIssue("warning", "", "", "persona name is empty")
+	}
+
+	for _, category := range TraitCategories {
+		defs := base.definitions(category)
+		values := config.values(category)
+
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			def, exists := defs[name]
+			if !exists {
+				addIssue("warning", category, name, "trait is not defined in base.json")
+				continue
+			}
+			if err := tl.validateTraitValue(values[name], def, name); err != nil {
+				addIssue("error", category, name, err.Error())
+			}
+		}
+	}
+
+	resolved, err := tl.resolve(&config, filename, nil)
+	if err != nil {
+		addIssue("error", "extends", "", err.Error())
+		return issues, nil
+	}
+
+	traits, err := tl.buildTraits(resolved)
+	if err != nil {
+		addIssue("error", "", "", err.Error())
+		return issues, nil
+	}
+
+	contexts := make([]string, 0, len(traits.ResponseModifiers))
+	for context := range traits.ResponseModifiers {
+		contexts = append(contexts, context)
+	}
+	sort.Strings(contexts)
+
+	for _, context := range contexts {
+		_, errs := traits.ApplyModifier(traits.ResponseModifiers[context])
+		for _, err := range errs {
+			addIssue("warning", "response_modifiers", context, err.Error())
+		}
+	}
+
+	// Values inherited from parents and mixins are checked here so that a
+	// broken mixin is reported against every file that uses it
+	for _, category := range TraitCategories {
+		defs := base.definitions(category)
+		for name, value := range traits.categoryValues(category) {
+			source := traits.TraitSource(category, name)
+			if source == filename || source == baseTraitsFile {
+				continue
+			}
+			if def, exists := defs[name]; exists {
+				if err := tl.validateTraitValue(value, def, name); err != nil {
+					addIssue("error", category, name, fmt.Sprintf("inherited from %s: %v", source, err))
+				}
+			}
+		}
+	}
+
+	for _, rule := range base.Constraints.TraitSumLimits.Rules {
+		if err := validateConstraintRule(traits, rule); err != nil {
+			addIssue("error", "constraints", strings.Join(rule.Traits, "+"), err.Error())
+		}
+	}
+
+	return issues, nil
+}
+
+// LintAll checks every personality file in the traits directory
+func (tl *TraitLoader) LintAll() ([]LintIssue, error) {
+	files, err := filepath.Glob(filepath.Join(tl.configPath, "traits", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	issues := make([]LintIssue, 0)
+	for _, file := range files {
+		filename := filepath.Base(file)
+		if filename == "base.json" {
+			continue
+		}
+
+		fileIssues, err := tl.LintFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, fileIssues...)
+	}
+
+	return issues, nil
+}
+
+// definitions returns the trait definitions for a category
+func (b *BaseTraitConfig) definitions(category string) map[string]TraitDefinition {
+	switch category {
+	case "core_dimensions":
+		return b.CoreDimensions
+	case "communication_style":
+		return b.CommunicationStyle
+	case "biases_and_tendencies":
+		return b.BiasesAndTendencies
+	case "response_patterns":
+		return b.ResponsePatterns
+	case "decision_making":
+		return b.DecisionMaking
+	case "temporal_orientation":
+		return b.TemporalOrientation
+	case "learning_style":
+		return b.LearningStyle
+	default:
+		return nil
+	}
+}
+
+// findDefinition looks up a trait definition by name in any category
+func (b *BaseTraitConfig) findDefinition(traitName string) (TraitDefinition, bool) {
+	for _, category := range TraitCategories {
+		if def, exists := b.definitions(category)[traitName]; exists {
+			return def, true
+		}
+	}
+	return TraitDefinition{}, false
+}
+
+// values returns the configured trait values for a category
+func (c *PersonalityConfig) values(category string) map[string]TraitValue {
+	switch category {
+	case "core_dimensions":
+		return c.CoreDimensions
+	case "communication_style":
+		return c.CommunicationStyle
+	case "biases_and_tendencies":
+		return c.BiasesAndTendencies
+	case "response_patterns":
+		return c.ResponsePatterns
+	case "decision_making":
+		return c.DecisionMaking
+	case "temporal_orientation":
+		return c.TemporalOrientation
+	case "learning_style":
+		return c.LearningStyle
+	case "custom_traits":
+		return c.CustomTraits
+	default:
+		return nil
+	}
+}
+
+// SetValue stores a trait value in the given category, creating the map if needed
+func (c *PersonalityConfig) SetValue(category, traitName string, value TraitValue) error {
+	target := c.values(category)
+	if target == nil {
+		target = make(map[string]TraitValue)
+		switch category {
+		case "core_dimensions":
+			c.CoreDimensions = target
+		case "communication_style":
+			c.CommunicationStyle = target
+		case "biases_and_tendencies":
+			c.BiasesAndTendencies = target
+		case "response_patterns":
+			c.ResponsePatterns = target
+		case "decision_making":
+			c.DecisionMaking = target
+		case "temporal_orientation":
+			c.TemporalOrientation = target
+		case "learning_style":
+			c.LearningStyle = target
+		case "custom_traits":
+			c.CustomTraits = target
+		default:
+			return fmt.Errorf("unknown trait category: %s", category)
+		}
+	}
+
+	target[traitName] = value
+	return nil
+}
+
+// normalizeTraitValue converts integer defaults to the float64 form used by JSON decoding
+func normalizeTraitValue(value TraitValue) TraitValue {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	default:
+		return value
+	}
+}
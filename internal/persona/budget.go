@@ -0,0 +1,348 @@
+package persona
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"personal-ai-board/internal/tokens"
+)
+
+// Budget settings for fitting prompts into a model's context window
+const (
+	budgetMarginShare  = 0.05 // Share of the context window kept free, as token counts are estimates
+	minTruncatedTokens = 32   // Context is dropped rather than truncated to fewer tokens
+	gistTokens         = 40   // Longest summary of an older conversation turn
+	lowerPriorityShare = 0.4  // Most of the tokens left that a section holds back for those after it
+)
+
+// ErrQuestionTooLong is returned when too little of the context window is
+// left to send even a truncated question
+var ErrQuestionTooLong = errors.New("question too long for context window")
+
+// PromptBudget reports how a prompt was fitted into the model's context window
+type PromptBudget struct {
+	ContextWindow  int              `json:"context_window"`
+	ResponseTokens int              `json:"response_tokens"` // Reserved for the response
+	PromptTokens   int              `json:"prompt_tokens"`   // Estimated tokens of the system message and prompt sent
+	Omitted        []OmittedContext `json:"omitted,omitempty"`
+}
+
+// OmittedContext describes context that was left out of a prompt, or
+// shortened, to fit the context window
+type OmittedContext struct {
+	Section string   `json:"section"`         // "question", "conversation", "memories" or "project"
+	Action  string   `json:"action"`          // "truncated", "summarized" or "dropped"
+	Items   int      `json:"items"`           // Turns, memories or project fields affected
+	Tokens  int      `json:"tokens"`          // Estimated tokens saved
+	Names   []string `json:"names,omitempty"` // IDs of memories or keys of project fields
+}
+
+// promptBudgeter hands out the tokens of the context window left after the
+// response, in order of priority
+type promptBudgeter struct {
+	count     func(string) int
+	limited   bool
+	remaining int
+	budget    *PromptBudget
+}
+
+// newPromptBudgeter creates a budgeter for the persona's model. Prompts are
+// not limited when the model's context window is unknown.
+func (p *Persona) newPromptBudgeter(responseTokens int) *promptBudgeter {
+	b := &promptBudgeter{count: tokens.Estimate}
+	if p.llmProvider == nil {
+		return b
+	}
+	if estimator, ok := p.llmProvider.(TokenEstimator); ok {
+		b.count = estimator.EstimateTokens
+	}
+
+	window := p.llmProvider.GetModelInfo().ContextSize
+	if window > 0 {
+		b.limited = true
+		b.remaining = window - responseTokens - int(float64(window)*budgetMarginShare)
+		b.budget = &PromptBudget{ContextWindow: window, ResponseTokens: responseTokens}
+	}
+	return b
+}
+
+// fits reports whether text fits in the tokens left
+func (b *promptBudgeter) fits(text string) bool {
+	return !b.limited || b.count(text) <= b.remaining
+}
+
+// take spends the tokens of text
+func (b *promptBudgeter) take(text string) {
+	if b.limited {
+		b.remaining -= b.count(text)
+	}
+}
+
+// reserve holds back tokens for later sections: what lines need, up to the
+// lower priority share of the tokens left. The returned function gives them
+// back.
+func (b *promptBudgeter) reserve(lines []string) func() {
+	if !b.limited || b.remaining <= 0 {
+		return func() {}
+	}
+	needed := 0
+	for _, line := range lines {
+		needed += b.count(line)
+	}
+	if max := int(float64(b.remaining) * lowerPriorityShare); needed > max {
+		needed = max
+	}
+	b.remaining -= needed
+	return func() { b.remaining += needed }
+}
+
+// omit records context that was shortened or left out
+func (b *promptBudgeter) omit(section, action string, saved int, names ...string) {
+	if b.budget == nil {
+		return
+	}
+	for i := range b.budget.Omitted {
+		omitted := &b.budget.Omitted[i]
+		if omitted.Section == section && omitted.Action == action {
+			omitted.Items++
+			omitted.Tokens += saved
+			omitted.Names = append(omitted.Names, names...)
+			return
+		}
+	}
+	b.budget.Omitted = append(b.budget.Omitted, OmittedContext{
+		Section: section,
+		Action:  action,
+		Items:   1,
+		Tokens:  saved,
+		Names:   names,
+	})
+}
+
+// fitQuestion returns the question, truncated if it does not fit even on its
+// own. It fails with ErrQuestionTooLong, recording the question as dropped,
+// when fewer than minTruncatedTokens would be left of it.
+func (b *promptBudgeter) fitQuestion(question string) (string, error) {
+	header := "## Current Question/Topic:\n\n\n"
+	if !b.limited || b.count(header)+b.count(question) <= b.remaining {
+		b.take(header)
+		b.take(question)
+		return question, nil
+	}
+
+	max := b.remaining - b.count(header)
+	if max < minTruncatedTokens {
+		b.omit("question", "dropped", b.count(question))
+		return "", fmt.Errorf("%w: %d tokens of it would fit in a window of %d", ErrQuestionTooLong, max, b.budget.ContextWindow)
+	}
+	b.take(header)
+	fitted, _ := tokens.Truncate(question, max, b.count)
+	b.take(fitted)
+	b.omit("question", "truncated", b.count(question)-b.count(fitted))
+	return fitted, nil
+}
+
+// fitConversation returns the lines of the most recent turns that fit, in
+// order. Older turns are summarized to their first sentence while there is
+// room, and dropped after that.
+func (b *promptBudgeter) fitConversation(history []ConversationTurn) []string {
+	if len(history) == 0 {
+		return nil
+	}
+	header := "## Recent Conversation:\n\n"
+	if !b.fits(header) {
+		for _, turn := range history {
+			b.omit("conversation", "dropped", b.count(turnLine(turn)))
+		}
+		return nil
+	}
+	b.take(header)
+
+	// Hold back room to summarize older turns should the full turns not fit
+	gists := make([]string, 0, len(history))
+	for _, turn := range history {
+		if gist, ok := gistLine(turn, b.count); ok {
+			gists = append(gists, gist)
+		}
+	}
+	release := func() {}
+	if !b.fits(strings.Join(allTurnLines(history), "")) {
+		release = b.reserve(gists)
+	}
+
+	lines := make([]string, len(history))
+	older := len(history)
+	for older > 0 {
+		line := turnLine(history[older-1])
+		if !b.fits(line) {
+			break
+		}
+		b.take(line)
+		lines[older-1] = line
+		older--
+	}
+	release()
+
+	// Summarize the older turns, newest first
+	for i := older - 1; i >= 0; i-- {
+		line := turnLine(history[i])
+		summary, ok := gistLine(history[i], b.count)
+		if ok && b.fits(summary) {
+			b.take(summary)
+			lines[i] = summary
+			b.omit("conversation", "summarized", b.count(line)-b.count(summary))
+		} else {
+			b.omit("conversation", "dropped", b.count(line))
+		}
+	}
+
+	fitted := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line != "" {
+			fitted = append(fitted, line)
+		}
+	}
+	return fitted
+}
+
+// fitMemories returns the memories that fit, most relevant first
+func (b *promptBudgeter) fitMemories(memories []MemoryEntry) []MemoryEntry {
+	if len(memories) == 0 {
+		return nil
+	}
+	header := "## Relevant Context from Memory:\n\n"
+	headerTaken := false
+
+	// Working memory holds copies of other memories, so the same memory can
+	// be retrieved twice
+	fitted := make([]MemoryEntry, 0, len(memories))
+	seen := make(map[string]bool, len(memories))
+	for _, memory := range memories {
+		if seen[memory.ID] {
+			continue
+		}
+		seen[memory.ID] = true
+
+		line := memoryLine(memory)
+		if !headerTaken && b.fits(header+line) {
+			b.take(header)
+			headerTaken = true
+		}
+		if headerTaken && b.fits(line) {
+			b.take(line)
+			fitted = append(fitted, memory)
+			continue
+		}
+		b.omit("memories", "dropped", b.count(line), memory.ID)
+	}
+	return fitted
+}
+
+// fitProject returns the lines of the project fields that fit, ordered by key.
+// A field too long for the tokens left is truncated when enough of it fits.
+func (b *promptBudgeter) fitProject(project map[string]interface{}) []string {
+	if len(project) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(project))
+	for key := range project {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	header := "## Project Context:\n\n"
+	headerTaken := false
+
+	var fitted []string
+	for _, key := range keys {
+		line := fmt.Sprintf("%s: %v\n", key, project[key])
+		if !headerTaken && b.fits(header) {
+			b.take(header)
+			headerTaken = true
+		}
+		if headerTaken && b.fits(line) {
+			b.take(line)
+			fitted = append(fitted, line)
+			continue
+		}
+		if headerTaken && b.remaining >= minTruncatedTokens {
+			shortened, _ := tokens.Truncate(strings.TrimSuffix(line, "\n"), b.remaining-1, b.count)
+			if shortened != "" {
+				shortened += "\n"
+				b.take(shortened)
+				fitted = append(fitted, shortened)
+				b.omit("project", "truncated", b.count(line)-b.count(shortened), key)
+				continue
+			}
+		}
+		b.omit("project", "dropped", b.count(line), key)
+	}
+	return fitted
+}
+
+// result returns the budget of the prompt that was built, or nil if the
+// prompt was not limited
+func (b *promptBudgeter) result(systemMessage, prompt string) *PromptBudget {
+	if b.budget == nil {
+		return nil
+	}
+	b.budget.PromptTokens = b.count(systemMessage) + b.count(prompt)
+	return b.budget
+}
+
+// memoryLines returns how memories appear in a prompt
+func memoryLines(memories []MemoryEntry) []string {
+	lines := make([]string, len(memories))
+	for i, memory := range memories {
+		lines[i] = memoryLine(memory)
+	}
+	return lines
+}
+
+// projectLines returns how project fields appear in a prompt
+func projectLines(project map[string]interface{}) []string {
+	lines := make([]string, 0, len(project))
+	for key, value := range project {
+		lines = append(lines, fmt.Sprintf("%s: %v\n", key, value))
+	}
+	return lines
+}
+
+// allTurnLines returns how conversation turns appear in a prompt
+func allTurnLines(history []ConversationTurn) []string {
+	lines := make([]string, len(history))
+	for i, turn := range history {
+		lines[i] = turnLine(turn)
+	}
+	return lines
+}
+
+// turnLine is how a conversation turn appears in a prompt
+func turnLine(turn ConversationTurn) string {
+	return fmt.Sprintf("%s: %s\n", turn.Speaker, turn.Content)
+}
+
+// memoryLine is how a memory appears in a prompt
+func memoryLine(memory MemoryEntry) string {
+	return fmt.Sprintf("- %s\n", memory.Content)
+}
+
+// gistLine summarizes a conversation turn by its first sentence. It reports
+// false if the turn is no longer than its summary.
+func gistLine(turn ConversationTurn, count func(string) int) (string, bool) {
+	content := strings.Join(strings.Fields(turn.Content), " ")
+	gist := content
+	for i := 0; i < len(gist)-1; i++ {
+		if strings.ContainsRune(".?!", rune(gist[i])) && gist[i+1] == ' ' {
+			gist = gist[:i+1]
+			break
+		}
+	}
+	gist, _ = tokens.Truncate(gist, gistTokens, count)
+	if gist == content {
+		return "", false
+	}
+	return fmt.Sprintf("%s (summarized): %s\n", turn.Speaker, gist), true
+}
@@ -0,0 +1,271 @@
+package persona
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"personal-ai-board/internal/tokens"
+)
+
+// limitedBudgeter hands out remaining tokens, counted with tokens.Estimate
+func limitedBudgeter(remaining int) *promptBudgeter {
+	return &promptBudgeter{count: tokens.Estimate, limited: true, remaining: remaining, budget: &PromptBudget{ContextWindow: 1000}}
+}
+
+// omitted returns what the budgeter recorded for a section and action
+func omitted(b *promptBudgeter, section, action string) (OmittedContext, bool) {
+	for _, o := range b.budget.Omitted {
+		if o.Section == section && o.Action == action {
+			return o, true
+		}
+	}
+	return OmittedContext{}, false
+}
+
+func TestFitQuestion(t *testing.T) {
+	question := strings.Repeat("How should we price the enterprise plan? ", 20)
+	header := tokens.Estimate("## Current Question/Topic:\n\n\n")
+	full := header + tokens.Estimate(question)
+
+	b := limitedBudgeter(full)
+	if fitted, err := b.fitQuestion(question); err != nil || fitted != question || len(b.budget.Omitted) != 0 || b.remaining != 0 {
+		t.Errorf("fits: %q, %v, omitted %+v, %d left", fitted, err, b.budget.Omitted, b.remaining)
+	}
+
+	b = limitedBudgeter(header + minTruncatedTokens)
+	fitted, err := b.fitQuestion(question)
+	if err != nil || fitted == question || !strings.HasPrefix(question, strings.TrimSuffix(fitted, "…")) {
+		t.Errorf("truncated: %q, %v", fitted, err)
+	}
+	if b.remaining < 0 {
+		t.Errorf("truncated: %d tokens over the window", -b.remaining)
+	}
+	if o, ok := omitted(b, "question", "truncated"); !ok || o.Tokens <= 0 {
+		t.Errorf("truncated: omitted %+v", b.budget.Omitted)
+	}
+
+	for _, remaining := range []int{header + minTruncatedTokens - 1, 0, -50} {
+		b = limitedBudgeter(remaining)
+		fitted, err := b.fitQuestion(question)
+		if !errors.Is(err, ErrQuestionTooLong) || fitted != "" {
+			t.Errorf("%d tokens left: %q, %v; want ErrQuestionTooLong", remaining, fitted, err)
+		}
+		if _, ok := omitted(b, "question", "dropped"); !ok {
+			t.Errorf("%d tokens left: omitted %+v", remaining, b.budget.Omitted)
+		}
+	}
+
+	unlimited := &promptBudgeter{count: tokens.Estimate}
+	if fitted, err := unlimited.fitQuestion(question); err != nil || fitted != question {
+		t.Errorf("unlimited: %q, %v", fitted, err)
+	}
+}
+
+func TestNewPromptBudgeterReservesCompletion(t *testing.T) {
+	p := &Persona{llmProvider: &windowProvider{window: 1000}}
+	b := p.newPromptBudgeter(200)
+	want := 1000 - 200 - int(1000*budgetMarginShare)
+	if !b.limited || b.remaining != want || b.budget.ResponseTokens != 200 || b.budget.ContextWindow != 1000 {
+		t.Errorf("remaining %d, budget %+v; want %d left", b.remaining, b.budget, want)
+	}
+
+	p = &Persona{llmProvider: &windowProvider{}}
+	if b := p.newPromptBudgeter(200); b.limited || b.budget != nil {
+		t.Error("limited without a known context window")
+	}
+}
+
+func TestReserve(t *testing.T) {
+	lines := []string{strings.Repeat("word ", 10), strings.Repeat("word ", 10)}
+	needed := tokens.Estimate(lines[0]) + tokens.Estimate(lines[1])
+
+	b := limitedBudgeter(1000)
+	release := b.reserve(lines)
+	if b.remaining != 1000-needed {
+		t.Errorf("reserved %d, want what the lines need, %d", 1000-b.remaining, needed)
+	}
+	release()
+	if b.remaining != 1000 {
+		t.Errorf("%d left after release, want 1000", b.remaining)
+	}
+
+	// No more than the lower priority share is held back
+	b = limitedBudgeter(needed)
+	release = b.reserve(lines)
+	if held := needed - b.remaining; held != int(float64(needed)*lowerPriorityShare) {
+		t.Errorf("held back %d of %d", held, needed)
+	}
+	release()
+}
+
+func TestFitConversation(t *testing.T) {
+	history := make([]ConversationTurn, 4)
+	for i := range history {
+		history[i] = ConversationTurn{
+			Speaker: fmt.Sprintf("Advisor %d", i),
+			Content: fmt.Sprintf("Point %d is short. ", i) + strings.Repeat("Then comes a long explanation of the reasoning behind it. ", 8),
+		}
+	}
+	header := tokens.Estimate("## Recent Conversation:\n\n")
+	all := header
+	for _, turn := range history {
+		all += tokens.Estimate(turnLine(turn))
+	}
+
+	b := limitedBudgeter(all)
+	if lines := b.fitConversation(history); len(lines) != 4 || len(b.budget.Omitted) != 0 {
+		t.Errorf("everything fits: %d lines, omitted %+v", len(lines), b.budget.Omitted)
+	}
+
+	// Room for the newest turns and a summary of some older ones
+	newest := tokens.Estimate(turnLine(history[3]))
+	b = limitedBudgeter(header + newest*2)
+	lines := b.fitConversation(history)
+	if b.remaining < 0 {
+		t.Errorf("%d tokens over the window", -b.remaining)
+	}
+	if len(lines) == 0 || lines[len(lines)-1] != turnLine(history[3]) {
+		t.Fatalf("newest turn not kept in full: %q", lines)
+	}
+	summarized, _ := omitted(b, "conversation", "summarized")
+	dropped, _ := omitted(b, "conversation", "dropped")
+	if summarized.Items == 0 {
+		t.Errorf("no older turn summarized: %+v", b.budget.Omitted)
+	}
+	if kept := len(lines) - summarized.Items; kept+summarized.Items+dropped.Items != len(history) {
+		t.Errorf("%d lines, omitted %+v do not account for %d turns", len(lines), b.budget.Omitted, len(history))
+	}
+	for _, line := range lines[:summarized.Items] {
+		if !strings.Contains(line, "(summarized): Point") {
+			t.Errorf("summary %q", line)
+		}
+	}
+
+	// Not even the header fits
+	b = limitedBudgeter(header - 1)
+	if lines := b.fitConversation(history); len(lines) != 0 {
+		t.Errorf("no room: %d lines", len(lines))
+	}
+	if dropped, _ := omitted(b, "conversation", "dropped"); dropped.Items != len(history) {
+		t.Errorf("no room: omitted %+v", b.budget.Omitted)
+	}
+}
+
+func TestFitMemories(t *testing.T) {
+	memories := []MemoryEntry{
+		{ID: "m1", Content: "The pricing page converts at two percent"},
+		{ID: "m1", Content: "The pricing page converts at two percent"},
+		{ID: "m2", Content: strings.Repeat("A long account of last year's failed launch. ", 10)},
+		{ID: "m3", Content: "Churn is highest in the first month"},
+	}
+	header := tokens.Estimate("## Relevant Context from Memory:\n\n")
+	first := tokens.Estimate(memoryLine(memories[0]))
+	last := tokens.Estimate(memoryLine(memories[3]))
+
+	b := limitedBudgeter(header + first + last)
+	fitted := b.fitMemories(memories)
+	if len(fitted) != 2 || fitted[0].ID != "m1" || fitted[1].ID != "m3" || b.remaining != 0 {
+		t.Errorf("fitted %+v with %d left", fitted, b.remaining)
+	}
+	if dropped, ok := omitted(b, "memories", "dropped"); !ok || dropped.Items != 1 || dropped.Names[0] != "m2" {
+		t.Errorf("omitted %+v", b.budget.Omitted)
+	}
+
+	// Without room for a memory, the header is not spent either
+	b = limitedBudgeter(header)
+	if fitted := b.fitMemories(memories); len(fitted) != 0 || b.remaining != header {
+		t.Errorf("no room: fitted %d with %d left", len(fitted), b.remaining)
+	}
+}
+
+func TestFitProject(t *testing.T) {
+	project := map[string]interface{}{
+		"budget":  "50k",
+		"summary": strings.Repeat("A marketplace for second-hand climbing gear. ", 20),
+		"stage":   "seed",
+	}
+	header := tokens.Estimate("## Project Context:\n\n")
+	short := tokens.Estimate("budget: 50k\n") + tokens.Estimate("stage: seed\n")
+
+	b := limitedBudgeter(header + short + minTruncatedTokens + 1)
+	lines := b.fitProject(project)
+	if len(lines) != 3 || lines[0] != "budget: 50k\n" || lines[1] != "stage: seed\n" || !strings.HasPrefix(lines[2], "summary: A marketplace") {
+		t.Fatalf("lines %q", lines)
+	}
+	if b.remaining < 0 {
+		t.Errorf("%d tokens over the window", -b.remaining)
+	}
+	if truncated, ok := omitted(b, "project", "truncated"); !ok || truncated.Names[0] != "summary" {
+		t.Errorf("omitted %+v", b.budget.Omitted)
+	}
+
+	b = limitedBudgeter(header + short + minTruncatedTokens - 1)
+	lines = b.fitProject(project)
+	if len(lines) != 2 {
+		t.Errorf("too little room to truncate: lines %q", lines)
+	}
+	if dropped, ok := omitted(b, "project", "dropped"); !ok || dropped.Names[0] != "summary" {
+		t.Errorf("omitted %+v", b.budget.Omitted)
+	}
+}
+
+func TestThinkQuestionTooLong(t *testing.T) {
+	traits, err := NewTraitLoader("../../config").BuildTraits(&PersonalityConfig{Extends: "base", PersonaType: "custom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	question := strings.Repeat("Why would anyone pay for this? ", 1000)
+
+	// The completion reserve leaves no room for the question
+	provider := &windowProvider{window: 600}
+	p, err := NewWithTraits("p1", "Skeptical CFO", "a careful investor", traits, nil, provider, nopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Think(context.Background(), question, ThinkingContext{}); !errors.Is(err, ErrQuestionTooLong) {
+		t.Fatalf("err = %v, want ErrQuestionTooLong", err)
+	}
+	if provider.calls != 0 {
+		t.Errorf("the LLM was asked %d times", provider.calls)
+	}
+
+	// With room for part of it, the question is cut to fit
+	provider = &windowProvider{window: 4000}
+	p, err = NewWithTraits("p1", "Skeptical CFO", "a careful investor", traits, nil, provider, nopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := p.Think(context.Background(), question, ThinkingContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider.calls != 1 || result.Budget == nil || result.Budget.PromptTokens+result.Budget.ResponseTokens > 4000 {
+		t.Errorf("calls %d, budget %+v", provider.calls, result.Budget)
+	}
+}
+
+// windowProvider answers every request, reporting a model with the context
+// window given
+type windowProvider struct {
+	window int
+	calls  int
+}
+
+func (w *windowProvider) GenerateResponse(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	w.calls++
+	return &LLMResponse{Content: "Key insight: check the numbers.", TokensUsed: 10}, nil
+}
+
+func (w *windowProvider) GetModelInfo() ModelInfo {
+	return ModelInfo{Name: "test", ContextSize: w.window}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Warn(string, ...interface{})  {}
@@ -0,0 +1,327 @@
+package persona
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Generator creates personality configurations from natural-language descriptions
+type Generator struct {
+	loader      *TraitLoader
+	llmProvider LLMProvider
+	logger      Logger
+}
+
+// GenerationResult holds a generated personality and any repairs made to it
+type GenerationResult struct {
+	Config  *PersonalityConfig `json:"config"`
+	Traits  *PersonalityTraits `json:"-"`
+	Repairs []string           `json:"repairs"`
+	Raw     string             `json:"raw"`
+}
+
+// NewGenerator creates a new persona generator
+func NewGenerator(loader *TraitLoader, llmProvider LLMProvider, logger Logger) *Generator {
+	return &Generator{
+		loader:      loader,
+		llmProvider: llmProvider,
+		logger:      logger,
+	}
+}
+
+// Generate asks the LLM to describe a persona matching the description, then
+// repairs and validates the result against the base trait configuration
+func (g *Generator) Generate(ctx context.Context, description string) (*GenerationResult, error) {
+	if strings.TrimSpace(description) == "" {
+		return nil, fmt.Errorf("description cannot be empty")
+	}
+
+	base, err := g.loader.BaseConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load base config: %w", err)
+	}
+
+	req := LLMRequest{
+		Prompt:      g.buildPrompt(base, description),
+		SystemMsg:   "You design personality profiles for AI advisors. You answer with a single JSON object and nothing else.",
+		Temperature: 0.4,
+		MaxTokens:   2500,
+	}
+
+	g.logger.Debug("Generating persona from description", "description_length", len(description))
+
+	resp, err := g.llmProvider.GenerateResponse(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("LLM generation failed: %w", err)
+	}
+
+	config, err := parseGeneratedConfig(resp.Content)
+	if err != nil {
+		return nil, err
+	}
+	config.Extends = "base"
+
+	repairs := g.loader.RepairConfig(config)
+	for _, repair := range repairs {
+		g.logger.Debug("Repaired generated trait", "repair", repair)
+	}
+
+	traits, err := g.loader.BuildTraits(config)
+	if err != nil {
+		return nil, fmt.Errorf("generated persona is still invalid after repair: %w", err)
+	}
+
+	return &GenerationResult{
+		Config:  config,
+		Traits:  traits,
+		Repairs: repairs,
+		Raw:     resp.Content,
+	}, nil
+}
+
+// buildPrompt describes every trait and constraint so the LLM can fill them in
+func (g *Generator) buildPrompt(base *BaseTraitConfig, description string) string {
+	var b strings.Builder
+
+	b.WriteString("Create a personality profile for this advisor:\n\n")
+	b.WriteString(description)
+	b.WriteString("\n\n## Traits to fill in\n")
+
+	for _, category := range TraitCategories {
+		defs := base.definitions(category)
+		names := make([]string, 0, len(defs))
+		for name := range defs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		b.WriteString(fmt.Sprintf("\n%s:\n", category))
+		for _, name := range names {
+			def := defs[name]
+			switch def.Type {
+			case "scale":
+				b.WriteString(fmt.Sprintf("- %s (integer %d-%d): %s\n", name, def.Range[0], def.Range[1], def.Description))
+			case "enum":
+				b.WriteString(fmt.Sprintf("- %s (one of %s): %s\n", name, strings.Join(def.Options, ", "), def.Description))
+			default:
+				b.WriteString(fmt.Sprintf("- %s: %s\n", name, def.Description))
+			}
+		}
+	}
+
+	if rules := base.Constraints.TraitSumLimits.Rules; len(rules) > 0 {
+		b.WriteString("\n## Constraints\n")
+		for _, rule := range rules {
+			b.WriteString(fmt.Sprintf("- %s: sum of %s", rule.Description, strings.Join(rule.Traits, " + ")))
+			if rule.MinTotal > 0 {
+				b.WriteString(fmt.Sprintf(" at least %d", rule.MinTotal))
+			}
+			if rule.MaxTotal > 0 {
+				b.WriteString(fmt.Sprintf(" at most %d", rule.MaxTotal))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(`
+## Output format
+Respond with one JSON object using exactly these keys:
+{
+  "persona_type": "snake_case_type",
+  "name": "Short memorable name",
+  "description": "One sentence description",
+  "core_dimensions": {"trait": 5},
+  "communication_style": {"trait": "option or number"},
+  "expertise_areas": ["area"],
+  "biases_and_tendencies": {"trait": 5},
+  "response_patterns": {"trait": 5},
+  "decision_making": {"trait": 5},
+  "temporal_orientation": {"trait": 5},
+  "learning_style": {"trait": 5},
+  "speaking_patterns": {"common_phrases": ["..."], "avoids_phrases": ["..."]},
+  "emotional_triggers": {"energizers": ["..."], "frustrations": ["..."]},
+  "response_modifiers": {"when_stressed": {"trait": "value"}}
+}
+`)
+
+	return b.String()
+}
+
+// parseGeneratedConfig extracts the JSON object from an LLM response
+func parseGeneratedConfig(content string) (*PersonalityConfig, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("LLM response does not contain a JSON object")
+	}
+
+	var config PersonalityConfig
+	if err := json.Unmarshal([]byte(content[start:end+1]), &config); err != nil {
+		return nil, fmt.Errorf("failed to parse generated persona: %w", err)
+	}
+
+	return &config, nil
+}
+
+// RepairConfig coerces trait values into their defined types, clamps them to
+// their ranges, drops unknown traits and rebalances constraint violations.
+// It returns a description of every change made.
+func (tl *TraitLoader) RepairConfig(config *PersonalityConfig) []string {
+	base, err := tl.BaseConfig()
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	repairs := make([]string, 0)
+
+	for _, category := range TraitCategories {
+		defs := base.definitions(category)
+		values := config.values(category)
+
+		for name, value := range values {
+			def, exists := defs[name]
+			if !exists {
+				delete(values, name)
+				repairs = append(repairs, fmt.Sprintf("%s.%s: removed unknown trait", category, name))
+				continue
+			}
+
+			repaired, note := repairTraitValue(value, def)
+			if note != "" {
+				values[name] = repaired
+				repairs = append(repairs, fmt.Sprintf("%s.%s: %s", category, name, note))
+			}
+		}
+	}
+
+	for _, rule := range base.Constraints.TraitSumLimits.Rules {
+		repairs = append(repairs, rebalanceConstraint(config, base, rule)...)
+	}
+
+	if strings.TrimSpace(config.Name) == "" {
+		config.Name = "Generated Advisor"
+		repairs = append(repairs, "name: missing, using default")
+	}
+
+	return repairs
+}
+
+// repairTraitValue returns a valid value for a definition and a note when it changed
+func repairTraitValue(value TraitValue, def TraitDefinition) (TraitValue, string) {
+	switch def.Type {
+	case "scale":
+		var number float64
+		switch v := value.(type) {
+		case float64:
+			number = v
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return normalizeTraitValue(def.Default), fmt.Sprintf("non-numeric value %q replaced with default", v)
+			}
+			number = parsed
+		default:
+			return normalizeTraitValue(def.Default), fmt.Sprintf("invalid value %v replaced with default", value)
+		}
+
+		clamped := math.Round(number)
+		if len(def.Range) >= 2 {
+			clamped = math.Max(float64(def.Range[0]), math.Min(float64(def.Range[1]), clamped))
+		}
+		if _, isNumber := value.(float64); isNumber && clamped == number {
+			return value, ""
+		}
+		return clamped, fmt.Sprintf("%v adjusted to %v", value, clamped)
+
+	case "enum":
+		str, ok := value.(string)
+		if !ok {
+			return normalizeTraitValue(def.Default), fmt.Sprintf("invalid value %v replaced with default", value)
+		}
+		for _, option := range def.Options {
+			if str == option {
+				return value, ""
+			}
+		}
+		normalized := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(str)), " ", "_")
+		for _, option := range def.Options {
+			if normalized == option {
+				return option, fmt.Sprintf("%q normalized to %q", str, option)
+			}
+		}
+		return normalizeTraitValue(def.Default), fmt.Sprintf("unknown option %q replaced with default", str)
+	}
+
+	return value, ""
+}
+
+// rebalanceConstraint adjusts the traits of a sum rule until the rule holds,
+// lowering the highest trait or raising the lowest one a point at a time
+func rebalanceConstraint(config *PersonalityConfig, base *BaseTraitConfig, rule ConstraintRule) []string {
+	if config.CoreDimensions == nil {
+		config.CoreDimensions = make(map[string]TraitValue)
+	}
+
+	current := func(name string) float64 {
+		if value, ok := config.CoreDimensions[name].(float64); ok {
+			return value
+		}
+		if def, ok := base.CoreDimensions[name]; ok {
+			if value, ok := normalizeTraitValue(def.Default).(float64); ok {
+				return value
+			}
+		}
+		return 0
+	}
+	limits := func(name string) (float64, float64) {
+		if def, ok := base.CoreDimensions[name]; ok && len(def.Range) >= 2 {
+			return float64(def.Range[0]), float64(def.Range[1])
+		}
+		return 1, 10
+	}
+	total := func() int {
+		sum := 0.0
+		for _, name := range rule.Traits {
+			sum += current(name)
+		}
+		return int(sum)
+	}
+
+	before := total()
+	for rule.MaxTotal > 0 && total() > rule.MaxTotal {
+		highest := ""
+		for _, name := range rule.Traits {
+			low, _ := limits(name)
+			if current(name) > low && (highest == "" || current(name) > current(highest)) {
+				highest = name
+			}
+		}
+		if highest == "" {
+			break
+		}
+		config.CoreDimensions[highest] = current(highest) - 1
+	}
+	for rule.MinTotal > 0 && total() < rule.MinTotal {
+		lowest := ""
+		for _, name := range rule.Traits {
+			_, high := limits(name)
+			if current(name) < high && (lowest == "" || current(name) < current(lowest)) {
+				lowest = name
+			}
+		}
+		if lowest == "" {
+			break
+		}
+		config.CoreDimensions[lowest] = current(lowest) + 1
+	}
+
+	if after := total(); after != before {
+		return []string{fmt.Sprintf("constraints: %s - total %d rebalanced to %d", rule.Description, before, after)}
+	}
+	return nil
+}
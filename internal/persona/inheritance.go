@@ -0,0 +1,313 @@
+package persona
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// baseTraitsFile is the root of every inheritance chain
+	baseTraitsFile = "base.json"
+	// inlineTraitsSource labels values that did not come from a traits file
+	inlineTraitsSource = "inline"
+	// mixinsDir holds reusable trait bundles, relative to the traits directory
+	mixinsDir = "mixins"
+)
+
+// allTraitCategories lists every category that holds trait values
+var allTraitCategories = append(append([]string{}, TraitCategories...), "custom_traits")
+
+// resolvedConfig is a personality configuration flattened across its
+// inheritance chain and mixins, with the file each value came from
+type resolvedConfig struct {
+	config  *PersonalityConfig
+	sources map[string]string
+	lineage []string
+}
+
+// ResolveConfig loads a personality file and flattens its inheritance chain and
+// mixins into a single configuration
+func (tl *TraitLoader) ResolveConfig(filename string) (*PersonalityConfig, error) {
+	name, err := traitFileName(filename)
+	if err != nil {
+		return nil, err
+	}
+	config, err := tl.readConfigFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := tl.resolve(config, name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolved.config, nil
+}
+
+// ResolveConfigFile is ResolveConfig for a personality file anywhere on disk.
+// The files it extends and its mixins are looked up in the traits directory.
+func (tl *TraitLoader) ResolveConfigFile(path string) (*PersonalityConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read personality config %s: %w", path, err)
+	}
+	config, err := parseConfig(data, path)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := tl.resolve(config, filepath.Base(path), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolved.config, nil
+}
+
+// TraitsDir returns the directory trait files are loaded from
+func (tl *TraitLoader) TraitsDir() string {
+	return filepath.Join(tl.configPath, "traits")
+}
+
+// readConfigFile parses a personality file relative to the traits directory.
+// name must have come from traitFileName.
+func (tl *TraitLoader) readConfigFile(name string) (*PersonalityConfig, error) {
+	dir := tl.TraitsDir()
+	path := filepath.Join(dir, name)
+	if rel, err := filepath.Rel(dir, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("personality config %s is outside %s", name, dir)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read personality config %s: %w", name, err)
+	}
+	return parseConfig(data, name)
+}
+
+// parseConfig parses the contents of a personality file
+func parseConfig(data []byte, name string) (*PersonalityConfig, error) {
+	var config PersonalityConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse personality config %s: %w", name, err)
+	}
+
+	return &config, nil
+}
+
+// resolve flattens a configuration on top of its parents and mixins. The stack
+// holds the files already being resolved and is used to detect cycles.
+func (tl *TraitLoader) resolve(config *PersonalityConfig, source string, stack []string) (*resolvedConfig, error) {
+	for i, visited := range stack {
+		if visited == source {
+			cycle := append(append([]string{}, stack[i:]...), source)
+			return nil, fmt.Errorf("inheritance cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	stack = append(stack, source)
+
+	var result *resolvedConfig
+	if isBaseReference(config.Extends) {
+		result = &resolvedConfig{
+			config:  &PersonalityConfig{},
+			sources: make(map[string]string),
+			lineage: []string{baseTraitsFile},
+		}
+	} else {
+		parentFile, err := traitFileName(config.Extends)
+		if err != nil {
+			return nil, fmt.Errorf("%s extends %s: %w", source, config.Extends, err)
+		}
+		parent, err := tl.readConfigFile(parentFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s extends %s: %w", source, config.Extends, err)
+		}
+
+		result, err = tl.resolve(parent, parentFile, stack)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, mixin := range config.Mixins {
+		name, err := traitFileName(mixin)
+		if err != nil || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("%s uses mixin %s: mixins are named by their file in %s", source, mixin, mixinsDir)
+		}
+		mixinFile := mixinsDir + "/" + name
+		mixinConfig, err := tl.readConfigFile(mixinFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s uses mixin %s: %w", source, mixin, err)
+		}
+		if !isBaseReference(mixinConfig.Extends) || len(mixinConfig.Mixins) > 0 {
+			return nil, fmt.Errorf("mixin %s cannot extend other files or use mixins", mixinFile)
+		}
+		result.layer(mixinConfig, mixinFile)
+	}
+
+	result.layer(config, source)
+	result.config.Extends = config.Extends
+	result.config.Mixins = config.Mixins
+
+	return result, nil
+}
+
+// layer applies a configuration on top of the values resolved so far
+func (r *resolvedConfig) layer(config *PersonalityConfig, source string) {
+	target := r.config
+
+	for _, category := range allTraitCategories {
+		for name, value := range config.values(category) {
+			target.SetValue(category, name, value)
+			r.sources[category+"."+name] = source
+		}
+	}
+
+	if config.PersonaType != "" {
+		target.PersonaType = config.PersonaType
+	}
+	if config.Name != "" {
+		target.Name = config.Name
+	}
+	if config.Description != "" {
+		target.Description = config.Description
+	}
+
+	target.ExpertiseAreas = appendUnique(target.ExpertiseAreas, config.ExpertiseAreas...)
+
+	patterns := &target.SpeakingPatterns
+	patterns.CommonPhrases = appendUnique(patterns.CommonPhrases, config.SpeakingPatterns.CommonPhrases...)
+	patterns.AvoidsPhrases = appendUnique(patterns.AvoidsPhrases, config.SpeakingPatterns.AvoidsPhrases...)
+	patterns.FavoriteAnalogies = appendUnique(patterns.FavoriteAnalogies, config.SpeakingPatterns.FavoriteAnalogies...)
+	patterns.FavoriteFrameworks = appendUnique(patterns.FavoriteFrameworks, config.SpeakingPatterns.FavoriteFrameworks...)
+	patterns.CreativeTechniques = appendUnique(patterns.CreativeTechniques, config.SpeakingPatterns.CreativeTechniques...)
+
+	target.EmotionalTriggers.Energizers = appendUnique(target.EmotionalTriggers.Energizers, config.EmotionalTriggers.Energizers...)
+	target.EmotionalTriggers.Frustrations = appendUnique(target.EmotionalTriggers.Frustrations, config.EmotionalTriggers.Frustrations...)
+
+	for context, modifier := range config.ResponseModifiers {
+		if target.ResponseModifiers == nil {
+			target.ResponseModifiers = make(map[string]TraitModifier)
+		}
+		merged := make(TraitModifier)
+		for name, value := range target.ResponseModifiers[context] {
+			merged[name] = value
+		}
+		for name, value := range modifier {
+			merged[name] = value
+		}
+		target.ResponseModifiers[context] = merged
+	}
+
+	if config.Memory != nil {
+		var settings MemorySettings
+		if target.Memory != nil {
+			settings = *target.Memory
+		}
+		settings = settings.Merge(config.Memory)
+		target.Memory = &settings
+	}
+
+	r.lineage = append(r.lineage, source)
+}
+
+// buildTraits merges a resolved configuration with the base definitions and
+// records where every effective value came from
+func (tl *TraitLoader) buildTraits(resolved *resolvedConfig) (*PersonalityTraits, error) {
+	traits, err := tl.mergeTraits(resolved.config)
+	if err != nil {
+		return nil, err
+	}
+
+	traits.Sources = make(map[string]string)
+	for _, category := range allTraitCategories {
+		for name := range traits.categoryValues(category) {
+			key := category + "." + name
+			if source, ok := resolved.sources[key]; ok {
+				traits.Sources[key] = source
+			} else {
+				traits.Sources[key] = baseTraitsFile
+			}
+		}
+	}
+	traits.Lineage = resolved.lineage
+
+	return traits, nil
+}
+
+// TraitSource returns the file an effective trait value came from
+func (pt *PersonalityTraits) TraitSource(category, traitName string) string {
+	if source, ok := pt.Sources[category+"."+traitName]; ok {
+		return source
+	}
+	return "unknown"
+}
+
+// TraitProvenance returns every effective trait value with its source file,
+// grouped by category
+func (pt *PersonalityTraits) TraitProvenance() map[string]map[string]interface{} {
+	provenance := make(map[string]map[string]interface{})
+
+	for _, category := range allTraitCategories {
+		values := pt.categoryValues(category)
+		if len(values) == 0 {
+			continue
+		}
+
+		entries := make(map[string]interface{}, len(values))
+		for name := range values {
+			entries[name] = map[string]interface{}{
+				"value":  values[name],
+				"source": pt.TraitSource(category, name),
+			}
+		}
+		provenance[category] = entries
+	}
+
+	return provenance
+}
+
+// isBaseReference reports whether an extends value refers to base.json
+func isBaseReference(extends string) bool {
+	name, err := traitFileName(extends)
+	return extends == "" || (err == nil && name == baseTraitsFile)
+}
+
+// traitFileName turns a trait file reference, such as a persona's extends or
+// mixins, into a file name relative to the traits directory, adding the .json
+// extension if missing. References name a file in the traits directory or its
+// mixins directory, so that trait files from the API or an LLM cannot read
+// other files.
+func traitFileName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if !strings.HasSuffix(name, ".json") {
+		name += ".json"
+	}
+
+	file := strings.TrimPrefix(name, mixinsDir+"/")
+	if file == ".json" || strings.Contains(file, "..") || strings.ContainsAny(file, `/\`) || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("invalid trait file %q: use the name of a file in the traits directory", name)
+	}
+	return name, nil
+}
+
+// appendUnique appends the values not already present in list
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range list {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
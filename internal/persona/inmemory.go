@@ -0,0 +1,228 @@
+package persona
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// InMemoryStorage is a Repository that keeps personas in memory. It behaves
+// like Storage, so that code using personas can be tested without SQLite.
+type InMemoryStorage struct {
+	mu           sync.RWMutex
+	personas     map[string]*storedPersona
+	interactions []InteractionLog
+}
+
+// storedPersona is a persona as InMemoryStorage keeps it, serialized the way
+// Storage writes it to the database
+type storedPersona struct {
+	info   PersonaInfo
+	traits []byte
+	memory []byte
+}
+
+// NewInMemoryStorage creates an empty in-memory persona store
+func NewInMemoryStorage() *InMemoryStorage {
+	return &InMemoryStorage{personas: make(map[string]*storedPersona)}
+}
+
+// SavePersona saves a persona, keeping the creation time of one already saved
+func (s *InMemoryStorage) SavePersona(persona *Persona) error {
+	traitsData, err := json.Marshal(persona.CurrentTraits())
+	if err != nil {
+		return fmt.Errorf("failed to serialize traits: %w", err)
+	}
+	memoryData, err := persona.memoryMgr.ExportMemory()
+	if err != nil {
+		return fmt.Errorf("failed to export memory: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := &storedPersona{
+		info: PersonaInfo{
+			ID:          persona.ID,
+			Name:        persona.Name,
+			Description: persona.Description,
+			CreatedAt:   persona.createdAt,
+			UpdatedAt:   time.Now(),
+		},
+		traits: traitsData,
+		memory: memoryData,
+	}
+	if existing, ok := s.personas[persona.ID]; ok {
+		stored.info.CreatedAt = existing.info.CreatedAt
+	}
+	s.personas[persona.ID] = stored
+
+	return nil
+}
+
+// LoadPersona loads a saved persona
+func (s *InMemoryStorage) LoadPersona(id string, llmProvider LLMProvider, logger Logger) (*Persona, error) {
+	s.mu.RLock()
+	stored, ok := s.personas[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("failed to load persona: %w", sql.ErrNoRows)
+	}
+
+	return restorePersona(stored.info, stored.traits, stored.memory, s, llmProvider, logger)
+}
+
+// ListPersonas returns all personas, most recently updated first
+func (s *InMemoryStorage) ListPersonas() ([]PersonaInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	personas := make([]PersonaInfo, 0, len(s.personas))
+	for _, stored := range s.personas {
+		personas = append(personas, stored.info)
+	}
+	sort.Slice(personas, func(i, j int) bool {
+		return personas[i].UpdatedAt.After(personas[j].UpdatedAt)
+	})
+
+	return personas, nil
+}
+
+// PagePersonas returns a page of personas, most recently updated first
+func (s *InMemoryStorage) PagePersonas(limit, offset int) ([]PersonaInfo, error) {
+	personas, err := s.ListPersonas()
+	if err != nil {
+		return nil, err
+	}
+	start, end := pageBounds(len(personas), limit, offset)
+	return personas[start:end], nil
+}
+
+// CountPersonas returns the number of personas
+func (s *InMemoryStorage) CountPersonas() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.personas), nil
+}
+
+// DeletePersona removes a persona and its interaction logs
+func (s *InMemoryStorage) DeletePersona(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.personas, id)
+
+	kept := s.interactions[:0]
+	for _, log := range s.interactions {
+		if log.PersonaID != id {
+			kept = append(kept, log)
+		}
+	}
+	s.interactions = kept
+
+	return nil
+}
+
+// SaveMemory replaces the stored memory of a persona
+func (s *InMemoryStorage) SaveMemory(personaID string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.personas[personaID]
+	if !ok {
+		return fmt.Errorf("failed to save memory of persona %s: %w", personaID, sql.ErrNoRows)
+	}
+	stored.memory = append([]byte(nil), data...)
+	stored.info.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// LoadMemory returns the stored memory of a persona
+func (s *InMemoryStorage) LoadMemory(personaID string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored, ok := s.personas[personaID]
+	if !ok {
+		return nil, fmt.Errorf("failed to load memory: %w", sql.ErrNoRows)
+	}
+	return append([]byte(nil), stored.memory...), nil
+}
+
+// LogInteraction records an LLM interaction. An empty ID or creation time is
+// filled in.
+func (s *InMemoryStorage) LogInteraction(log *InteractionLog) error {
+	if log.ID == "" {
+		log.ID = fmt.Sprintf("%s_%d", log.PersonaID, time.Now().UnixNano())
+	}
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = time.Now()
+	}
+
+	// Round-trip the context through JSON as the database does
+	stored := *log
+	if log.Context != nil {
+		data, err := json.Marshal(log.Context)
+		if err != nil {
+			return fmt.Errorf("failed to serialize interaction context: %w", err)
+		}
+		stored.Context = nil
+		if err := json.Unmarshal(data, &stored.Context); err != nil {
+			return fmt.Errorf("failed to serialize interaction context: %w", err)
+		}
+	}
+	stored.Duration = log.Duration.Truncate(time.Millisecond)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.interactions {
+		if existing.ID == log.ID {
+			return fmt.Errorf("failed to log interaction: %s already exists", log.ID)
+		}
+	}
+	s.interactions = append(s.interactions, stored)
+
+	return nil
+}
+
+// ListInteractions returns a persona's logged interactions, newest first. A
+// limit of zero returns them all.
+func (s *InMemoryStorage) ListInteractions(personaID string, limit int) ([]InteractionLog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	logs := make([]InteractionLog, 0)
+	for _, log := range s.interactions {
+		if log.PersonaID == personaID {
+			logs = append(logs, log)
+		}
+	}
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].CreatedAt.After(logs[j].CreatedAt)
+	})
+	if limit > 0 && len(logs) > limit {
+		logs = logs[:limit]
+	}
+
+	return logs, nil
+}
+
+// pageBounds returns the range of indexes of a page of n items. A limit of
+// zero takes the rest of the items from offset.
+func pageBounds(n, limit, offset int) (int, int) {
+	start := offset
+	if start > n {
+		start = n
+	}
+	end := n
+	if limit > 0 && start+limit < n {
+		end = start + limit
+	}
+	return start, end
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -45,22 +46,80 @@ type Memory struct {
 	DecayRate      float64 `json:"decay_rate"`       // Memory decay rate
 }
 
+// MemorySettings controls the size of each memory tier and how fast memories fade
+type MemorySettings struct {
+	ShortTermLimit int     `json:"short_term_limit,omitempty"` // Max short-term memories
+	LongTermLimit  int     `json:"long_term_limit,omitempty"`  // Max long-term memories
+	DecayRate      float64 `json:"decay_rate,omitempty"`       // Memory decay rate
+}
+
+// DefaultMemorySettings returns the built-in memory tier limits
+func DefaultMemorySettings() MemorySettings {
+	return MemorySettings{
+		ShortTermLimit: 50,   // Keep last 50 interactions in short-term
+		LongTermLimit:  200,  // Keep up to 200 consolidated long-term memories
+		DecayRate:      0.95, // Memory strength decays by 5% over time
+	}
+}
+
+var (
+	defaultMemorySettingsMu sync.RWMutex
+	defaultMemorySettings   = DefaultMemorySettings()
+)
+
+// SetDefaultMemorySettings sets the memory settings used by newly created personas.
+// It is normally called once at startup with values from the application config.
+func SetDefaultMemorySettings(settings MemorySettings) {
+	defaultMemorySettingsMu.Lock()
+	defer defaultMemorySettingsMu.Unlock()
+	defaultMemorySettings = DefaultMemorySettings().Merge(&settings)
+}
+
+// CurrentMemorySettings returns the configured default memory settings
+func CurrentMemorySettings() MemorySettings {
+	defaultMemorySettingsMu.RLock()
+	defer defaultMemorySettingsMu.RUnlock()
+	return defaultMemorySettings
+}
+
+// Merge returns a copy of the settings with non-zero override values applied
+func (s MemorySettings) Merge(override *MemorySettings) MemorySettings {
+	if override == nil {
+		return s
+	}
+	if override.ShortTermLimit > 0 {
+		s.ShortTermLimit = override.ShortTermLimit
+	}
+	if override.LongTermLimit > 0 {
+		s.LongTermLimit = override.LongTermLimit
+	}
+	if override.DecayRate > 0 && override.DecayRate <= 1 {
+		s.DecayRate = override.DecayRate
+	}
+	return s
+}
+
 // MemoryManager handles memory operations and consolidation
 type MemoryManager struct {
 	memory *Memory
 }
 
-// NewMemory creates a new memory instance for a persona
+// NewMemory creates a new memory instance for a persona using the configured defaults
 func NewMemory(personaID string) *Memory {
+	return NewMemoryWithSettings(personaID, CurrentMemorySettings())
+}
+
+// NewMemoryWithSettings creates a new memory instance with explicit tier limits
+func NewMemoryWithSettings(personaID string, settings MemorySettings) *Memory {
 	return &Memory{
 		PersonaID:      personaID,
 		Context:        make(map[string]interface{}),
 		ShortTerm:      make([]MemoryEntry, 0),
 		LongTerm:       make([]MemoryEntry, 0),
 		WorkingMemory:  make([]MemoryEntry, 0),
-		ShortTermLimit: 50,  // Keep last 50 interactions in short-term
-		LongTermLimit:  200, // Keep up to 200 consolidated long-term memories
-		DecayRate:      0.95, // Memory strength decays by 5% over time
+		ShortTermLimit: settings.ShortTermLimit,
+		LongTermLimit:  settings.LongTermLimit,
+		DecayRate:      settings.DecayRate,
 	}
 }
 
@@ -143,6 +202,18 @@ func (mm *MemoryManager) RetrieveRelevant(prompt string, limit int) []MemoryEntr
 	return result
 }
 
+// Find returns the memory with the given ID from any memory tier
+func (mm *MemoryManager) Find(id string) (MemoryEntry, bool) {
+	for _, tier := range [][]MemoryEntry{mm.memory.WorkingMemory, mm.memory.ShortTerm, mm.memory.LongTerm} {
+		for _, memory := range tier {
+			if memory.ID == id {
+				return memory, true
+			}
+		}
+	}
+	return MemoryEntry{}, false
+}
+
 // calculateRelevanceScore computes how relevant a memory is to the current prompt
 func (mm *MemoryManager) calculateRelevanceScore(memory MemoryEntry, promptLower string, promptWords []string) float64 {
 	score := 0.0
@@ -232,20 +303,26 @@ func (mm *MemoryManager) consolidateMemories() {
 	mm.memory.LongTerm = append(mm.memory.LongTerm, consolidated...)
 	
 	// Trim long-term memory if needed
-	if len(mm.memory.LongTerm) > mm.memory.LongTermLimit {
-		// Keep most important memories
-		sort.Slice(mm.memory.LongTerm, func(i, j int) bool {
-			scoreI := mm.memory.LongTerm[i].Weight * mm.memory.LongTerm[i].Decay
-			scoreJ := mm.memory.LongTerm[j].Weight * mm.memory.LongTerm[j].Decay
-			return scoreI > scoreJ
-		})
-		mm.memory.LongTerm = mm.memory.LongTerm[:mm.memory.LongTermLimit]
-	}
+	mm.trimLongTerm()
 	
 	// Apply memory decay
 	mm.applyMemoryDecay()
 }
 
+// trimLongTerm keeps only the most important long-term memories within the limit
+func (mm *MemoryManager) trimLongTerm() {
+	if len(mm.memory.LongTerm) <= mm.memory.LongTermLimit {
+		return
+	}
+
+	sort.Slice(mm.memory.LongTerm, func(i, j int) bool {
+		scoreI := mm.memory.LongTerm[i].Weight * mm.memory.LongTerm[i].Decay
+		scoreJ := mm.memory.LongTerm[j].Weight * mm.memory.LongTerm[j].Decay
+		return scoreI > scoreJ
+	})
+	mm.memory.LongTerm = mm.memory.LongTerm[:mm.memory.LongTermLimit]
+}
+
 // consolidateSimilarMemories merges similar memories to reduce redundancy
 func (mm *MemoryManager) consolidateSimilarMemories(memories []MemoryEntry) []MemoryEntry {
 	if len(memories) == 0 {
@@ -508,7 +585,34 @@ func (mm *MemoryManager) ExportMemory() ([]byte, error) {
 	return json.Marshal(mm.memory)
 }
 
-// ImportMemory imports memory data from persistence
+// ImportMemory imports memory data from persistence.
+// Tier limits always come from the current settings, never from the stored data.
 func (mm *MemoryManager) ImportMemory(data []byte) error {
-	return json.Unmarshal(data, mm.memory)
+	settings := mm.Settings()
+	if err := json.Unmarshal(data, mm.memory); err != nil {
+		return err
+	}
+	mm.ApplySettings(settings)
+	return nil
+}
+
+// Settings returns the memory settings currently in effect
+func (mm *MemoryManager) Settings() MemorySettings {
+	return MemorySettings{
+		ShortTermLimit: mm.memory.ShortTermLimit,
+		LongTermLimit:  mm.memory.LongTermLimit,
+		DecayRate:      mm.memory.DecayRate,
+	}
+}
+
+// ApplySettings changes the tier limits and trims memories that no longer fit
+func (mm *MemoryManager) ApplySettings(settings MemorySettings) {
+	mm.memory.ShortTermLimit = settings.ShortTermLimit
+	mm.memory.LongTermLimit = settings.LongTermLimit
+	mm.memory.DecayRate = settings.DecayRate
+
+	if len(mm.memory.ShortTerm) >= mm.memory.ShortTermLimit {
+		mm.consolidateMemories()
+	}
+	mm.trimLongTerm()
 }
\ No newline at end of file
@@ -0,0 +1,244 @@
+package persona
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultScaleRange bounds numeric traits that have no definition, such as custom traits
+var defaultScaleRange = []int{1, 10}
+
+// Clone returns a deep copy of the traits that can be modified without
+// affecting the original
+func (pt *PersonalityTraits) Clone() *PersonalityTraits {
+	clone := *pt
+
+	clone.CoreDimensions = cloneTraitMap(pt.CoreDimensions)
+	clone.CommunicationStyle = cloneTraitMap(pt.CommunicationStyle)
+	clone.BiasesAndTendencies = cloneTraitMap(pt.BiasesAndTendencies)
+	clone.ResponsePatterns = cloneTraitMap(pt.ResponsePatterns)
+	clone.DecisionMaking = cloneTraitMap(pt.DecisionMaking)
+	clone.TemporalOrientation = cloneTraitMap(pt.TemporalOrientation)
+	clone.LearningStyle = cloneTraitMap(pt.LearningStyle)
+	clone.CustomTraits = cloneTraitMap(pt.CustomTraits)
+
+	clone.ExpertiseAreas = append([]string(nil), pt.ExpertiseAreas...)
+	clone.SpeakingPatterns = SpeakingPatterns{
+		CommonPhrases:      append([]string(nil), pt.SpeakingPatterns.CommonPhrases...),
+		AvoidsPhrases:      append([]string(nil), pt.SpeakingPatterns.AvoidsPhrases...),
+		FavoriteAnalogies:  append([]string(nil), pt.SpeakingPatterns.FavoriteAnalogies...),
+		FavoriteFrameworks: append([]string(nil), pt.SpeakingPatterns.FavoriteFrameworks...),
+		CreativeTechniques: append([]string(nil), pt.SpeakingPatterns.CreativeTechniques...),
+	}
+	clone.EmotionalTriggers = EmotionalTriggers{
+		Energizers:   append([]string(nil), pt.EmotionalTriggers.Energizers...),
+		Frustrations: append([]string(nil), pt.EmotionalTriggers.Frustrations...),
+	}
+
+	if pt.ResponseModifiers != nil {
+		clone.ResponseModifiers = make(map[string]TraitModifier, len(pt.ResponseModifiers))
+		for context, modifier := range pt.ResponseModifiers {
+			clone.ResponseModifiers[context] = TraitModifier(cloneTraitMap(modifier))
+		}
+	}
+
+	if pt.Sources != nil {
+		clone.Sources = make(map[string]string, len(pt.Sources))
+		for key, source := range pt.Sources {
+			clone.Sources[key] = source
+		}
+	}
+	clone.Lineage = append([]string(nil), pt.Lineage...)
+
+	return &clone
+}
+
+// ApplyContextModifier returns a copy of the traits with the named response
+// modifier applied. Entries that cannot be applied are skipped and reported;
+// the receiver is never modified.
+func (pt *PersonalityTraits) ApplyContextModifier(context string) (*PersonalityTraits, []error) {
+	modifier, exists := pt.ResponseModifiers[context]
+	if !exists {
+		return pt, nil
+	}
+
+	modified, errs := pt.ApplyModifier(modifier)
+	for i, err := range errs {
+		errs[i] = fmt.Errorf("response modifier %s: %w", context, err)
+	}
+	return modified, errs
+}
+
+// ApplyModifier returns a copy of the traits with the modifier applied. Keys
+// name a trait in any category, or "category.trait" to pick one explicitly.
+// Values are absolute, or relative adjustments such as "+2" and "-1" for
+// numeric traits. Numeric results are clamped to the trait's range and enum
+// values must be one of its options; entries that fail are reported and skipped.
+// Changes that break a trait sum constraint the traits met before are undone
+// and reported.
+func (pt *PersonalityTraits) ApplyModifier(modifier TraitModifier) (*PersonalityTraits, []error) {
+	modified := pt.Clone()
+	errs := make([]error, 0)
+
+	keys := make([]string, 0, len(modifier))
+	for key := range modifier {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		category, traitName, found := modified.modifierTarget(key)
+		if !found {
+			errs = append(errs, fmt.Errorf("modifier targets unknown trait %s", key))
+			continue
+		}
+
+		values := modified.categoryValues(category)
+		value, err := modified.overlayValue(category, traitName, values[traitName], modifier[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s.%s: %w", category, traitName, err))
+			continue
+		}
+		values[traitName] = value
+	}
+
+	return modified, append(errs, modified.restoreConstraints(pt)...)
+}
+
+// restoreConstraints undoes the changes from original to the traits of every
+// trait sum constraint the change breaks. Undoing one rule's traits can break
+// another rule, so rules are checked until none is broken.
+func (pt *PersonalityTraits) restoreConstraints(original *PersonalityTraits) []error {
+	if pt.Base == nil {
+		return nil
+	}
+	rules := pt.Base.Constraints.TraitSumLimits.Rules
+
+	var errs []error
+	for pass := 0; pass < len(rules); pass++ {
+		restored := false
+		for _, rule := range rules {
+			err := validateConstraintRule(pt, rule)
+			if err == nil || validateConstraintRule(original, rule) != nil {
+				continue
+			}
+			for _, traitName := range rule.Traits {
+				if value, exists := original.CoreDimensions[traitName]; exists {
+					pt.CoreDimensions[traitName] = value
+				}
+			}
+			errs = append(errs, fmt.Errorf("changes to %s undone: %w", strings.Join(rule.Traits, ", "), err))
+			restored = true
+		}
+		if !restored {
+			break
+		}
+	}
+	return errs
+}
+
+// modifierTarget resolves a modifier key to the category and trait it changes
+func (pt *PersonalityTraits) modifierTarget(key string) (string, string, bool) {
+	if category, traitName, qualified := strings.Cut(key, "."); qualified {
+		if _, exists := pt.categoryValues(category)[traitName]; exists {
+			return category, traitName, true
+		}
+		return "", "", false
+	}
+
+	for _, category := range allTraitCategories {
+		if _, exists := pt.categoryValues(category)[key]; exists {
+			return category, key, true
+		}
+	}
+	return "", "", false
+}
+
+// overlayValue computes the new value of a trait and validates it against
+// the trait's definition
+func (pt *PersonalityTraits) overlayValue(category, traitName string, current, change TraitValue) (TraitValue, error) {
+	def, defined := pt.definition(category, traitName)
+	if !defined {
+		def = TraitDefinition{Type: "scale", Range: defaultScaleRange}
+		if _, numeric := current.(float64); !numeric {
+			// Undefined non-numeric traits are replaced as-is
+			return change, nil
+		}
+	}
+
+	switch def.Type {
+	case "scale":
+		target, err := scaleOverlay(current, change)
+		if err != nil {
+			return nil, err
+		}
+		if len(def.Range) >= 2 {
+			target = math.Max(float64(def.Range[0]), math.Min(float64(def.Range[1]), target))
+		}
+		return target, nil
+
+	case "enum":
+		str, ok := change.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected one of %v, got %v", def.Options, change)
+		}
+		for _, option := range def.Options {
+			if str == option {
+				return str, nil
+			}
+		}
+		return nil, fmt.Errorf("value %q is not in valid options %v", str, def.Options)
+	}
+
+	return change, nil
+}
+
+// scaleOverlay applies an absolute or relative change to a numeric value
+func scaleOverlay(current, change TraitValue) (float64, error) {
+	switch v := change.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case string:
+		text := strings.TrimSpace(v)
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid numeric adjustment %q", v)
+		}
+		if strings.HasPrefix(text, "+") || strings.HasPrefix(text, "-") {
+			base, ok := current.(float64)
+			if !ok {
+				return 0, fmt.Errorf("cannot adjust non-numeric value %v", current)
+			}
+			return base + number, nil
+		}
+		return number, nil
+	default:
+		return 0, fmt.Errorf("invalid numeric value %v", change)
+	}
+}
+
+// definition returns the base definition of a trait, if the base config is known
+func (pt *PersonalityTraits) definition(category, traitName string) (TraitDefinition, bool) {
+	if pt.Base == nil {
+		return TraitDefinition{}, false
+	}
+	def, exists := pt.Base.definitions(category)[traitName]
+	return def, exists
+}
+
+// cloneTraitMap copies a trait value map, preserving nil
+func cloneTraitMap(values map[string]TraitValue) map[string]TraitValue {
+	if values == nil {
+		return nil
+	}
+	clone := make(map[string]TraitValue, len(values))
+	for key, value := range values {
+		clone[key] = value
+	}
+	return clone
+}
@@ -2,9 +2,9 @@ package persona
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -13,9 +13,10 @@ type Persona struct {
 	ID          string             `json:"id"`
 	Name        string             `json:"name"`
 	Description string             `json:"description"`
-	Traits      *PersonalityTraits `json:"traits"`
+	Traits      *PersonalityTraits `json:"traits"` // Read with CurrentTraits once the persona is shared
+	traitsMu    sync.RWMutex
 	memoryMgr   *MemoryManager
-	db          *sql.DB
+	repo        Repository
 	llmProvider LLMProvider
 	logger      Logger
 	createdAt   time.Time
@@ -28,6 +29,19 @@ type LLMProvider interface {
 	GetModelInfo() ModelInfo
 }
 
+// StreamingLLMProvider is implemented by providers that can deliver a response
+// incrementally. onChunk receives each piece of content as it arrives.
+type StreamingLLMProvider interface {
+	LLMProvider
+	GenerateResponseStream(ctx context.Context, req LLMRequest, onChunk func(string)) (*LLMResponse, error)
+}
+
+// TokenEstimator is implemented by providers that can count the tokens of text
+// the way their model does. Other providers' tokens are estimated.
+type TokenEstimator interface {
+	EstimateTokens(text string) int
+}
+
 // LLMRequest represents a request to the LLM
 type LLMRequest struct {
 	Prompt      string                 `json:"prompt"`
@@ -39,19 +53,33 @@ type LLMRequest struct {
 
 // LLMResponse represents the response from the LLM
 type LLMResponse struct {
-	Content      string        `json:"content"`
-	TokensUsed   int           `json:"tokens_used"`
-	Model        string        `json:"model"`
-	Duration     time.Duration `json:"duration"`
-	FinishReason string        `json:"finish_reason"`
+	Content          string        `json:"content"`
+	TokensUsed       int           `json:"tokens_used"`
+	PromptTokens     int           `json:"prompt_tokens,omitempty"`
+	CompletionTokens int           `json:"completion_tokens,omitempty"`
+	Model            string        `json:"model"`
+	Duration         time.Duration `json:"duration"`
+	FinishReason     string        `json:"finish_reason"`
 }
 
 // ModelInfo contains information about the LLM model
 type ModelInfo struct {
-	Name      string  `json:"name"`
-	Provider  string  `json:"provider"`
-	MaxTokens int     `json:"max_tokens"`
-	CostPer1K float64 `json:"cost_per_1k"`
+	Name            string  `json:"name"`
+	Provider        string  `json:"provider"`
+	MaxTokens       int     `json:"max_tokens"`
+	ContextSize     int     `json:"context_size"` // Tokens of prompt and response together, or 0 if unknown
+	CostPer1K       float64 `json:"cost_per_1k"`  // Average of the input and output prices
+	InputCostPer1K  float64 `json:"input_cost_per_1k"`
+	OutputCostPer1K float64 `json:"output_cost_per_1k"`
+}
+
+// Cost returns the price in USD of a response. Tokens that are not split into
+// prompt and completion are charged at the average price.
+func (m ModelInfo) Cost(totalTokens, promptTokens, completionTokens int) float64 {
+	if promptTokens+completionTokens == 0 {
+		return float64(totalTokens) / 1000 * m.CostPer1K
+	}
+	return float64(promptTokens)/1000*m.InputCostPer1K + float64(completionTokens)/1000*m.OutputCostPer1K
 }
 
 // Logger interface for structured logging
@@ -81,19 +109,23 @@ type ConversationTurn struct {
 
 // ThinkingResult represents the output of a persona's thinking process
 type ThinkingResult struct {
-	Response        string             `json:"response"`
-	Reasoning       string             `json:"reasoning"`
-	Confidence      float64            `json:"confidence"`
-	EmotionalTone   string             `json:"emotional_tone"`
-	KeyInsights     []string           `json:"key_insights"`
-	Questions       []string           `json:"questions"`
-	Recommendations []string           `json:"recommendations"`
-	MemoriesUsed    []string           `json:"memories_used"`
-	TraitsInfluence map[string]float64 `json:"traits_influence"`
+	Response         string             `json:"response"`
+	Reasoning        string             `json:"reasoning"`
+	Confidence       float64            `json:"confidence"`
+	EmotionalTone    string             `json:"emotional_tone"`
+	KeyInsights      []string           `json:"key_insights"`
+	Questions        []string           `json:"questions"`
+	Recommendations  []string           `json:"recommendations"`
+	MemoriesUsed     []string           `json:"memories_used"`
+	TraitsInfluence  map[string]float64 `json:"traits_influence"`
+	TokensUsed       int                `json:"tokens_used"`
+	PromptTokens     int                `json:"prompt_tokens,omitempty"`
+	CompletionTokens int                `json:"completion_tokens,omitempty"`
+	Budget           *PromptBudget      `json:"budget,omitempty"` // How the prompt was fitted into the context window
 }
 
 // New creates a new persona with the specified configuration
-func New(id, name, description string, traitsConfig string, db *sql.DB, llmProvider LLMProvider, logger Logger) (*Persona, error) {
+func New(id, name, description string, traitsConfig string, repo Repository, llmProvider LLMProvider, logger Logger) (*Persona, error) {
 	// Load personality traits
 	loader := NewTraitLoader("config")
 	traits, err := loader.LoadPersonalityConfig(traitsConfig)
@@ -101,46 +133,24 @@ func New(id, name, description string, traitsConfig string, db *sql.DB, llmProvi
 		return nil, fmt.Errorf("failed to load personality traits: %w", err)
 	}
 
-	// Create memory system
-	memory := NewMemory(id)
-	memoryMgr := NewMemoryManager(memory)
-
-	persona := &Persona{
-		ID:          id,
-		Name:        name,
-		Description: description,
-		Traits:      traits,
-		memoryMgr:   memoryMgr,
-		db:          db,
-		llmProvider: llmProvider,
-		logger:      logger,
-		createdAt:   time.Now(),
-		updatedAt:   time.Now(),
-	}
-
-	// Load existing memory from database
-	if err := persona.loadMemoryFromDB(); err != nil {
-		logger.Warn("Failed to load existing memory", "persona_id", id, "error", err)
-	}
-
-	// Initialize persona with core personality
-	if err := persona.initializePersonality(); err != nil {
-		return nil, fmt.Errorf("failed to initialize personality: %w", err)
-	}
-
-	return persona, nil
+	return NewWithTraits(id, name, description, traits, repo, llmProvider, logger)
 }
 
 // NewFromJSONTraits creates a new persona with traits loaded from JSON string
-func NewFromJSONTraits(id, name, description string, traitsJSON string, db *sql.DB, llmProvider LLMProvider, logger Logger) (*Persona, error) {
+func NewFromJSONTraits(id, name, description string, traitsJSON string, repo Repository, llmProvider LLMProvider, logger Logger) (*Persona, error) {
 	// Load personality traits from JSON string
 	traits, err := LoadPersonalityConfigFromJSONSimple(traitsJSON)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load personality traits from JSON: %w", err)
 	}
 
+	return NewWithTraits(id, name, description, traits, repo, llmProvider, logger)
+}
+
+// NewWithTraits creates a new persona from already loaded and validated traits
+func NewWithTraits(id, name, description string, traits *PersonalityTraits, repo Repository, llmProvider LLMProvider, logger Logger) (*Persona, error) {
 	// Create memory system
-	memory := NewMemory(id)
+	memory := NewMemoryWithSettings(id, traits.MemorySettings())
 	memoryMgr := NewMemoryManager(memory)
 
 	persona := &Persona{
@@ -149,15 +159,15 @@ func NewFromJSONTraits(id, name, description string, traitsJSON string, db *sql.
 		Description: description,
 		Traits:      traits,
 		memoryMgr:   memoryMgr,
-		db:          db,
+		repo:        repo,
 		llmProvider: llmProvider,
 		logger:      logger,
 		createdAt:   time.Now(),
 		updatedAt:   time.Now(),
 	}
 
-	// Load existing memory from database
-	if err := persona.loadMemoryFromDB(); err != nil {
+	// Load existing memory from the repository
+	if err := persona.loadMemory(); err != nil {
 		logger.Warn("Failed to load existing memory", "persona_id", id, "error", err)
 	}
 
@@ -171,26 +181,41 @@ func NewFromJSONTraits(id, name, description string, traitsJSON string, db *sql.
 
 // Think is the main method for persona reasoning and response generation
 func (p *Persona) Think(ctx context.Context, prompt string, context ThinkingContext) (*ThinkingResult, error) {
+	return p.ThinkStream(ctx, prompt, context, nil)
+}
+
+// ThinkStream is like Think but passes the response text to onChunk as it is
+// generated. Providers that cannot stream deliver the whole response at once.
+func (p *Persona) ThinkStream(ctx context.Context, prompt string, context ThinkingContext, onChunk func(string)) (*ThinkingResult, error) {
 	startTime := time.Now()
 
 	p.logger.Debug("Persona thinking started", "persona_id", p.ID, "prompt_length", len(prompt))
 
+	// Think with the traits as they are now, even if they are reloaded meanwhile
+	traits := p.CurrentTraits()
+
 	// Update emotional state based on context
-	emotionalState := p.determineEmotionalState(context)
+	emotionalState := p.determineEmotionalState(context, traits)
 
 	// Apply context-specific trait modifications
-	workingTraits := p.applyContextualTraits(context, emotionalState)
+	workingTraits := p.applyContextualTraits(context, emotionalState, traits)
 
 	// Retrieve relevant memories
 	relevantMemories := p.memoryMgr.RetrieveRelevant(prompt, 5)
 
-	// Build the complete prompt for LLM
-	enhancedPrompt, systemMessage := p.buildPrompt(prompt, context, relevantMemories, workingTraits, emotionalState)
-
 	// Determine LLM parameters based on personality
 	temperature := p.calculateTemperature(workingTraits)
 	maxTokens := p.calculateMaxTokens(workingTraits)
 
+	// Build the complete prompt for LLM, fitting it into the context window
+	enhancedPrompt, systemMessage, relevantMemories, budget, err := p.buildPrompt(prompt, context, relevantMemories, workingTraits, emotionalState, maxTokens)
+	if err != nil {
+		return nil, err
+	}
+	if budget != nil && len(budget.Omitted) > 0 {
+		p.logger.Info("Prompt shortened to fit the context window", "persona_id", p.ID, "context_window", budget.ContextWindow, "omitted", budget.Omitted)
+	}
+
 	// Generate response using LLM
 	llmReq := LLMRequest{
 		Prompt:      enhancedPrompt,
@@ -200,7 +225,7 @@ func (p *Persona) Think(ctx context.Context, prompt string, context ThinkingCont
 		Context:     context.ProjectContext,
 	}
 
-	llmResp, err := p.llmProvider.GenerateResponse(ctx, llmReq)
+	llmResp, err := p.generate(ctx, llmReq, onChunk)
 	if err != nil {
 		return nil, fmt.Errorf("LLM generation failed: %w", err)
 	}
@@ -210,6 +235,7 @@ func (p *Persona) Think(ctx context.Context, prompt string, context ThinkingCont
 	if err != nil {
 		return nil, fmt.Errorf("failed to process LLM response: %w", err)
 	}
+	result.Budget = budget
 
 	// Store the interaction in memory
 	p.storeInteraction(prompt, result, context, emotionalState)
@@ -222,8 +248,53 @@ func (p *Persona) Think(ctx context.Context, prompt string, context ThinkingCont
 	return result, nil
 }
 
+// generate sends a request to the LLM, streaming the response when a chunk
+// callback is given
+func (p *Persona) generate(ctx context.Context, req LLMRequest, onChunk func(string)) (*LLMResponse, error) {
+	if p.llmProvider == nil {
+		return nil, fmt.Errorf("persona %s has no LLM provider", p.ID)
+	}
+	if onChunk == nil {
+		return p.llmProvider.GenerateResponse(ctx, req)
+	}
+
+	if streaming, ok := p.llmProvider.(StreamingLLMProvider); ok {
+		return streaming.GenerateResponseStream(ctx, req, onChunk)
+	}
+
+	resp, err := p.llmProvider.GenerateResponse(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	onChunk(resp.Content)
+	return resp, nil
+}
+
+// Memory returns one of the persona's memories by ID, such as those listed in
+// ThinkingResult.MemoriesUsed
+func (p *Persona) Memory(id string) (MemoryEntry, bool) {
+	return p.memoryMgr.Find(id)
+}
+
+// CurrentTraits returns the persona's traits. They may be swapped while the
+// persona is in use, when the files they were loaded from change.
+func (p *Persona) CurrentTraits() *PersonalityTraits {
+	p.traitsMu.RLock()
+	defer p.traitsMu.RUnlock()
+	return p.Traits
+}
+
+// SwapTraits replaces the persona's traits. Responses already being generated
+// keep the traits they started with.
+func (p *Persona) SwapTraits(traits *PersonalityTraits) {
+	p.traitsMu.Lock()
+	defer p.traitsMu.Unlock()
+	p.Traits = traits
+	p.updatedAt = time.Now()
+}
+
 // determineEmotionalState analyzes context to determine current emotional state
-func (p *Persona) determineEmotionalState(context ThinkingContext) string {
+func (p *Persona) determineEmotionalState(context ThinkingContext, traits *PersonalityTraits) string {
 	// Check for explicit emotional state
 	if context.EmotionalState != "" {
 		return context.EmotionalState
@@ -241,14 +312,14 @@ func (p *Persona) determineEmotionalState(context ThinkingContext) string {
 			lowerContent := strings.ToLower(turn.Content)
 
 			// Check energizers
-			for _, energizer := range p.Traits.EmotionalTriggers.Energizers {
+			for _, energizer := range traits.EmotionalTriggers.Energizers {
 				if strings.Contains(lowerContent, strings.ToLower(energizer)) {
 					return "excited"
 				}
 			}
 
 			// Check frustrations
-			for _, frustration := range p.Traits.EmotionalTriggers.Frustrations {
+			for _, frustration := range traits.EmotionalTriggers.Frustrations {
 				if strings.Contains(lowerContent, strings.ToLower(frustration)) {
 					return "frustrated"
 				}
@@ -261,38 +332,67 @@ func (p *Persona) determineEmotionalState(context ThinkingContext) string {
 }
 
 // applyContextualTraits modifies traits based on current context and emotional state
-func (p *Persona) applyContextualTraits(context ThinkingContext, emotionalState string) *PersonalityTraits {
+func (p *Persona) applyContextualTraits(context ThinkingContext, emotionalState string, traits *PersonalityTraits) *PersonalityTraits {
 	// Start with base traits
-	workingTraits := p.Traits
+	workingTraits := traits
+
+	var errs []error
 
 	// Apply emotional state modifiers
-	if _, exists := p.Traits.ResponseModifiers[emotionalState]; exists {
-		workingTraits = p.Traits.ApplyContextModifier(emotionalState)
+	if _, exists := traits.ResponseModifiers[emotionalState]; exists {
+		var modifierErrs []error
+		workingTraits, modifierErrs = traits.ApplyContextModifier(emotionalState)
+		errs = append(errs, modifierErrs...)
 	}
 
 	// Apply focus-specific modifiers
 	if context.Focus != "" {
-		if _, exists := p.Traits.ResponseModifiers[context.Focus]; exists {
-			workingTraits = workingTraits.ApplyContextModifier(context.Focus)
+		if _, exists := traits.ResponseModifiers[context.Focus]; exists {
+			var modifierErrs []error
+			workingTraits, modifierErrs = workingTraits.ApplyContextModifier(context.Focus)
+			errs = append(errs, modifierErrs...)
 		}
 	}
 
+	for _, err := range errs {
+		p.logger.Warn("Response modifier not fully applied", "persona_id", p.ID, "error", err)
+	}
+
 	return workingTraits
 }
 
-// buildPrompt constructs the complete prompt for the LLM including personality context
-func (p *Persona) buildPrompt(prompt string, context ThinkingContext, memories []MemoryEntry, traits *PersonalityTraits, emotionalState string) (string, string) {
+// buildPrompt constructs the complete prompt for the LLM including personality
+// context. When the model's context window is known, context is added in order
+// of priority until the window is full, leaving room for maxTokens of
+// response: the system message, the question, recent conversation turns,
+// memories and then project context. It returns the memories included and how
+// the prompt was fitted, or ErrQuestionTooLong if the question cannot be sent.
+func (p *Persona) buildPrompt(prompt string, context ThinkingContext, memories []MemoryEntry, traits *PersonalityTraits, emotionalState string, maxTokens int) (string, string, []MemoryEntry, *PromptBudget, error) {
 	// Build system message with personality
 	systemMessage := p.buildSystemMessage(traits, emotionalState)
+	instruction := p.buildPersonalityInstruction(traits, emotionalState)
+
+	budgeter := p.newPromptBudgeter(maxTokens)
+	budgeter.take(systemMessage)
+	budgeter.take(instruction)
+	question, err := budgeter.fitQuestion(prompt)
+	if err != nil {
+		return "", "", nil, budgeter.budget, err
+	}
+	release := budgeter.reserve(append(memoryLines(memories), projectLines(context.ProjectContext)...))
+	conversation := budgeter.fitConversation(context.ConversationHistory)
+	release()
+	memories = budgeter.fitMemories(memories)
+	project := budgeter.fitProject(context.ProjectContext)
 
 	// Build the enhanced prompt
 	var promptBuilder strings.Builder
 
 	// Add conversation history context
-	if len(context.ConversationHistory) > 0 {
+	if len(conversation) > 0 {
 		promptBuilder.WriteString("## Recent Conversation:\n")
-		for _, turn := range context.ConversationHistory {
-			promptBuilder.WriteString(fmt.Sprintf("%s: %s\n", turn.Speaker, turn.Content))
+		for _, line := range conversation {
+			promptBuilder.WriteString(line)
 		}
 		promptBuilder.WriteString("\n")
 	}
@@ -301,29 +401,30 @@ func (p *Persona) buildPrompt(prompt string, context ThinkingContext, memories [
 	if len(memories) > 0 {
 		promptBuilder.WriteString("## Relevant Context from Memory:\n")
 		for _, memory := range memories {
-			promptBuilder.WriteString(fmt.Sprintf("- %s\n", memory.Content))
+			promptBuilder.WriteString(memoryLine(memory))
 		}
 		promptBuilder.WriteString("\n")
 	}
 
 	// Add project context if available
-	if len(context.ProjectContext) > 0 {
+	if len(project) > 0 {
 		promptBuilder.WriteString("## Project Context:\n")
-		for key, value := range context.ProjectContext {
-			promptBuilder.WriteString(fmt.Sprintf("%s: %v\n", key, value))
+		for _, line := range project {
+			promptBuilder.WriteString(line)
 		}
 		promptBuilder.WriteString("\n")
 	}
 
 	// Add the main prompt
 	promptBuilder.WriteString("## Current Question/Topic:\n")
-	promptBuilder.WriteString(prompt)
+	promptBuilder.WriteString(question)
 	promptBuilder.WriteString("\n\n")
 
 	// Add personality-specific instruction
-	promptBuilder.WriteString(p.buildPersonalityInstruction(traits, emotionalState))
+	promptBuilder.WriteString(instruction)
 
-	return promptBuilder.String(), systemMessage
+	enhancedPrompt := promptBuilder.String()
+	return enhancedPrompt, systemMessage, memories, budgeter.result(systemMessage, enhancedPrompt), nil
 }
 
 // buildSystemMessage creates the system message that defines the persona's behavior
@@ -498,25 +599,33 @@ func (p *Persona) processLLMResponse(llmResp *LLMResponse, traits *PersonalityTr
 	// Determine emotional tone
 	emotionalTone := p.analyzeEmotionalTone(llmResp.Content, traits)
 
-	// Track which memories were used
-	memoriesUsed := make([]string, len(memories))
-	for i, memory := range memories {
-		memoriesUsed[i] = memory.ID
+	// Track which memories were used. Working memory holds copies of other
+	// memories, so the same memory can be retrieved twice.
+	memoriesUsed := make([]string, 0, len(memories))
+	seen := make(map[string]bool, len(memories))
+	for _, memory := range memories {
+		if !seen[memory.ID] {
+			seen[memory.ID] = true
+			memoriesUsed = append(memoriesUsed, memory.ID)
+		}
 	}
 
 	// Analyze trait influences
 	traitInfluence := p.analyzeTraitInfluence(traits)
 
 	return &ThinkingResult{
-		Response:        llmResp.Content,
-		Reasoning:       p.extractReasoning(llmResp.Content),
-		Confidence:      confidence,
-		EmotionalTone:   emotionalTone,
-		KeyInsights:     insights,
-		Questions:       questions,
-		Recommendations: recommendations,
-		MemoriesUsed:    memoriesUsed,
-		TraitsInfluence: traitInfluence,
+		Response:         llmResp.Content,
+		Reasoning:        p.extractReasoning(llmResp.Content),
+		Confidence:       confidence,
+		EmotionalTone:    emotionalTone,
+		KeyInsights:      insights,
+		Questions:        questions,
+		Recommendations:  recommendations,
+		MemoriesUsed:     memoriesUsed,
+		TraitsInfluence:  traitInfluence,
+		TokensUsed:       llmResp.TokensUsed,
+		PromptTokens:     llmResp.PromptTokens,
+		CompletionTokens: llmResp.CompletionTokens,
 	}, nil
 }
 
@@ -0,0 +1,157 @@
+package persona
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TraitReloader rebuilds the traits of loaded personas when the trait files
+// they were built from change. Traits are only swapped in once they load and
+// validate; a persona keeps its traits when its files are broken.
+type TraitReloader struct {
+	configPath string
+	logger     Logger
+
+	mu       sync.Mutex
+	personas map[*Persona]struct{}
+	reloaded map[string]*PersonalityTraits // Persona file -> traits rebuilt since the reloader was created
+}
+
+// NewTraitReloader creates a reloader for the trait files under configPath,
+// the directory that holds traits/base.json
+func NewTraitReloader(configPath string, logger Logger) *TraitReloader {
+	return &TraitReloader{
+		configPath: configPath,
+		logger:     logger,
+		personas:   make(map[*Persona]struct{}),
+		reloaded:   make(map[string]*PersonalityTraits),
+	}
+}
+
+// TraitsDir returns the directory of trait files the reloader rebuilds from
+func (r *TraitReloader) TraitsDir() string {
+	return filepath.Join(r.configPath, "traits")
+}
+
+// Track keeps a loaded persona's traits up to date until it is untracked. A
+// persona built from a file that was reloaded before it was loaded gets the
+// reloaded traits straight away.
+func (r *TraitReloader) Track(p *Persona) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.personas[p] = struct{}{}
+	if traits, ok := r.reloaded[p.CurrentTraits().personaFile()]; ok {
+		p.SwapTraits(traits)
+	}
+}
+
+// Untrack stops updating a persona's traits
+func (r *TraitReloader) Untrack(p *Persona) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.personas, p)
+}
+
+// Reload rebuilds the traits of every tracked persona built from one of the
+// changed files, given as paths. It returns the IDs of the personas whose
+// traits were swapped, and an error for every persona file that failed to
+// load or validate.
+func (r *TraitReloader) Reload(changed []string) ([]string, []error) {
+	files := make(map[string]bool)
+	for _, path := range changed {
+		if name, ok := r.traitFile(path); ok {
+			files[name] = true
+		}
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Find the persona files whose lineage includes a changed file
+	affected := make(map[string]bool)
+	for p := range r.personas {
+		if file, ok := affectedFile(p.CurrentTraits(), files); ok {
+			affected[file] = true
+		}
+	}
+	for _, traits := range r.reloaded {
+		if file, ok := affectedFile(traits, files); ok {
+			affected[file] = true
+		}
+	}
+
+	names := make([]string, 0, len(affected))
+	for file := range affected {
+		names = append(names, file)
+	}
+	sort.Strings(names)
+
+	// A fresh loader reads base.json again, in case it changed too
+	loader := NewTraitLoader(r.configPath)
+	var swapped []string
+	var errs []error
+	for _, file := range names {
+		traits, err := loader.LoadPersonalityConfig(file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		r.reloaded[file] = traits
+
+		for p := range r.personas {
+			if p.CurrentTraits().personaFile() == file {
+				p.SwapTraits(traits)
+				swapped = append(swapped, p.ID)
+			}
+		}
+		r.logger.Info("Reloaded persona traits", "file", file)
+	}
+	sort.Strings(swapped)
+
+	return swapped, errs
+}
+
+// traitFile returns a path's name relative to the traits directory, as it
+// appears in a persona's lineage
+func (r *TraitReloader) traitFile(path string) (string, bool) {
+	rel, err := filepath.Rel(r.TraitsDir(), path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// affectedFile returns the persona file traits were built from, if their
+// lineage includes one of files
+func affectedFile(traits *PersonalityTraits, files map[string]bool) (string, bool) {
+	file := traits.personaFile()
+	if file == "" {
+		return "", false
+	}
+	for _, source := range traits.Lineage {
+		if files[source] {
+			return file, true
+		}
+	}
+	return "", false
+}
+
+// personaFile returns the trait file the traits were loaded from, or an empty
+// string if they were not loaded from one
+func (pt *PersonalityTraits) personaFile() string {
+	if pt == nil || len(pt.Lineage) < 2 {
+		return ""
+	}
+	file := pt.Lineage[len(pt.Lineage)-1]
+	if file == inlineTraitsSource || strings.HasPrefix(file, mixinsDir+"/") {
+		return ""
+	}
+	return file
+}
@@ -0,0 +1,55 @@
+package persona
+
+import "time"
+
+// PersonaRepository stores personas. LoadPersona returns an error wrapping
+// sql.ErrNoRows for an unknown persona.
+type PersonaRepository interface {
+	SavePersona(persona *Persona) error
+	LoadPersona(id string, llmProvider LLMProvider, logger Logger) (*Persona, error)
+	ListPersonas() ([]PersonaInfo, error)
+	// PagePersonas returns personas in the order of ListPersonas, skipping
+	// offset of them. A limit of zero returns the rest.
+	PagePersonas(limit, offset int) ([]PersonaInfo, error)
+	CountPersonas() (int, error)
+	DeletePersona(id string) error
+}
+
+// MemoryRepository stores the exported memory of saved personas. Both methods
+// return an error wrapping sql.ErrNoRows for an unknown persona.
+type MemoryRepository interface {
+	SaveMemory(personaID string, data []byte) error
+	LoadMemory(personaID string) ([]byte, error)
+}
+
+// InteractionRepository stores the log of requests personas make to LLMs
+type InteractionRepository interface {
+	LogInteraction(log *InteractionLog) error
+	// ListInteractions returns a persona's interactions, newest first. A limit
+	// of zero returns them all.
+	ListInteractions(personaID string, limit int) ([]InteractionLog, error)
+}
+
+// Repository stores personas with their memory and interaction logs.
+// Deleting a persona deletes its interaction logs.
+type Repository interface {
+	PersonaRepository
+	MemoryRepository
+	InteractionRepository
+}
+
+// InteractionLog records one request to an LLM and its response
+type InteractionLog struct {
+	ID            string                 `json:"id"`
+	PersonaID     string                 `json:"persona_id"`
+	Prompt        string                 `json:"prompt"`
+	SystemMessage string                 `json:"system_message"`
+	Response      string                 `json:"response"`
+	Model         string                 `json:"model"`
+	Temperature   float64                `json:"temperature"`
+	MaxTokens     int                    `json:"max_tokens"`
+	TokensUsed    int                    `json:"tokens_used"`
+	Duration      time.Duration          `json:"duration"`
+	Context       map[string]interface{} `json:"context,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
@@ -3,24 +3,34 @@ package persona
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
+
+	"personal-ai-board/internal/auth"
 )
 
-// Storage handles database operations for personas
+// Storage handles database operations for the personas of one workspace
 type Storage struct {
-	db *sql.DB
+	db        *sql.DB
+	workspace string
 }
 
-// NewStorage creates a new storage instance
+// NewStorage creates a storage instance for the default workspace
 func NewStorage(db *sql.DB) *Storage {
-	return &Storage{db: db}
+	return NewWorkspaceStorage(db, auth.DefaultWorkspaceID)
+}
+
+// NewWorkspaceStorage creates a storage instance for the personas of a
+// workspace
+func NewWorkspaceStorage(db *sql.DB, workspaceID string) *Storage {
+	return &Storage{db: db, workspace: workspaceID}
 }
 
 // SavePersona saves a persona to the database
 func (s *Storage) SavePersona(persona *Persona) error {
 	// Serialize traits
-	traitsData, err := json.Marshal(persona.Traits)
+	traitsData, err := json.Marshal(persona.CurrentTraits())
 	if err != nil {
 		return fmt.Errorf("failed to serialize traits: %w", err)
 	}
@@ -31,16 +41,26 @@ func (s *Storage) SavePersona(persona *Persona) error {
 		return fmt.Errorf("failed to export memory: %w", err)
 	}
 
-	// Insert or update persona
+	// Insert or update persona. An upsert keeps board memberships, which a
+	// REPLACE would cascade-delete. A persona of another workspace is left
+	// alone.
 	query := `
-		INSERT OR REPLACE INTO personas (
-			id, name, description, traits_config, memory_data,
+		INSERT INTO personas (
+			id, workspace_id, name, description, traits_config, memory_data,
 			created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			description = excluded.description,
+			traits_config = excluded.traits_config,
+			memory_data = excluded.memory_data,
+			updated_at = excluded.updated_at
+		WHERE personas.workspace_id = excluded.workspace_id
 	`
 
-	_, err = s.db.Exec(query,
+	result, err := s.db.Exec(query,
 		persona.ID,
+		s.workspace,
 		persona.Name,
 		persona.Description,
 		string(traitsData),
@@ -52,6 +72,9 @@ func (s *Storage) SavePersona(persona *Persona) error {
 	if err != nil {
 		return fmt.Errorf("failed to save persona: %w", err)
 	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("failed to save persona %s: %w", persona.ID, sql.ErrNoRows)
+	}
 
 	return nil
 }
@@ -61,70 +84,87 @@ func (s *Storage) LoadPersona(id string, llmProvider LLMProvider, logger Logger)
 	query := `
 		SELECT id, name, description, traits_config, memory_data,
 		       created_at, updated_at
-		FROM personas WHERE id = ?
+		FROM personas WHERE id = ? AND workspace_id = ?
 	`
 
-	var persona Persona
+	var info PersonaInfo
 	var traitsData, memoryData string
-	var createdAt, updatedAt time.Time
 
-	err := s.db.QueryRow(query, id).Scan(
-		&persona.ID,
-		&persona.Name,
-		&persona.Description,
+	err := s.db.QueryRow(query, id, s.workspace).Scan(
+		&info.ID,
+		&info.Name,
+		&info.Description,
 		&traitsData,
 		&memoryData,
-		&createdAt,
-		&updatedAt,
+		&info.CreatedAt,
+		&info.UpdatedAt,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to load persona: %w", err)
 	}
 
+	return restorePersona(info, []byte(traitsData), []byte(memoryData), s, llmProvider, logger)
+}
+
+// restorePersona rebuilds a saved persona from its serialized traits and memory
+func restorePersona(info PersonaInfo, traitsData, memoryData []byte, repo Repository, llmProvider LLMProvider, logger Logger) (*Persona, error) {
 	// Deserialize traits
 	var traits PersonalityTraits
-	if err := json.Unmarshal([]byte(traitsData), &traits); err != nil {
+	if err := json.Unmarshal(traitsData, &traits); err != nil {
 		return nil, fmt.Errorf("failed to deserialize traits: %w", err)
 	}
 
 	// Create memory system
-	memory := NewMemory(id)
+	memory := NewMemoryWithSettings(info.ID, traits.MemorySettings())
 	memoryMgr := NewMemoryManager(memory)
 
 	// Import memory data
-	if memoryData != "" {
-		if err := memoryMgr.ImportMemory([]byte(memoryData)); err != nil {
-			logger.Warn("Failed to import memory data", "persona_id", id, "error", err)
+	if len(memoryData) > 0 {
+		if err := memoryMgr.ImportMemory(memoryData); err != nil {
+			logger.Warn("Failed to import memory data", "persona_id", info.ID, "error", err)
 		}
 	}
 
-	persona.Traits = &traits
-	persona.memoryMgr = memoryMgr
-	persona.db = s.db
-	persona.llmProvider = llmProvider
-	persona.logger = logger
-	persona.createdAt = createdAt
-	persona.updatedAt = updatedAt
-
-	return &persona, nil
+	return &Persona{
+		ID:          info.ID,
+		Name:        info.Name,
+		Description: info.Description,
+		Traits:      &traits,
+		memoryMgr:   memoryMgr,
+		repo:        repo,
+		llmProvider: llmProvider,
+		logger:      logger,
+		createdAt:   info.CreatedAt,
+		updatedAt:   info.UpdatedAt,
+	}, nil
 }
 
 // ListPersonas returns all personas
 func (s *Storage) ListPersonas() ([]PersonaInfo, error) {
+	return s.PagePersonas(0, 0)
+}
+
+// PagePersonas returns a page of personas, most recently updated first
+func (s *Storage) PagePersonas(limit, offset int) ([]PersonaInfo, error) {
+	if limit == 0 {
+		limit = -1 // SQLite reads a negative limit as no limit
+	}
 	query := `
 		SELECT id, name, description, created_at, updated_at
 		FROM personas
+		WHERE workspace_id = ?
 		ORDER BY updated_at DESC
+		LIMIT ? OFFSET ?
 	`
 
-	rows, err := s.db.Query(query)
+	rows, err := s.db.Query(query, s.workspace, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query personas: %w", err)
 	}
 	defer rows.Close()
 
-	var personas []PersonaInfo
+	personas := make([]PersonaInfo, 0)
 	for rows.Next() {
 		var info PersonaInfo
 		err := rows.Scan(
@@ -143,13 +183,25 @@ func (s *Storage) ListPersonas() ([]PersonaInfo, error) {
 	return personas, nil
 }
 
+// CountPersonas returns the number of personas
+func (s *Storage) CountPersonas() (int, error) {
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM personas WHERE workspace_id = ?", s.workspace).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count personas: %w", err)
+	}
+	return count, nil
+}
+
 // DeletePersona removes a persona from the database
 func (s *Storage) DeletePersona(id string) error {
 	// Delete from personas table
-	_, err := s.db.Exec("DELETE FROM personas WHERE id = ?", id)
+	result, err := s.db.Exec("DELETE FROM personas WHERE id = ? AND workspace_id = ?", id, s.workspace)
 	if err != nil {
 		return fmt.Errorf("failed to delete persona: %w", err)
 	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil
+	}
 
 	// Delete related LLM logs
 	_, err = s.db.Exec("DELETE FROM llm_interaction_logs WHERE persona_id = ?", id)
@@ -160,6 +212,129 @@ func (s *Storage) DeletePersona(id string) error {
 	return nil
 }
 
+// SaveMemory replaces the stored memory of a persona
+func (s *Storage) SaveMemory(personaID string, data []byte) error {
+	result, err := s.db.Exec(`UPDATE personas SET memory_data = ?, updated_at = ? WHERE id = ? AND workspace_id = ?`,
+		string(data), time.Now(), personaID, s.workspace)
+	if err != nil {
+		return fmt.Errorf("failed to save memory: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("failed to save memory of persona %s: %w", personaID, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// LoadMemory returns the stored memory of a persona
+func (s *Storage) LoadMemory(personaID string) ([]byte, error) {
+	var data sql.NullString
+	if err := s.db.QueryRow("SELECT memory_data FROM personas WHERE id = ? AND workspace_id = ?", personaID, s.workspace).Scan(&data); err != nil {
+		return nil, fmt.Errorf("failed to load memory: %w", err)
+	}
+	return []byte(data.String), nil
+}
+
+// LogInteraction records an LLM interaction. An empty ID or creation time is
+// filled in.
+func (s *Storage) LogInteraction(log *InteractionLog) error {
+	if log.ID == "" {
+		log.ID = fmt.Sprintf("%s_%d", log.PersonaID, time.Now().UnixNano())
+	}
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = time.Now()
+	}
+
+	contextData, err := json.Marshal(log.Context)
+	if err != nil {
+		return fmt.Errorf("failed to serialize interaction context: %w", err)
+	}
+
+	query := `
+		INSERT INTO llm_interaction_logs (
+			id, persona_id, prompt, system_message, response,
+			model_name, temperature, max_tokens, tokens_used,
+			duration_ms, context_data, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err = s.db.Exec(query,
+		log.ID,
+		log.PersonaID,
+		log.Prompt,
+		log.SystemMessage,
+		log.Response,
+		log.Model,
+		log.Temperature,
+		log.MaxTokens,
+		log.TokensUsed,
+		log.Duration.Milliseconds(),
+		string(contextData),
+		log.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to log interaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListInteractions returns a persona's logged interactions, newest first. A
+// limit of zero returns them all.
+func (s *Storage) ListInteractions(personaID string, limit int) ([]InteractionLog, error) {
+	query := `
+		SELECT id, persona_id, prompt, COALESCE(system_message, ''), response,
+		       model_name, COALESCE(temperature, 0), COALESCE(max_tokens, 0), COALESCE(tokens_used, 0),
+		       COALESCE(duration_ms, 0), COALESCE(context_data, ''), created_at
+		FROM llm_interaction_logs
+		WHERE persona_id = ? AND persona_id IN (SELECT id FROM personas WHERE workspace_id = ?)
+		ORDER BY created_at DESC
+	`
+	args := []interface{}{personaID, s.workspace}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query interactions: %w", err)
+	}
+	defer rows.Close()
+
+	logs := make([]InteractionLog, 0)
+	for rows.Next() {
+		var log InteractionLog
+		var durationMs int64
+		var contextData string
+		err := rows.Scan(
+			&log.ID,
+			&log.PersonaID,
+			&log.Prompt,
+			&log.SystemMessage,
+			&log.Response,
+			&log.Model,
+			&log.Temperature,
+			&log.MaxTokens,
+			&log.TokensUsed,
+			&durationMs,
+			&contextData,
+			&log.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan interaction: %w", err)
+		}
+		log.Duration = time.Duration(durationMs) * time.Millisecond
+		if contextData != "" && contextData != "null" {
+			if err := json.Unmarshal([]byte(contextData), &log.Context); err != nil {
+				return nil, fmt.Errorf("failed to deserialize interaction context: %w", err)
+			}
+		}
+		logs = append(logs, log)
+	}
+
+	return logs, rows.Err()
+}
+
 // PersonaInfo contains basic information about a persona
 type PersonaInfo struct {
 	ID          string    `json:"id"`
@@ -169,22 +344,29 @@ type PersonaInfo struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
-// Database operations for persona memory and interactions
-func (p *Persona) loadMemoryFromDB() error {
-	if p.db == nil {
-		return fmt.Errorf("database connection not available")
+// loadMemory imports the memory saved for the persona, if it has been saved
+func (p *Persona) loadMemory() error {
+	if p.repo == nil {
+		return nil
 	}
 
-	// Load memory is handled in the storage layer during persona loading
-	// This method is called during initialization but actual loading
-	// happens in LoadPersona method
-	return nil
+	data, err := p.repo.LoadMemory(p.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return p.memoryMgr.ImportMemory(data)
 }
 
-// saveMemoryToDB saves memory data to database
-func (p *Persona) saveMemoryToDB() error {
-	if p.db == nil {
-		return fmt.Errorf("database connection not available")
+// saveMemory saves the persona's memory to its repository
+func (p *Persona) saveMemory() error {
+	if p.repo == nil {
+		return fmt.Errorf("no repository to save memory to")
 	}
 
 	memoryData, err := p.memoryMgr.ExportMemory()
@@ -192,50 +374,28 @@ func (p *Persona) saveMemoryToDB() error {
 		return fmt.Errorf("failed to export memory: %w", err)
 	}
 
-	query := `UPDATE personas SET memory_data = ?, updated_at = ? WHERE id = ?`
-	_, err = p.db.Exec(query, string(memoryData), time.Now(), p.ID)
-	if err != nil {
-		return fmt.Errorf("failed to save memory: %w", err)
-	}
-
-	return nil
+	return p.repo.SaveMemory(p.ID, memoryData)
 }
 
-// logInteraction logs the LLM interaction to database
+// logInteraction logs the LLM interaction to the persona's repository
 func (p *Persona) logInteraction(req LLMRequest, resp *LLMResponse, duration time.Duration) {
-	if p.db == nil {
-		p.logger.Warn("Database not available for logging interaction")
+	if p.repo == nil {
+		p.logger.Warn("No repository available for logging interaction")
 		return
 	}
 
-	// Prepare context data
-	contextData, _ := json.Marshal(req.Context)
-
-	query := `
-		INSERT INTO llm_interaction_logs (
-			id, persona_id, prompt, system_message, response,
-			model_name, temperature, max_tokens, tokens_used,
-			duration_ms, context_data, created_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`
-
-	logID := fmt.Sprintf("%s_%d", p.ID, time.Now().UnixNano())
-
-	_, err := p.db.Exec(query,
-		logID,
-		p.ID,
-		req.Prompt,
-		req.SystemMsg,
-		resp.Content,
-		resp.Model,
-		req.Temperature,
-		req.MaxTokens,
-		resp.TokensUsed,
-		duration.Milliseconds(),
-		string(contextData),
-		time.Now(),
-	)
-
+	err := p.repo.LogInteraction(&InteractionLog{
+		PersonaID:     p.ID,
+		Prompt:        req.Prompt,
+		SystemMessage: req.SystemMsg,
+		Response:      resp.Content,
+		Model:         resp.Model,
+		Temperature:   req.Temperature,
+		MaxTokens:     req.MaxTokens,
+		TokensUsed:    resp.TokensUsed,
+		Duration:      duration,
+		Context:       req.Context,
+	})
 	if err != nil {
 		p.logger.Error("Failed to log LLM interaction", "persona_id", p.ID, "error", err)
 	}
@@ -351,11 +511,10 @@ func (p *Persona) UpdateTraits(newTraitsConfig string) error {
 		return fmt.Errorf("failed to load new traits: %w", err)
 	}
 
-	p.Traits = newTraits
-	p.updatedAt = time.Now()
+	p.SwapTraits(newTraits)
 
 	// Save to database
-	if err := p.saveMemoryToDB(); err != nil {
+	if err := p.saveMemory(); err != nil {
 		p.logger.Warn("Failed to save updated traits to database", "persona_id", p.ID, "error", err)
 	}
 
@@ -377,34 +536,39 @@ func (p *Persona) UpdateTraits(newTraitsConfig string) error {
 
 // GetPersonalityProfile returns a summary of the persona's personality
 func (p *Persona) GetPersonalityProfile() map[string]interface{} {
+	traits := p.CurrentTraits()
 	profile := map[string]interface{}{
 		"id":           p.ID,
 		"name":         p.Name,
 		"description":  p.Description,
-		"persona_type": p.Traits.Config.PersonaType,
+		"persona_type": traits.Config.PersonaType,
 		"created_at":   p.createdAt,
 		"updated_at":   p.updatedAt,
 	}
 
 	// Add key traits
 	profile["core_traits"] = map[string]interface{}{
-		"creativity":     p.Traits.GetIntTrait("core_dimensions", "creativity"),
-		"analytical":     p.Traits.GetIntTrait("core_dimensions", "analytical"),
-		"optimism":       p.Traits.GetIntTrait("core_dimensions", "optimism"),
-		"risk_tolerance": p.Traits.GetIntTrait("core_dimensions", "risk_tolerance"),
-		"empathy":        p.Traits.GetIntTrait("core_dimensions", "empathy"),
-		"assertiveness":  p.Traits.GetIntTrait("core_dimensions", "assertiveness"),
+		"creativity":     traits.GetIntTrait("core_dimensions", "creativity"),
+		"analytical":     traits.GetIntTrait("core_dimensions", "analytical"),
+		"optimism":       traits.GetIntTrait("core_dimensions", "optimism"),
+		"risk_tolerance": traits.GetIntTrait("core_dimensions", "risk_tolerance"),
+		"empathy":        traits.GetIntTrait("core_dimensions", "empathy"),
+		"assertiveness":  traits.GetIntTrait("core_dimensions", "assertiveness"),
 	}
 
 	// Add communication style
 	profile["communication_style"] = map[string]interface{}{
-		"formality":  p.Traits.GetStringTrait("communication_style", "formality"),
-		"directness": p.Traits.GetStringTrait("communication_style", "directness"),
-		"verbosity":  p.Traits.GetStringTrait("communication_style", "verbosity"),
+		"formality":  traits.GetStringTrait("communication_style", "formality"),
+		"directness": traits.GetStringTrait("communication_style", "directness"),
+		"verbosity":  traits.GetStringTrait("communication_style", "verbosity"),
 	}
 
+	// Add every effective trait value with the file it came from
+	profile["trait_sources"] = traits.TraitProvenance()
+	profile["lineage"] = traits.Lineage
+
 	// Add expertise and memory stats
-	profile["expertise_areas"] = p.Traits.ExpertiseAreas
+	profile["expertise_areas"] = traits.ExpertiseAreas
 	profile["memory_stats"] = p.memoryMgr.GetMemoryStats()
 
 	return profile
@@ -413,7 +577,7 @@ func (p *Persona) GetPersonalityProfile() map[string]interface{} {
 // Clone creates a copy of the persona with slight variations
 func (p *Persona) Clone(newID, newName string) (*Persona, error) {
 	// Create a copy of traits with small random variations
-	clonedTraits := *p.Traits
+	clonedTraits := p.CurrentTraits().Clone()
 
 	// Apply small random variations to core dimensions (±1 point)
 	for key := range clonedTraits.CoreDimensions {
@@ -429,14 +593,8 @@ func (p *Persona) Clone(newID, newName string) (*Persona, error) {
 		}
 	}
 
-	// Create new persona with varied traits
-	traitsData, err := json.Marshal(&clonedTraits)
-	if err != nil {
-		return nil, fmt.Errorf("failed to serialize cloned traits: %w", err)
-	}
-
-	// Create new persona instance
-	newPersona, err := New(newID, newName, p.Description, string(traitsData), p.db, p.llmProvider, p.logger)
+	// Create new persona instance with the varied traits
+	newPersona, err := NewWithTraits(newID, newName, p.Description, clonedTraits, p.repo, p.llmProvider, p.logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create cloned persona: %w", err)
 	}
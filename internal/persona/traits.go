@@ -38,6 +38,7 @@ type BaseTraitConfig struct {
 // PersonalityConfig represents a specific personality configuration
 type PersonalityConfig struct {
 	Extends             string                   `json:"extends"`
+	Mixins              []string                 `json:"mixins,omitempty"`
 	PersonaType         string                   `json:"persona_type"`
 	Name                string                   `json:"name"`
 	Description         string                   `json:"description"`
@@ -53,6 +54,7 @@ type PersonalityConfig struct {
 	SpeakingPatterns    SpeakingPatterns         `json:"speaking_patterns"`
 	EmotionalTriggers   EmotionalTriggers        `json:"emotional_triggers"`
 	ResponseModifiers   map[string]TraitModifier `json:"response_modifiers"`
+	Memory              *MemorySettings          `json:"memory,omitempty"`
 }
 
 // TraitConstraints defines validation rules for trait combinations
@@ -105,6 +107,8 @@ type PersonalityTraits struct {
 	SpeakingPatterns    SpeakingPatterns
 	EmotionalTriggers   EmotionalTriggers
 	ResponseModifiers   map[string]TraitModifier
+	Sources             map[string]string // "category.trait" -> file the value came from
+	Lineage             []string          // files applied, from base.json to the persona itself
 }
 
 // TraitLoader handles loading and validation of personality traits
@@ -152,26 +156,23 @@ func (tl *TraitLoader) LoadPersonalityConfig(filename string) (*PersonalityTrait
 		}
 	}
 
-	configPath := filepath.Join(tl.configPath, "traits", filename)
-
-	file, err := os.Open(configPath)
+	name, err := traitFileName(filename)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open personality config: %w", err)
+		return nil, err
 	}
-	defer file.Close()
-
-	data, err := io.ReadAll(file)
+	config, err := tl.readConfigFile(name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read personality config: %w", err)
+		return nil, err
 	}
 
-	var config PersonalityConfig
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse personality config: %w", err)
+	// Flatten the inheritance chain and mixins
+	resolved, err := tl.resolve(config, name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve inheritance: %w", err)
 	}
 
-	// Merge base config with personality config
-	traits, err := tl.mergeTraits(&config)
+	// Merge base config with the resolved personality config
+	traits, err := tl.buildTraits(resolved)
 	if err != nil {
 		return nil, fmt.Errorf("failed to merge traits: %w", err)
 	}
@@ -306,27 +307,21 @@ func (tl *TraitLoader) mergeTraitCategory(baseDefs map[string]TraitDefinition, c
 
 // validateTraits validates that trait values conform to their definitions and constraints
 func (tl *TraitLoader) validateTraits(traits *PersonalityTraits) error {
-	// Validate core dimensions
-	for key, value := range traits.CoreDimensions {
-		if def, exists := tl.baseConfig.CoreDimensions[key]; exists {
-			if err := tl.validateTraitValue(value, def, key); err != nil {
-				return err
-			}
-		}
-	}
-
-	// Validate communication style
-	for key, value := range traits.CommunicationStyle {
-		if def, exists := tl.baseConfig.CommunicationStyle[key]; exists {
-			if err := tl.validateTraitValue(value, def, key); err != nil {
-				return err
+	// Validate every category that has definitions in the base config
+	for _, category := range TraitCategories {
+		defs := tl.baseConfig.definitions(category)
+		for key, value := range traits.categoryValues(category) {
+			if def, exists := defs[key]; exists {
+				if err := tl.validateTraitValue(value, def, key); err != nil {
+					return err
+				}
 			}
 		}
 	}
 
 	// Validate constraint rules
 	for _, rule := range tl.baseConfig.Constraints.TraitSumLimits.Rules {
-		if err := tl.validateConstraintRule(traits, rule); err != nil {
+		if err := validateConstraintRule(traits, rule); err != nil {
 			return err
 		}
 	}
@@ -367,7 +362,7 @@ func (tl *TraitLoader) validateTraitValue(value TraitValue, def TraitDefinition,
 }
 
 // validateConstraintRule validates a constraint rule across multiple traits
-func (tl *TraitLoader) validateConstraintRule(traits *PersonalityTraits, rule ConstraintRule) error {
+func validateConstraintRule(traits *PersonalityTraits, rule ConstraintRule) error {
 	total := 0
 	for _, traitName := range rule.Traits {
 		if value, exists := traits.CoreDimensions[traitName]; exists {
@@ -388,35 +383,43 @@ func (tl *TraitLoader) validateConstraintRule(traits *PersonalityTraits, rule Co
 	return nil
 }
 
+// MemorySettings returns the memory settings for this personality, applying any
+// per-persona overrides from the traits file on top of the configured defaults
+func (pt *PersonalityTraits) MemorySettings() MemorySettings {
+	settings := CurrentMemorySettings()
+	if pt == nil || pt.Config == nil {
+		return settings
+	}
+	return settings.Merge(pt.Config.Memory)
+}
+
 // GetTraitValue retrieves a trait value with type safety
 func (pt *PersonalityTraits) GetTraitValue(category, traitName string) (TraitValue, bool) {
+	value, exists := pt.categoryValues(category)[traitName]
+	return value, exists
+}
+
+// categoryValues returns the trait map for a category
+func (pt *PersonalityTraits) categoryValues(category string) map[string]TraitValue {
 	switch category {
 	case "core_dimensions":
-		value, exists := pt.CoreDimensions[traitName]
-		return value, exists
+		return pt.CoreDimensions
 	case "communication_style":
-		value, exists := pt.CommunicationStyle[traitName]
-		return value, exists
+		return pt.CommunicationStyle
 	case "biases_and_tendencies":
-		value, exists := pt.BiasesAndTendencies[traitName]
-		return value, exists
+		return pt.BiasesAndTendencies
 	case "response_patterns":
-		value, exists := pt.ResponsePatterns[traitName]
-		return value, exists
+		return pt.ResponsePatterns
 	case "decision_making":
-		value, exists := pt.DecisionMaking[traitName]
-		return value, exists
+		return pt.DecisionMaking
 	case "temporal_orientation":
-		value, exists := pt.TemporalOrientation[traitName]
-		return value, exists
+		return pt.TemporalOrientation
 	case "learning_style":
-		value, exists := pt.LearningStyle[traitName]
-		return value, exists
+		return pt.LearningStyle
 	case "custom_traits":
-		value, exists := pt.CustomTraits[traitName]
-		return value, exists
+		return pt.CustomTraits
 	default:
-		return nil, false
+		return nil
 	}
 }
 
@@ -439,28 +442,3 @@ func (pt *PersonalityTraits) GetStringTrait(category, traitName string) string {
 	}
 	return "" // Default empty string
 }
-
-// ApplyContextModifier applies trait modifications based on context
-func (pt *PersonalityTraits) ApplyContextModifier(context string) *PersonalityTraits {
-	if modifier, exists := pt.ResponseModifiers[context]; exists {
-		// Create a copy of traits with modifications
-		modified := *pt
-
-		// Apply modifications to relevant trait categories
-		for traitName, newValue := range modifier {
-			// Try to find and update the trait in appropriate category
-			if _, exists := modified.CoreDimensions[traitName]; exists {
-				modified.CoreDimensions[traitName] = newValue
-			} else if _, exists := modified.CommunicationStyle[traitName]; exists {
-				modified.CommunicationStyle[traitName] = newValue
-			} else if _, exists := modified.ResponsePatterns[traitName]; exists {
-				modified.ResponsePatterns[traitName] = newValue
-			}
-			// Add other categories as needed
-		}
-
-		return &modified
-	}
-
-	return pt
-}
@@ -0,0 +1,302 @@
+package project
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// InMemoryStorage is a Repository that keeps projects in memory. It behaves
+// like Storage, so that code using projects can be tested without SQLite.
+type InMemoryStorage struct {
+	mu        sync.RWMutex
+	projects  map[string]*Project
+	ideas     map[string]*Idea
+	documents map[string]*Document
+}
+
+// NewInMemoryStorage creates an empty in-memory project store
+func NewInMemoryStorage() *InMemoryStorage {
+	return &InMemoryStorage{
+		projects:  make(map[string]*Project),
+		ideas:     make(map[string]*Idea),
+		documents: make(map[string]*Document),
+	}
+}
+
+// SaveProject saves a project, keeping the creation time of one already
+// saved. Ideas are saved separately with SaveIdea.
+func (s *InMemoryStorage) SaveProject(project *Project) error {
+	stored := &Project{}
+	if err := copyJSON(project, stored); err != nil {
+		return fmt.Errorf("failed to save project: %w", err)
+	}
+	stored.Ideas = nil
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.projects[project.ID]; ok {
+		stored.CreatedAt = existing.CreatedAt
+	}
+	stored.UpdatedAt = time.Now()
+	s.projects[project.ID] = stored
+
+	return nil
+}
+
+// LoadProject loads a project and its ideas
+func (s *InMemoryStorage) LoadProject(id string) (*Project, error) {
+	s.mu.RLock()
+	stored, ok := s.projects[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("failed to load project: %w", sql.ErrNoRows)
+	}
+
+	project := &Project{}
+	if err := copyJSON(stored, project); err != nil {
+		return nil, fmt.Errorf("failed to load project: %w", err)
+	}
+	if project.Metadata == nil {
+		project.Metadata = make(map[string]interface{})
+	}
+
+	ideas, err := s.ListIdeas(id)
+	if err != nil {
+		return nil, err
+	}
+	project.Ideas = ideas
+
+	return project, nil
+}
+
+// ListProjects returns all projects, most recently updated first
+func (s *InMemoryStorage) ListProjects() ([]ProjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ideaCounts := make(map[string]int)
+	for _, idea := range s.ideas {
+		ideaCounts[idea.ProjectID]++
+	}
+
+	projects := make([]ProjectInfo, 0, len(s.projects))
+	for _, project := range s.projects {
+		projects = append(projects, ProjectInfo{
+			ID:          project.ID,
+			Name:        project.Name,
+			Description: project.Description,
+			Status:      project.Status,
+			IdeaCount:   ideaCounts[project.ID],
+			CreatedAt:   project.CreatedAt,
+			UpdatedAt:   project.UpdatedAt,
+		})
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].UpdatedAt.After(projects[j].UpdatedAt)
+	})
+
+	return projects, nil
+}
+
+// PageProjects returns a page of projects, most recently updated first
+func (s *InMemoryStorage) PageProjects(limit, offset int) ([]ProjectInfo, error) {
+	projects, err := s.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+	start, end := pageBounds(len(projects), limit, offset)
+	return projects[start:end], nil
+}
+
+// CountProjects returns the number of projects
+func (s *InMemoryStorage) CountProjects() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.projects), nil
+}
+
+// DeleteProject removes a project with its ideas and documents
+func (s *InMemoryStorage) DeleteProject(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.projects[id]; !ok {
+		return fmt.Errorf("project not found: %s", id)
+	}
+	delete(s.projects, id)
+
+	for ideaID, idea := range s.ideas {
+		if idea.ProjectID == id {
+			delete(s.ideas, ideaID)
+		}
+	}
+	for docID, doc := range s.documents {
+		if doc.ProjectID == id {
+			delete(s.documents, docID)
+		}
+	}
+
+	return nil
+}
+
+// SaveIdea saves an idea of a saved project
+func (s *InMemoryStorage) SaveIdea(idea *Idea) error {
+	stored := &Idea{}
+	if err := copyJSON(idea, stored); err != nil {
+		return fmt.Errorf("failed to save idea: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.projects[idea.ProjectID]; !ok {
+		return fmt.Errorf("failed to save idea: project not found: %s", idea.ProjectID)
+	}
+	if existing, ok := s.ideas[idea.ID]; ok {
+		stored.ProjectID = existing.ProjectID
+		stored.CreatedAt = existing.CreatedAt
+	}
+	stored.UpdatedAt = time.Now()
+	s.ideas[idea.ID] = stored
+
+	return nil
+}
+
+// ListIdeas returns the ideas of a project, highest priority first
+func (s *InMemoryStorage) ListIdeas(projectID string) ([]Idea, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ideas := make([]Idea, 0)
+	for _, stored := range s.ideas {
+		if stored.ProjectID != projectID {
+			continue
+		}
+		var idea Idea
+		if err := copyJSON(stored, &idea); err != nil {
+			return nil, fmt.Errorf("failed to scan idea: %w", err)
+		}
+		ideas = append(ideas, idea)
+	}
+	sort.Slice(ideas, func(i, j int) bool {
+		if ideas[i].Priority != ideas[j].Priority {
+			return ideas[i].Priority > ideas[j].Priority
+		}
+		return ideas[i].CreatedAt.Before(ideas[j].CreatedAt)
+	})
+
+	return ideas, nil
+}
+
+// PageIdeas returns a page of the ideas of a project, highest priority first
+func (s *InMemoryStorage) PageIdeas(projectID string, limit, offset int) ([]Idea, error) {
+	ideas, err := s.ListIdeas(projectID)
+	if err != nil {
+		return nil, err
+	}
+	start, end := pageBounds(len(ideas), limit, offset)
+	return ideas[start:end], nil
+}
+
+// CountIdeas returns the number of ideas in a project
+func (s *InMemoryStorage) CountIdeas(projectID string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, idea := range s.ideas {
+		if idea.ProjectID == projectID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// DeleteIdea removes an idea
+func (s *InMemoryStorage) DeleteIdea(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.ideas[id]; !ok {
+		return fmt.Errorf("idea not found: %s", id)
+	}
+	delete(s.ideas, id)
+
+	return nil
+}
+
+// SaveDocument records a document attached to a saved project
+func (s *InMemoryStorage) SaveDocument(doc *Document) error {
+	stored := &Document{}
+	if err := copyJSON(doc, stored); err != nil {
+		return fmt.Errorf("failed to save document: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.projects[doc.ProjectID]; !ok {
+		return fmt.Errorf("failed to save document: project not found: %s", doc.ProjectID)
+	}
+	if existing, ok := s.documents[doc.ID]; ok {
+		stored.ProjectID = existing.ProjectID
+		stored.CreatedAt = existing.CreatedAt
+	}
+	stored.UpdatedAt = time.Now()
+	s.documents[doc.ID] = stored
+
+	return nil
+}
+
+// ListDocuments returns the documents of a project, newest first
+func (s *InMemoryStorage) ListDocuments(projectID string) ([]Document, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	docs := make([]Document, 0)
+	for _, stored := range s.documents {
+		if stored.ProjectID != projectID {
+			continue
+		}
+		var doc Document
+		if err := copyJSON(stored, &doc); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].CreatedAt.After(docs[j].CreatedAt)
+	})
+
+	return docs, nil
+}
+
+// copyJSON deep-copies a value through JSON, as saving it to the database and
+// loading it back would
+func copyJSON(from, to interface{}) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
+
+// pageBounds returns the range of indexes of a page of n items. A limit of
+// zero takes the rest of the items from offset.
+func pageBounds(n, limit, offset int) (int, int) {
+	start := offset
+	if start > n {
+		start = n
+	}
+	end := n
+	if limit > 0 && start+limit < n {
+		end = start + limit
+	}
+	return start, end
+}
@@ -0,0 +1,101 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Project statuses
+const (
+	StatusActive   = "active"
+	StatusArchived = "archived"
+)
+
+// Project groups ideas and documents for analysis by a board
+type Project struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Status      string                 `json:"status"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Ideas       []Idea                 `json:"ideas"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// Idea is a single idea captured within a project
+type Idea struct {
+	ID          string    `json:"id"`
+	ProjectID   string    `json:"project_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	Content     string    `json:"content,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Priority    int       `json:"priority"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Document is a file attached to a project
+type Document struct {
+	ID          string                 `json:"id"`
+	ProjectID   string                 `json:"project_id"`
+	Name        string                 `json:"name"`
+	FilePath    string                 `json:"file_path"`
+	ContentType string                 `json:"content_type"`
+	Size        int64                  `json:"size"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// ProjectInfo contains summary information about a project
+type ProjectInfo struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+	IdeaCount   int       `json:"idea_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// New creates a new active project
+func New(id, name, description string) (*Project, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("project name cannot be empty")
+	}
+
+	now := time.Now()
+	return &Project{
+		ID:          id,
+		Name:        name,
+		Description: description,
+		Status:      StatusActive,
+		Metadata:    make(map[string]interface{}),
+		Ideas:       make([]Idea, 0),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// NewIdea creates a new draft idea for a project
+func NewIdea(id, projectID, title, content string) (*Idea, error) {
+	if strings.TrimSpace(title) == "" {
+		return nil, fmt.Errorf("idea title cannot be empty")
+	}
+
+	now := time.Now()
+	return &Idea{
+		ID:        id,
+		ProjectID: projectID,
+		Title:     title,
+		Content:   content,
+		Tags:      make([]string, 0),
+		Status:    "draft",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
@@ -0,0 +1,25 @@
+package project
+
+// Repository stores projects with their ideas and documents. LoadProject
+// returns an error wrapping sql.ErrNoRows for an unknown project, and
+// DeleteProject and DeleteIdea an error for one that does not exist. Deleting
+// a project deletes its ideas and documents.
+type Repository interface {
+	SaveProject(project *Project) error
+	LoadProject(id string) (*Project, error)
+	ListProjects() ([]ProjectInfo, error)
+	// PageProjects returns projects in the order of ListProjects, skipping
+	// offset of them. A limit of zero returns the rest.
+	PageProjects(limit, offset int) ([]ProjectInfo, error)
+	CountProjects() (int, error)
+	DeleteProject(id string) error
+	SaveIdea(idea *Idea) error
+	ListIdeas(projectID string) ([]Idea, error)
+	// PageIdeas returns a project's ideas in the order of ListIdeas, skipping
+	// offset of them. A limit of zero returns the rest.
+	PageIdeas(projectID string, limit, offset int) ([]Idea, error)
+	CountIdeas(projectID string) (int, error)
+	DeleteIdea(id string) error
+	SaveDocument(doc *Document) error
+	ListDocuments(projectID string) ([]Document, error)
+}
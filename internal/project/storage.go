@@ -0,0 +1,363 @@
+package project
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"personal-ai-board/internal/auth"
+)
+
+// Storage handles database operations for the projects of one workspace and
+// their ideas and documents
+type Storage struct {
+	db        *sql.DB
+	workspace string
+}
+
+// NewStorage creates a storage instance for the default workspace
+func NewStorage(db *sql.DB) *Storage {
+	return NewWorkspaceStorage(db, auth.DefaultWorkspaceID)
+}
+
+// NewWorkspaceStorage creates a storage instance for the projects of a
+// workspace
+func NewWorkspaceStorage(db *sql.DB, workspaceID string) *Storage {
+	return &Storage{db: db, workspace: workspaceID}
+}
+
+// SaveProject saves a project to the database. Ideas are saved separately with SaveIdea.
+func (s *Storage) SaveProject(project *Project) error {
+	metadata, err := json.Marshal(project.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to serialize project metadata: %w", err)
+	}
+
+	// Upsert rather than replace so that ideas are not cascade-deleted. A
+	// project of another workspace is left alone.
+	result, err := s.db.Exec(`
+		INSERT INTO projects (id, workspace_id, name, description, metadata, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			description = excluded.description,
+			metadata = excluded.metadata,
+			status = excluded.status,
+			updated_at = excluded.updated_at
+		WHERE projects.workspace_id = excluded.workspace_id
+	`, project.ID, s.workspace, project.Name, project.Description, string(metadata), project.Status, project.CreatedAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save project: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("failed to save project %s: %w", project.ID, sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// LoadProject loads a project and its ideas from the database
+func (s *Storage) LoadProject(id string) (*Project, error) {
+	var project Project
+	var description, metadata, status sql.NullString
+
+	err := s.db.QueryRow(`
+		SELECT id, name, description, metadata, status, created_at, updated_at
+		FROM projects WHERE id = ? AND workspace_id = ?
+	`, id, s.workspace).Scan(
+		&project.ID,
+		&project.Name,
+		&description,
+		&metadata,
+		&status,
+		&project.CreatedAt,
+		&project.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project: %w", err)
+	}
+
+	project.Description = description.String
+	project.Status = status.String
+	project.Metadata = make(map[string]interface{})
+	if metadata.Valid && metadata.String != "" {
+		if err := json.Unmarshal([]byte(metadata.String), &project.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to deserialize project metadata: %w", err)
+		}
+	}
+
+	ideas, err := s.ListIdeas(id)
+	if err != nil {
+		return nil, err
+	}
+	project.Ideas = ideas
+
+	return &project, nil
+}
+
+// ListProjects returns all projects
+func (s *Storage) ListProjects() ([]ProjectInfo, error) {
+	return s.PageProjects(0, 0)
+}
+
+// PageProjects returns a page of projects, most recently updated first
+func (s *Storage) PageProjects(limit, offset int) ([]ProjectInfo, error) {
+	if limit == 0 {
+		limit = -1 // SQLite reads a negative limit as no limit
+	}
+	rows, err := s.db.Query(`
+		SELECT p.id, p.name, COALESCE(p.description, ''), COALESCE(p.status, ''),
+		       (SELECT COUNT(*) FROM project_ideas i WHERE i.project_id = p.id),
+		       p.created_at, p.updated_at
+		FROM projects p
+		WHERE p.workspace_id = ?
+		ORDER BY p.updated_at DESC
+		LIMIT ? OFFSET ?
+	`, s.workspace, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := make([]ProjectInfo, 0)
+	for rows.Next() {
+		var info ProjectInfo
+		err := rows.Scan(
+			&info.ID,
+			&info.Name,
+			&info.Description,
+			&info.Status,
+			&info.IdeaCount,
+			&info.CreatedAt,
+			&info.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, info)
+	}
+
+	return projects, rows.Err()
+}
+
+// CountProjects returns the number of projects
+func (s *Storage) CountProjects() (int, error) {
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM projects WHERE workspace_id = ?", s.workspace).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count projects: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteProject removes a project and its ideas from the database
+func (s *Storage) DeleteProject(id string) error {
+	result, err := s.db.Exec("DELETE FROM projects WHERE id = ? AND workspace_id = ?", id, s.workspace)
+	if err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("project not found: %s", id)
+	}
+
+	if _, err := s.db.Exec("DELETE FROM project_ideas WHERE project_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete project ideas: %w", err)
+	}
+
+	return nil
+}
+
+// SaveIdea saves an idea to the database
+func (s *Storage) SaveIdea(idea *Idea) error {
+	tags, err := json.Marshal(idea.Tags)
+	if err != nil {
+		return fmt.Errorf("failed to serialize idea tags: %w", err)
+	}
+	if err := s.checkProject(idea.ProjectID); err != nil {
+		return fmt.Errorf("failed to save idea: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO project_ideas (id, project_id, title, description, content, tags, priority, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			title = excluded.title,
+			description = excluded.description,
+			content = excluded.content,
+			tags = excluded.tags,
+			priority = excluded.priority,
+			status = excluded.status,
+			updated_at = excluded.updated_at
+	`, idea.ID, idea.ProjectID, idea.Title, idea.Description, idea.Content, string(tags),
+		idea.Priority, idea.Status, idea.CreatedAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save idea: %w", err)
+	}
+
+	return nil
+}
+
+// ListIdeas returns the ideas of a project, highest priority first
+func (s *Storage) ListIdeas(projectID string) ([]Idea, error) {
+	return s.PageIdeas(projectID, 0, 0)
+}
+
+// PageIdeas returns a page of the ideas of a project, highest priority first
+func (s *Storage) PageIdeas(projectID string, limit, offset int) ([]Idea, error) {
+	if limit == 0 {
+		limit = -1 // SQLite reads a negative limit as no limit
+	}
+	rows, err := s.db.Query(`
+		SELECT id, project_id, title, COALESCE(description, ''), COALESCE(content, ''),
+		       COALESCE(tags, ''), priority, COALESCE(status, ''), created_at, updated_at
+		FROM project_ideas
+		WHERE project_id = ? AND project_id IN (SELECT id FROM projects WHERE workspace_id = ?)
+		ORDER BY priority DESC, created_at
+		LIMIT ? OFFSET ?
+	`, projectID, s.workspace, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ideas: %w", err)
+	}
+	defer rows.Close()
+
+	ideas := make([]Idea, 0)
+	for rows.Next() {
+		var idea Idea
+		var tags string
+		err := rows.Scan(
+			&idea.ID,
+			&idea.ProjectID,
+			&idea.Title,
+			&idea.Description,
+			&idea.Content,
+			&tags,
+			&idea.Priority,
+			&idea.Status,
+			&idea.CreatedAt,
+			&idea.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan idea: %w", err)
+		}
+		if tags != "" {
+			if err := json.Unmarshal([]byte(tags), &idea.Tags); err != nil {
+				return nil, fmt.Errorf("failed to deserialize idea tags: %w", err)
+			}
+		}
+		ideas = append(ideas, idea)
+	}
+
+	return ideas, rows.Err()
+}
+
+// CountIdeas returns the number of ideas in a project
+func (s *Storage) CountIdeas(projectID string) (int, error) {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM project_ideas
+		WHERE project_id = ? AND project_id IN (SELECT id FROM projects WHERE workspace_id = ?)
+	`, projectID, s.workspace).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count ideas: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteIdea removes an idea from the database
+func (s *Storage) DeleteIdea(id string) error {
+	result, err := s.db.Exec(`
+		DELETE FROM project_ideas
+		WHERE id = ? AND project_id IN (SELECT id FROM projects WHERE workspace_id = ?)
+	`, id, s.workspace)
+	if err != nil {
+		return fmt.Errorf("failed to delete idea: %w", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("idea not found: %s", id)
+	}
+
+	return nil
+}
+
+// SaveDocument records a document attached to a project
+func (s *Storage) SaveDocument(doc *Document) error {
+	metadata, err := json.Marshal(doc.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to serialize document metadata: %w", err)
+	}
+	if err := s.checkProject(doc.ProjectID); err != nil {
+		return fmt.Errorf("failed to save document: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO project_documents (id, project_id, name, file_path, content_type, size, metadata, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			file_path = excluded.file_path,
+			content_type = excluded.content_type,
+			size = excluded.size,
+			metadata = excluded.metadata,
+			updated_at = excluded.updated_at
+	`, doc.ID, doc.ProjectID, doc.Name, doc.FilePath, doc.ContentType, doc.Size, string(metadata), doc.CreatedAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save document: %w", err)
+	}
+
+	return nil
+}
+
+// ListDocuments returns the documents of a project, newest first
+func (s *Storage) ListDocuments(projectID string) ([]Document, error) {
+	rows, err := s.db.Query(`
+		SELECT id, project_id, name, file_path, content_type, size, COALESCE(metadata, ''), created_at, updated_at
+		FROM project_documents
+		WHERE project_id = ? AND project_id IN (SELECT id FROM projects WHERE workspace_id = ?)
+		ORDER BY created_at DESC
+	`, projectID, s.workspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query documents: %w", err)
+	}
+	defer rows.Close()
+
+	docs := make([]Document, 0)
+	for rows.Next() {
+		var doc Document
+		var metadata string
+		err := rows.Scan(
+			&doc.ID,
+			&doc.ProjectID,
+			&doc.Name,
+			&doc.FilePath,
+			&doc.ContentType,
+			&doc.Size,
+			&metadata,
+			&doc.CreatedAt,
+			&doc.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		if metadata != "" {
+			if err := json.Unmarshal([]byte(metadata), &doc.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to deserialize document metadata: %w", err)
+			}
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, rows.Err()
+}
+
+// checkProject returns an error wrapping sql.ErrNoRows unless a project
+// belongs to the storage's workspace
+func (s *Storage) checkProject(projectID string) error {
+	var id string
+	err := s.db.QueryRow("SELECT id FROM projects WHERE id = ? AND workspace_id = ?", projectID, s.workspace).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("project %s: %w", projectID, err)
+	}
+	return nil
+}
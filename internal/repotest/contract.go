@@ -0,0 +1,643 @@
+package repotest
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"personal-ai-board/internal/analysis"
+	"personal-ai-board/internal/board"
+	"personal-ai-board/internal/persona"
+	"personal-ai-board/internal/project"
+)
+
+// check is one part of the repository contract
+type check struct {
+	name string
+	run  func(Repositories) error
+}
+
+// checks is the repository contract every implementation must satisfy
+var checks = []check{
+	{"personas", checkPersonas},
+	{"persona memory", checkMemory},
+	{"interaction logs", checkInteractions},
+	{"boards", checkBoards},
+	{"projects", checkProjects},
+	{"ideas and documents", checkIdeasAndDocuments},
+	{"analyses", checkAnalyses},
+}
+
+// nopLogger discards log messages
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string, args ...interface{})  {}
+func (nopLogger) Error(msg string, args ...interface{}) {}
+func (nopLogger) Debug(msg string, args ...interface{}) {}
+func (nopLogger) Warn(msg string, args ...interface{})  {}
+
+// savePersona creates and saves a persona
+func savePersona(repos Repositories, id, name string) (*persona.Persona, error) {
+	p, err := persona.NewFromJSONTraits(id, name, name+" persona", `{"name": "`+name+`", "expertise_areas": ["testing"]}`, repos.Personas, nil, nopLogger{})
+	if err != nil {
+		return nil, err
+	}
+	if err := repos.Personas.SavePersona(p); err != nil {
+		return nil, fmt.Errorf("save persona %s: %w", id, err)
+	}
+	return p, nil
+}
+
+// notFound describes an error that should have wrapped sql.ErrNoRows
+func notFound(what string, err error) error {
+	return fmt.Errorf("%s: got error %v, want one wrapping sql.ErrNoRows", what, err)
+}
+
+func checkPersonas(repos Repositories) error {
+	if _, err := repos.Personas.LoadPersona("missing", nil, nopLogger{}); !errors.Is(err, sql.ErrNoRows) {
+		return notFound("load unknown persona", err)
+	}
+
+	first, err := savePersona(repos, "persona_a", "Ada")
+	if err != nil {
+		return err
+	}
+	if _, err := savePersona(repos, "persona_b", "Brian"); err != nil {
+		return err
+	}
+
+	personas, err := repos.Personas.ListPersonas()
+	if err != nil {
+		return fmt.Errorf("list personas: %w", err)
+	}
+	if len(personas) != 2 || personas[0].ID != "persona_b" || personas[1].ID != "persona_a" {
+		return fmt.Errorf("list personas: got %v, want persona_b then persona_a", personas)
+	}
+	createdAt := personas[1].CreatedAt
+
+	first.Name = "Ada Lovelace"
+	if err := repos.Personas.SavePersona(first); err != nil {
+		return fmt.Errorf("update persona: %w", err)
+	}
+	loaded, err := repos.Personas.LoadPersona("persona_a", nil, nopLogger{})
+	if err != nil {
+		return fmt.Errorf("load persona: %w", err)
+	}
+	if loaded.Name != "Ada Lovelace" || loaded.Description != "Ada persona" {
+		return fmt.Errorf("load persona: got %q (%q), want the updated name", loaded.Name, loaded.Description)
+	}
+	if len(loaded.Traits.ExpertiseAreas) != 1 || loaded.Traits.ExpertiseAreas[0] != "testing" {
+		return fmt.Errorf("load persona: got expertise %v, want [testing]", loaded.Traits.ExpertiseAreas)
+	}
+
+	personas, err = repos.Personas.ListPersonas()
+	if err != nil {
+		return fmt.Errorf("list personas: %w", err)
+	}
+	if len(personas) != 2 || personas[0].ID != "persona_a" {
+		return fmt.Errorf("list personas after update: got %v, want persona_a first", personas)
+	}
+	if !personas[0].CreatedAt.Equal(createdAt) {
+		return fmt.Errorf("update persona: creation time changed from %v to %v", createdAt, personas[0].CreatedAt)
+	}
+	if page, err := repos.Personas.PagePersonas(1, 1); err != nil || len(page) != 1 || page[0].ID != "persona_b" {
+		return fmt.Errorf("page personas: got %v (%v), want persona_b", page, err)
+	}
+	if count, err := repos.Personas.CountPersonas(); err != nil || count != 2 {
+		return fmt.Errorf("count personas: got %d (%v), want 2", count, err)
+	}
+
+	if err := repos.Personas.DeletePersona("persona_a"); err != nil {
+		return fmt.Errorf("delete persona: %w", err)
+	}
+	if _, err := repos.Personas.LoadPersona("persona_a", nil, nopLogger{}); !errors.Is(err, sql.ErrNoRows) {
+		return notFound("load deleted persona", err)
+	}
+	if personas, err := repos.Personas.ListPersonas(); err != nil || len(personas) != 1 {
+		return fmt.Errorf("list personas after delete: got %v (%v), want one persona", personas, err)
+	}
+
+	return nil
+}
+
+func checkMemory(repos Repositories) error {
+	if err := repos.Personas.SaveMemory("missing", []byte("{}")); !errors.Is(err, sql.ErrNoRows) {
+		return notFound("save memory of unknown persona", err)
+	}
+	if _, err := repos.Personas.LoadMemory("missing"); !errors.Is(err, sql.ErrNoRows) {
+		return notFound("load memory of unknown persona", err)
+	}
+
+	if _, err := savePersona(repos, "persona_a", "Ada"); err != nil {
+		return err
+	}
+	memory := []byte(`{"persona_id": "persona_a", "short_term": []}`)
+	if err := repos.Personas.SaveMemory("persona_a", memory); err != nil {
+		return fmt.Errorf("save memory: %w", err)
+	}
+	loaded, err := repos.Personas.LoadMemory("persona_a")
+	if err != nil {
+		return fmt.Errorf("load memory: %w", err)
+	}
+	if !bytes.Equal(loaded, memory) {
+		return fmt.Errorf("load memory: got %s, want %s", loaded, memory)
+	}
+
+	return nil
+}
+
+func checkInteractions(repos Repositories) error {
+	for _, id := range []string{"persona_a", "persona_b"} {
+		if _, err := savePersona(repos, id, id); err != nil {
+			return err
+		}
+	}
+
+	start := time.Now().Add(-time.Hour)
+	logs := []*persona.InteractionLog{
+		{ID: "log_1", PersonaID: "persona_a", Prompt: "first", Response: "one", Model: "test", CreatedAt: start},
+		{ID: "log_2", PersonaID: "persona_a", Prompt: "second", Response: "two", Model: "test", CreatedAt: start.Add(time.Minute)},
+		{ID: "log_3", PersonaID: "persona_b", Prompt: "other", Response: "three", Model: "test", CreatedAt: start.Add(2 * time.Minute)},
+		{
+			PersonaID:   "persona_a",
+			Prompt:      "third",
+			Response:    "four",
+			Model:       "test",
+			Temperature: 0.5,
+			MaxTokens:   100,
+			TokensUsed:  42,
+			Duration:    1500 * time.Millisecond,
+			Context:     map[string]interface{}{"topic": "testing"},
+		},
+	}
+	for _, log := range logs {
+		if err := repos.Personas.LogInteraction(log); err != nil {
+			return fmt.Errorf("log interaction %q: %w", log.Prompt, err)
+		}
+	}
+	if logs[3].ID == "" || logs[3].CreatedAt.IsZero() {
+		return fmt.Errorf("log interaction: ID and creation time were not filled in")
+	}
+	if err := repos.Personas.LogInteraction(&persona.InteractionLog{ID: "log_1", PersonaID: "persona_a", Prompt: "again", Response: "again", Model: "test"}); err == nil {
+		return fmt.Errorf("log interaction with a duplicate ID: got no error")
+	}
+
+	listed, err := repos.Personas.ListInteractions("persona_a", 0)
+	if err != nil {
+		return fmt.Errorf("list interactions: %w", err)
+	}
+	if len(listed) != 3 || listed[0].ID != logs[3].ID || listed[1].ID != "log_2" || listed[2].ID != "log_1" {
+		return fmt.Errorf("list interactions: got %d, want the 3 of persona_a newest first", len(listed))
+	}
+	latest := listed[0]
+	if latest.TokensUsed != 42 || latest.MaxTokens != 100 || latest.Temperature != 0.5 || latest.Duration != 1500*time.Millisecond {
+		return fmt.Errorf("list interactions: fields not kept: %+v", latest)
+	}
+	if latest.Context["topic"] != "testing" {
+		return fmt.Errorf("list interactions: got context %v, want the topic", latest.Context)
+	}
+
+	if limited, err := repos.Personas.ListInteractions("persona_a", 2); err != nil || len(limited) != 2 || limited[1].ID != "log_2" {
+		return fmt.Errorf("list interactions with a limit: got %d (%v), want the newest 2", len(limited), err)
+	}
+
+	if err := repos.Personas.DeletePersona("persona_a"); err != nil {
+		return fmt.Errorf("delete persona: %w", err)
+	}
+	if listed, err := repos.Personas.ListInteractions("persona_a", 0); err != nil || len(listed) != 0 {
+		return fmt.Errorf("list interactions of deleted persona: got %d (%v), want none", len(listed), err)
+	}
+	if listed, err := repos.Personas.ListInteractions("persona_b", 0); err != nil || len(listed) != 1 {
+		return fmt.Errorf("list interactions of other persona: got %d (%v), want 1", len(listed), err)
+	}
+
+	return nil
+}
+
+func checkBoards(repos Repositories) error {
+	if _, err := repos.Boards.LoadBoard("missing"); !errors.Is(err, sql.ErrNoRows) {
+		return notFound("load unknown board", err)
+	}
+	if err := repos.Boards.DeleteBoard("missing"); err == nil {
+		return fmt.Errorf("delete unknown board: got no error")
+	}
+
+	for _, p := range [][2]string{{"persona_a", "Ada"}, {"persona_b", "Brian"}, {"persona_c", "Cleo"}} {
+		if _, err := savePersona(repos, p[0], p[1]); err != nil {
+			return err
+		}
+	}
+
+	b, err := board.New("board_a", "Advisors", "People to ask")
+	if err != nil {
+		return err
+	}
+	b.Metadata["focus"] = "product"
+	for _, id := range []string{"persona_b", "persona_a", "persona_c"} {
+		if err := b.AddMember(id, "advisor"); err != nil {
+			return err
+		}
+	}
+	if err := repos.Boards.SaveBoard(b); err != nil {
+		return fmt.Errorf("save board: %w", err)
+	}
+	other, err := board.New("board_b", "Critics", "")
+	if err != nil {
+		return err
+	}
+	if err := repos.Boards.SaveBoard(other); err != nil {
+		return fmt.Errorf("save board: %w", err)
+	}
+
+	loaded, err := repos.Boards.LoadBoard("board_a")
+	if err != nil {
+		return fmt.Errorf("load board: %w", err)
+	}
+	if loaded.Name != "Advisors" || loaded.Description != "People to ask" || loaded.Metadata["focus"] != "product" {
+		return fmt.Errorf("load board: got %+v", loaded)
+	}
+	if len(loaded.Members) != 3 {
+		return fmt.Errorf("load board: got %d members, want 3", len(loaded.Members))
+	}
+	for i, want := range []struct{ id, name string }{{"persona_b", "Brian"}, {"persona_a", "Ada"}, {"persona_c", "Cleo"}} {
+		member := loaded.Members[i]
+		if member.PersonaID != want.id || member.PersonaName != want.name || member.Position != i || member.Role != "advisor" {
+			return fmt.Errorf("load board: member %d is %+v, want %s (%s)", i, member, want.id, want.name)
+		}
+	}
+
+	boards, err := repos.Boards.ListBoards()
+	if err != nil {
+		return fmt.Errorf("list boards: %w", err)
+	}
+	if len(boards) != 2 || boards[0].ID != "board_b" || boards[1].PersonaCount != 3 {
+		return fmt.Errorf("list boards: got %v, want board_b then board_a with 3 personas", boards)
+	}
+	createdAt := boards[1].CreatedAt
+	if page, err := repos.Boards.PageBoards(1, 1); err != nil || len(page) != 1 || page[0].ID != "board_a" {
+		return fmt.Errorf("page boards: got %v (%v), want board_a", page, err)
+	}
+	if page, err := repos.Boards.PageBoards(0, 2); err != nil || len(page) != 0 {
+		return fmt.Errorf("page boards past the end: got %v (%v), want none", page, err)
+	}
+	if count, err := repos.Boards.CountBoards(); err != nil || count != 2 {
+		return fmt.Errorf("count boards: got %d (%v), want 2", count, err)
+	}
+
+	if err := loaded.RemoveMember("persona_a"); err != nil {
+		return err
+	}
+	if err := repos.Boards.SaveBoard(loaded); err != nil {
+		return fmt.Errorf("update board: %w", err)
+	}
+	boards, err = repos.Boards.ListBoards()
+	if err != nil {
+		return fmt.Errorf("list boards: %w", err)
+	}
+	if boards[0].ID != "board_a" || boards[0].PersonaCount != 2 || !boards[0].CreatedAt.Equal(createdAt) {
+		return fmt.Errorf("list boards after update: got %v, want board_a first with 2 personas and its creation time", boards)
+	}
+	if updated, err := repos.Boards.LoadBoard("board_a"); err != nil || len(updated.Members) != 2 || updated.Members[1].PersonaID != "persona_c" || updated.Members[1].Position != 1 {
+		return fmt.Errorf("load updated board: got %+v (%v), want persona_b and persona_c", updated, err)
+	}
+
+	if err := repos.Boards.DeleteBoard("board_a"); err != nil {
+		return fmt.Errorf("delete board: %w", err)
+	}
+	if _, err := repos.Boards.LoadBoard("board_a"); !errors.Is(err, sql.ErrNoRows) {
+		return notFound("load deleted board", err)
+	}
+
+	return nil
+}
+
+// saveProject creates and saves a project
+func saveProject(repos Repositories, id, name string) (*project.Project, error) {
+	p, err := project.New(id, name, name+" description")
+	if err != nil {
+		return nil, err
+	}
+	if err := repos.Projects.SaveProject(p); err != nil {
+		return nil, fmt.Errorf("save project %s: %w", id, err)
+	}
+	return p, nil
+}
+
+func checkProjects(repos Repositories) error {
+	if _, err := repos.Projects.LoadProject("missing"); !errors.Is(err, sql.ErrNoRows) {
+		return notFound("load unknown project", err)
+	}
+	if err := repos.Projects.DeleteProject("missing"); err == nil {
+		return fmt.Errorf("delete unknown project: got no error")
+	}
+
+	first, err := saveProject(repos, "project_a", "Launch")
+	if err != nil {
+		return err
+	}
+	if _, err := saveProject(repos, "project_b", "Hiring"); err != nil {
+		return err
+	}
+
+	projects, err := repos.Projects.ListProjects()
+	if err != nil {
+		return fmt.Errorf("list projects: %w", err)
+	}
+	if len(projects) != 2 || projects[0].ID != "project_b" || projects[1].ID != "project_a" {
+		return fmt.Errorf("list projects: got %v, want project_b then project_a", projects)
+	}
+	createdAt := projects[1].CreatedAt
+	if page, err := repos.Projects.PageProjects(1, 0); err != nil || len(page) != 1 || page[0].ID != "project_b" {
+		return fmt.Errorf("page projects: got %v (%v), want project_b", page, err)
+	}
+	if count, err := repos.Projects.CountProjects(); err != nil || count != 2 {
+		return fmt.Errorf("count projects: got %d (%v), want 2", count, err)
+	}
+
+	first.Status = project.StatusArchived
+	first.Metadata["owner"] = "me"
+	if err := repos.Projects.SaveProject(first); err != nil {
+		return fmt.Errorf("update project: %w", err)
+	}
+	loaded, err := repos.Projects.LoadProject("project_a")
+	if err != nil {
+		return fmt.Errorf("load project: %w", err)
+	}
+	if loaded.Name != "Launch" || loaded.Status != project.StatusArchived || loaded.Metadata["owner"] != "me" {
+		return fmt.Errorf("load project: got %+v", loaded)
+	}
+	if !loaded.CreatedAt.Equal(createdAt) {
+		return fmt.Errorf("update project: creation time changed from %v to %v", createdAt, loaded.CreatedAt)
+	}
+	if projects, err := repos.Projects.ListProjects(); err != nil || projects[0].ID != "project_a" {
+		return fmt.Errorf("list projects after update: got %v (%v), want project_a first", projects, err)
+	}
+
+	if err := repos.Projects.DeleteProject("project_a"); err != nil {
+		return fmt.Errorf("delete project: %w", err)
+	}
+	if _, err := repos.Projects.LoadProject("project_a"); !errors.Is(err, sql.ErrNoRows) {
+		return notFound("load deleted project", err)
+	}
+
+	return nil
+}
+
+func checkIdeasAndDocuments(repos Repositories) error {
+	if _, err := saveProject(repos, "project_a", "Launch"); err != nil {
+		return err
+	}
+	if _, err := saveProject(repos, "project_b", "Hiring"); err != nil {
+		return err
+	}
+
+	orphan, err := project.NewIdea("idea_orphan", "missing", "Orphan", "")
+	if err != nil {
+		return err
+	}
+	if err := repos.Projects.SaveIdea(orphan); err == nil {
+		return fmt.Errorf("save idea of unknown project: got no error")
+	}
+
+	start := time.Now().Add(-time.Hour)
+	ideas := []struct {
+		id, project string
+		priority    int
+	}{
+		{"idea_low", "project_a", 1},
+		{"idea_high", "project_a", 5},
+		{"idea_low_later", "project_a", 1},
+		{"idea_other", "project_b", 3},
+	}
+	for i, spec := range ideas {
+		idea, err := project.NewIdea(spec.id, spec.project, spec.id, "content of "+spec.id)
+		if err != nil {
+			return err
+		}
+		idea.Priority = spec.priority
+		idea.Tags = []string{"tag"}
+		idea.CreatedAt = start.Add(time.Duration(i) * time.Minute)
+		if err := repos.Projects.SaveIdea(idea); err != nil {
+			return fmt.Errorf("save idea %s: %w", spec.id, err)
+		}
+	}
+
+	listed, err := repos.Projects.ListIdeas("project_a")
+	if err != nil {
+		return fmt.Errorf("list ideas: %w", err)
+	}
+	if len(listed) != 3 || listed[0].ID != "idea_high" || listed[1].ID != "idea_low" || listed[2].ID != "idea_low_later" {
+		return fmt.Errorf("list ideas: got %v, want idea_high, idea_low, idea_low_later", listed)
+	}
+	if listed[0].Content != "content of idea_high" || len(listed[0].Tags) != 1 || listed[0].Status != "draft" {
+		return fmt.Errorf("list ideas: fields not kept: %+v", listed[0])
+	}
+	if page, err := repos.Projects.PageIdeas("project_a", 2, 1); err != nil || len(page) != 2 || page[0].ID != "idea_low" || page[1].ID != "idea_low_later" {
+		return fmt.Errorf("page ideas: got %v (%v), want idea_low, idea_low_later", page, err)
+	}
+	if count, err := repos.Projects.CountIdeas("project_a"); err != nil || count != 3 {
+		return fmt.Errorf("count ideas: got %d (%v), want 3", count, err)
+	}
+
+	listed[1].Priority = 9
+	if err := repos.Projects.SaveIdea(&listed[1]); err != nil {
+		return fmt.Errorf("update idea: %w", err)
+	}
+	loaded, err := repos.Projects.LoadProject("project_a")
+	if err != nil {
+		return fmt.Errorf("load project: %w", err)
+	}
+	if len(loaded.Ideas) != 3 || loaded.Ideas[0].ID != "idea_low" {
+		return fmt.Errorf("load project: got ideas %v, want the updated idea_low first", loaded.Ideas)
+	}
+
+	if err := repos.Projects.DeleteIdea("idea_low_later"); err != nil {
+		return fmt.Errorf("delete idea: %w", err)
+	}
+	if err := repos.Projects.DeleteIdea("idea_low_later"); err == nil {
+		return fmt.Errorf("delete deleted idea: got no error")
+	}
+	if listed, err := repos.Projects.ListIdeas("project_a"); err != nil || len(listed) != 2 {
+		return fmt.Errorf("list ideas after delete: got %d (%v), want 2", len(listed), err)
+	}
+
+	for i, id := range []string{"doc_old", "doc_new"} {
+		doc := &project.Document{
+			ID:          id,
+			ProjectID:   "project_a",
+			Name:        id + ".md",
+			FilePath:    "/tmp/" + id + ".md",
+			ContentType: "text/markdown",
+			Size:        int64(10 * (i + 1)),
+			Metadata:    map[string]interface{}{"source": "test"},
+			CreatedAt:   start.Add(time.Duration(i) * time.Minute),
+			UpdatedAt:   start.Add(time.Duration(i) * time.Minute),
+		}
+		if err := repos.Projects.SaveDocument(doc); err != nil {
+			return fmt.Errorf("save document %s: %w", id, err)
+		}
+	}
+	if err := repos.Projects.SaveDocument(&project.Document{ID: "doc_orphan", ProjectID: "missing", Name: "x", FilePath: "x", ContentType: "text/plain"}); err == nil {
+		return fmt.Errorf("save document of unknown project: got no error")
+	}
+	docs, err := repos.Projects.ListDocuments("project_a")
+	if err != nil {
+		return fmt.Errorf("list documents: %w", err)
+	}
+	if len(docs) != 2 || docs[0].ID != "doc_new" || docs[0].Size != 20 || docs[0].Metadata["source"] != "test" {
+		return fmt.Errorf("list documents: got %v, want doc_new then doc_old", docs)
+	}
+
+	projects, err := repos.Projects.ListProjects()
+	if err != nil {
+		return fmt.Errorf("list projects: %w", err)
+	}
+	counts := make(map[string]int)
+	for _, info := range projects {
+		counts[info.ID] = info.IdeaCount
+	}
+	if counts["project_a"] != 2 || counts["project_b"] != 1 {
+		return fmt.Errorf("list projects: got idea counts %v, want 2 and 1", counts)
+	}
+
+	if err := repos.Projects.DeleteProject("project_a"); err != nil {
+		return fmt.Errorf("delete project: %w", err)
+	}
+	if ideas, err := repos.Projects.ListIdeas("project_a"); err != nil || len(ideas) != 0 {
+		return fmt.Errorf("list ideas of deleted project: got %d (%v), want none", len(ideas), err)
+	}
+	if docs, err := repos.Projects.ListDocuments("project_a"); err != nil || len(docs) != 0 {
+		return fmt.Errorf("list documents of deleted project: got %d (%v), want none", len(docs), err)
+	}
+	if ideas, err := repos.Projects.ListIdeas("project_b"); err != nil || len(ideas) != 1 {
+		return fmt.Errorf("list ideas of other project: got %d (%v), want 1", len(ideas), err)
+	}
+
+	return nil
+}
+
+func checkAnalyses(repos Repositories) error {
+	if _, err := repos.Analyses.LoadResult("missing"); !errors.Is(err, sql.ErrNoRows) {
+		return notFound("load unknown result", err)
+	}
+
+	if _, err := savePersona(repos, "persona_a", "Ada"); err != nil {
+		return err
+	}
+	if _, err := saveProject(repos, "project_a", "Launch"); err != nil {
+		return err
+	}
+	b, err := board.New("board_a", "Advisors", "")
+	if err != nil {
+		return err
+	}
+	if err := b.AddMember("persona_a", ""); err != nil {
+		return err
+	}
+	if err := repos.Boards.SaveBoard(b); err != nil {
+		return fmt.Errorf("save board: %w", err)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	orphan := &analysis.Result{ID: "result_orphan", RequestID: "missing", ProjectID: "project_a", BoardID: "board_a", Mode: "analysis", Status: analysis.StatusRunning, StartedAt: start, CreatedAt: start}
+	if err := repos.Analyses.SaveResult(orphan); err == nil {
+		return fmt.Errorf("save result of unknown request: got no error")
+	}
+
+	for i, id := range []string{"a", "b", "c"} {
+		startedAt := start.Add(time.Duration(i) * time.Minute)
+		req := &analysis.Request{
+			ID:        "request_" + id,
+			ProjectID: "project_a",
+			BoardID:   "board_a",
+			Mode:      "analysis",
+			Topic:     "topic " + id,
+			Rounds:    1,
+			CreatedAt: startedAt,
+		}
+		if err := repos.Analyses.SaveRequest(req); err != nil {
+			return fmt.Errorf("save request %s: %w", req.ID, err)
+		}
+		result := &analysis.Result{
+			ID:        "result_" + id,
+			RequestID: req.ID,
+			ProjectID: req.ProjectID,
+			BoardID:   req.BoardID,
+			Mode:      req.Mode,
+			Topic:     req.Topic,
+			Status:    analysis.StatusRunning,
+			Responses: make([]analysis.PersonaResponse, 0),
+			StartedAt: startedAt,
+			CreatedAt: startedAt,
+		}
+		if err := repos.Analyses.SaveResult(result); err != nil {
+			return fmt.Errorf("save result %s: %w", result.ID, err)
+		}
+	}
+	if err := repos.Analyses.SaveRequest(&analysis.Request{ID: "request_a", ProjectID: "project_a", BoardID: "board_a", Mode: "analysis", CreatedAt: start}); err == nil {
+		return fmt.Errorf("save duplicate request: got no error")
+	}
+
+	result, err := repos.Analyses.LoadResult("result_b")
+	if err != nil {
+		return fmt.Errorf("load result: %w", err)
+	}
+	if result.Status != analysis.StatusRunning || result.Topic != "topic b" || result.CompletedAt != nil {
+		return fmt.Errorf("load result: got %+v", result)
+	}
+
+	completedAt := result.StartedAt.Add(90 * time.Second)
+	result.Status = analysis.StatusCompleted
+	result.Summary = "done"
+	result.Insights = []string{"ship it"}
+	result.Responses = []analysis.PersonaResponse{{PersonaID: "persona_a", PersonaName: "Ada", Round: 1, Content: "yes", Confidence: 0.8, CreatedAt: completedAt}}
+	result.Metrics = analysis.Metrics{Rounds: 1, Responses: 1, TotalTokens: 50, CostUSD: 0.01}
+	result.CompletedAt = &completedAt
+	result.Duration = 90 * time.Second
+	result.Mode = "discussion"
+	if err := repos.Analyses.SaveResult(result); err != nil {
+		return fmt.Errorf("update result: %w", err)
+	}
+
+	loaded, err := repos.Analyses.LoadResult("result_b")
+	if err != nil {
+		return fmt.Errorf("load result: %w", err)
+	}
+	if loaded.Status != analysis.StatusCompleted || loaded.Summary != "done" || len(loaded.Insights) != 1 || loaded.Duration != 90*time.Second {
+		return fmt.Errorf("load updated result: got %+v", loaded)
+	}
+	if len(loaded.Responses) != 1 || loaded.Responses[0].Content != "yes" || loaded.Metrics.TotalTokens != 50 {
+		return fmt.Errorf("load updated result: responses and metrics not kept: %+v", loaded)
+	}
+	if loaded.CompletedAt == nil || !loaded.CompletedAt.Equal(completedAt) {
+		return fmt.Errorf("load updated result: got completion time %v, want %v", loaded.CompletedAt, completedAt)
+	}
+	if loaded.Mode != "analysis" {
+		return fmt.Errorf("update result: mode changed to %q, want it kept as first saved", loaded.Mode)
+	}
+
+	results, err := repos.Analyses.ListResults(0)
+	if err != nil {
+		return fmt.Errorf("list results: %w", err)
+	}
+	if len(results) != 3 || results[0].ID != "result_c" || results[2].ID != "result_a" {
+		return fmt.Errorf("list results: got %v, want result_c, result_b, result_a", results)
+	}
+	info := results[1]
+	if info.ProjectName != "Launch" || info.BoardName != "Advisors" || info.Topic != "topic b" || info.Status != analysis.StatusCompleted || info.Duration != 90*time.Second {
+		return fmt.Errorf("list results: got %+v", info)
+	}
+	if limited, err := repos.Analyses.ListResults(2); err != nil || len(limited) != 2 || limited[1].ID != "result_b" {
+		return fmt.Errorf("list results with a limit: got %v (%v), want the newest 2", limited, err)
+	}
+	if page, err := repos.Analyses.PageResults(0, 1); err != nil || len(page) != 2 || page[0].ID != "result_b" {
+		return fmt.Errorf("page results: got %v (%v), want result_b, result_a", page, err)
+	}
+	if count, err := repos.Analyses.CountResults(); err != nil || count != 3 {
+		return fmt.Errorf("count results: got %d (%v), want 3", count, err)
+	}
+
+	return nil
+}
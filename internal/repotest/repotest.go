@@ -0,0 +1,115 @@
+package repotest
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"personal-ai-board/internal/analysis"
+	"personal-ai-board/internal/board"
+	"personal-ai-board/internal/db"
+	"personal-ai-board/internal/persona"
+	"personal-ai-board/internal/project"
+)
+
+// Repositories is one implementation of every repository, sharing a store
+type Repositories struct {
+	Personas persona.Repository
+	Boards   board.Repository
+	Projects project.Repository
+	Analyses analysis.Repository
+}
+
+// Engine returns the repositories an analysis engine uses
+func (r Repositories) Engine() analysis.Repositories {
+	return analysis.Repositories{
+		Boards:   r.Boards,
+		Projects: r.Projects,
+		Personas: r.Personas,
+		Analyses: r.Analyses,
+	}
+}
+
+// Opener creates empty repositories and a function that releases them
+type Opener func() (Repositories, func(), error)
+
+// InMemory creates empty in-memory repositories. Unlike the database, they do
+// not check references between repositories: a board may seat a persona that
+// does not exist, and deleting a project leaves its analyses.
+func InMemory() Repositories {
+	personas := persona.NewInMemoryStorage()
+	boards := board.NewInMemoryStorage(personas)
+	projects := project.NewInMemoryStorage()
+
+	return Repositories{
+		Personas: personas,
+		Boards:   boards,
+		Projects: projects,
+		Analyses: analysis.NewInMemoryStorage(projects, boards),
+	}
+}
+
+// OpenInMemory is an Opener for in-memory repositories
+func OpenInMemory() (Repositories, func(), error) {
+	return InMemory(), func() {}, nil
+}
+
+// SQLite creates repositories over a migrated database
+func SQLite(database *sql.DB) Repositories {
+	return Repositories{
+		Personas: persona.NewStorage(database),
+		Boards:   board.NewStorage(database),
+		Projects: project.NewStorage(database),
+		Analyses: analysis.NewStorage(database),
+	}
+}
+
+// OpenSQLite is an Opener for repositories over a new, migrated database in a
+// temporary directory, which is removed when the repositories are released
+func OpenSQLite() (Repositories, func(), error) {
+	dir, err := os.MkdirTemp("", "personal-ai-board-repotest-")
+	if err != nil {
+		return Repositories{}, nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	config := db.DefaultConfig()
+	config.Path = filepath.Join(dir, "test.db")
+	database, err := db.Connect(config)
+	if err != nil {
+		os.RemoveAll(dir)
+		return Repositories{}, nil, err
+	}
+	closer := func() {
+		database.Close()
+		os.RemoveAll(dir)
+	}
+	if err := database.Migrate(); err != nil {
+		closer()
+		return Repositories{}, nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	return SQLite(database.DB), closer, nil
+}
+
+// Test runs the repository contract against repositories from open. Every
+// check gets its own empty repositories. The error lists each failed check.
+func Test(open Opener) error {
+	var failures []string
+	for _, check := range checks {
+		repos, closer, err := open()
+		if err != nil {
+			return fmt.Errorf("failed to open repositories: %w", err)
+		}
+		if err := check.run(repos); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", check.name, err))
+		}
+		closer()
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d repository checks failed:\n%s", len(failures), len(checks), strings.Join(failures, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package repotest
+
+import "testing"
+
+func TestContract(t *testing.T) {
+	openers := []struct {
+		name string
+		open Opener
+	}{
+		{"sqlite", OpenSQLite},
+		{"in-memory", OpenInMemory},
+	}
+
+	for _, opener := range openers {
+		t.Run(opener.name, func(t *testing.T) {
+			if err := Test(opener.open); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
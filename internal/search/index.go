@@ -0,0 +1,90 @@
+package search
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ftsSchema creates the full-text index over search_entries and the triggers
+// that keep it in sync. It is an external-content table, so the text is only
+// stored once, in search_entries.
+var ftsSchema = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS search_fts USING fts5(
+		title, body,
+		content = 'search_entries',
+		content_rowid = 'id',
+		tokenize = 'porter unicode61'
+	)`,
+	`CREATE TRIGGER IF NOT EXISTS search_fts_insert AFTER INSERT ON search_entries BEGIN
+		INSERT INTO search_fts (rowid, title, body) VALUES (NEW.id, NEW.title, NEW.body);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS search_fts_delete AFTER DELETE ON search_entries BEGIN
+		INSERT INTO search_fts (search_fts, rowid, title, body) VALUES ('delete', OLD.id, OLD.title, OLD.body);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS search_fts_update AFTER UPDATE ON search_entries BEGIN
+		INSERT INTO search_fts (search_fts, rowid, title, body) VALUES ('delete', OLD.id, OLD.title, OLD.body);
+		INSERT INTO search_fts (rowid, title, body) VALUES (NEW.id, NEW.title, NEW.body);
+	END`,
+}
+
+// ftsTriggers are the triggers in ftsSchema
+var ftsTriggers = []string{"search_fts_insert", "search_fts_delete", "search_fts_update"}
+
+// FallbackNotice explains why searches match plain substrings when SQLite has
+// no FTS5
+const FallbackNotice = "SQLite was built without FTS5, so searches match plain substrings; build with -tags sqlite_fts5 for ranked full-text search"
+
+// FTS5Available reports whether SQLite was built with FTS5, which needs the
+// sqlite_fts5 build tag
+func FTS5Available(db *sql.DB) bool {
+	var used bool
+	if err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&used); err != nil {
+		return false
+	}
+	return used
+}
+
+// EnsureIndex creates the full-text index when SQLite supports FTS5, filling
+// it from search_entries the first time. Without FTS5 it removes the index's
+// triggers, which would otherwise fail every write, and searches fall back to
+// LIKE. It reports whether the full-text index is in use.
+func EnsureIndex(db *sql.DB) (bool, error) {
+	if !FTS5Available(db) {
+		for _, trigger := range ftsTriggers {
+			if _, err := db.Exec("DROP TRIGGER IF EXISTS " + trigger); err != nil {
+				return false, fmt.Errorf("failed to drop search trigger %s: %w", trigger, err)
+			}
+		}
+		return false, nil
+	}
+
+	var exists int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type IN ('table', 'trigger') AND name IN ('search_fts', 'search_fts_insert', 'search_fts_delete', 'search_fts_update')").Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check search index: %w", err)
+	}
+	if exists == len(ftsSchema) {
+		return true, nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, statement := range ftsSchema {
+		if _, err := tx.Exec(statement); err != nil {
+			return false, fmt.Errorf("failed to create search index: %w", err)
+		}
+	}
+	// Entries written while the triggers were missing are not indexed yet
+	if _, err := tx.Exec("INSERT INTO search_fts (search_fts) VALUES ('rebuild')"); err != nil {
+		return false, fmt.Errorf("failed to build search index: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit search index: %w", err)
+	}
+	return true, nil
+}
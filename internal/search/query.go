@@ -0,0 +1,68 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ParseQuery parses search text that may include filters written as
+// qualifiers, such as "pricing type:idea project:project_1 since:2024-01-01".
+// The qualifiers are type (a comma separated list), project, board, persona,
+// since and until.
+func ParseQuery(text string) (Query, error) {
+	var query Query
+	var words []string
+
+	for _, field := range strings.Fields(text) {
+		name, value, ok := strings.Cut(field, ":")
+		if !ok || value == "" {
+			words = append(words, field)
+			continue
+		}
+
+		var err error
+		switch strings.ToLower(name) {
+		case "type":
+			for _, kind := range strings.Split(value, ",") {
+				if kind = strings.TrimSpace(kind); kind != "" {
+					query.Kinds = append(query.Kinds, strings.ToLower(kind))
+				}
+			}
+		case "project":
+			query.ProjectID = value
+		case "board":
+			query.BoardID = value
+		case "persona":
+			query.Persona = value
+		case "since":
+			query.Since, err = ParseDate(value, false)
+		case "until":
+			query.Until, err = ParseDate(value, true)
+		default:
+			words = append(words, field)
+		}
+		if err != nil {
+			return Query{}, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	query.Text = strings.Join(words, " ")
+	return query, nil
+}
+
+// ParseDate parses a date given as YYYY-MM-DD in local time or as RFC 3339.
+// A plain date given as the end of a range covers the whole day.
+func ParseDate(value string, endOfRange bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date such as 2024-01-31 or an RFC 3339 time", value)
+	}
+	if endOfRange {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -0,0 +1,455 @@
+package search
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"personal-ai-board/internal/auth"
+)
+
+// Kinds of search result
+const (
+	KindPersona  = "persona"
+	KindIdea     = "idea"
+	KindDocument = "document"
+	KindAnalysis = "analysis"
+	KindResponse = "response"
+	KindInsight  = "insight"
+)
+
+// Kinds lists every kind of search result
+var Kinds = []string{KindPersona, KindIdea, KindDocument, KindAnalysis, KindResponse, KindInsight}
+
+// Highlight markers placed around matched terms in snippets
+const (
+	HighlightStart = "**"
+	HighlightEnd   = "**"
+)
+
+// Weights of matches in the title and body when ranking results
+const (
+	titleWeight = 5.0
+	bodyWeight  = 1.0
+)
+
+// DefaultLimit is the number of results returned when a query sets no limit
+const DefaultLimit = 20
+
+// snippetWords is roughly how many words a snippet shows
+const snippetWords = 16
+
+// Logger interface for structured logging
+type Logger interface {
+	Info(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+	Debug(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+}
+
+// Query is a search and the filters that narrow it
+type Query struct {
+	Text      string
+	Kinds     []string
+	ProjectID string
+	BoardID   string
+	// Persona matches a persona ID or the start of a persona name
+	Persona string
+	Since   time.Time
+	Until   time.Time
+	Limit   int
+}
+
+// Result is an entry that matched a search
+type Result struct {
+	Kind        string    `json:"kind"`
+	Source      string    `json:"source"`
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Snippet     string    `json:"snippet"`
+	ProjectID   string    `json:"project_id,omitempty"`
+	ProjectName string    `json:"project_name,omitempty"`
+	BoardID     string    `json:"board_id,omitempty"`
+	BoardName   string    `json:"board_name,omitempty"`
+	PersonaID   string    `json:"persona_id,omitempty"`
+	PersonaName string    `json:"persona_name,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	Score       float64   `json:"score"`
+}
+
+// Service searches the personas, ideas, documents and analyses of a workspace
+type Service struct {
+	db        *sql.DB
+	workspace string
+	logger    Logger
+}
+
+// NewService creates a search service over the default workspace
+func NewService(db *sql.DB, logger Logger) *Service {
+	return NewWorkspaceService(db, auth.DefaultWorkspaceID, logger)
+}
+
+// NewWorkspaceService creates a search service over a workspace
+func NewWorkspaceService(db *sql.DB, workspaceID string, logger Logger) *Service {
+	return &Service{db: db, workspace: workspaceID, logger: logger}
+}
+
+// FullText reports whether searches use the FTS5 index rather than LIKE
+func (s *Service) FullText() bool {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'search_fts'").Scan(&count)
+	return err == nil && count > 0 && FTS5Available(s.db)
+}
+
+// Search returns the entries matching a query, best match first
+func (s *Service) Search(query Query) ([]Result, error) {
+	terms := queryTerms(query.Text)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("search query is empty")
+	}
+	for _, kind := range query.Kinds {
+		if !validKind(kind) {
+			return nil, fmt.Errorf("unknown result type %q (expected one of %s)", kind, strings.Join(Kinds, ", "))
+		}
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultLimit
+	}
+
+	start := time.Now()
+	var results []Result
+	var err error
+	fullText := s.FullText()
+	if fullText {
+		results, err = s.searchFTS(query, terms)
+	} else {
+		results, err = s.searchLike(query, terms)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug("Searched", "query", query.Text, "results", len(results), "full_text", fullText, "duration", time.Since(start))
+	return results, nil
+}
+
+// resultColumns are the columns every search reads, in the order scanResult
+// expects them
+const resultColumns = `e.kind, e.source, e.ref_id, e.title, e.body,
+	COALESCE(e.project_id, ''), COALESCE(p.name, ''),
+	COALESCE(e.board_id, ''), COALESCE(b.name, ''),
+	COALESCE(e.persona_id, ''), COALESCE(e.persona_name, ''), e.created_at`
+
+// resultJoins add the names of the project and board an entry belongs to,
+// and the persona a persona entry is drawn from
+const resultJoins = `LEFT JOIN projects p ON p.id = e.project_id
+	LEFT JOIN boards b ON b.id = e.board_id
+	LEFT JOIN personas wp ON e.source = 'personas' AND wp.id = e.ref_id`
+
+// searchFTS searches the full-text index, ranked by BM25
+func (s *Service) searchFTS(query Query, terms []string) ([]Result, error) {
+	where, args := s.filters(query)
+	where = append([]string{"search_fts MATCH ?"}, where...)
+	args = append([]interface{}{ftsExpression(terms)}, args...)
+	args = append(args, query.Limit)
+
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT %s,
+			snippet(search_fts, 1, '%s', '%s', '…', %d),
+			bm25(search_fts, %g, %g) AS score
+		FROM search_fts
+		JOIN search_entries e ON e.id = search_fts.rowid
+		%s
+		WHERE %s
+		ORDER BY score
+		LIMIT ?
+	`, resultColumns, HighlightStart, HighlightEnd, snippetWords, titleWeight, bodyWeight, resultJoins, strings.Join(where, " AND ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	var results []Result
+	for rows.Next() {
+		var snippet string
+		var rank float64
+		result, err := scanResult(rows, &snippet, &rank)
+		if err != nil {
+			return nil, err
+		}
+		// BM25 scores are negative, with better matches lower
+		result.Score = -rank
+		if strings.Contains(snippet, HighlightStart) {
+			result.Snippet = collapseSpace(snippet)
+		} else {
+			// Only the title matched
+			result.Snippet = truncateWords(result.Snippet, snippetWords)
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	return results, nil
+}
+
+// searchLike searches with LIKE when SQLite has no FTS5, ranking results by
+// how often the terms appear
+func (s *Service) searchLike(query Query, terms []string) ([]Result, error) {
+	where, args := s.filters(query)
+	for _, term := range terms {
+		pattern := "%" + escapeLike(term) + "%"
+		where = append(where, `(e.title LIKE ? ESCAPE '\' OR e.body LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
+
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT %s
+		FROM search_entries e
+		%s
+		WHERE %s
+	`, resultColumns, resultJoins, strings.Join(where, " AND ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	var results []Result
+	for rows.Next() {
+		result, err := scanResult(rows)
+		if err != nil {
+			return nil, err
+		}
+		result.Score = likeScore(result.Title, result.Snippet, terms)
+		if snippet := highlight(result.Snippet, terms); snippet != "" {
+			result.Snippet = snippet
+		} else {
+			result.Snippet = truncateWords(result.Snippet, snippetWords)
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].CreatedAt.After(results[j].CreatedAt)
+	})
+	if len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+
+	return results, nil
+}
+
+// scanResult reads the resultColumns of a row, followed by any extra columns.
+// The snippet is set to the whole body.
+func scanResult(rows *sql.Rows, extra ...interface{}) (Result, error) {
+	var result Result
+	var body string
+	var createdAt sql.NullTime
+	dest := []interface{}{
+		&result.Kind, &result.Source, &result.ID, &result.Title, &body,
+		&result.ProjectID, &result.ProjectName,
+		&result.BoardID, &result.BoardName,
+		&result.PersonaID, &result.PersonaName, &createdAt,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return Result{}, fmt.Errorf("failed to scan search result: %w", err)
+	}
+
+	result.CreatedAt = createdAt.Time
+	result.Snippet = collapseSpace(body)
+	return result, nil
+}
+
+// filters returns the WHERE conditions and arguments for a query's filters,
+// limited to the service's workspace
+func (s *Service) filters(query Query) ([]string, []interface{}) {
+	// Personas belong to a workspace themselves; everything else belongs to
+	// the workspace of its project
+	where := []string{"COALESCE(p.workspace_id, wp.workspace_id) = ?"}
+	args := []interface{}{s.workspace}
+
+	if len(query.Kinds) > 0 {
+		where = append(where, "e.kind IN (?"+strings.Repeat(", ?", len(query.Kinds)-1)+")")
+		for _, kind := range query.Kinds {
+			args = append(args, kind)
+		}
+	}
+	if query.ProjectID != "" {
+		where = append(where, "e.project_id = ?")
+		args = append(args, query.ProjectID)
+	}
+	if query.BoardID != "" {
+		where = append(where, "e.board_id = ?")
+		args = append(args, query.BoardID)
+	}
+	if query.Persona != "" {
+		where = append(where, `(e.persona_id = ? OR e.persona_name LIKE ? ESCAPE '\')`)
+		args = append(args, query.Persona, escapeLike(query.Persona)+"%")
+	}
+	// created_at is stored in UTC as 'YYYY-MM-DD HH:MM:SS', which compares
+	// correctly as text
+	if !query.Since.IsZero() {
+		where = append(where, "e.created_at >= ?")
+		args = append(args, query.Since.UTC().Format("2006-01-02 15:04:05"))
+	}
+	if !query.Until.IsZero() {
+		where = append(where, "e.created_at < ?")
+		args = append(args, query.Until.UTC().Format("2006-01-02 15:04:05"))
+	}
+
+	return where, args
+}
+
+// queryTerms splits search text into lower-case words
+func queryTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// ftsExpression builds an FTS5 query that matches every term, treating the
+// last one as a prefix so that results appear while a word is being typed
+func ftsExpression(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + term + `"`
+	}
+	quoted[len(quoted)-1] += "*"
+	return strings.Join(quoted, " ")
+}
+
+// validKind reports whether kind is a kind of search result
+func validKind(kind string) bool {
+	for _, k := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeLike escapes the LIKE wildcards in a term
+func escapeLike(term string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+}
+
+// likeScore ranks a LIKE match by how often the terms appear, counting the
+// title more than the body
+func likeScore(title, body string, terms []string) float64 {
+	title, body = strings.ToLower(title), strings.ToLower(body)
+	score := 0.0
+	for _, term := range terms {
+		score += titleWeight*float64(strings.Count(title, term)) + bodyWeight*float64(strings.Count(body, term))
+	}
+	return score
+}
+
+// highlight cuts a snippet of text around the first term it contains and marks
+// every term in it. It returns "" if no term appears in text.
+func highlight(text string, terms []string) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// Find each match as a range of runes
+	type match struct{ start, end int }
+	var matches []match
+	for i := 0; i < len(lower); {
+		matched := 0
+		for _, term := range terms {
+			if n := len([]rune(term)); n > matched && hasPrefix(lower[i:], []rune(term)) {
+				matched = n
+			}
+		}
+		if matched > 0 {
+			// Mark the rest of the word too, as the full-text index does
+			end := i + matched
+			for end < len(lower) && (unicode.IsLetter(lower[end]) || unicode.IsDigit(lower[end])) {
+				end++
+			}
+			matches = append(matches, match{i, end})
+			i = end
+			continue
+		}
+		i++
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+
+	// Show a few words before the first match and the rest up to the limit
+	start := matches[0].start
+	for words := 0; start > 0; start-- {
+		if unicode.IsSpace(runes[start-1]) {
+			if words++; words > snippetWords/4 {
+				break
+			}
+		}
+	}
+	end := start
+	for words := 0; end < len(runes); end++ {
+		if unicode.IsSpace(runes[end]) {
+			if words++; words >= snippetWords {
+				break
+			}
+		}
+	}
+
+	var s strings.Builder
+	if start > 0 {
+		s.WriteString("…")
+	}
+	pos := start
+	for _, m := range matches {
+		if m.start < start || m.end > end {
+			continue
+		}
+		s.WriteString(string(runes[pos:m.start]))
+		s.WriteString(HighlightStart + string(runes[m.start:m.end]) + HighlightEnd)
+		pos = m.end
+	}
+	s.WriteString(string(runes[pos:end]))
+	if end < len(runes) {
+		s.WriteString("…")
+	}
+	return s.String()
+}
+
+// hasPrefix reports whether s starts with prefix
+func hasPrefix(s, prefix []rune) bool {
+	if len(prefix) > len(s) {
+		return false
+	}
+	for i, r := range prefix {
+		if s[i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// collapseSpace replaces runs of whitespace, including newlines, with a space
+func collapseSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// truncateWords shortens text to a number of words
+func truncateWords(text string, words int) string {
+	fields := strings.Fields(text)
+	if len(fields) <= words {
+		return text
+	}
+	return strings.Join(fields[:words], " ") + "…"
+}
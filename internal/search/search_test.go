@@ -0,0 +1,187 @@
+package search
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"personal-ai-board/internal/db"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Warn(string, ...interface{})  {}
+
+// testDB returns a migrated database in a temporary directory
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	config := db.DefaultConfig()
+	config.Path = filepath.Join(t.TempDir(), "search.db")
+	database, err := db.Connect(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return database.DB
+}
+
+// addPersona inserts a persona, which the triggers add to search_entries
+func addPersona(t *testing.T, database *sql.DB, id, name, description string) {
+	t.Helper()
+	now := time.Now()
+	if _, err := database.Exec(
+		"INSERT INTO personas (id, name, description, traits_config, created_at, updated_at) VALUES (?, ?, ?, '{}', ?, ?)",
+		id, name, description, now, now,
+	); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// resultIDs lists the IDs of results in order
+func resultIDs(results []Result) string {
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestSearchLike(t *testing.T) {
+	database := testDB(t)
+	addPersona(t, database, "p_body", "Growth Hacker", "Cares about pricing now and then")
+	addPersona(t, database, "p_title", "Pricing Strategist", "Thinks about pricing and pricing tiers")
+	addPersona(t, database, "p_none", "Skeptical CFO", "Watches the cash")
+	service := NewService(database, nopLogger{})
+
+	results, err := service.searchLike(Query{Limit: DefaultLimit}, []string{"pricing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := resultIDs(results); ids != "p_title,p_body" {
+		t.Fatalf("results %s, want the title match first", ids)
+	}
+	if results[0].Score <= results[1].Score || !strings.Contains(results[1].Snippet, "**pricing**") {
+		t.Errorf("results %+v", results)
+	}
+
+	if results, _ := service.searchLike(Query{Limit: 1}, []string{"pricing"}); resultIDs(results) != "p_title" {
+		t.Errorf("limited to one: %s", resultIDs(results))
+	}
+	if results, _ := service.searchLike(Query{Limit: DefaultLimit}, []string{"pricing", "tiers"}); resultIDs(results) != "p_title" {
+		t.Errorf("every term: %s", resultIDs(results))
+	}
+	if results, _ := service.searchLike(Query{Limit: DefaultLimit, Kinds: []string{KindIdea}}, []string{"pricing"}); len(results) != 0 {
+		t.Errorf("ideas only: %s", resultIDs(results))
+	}
+	if results, _ := NewWorkspaceService(database, "workspace_other", nopLogger{}).searchLike(Query{Limit: DefaultLimit}, []string{"pricing"}); len(results) != 0 {
+		t.Errorf("other workspace: %s", resultIDs(results))
+	}
+}
+
+func TestSearchFallsBackToLike(t *testing.T) {
+	database := testDB(t)
+	if FTS5Available(database) {
+		t.Skip("SQLite has FTS5; the fallback is used in builds without the sqlite_fts5 tag")
+	}
+
+	fullText, err := EnsureIndex(database)
+	if err != nil || fullText {
+		t.Fatalf("full text %v: %v", fullText, err)
+	}
+	service := NewService(database, nopLogger{})
+	if service.FullText() {
+		t.Error("full text reported without FTS5")
+	}
+
+	// Writes still work without the index's triggers
+	addPersona(t, database, "p1", "Pricing Strategist", "Thinks about tiers")
+	if _, err := database.Exec("UPDATE personas SET description = 'Thinks about discounts' WHERE id = 'p1'"); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := service.Search(Query{Text: "discounts"})
+	if err != nil || resultIDs(results) != "p1" {
+		t.Errorf("results %s: %v", resultIDs(results), err)
+	}
+}
+
+func TestSearchFullText(t *testing.T) {
+	database := testDB(t)
+	if !FTS5Available(database) {
+		t.Skip("SQLite has no FTS5; run with -tags sqlite_fts5")
+	}
+
+	// Entries written before the index exists are indexed when it is built
+	addPersona(t, database, "p_body", "Growth Hacker", "Cares about pricing now and then")
+	for i := 0; i < 2; i++ {
+		if fullText, err := EnsureIndex(database); err != nil || !fullText {
+			t.Fatalf("full text %v: %v", fullText, err)
+		}
+	}
+	addPersona(t, database, "p_title", "Pricing Strategist", "Thinks about tiers")
+	addPersona(t, database, "p_none", "Skeptical CFO", "Watches the cash")
+
+	service := NewService(database, nopLogger{})
+	if !service.FullText() {
+		t.Fatal("full text not in use")
+	}
+
+	results, err := service.Search(Query{Text: "pricing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := resultIDs(results); ids != "p_title,p_body" {
+		t.Fatalf("results %s, want the title match first", ids)
+	}
+	if results[0].Score <= results[1].Score || !strings.Contains(results[1].Snippet, "**pricing**") {
+		t.Errorf("results %+v", results)
+	}
+	// The last word is matched as a prefix, and words by their stem
+	if results, _ := service.Search(Query{Text: "watch"}); resultIDs(results) != "p_none" {
+		t.Errorf("stem: %s", resultIDs(results))
+	}
+	if results, _ := service.Search(Query{Text: "strat"}); resultIDs(results) != "p_title" {
+		t.Errorf("prefix: %s", resultIDs(results))
+	}
+
+	// The triggers keep the index in step with updates and deletes
+	if _, err := database.Exec("UPDATE personas SET description = 'Thinks about discounts' WHERE id = 'p_title'"); err != nil {
+		t.Fatal(err)
+	}
+	if results, _ := service.Search(Query{Text: "tiers"}); len(results) != 0 {
+		t.Errorf("old text still found: %s", resultIDs(results))
+	}
+	if results, _ := service.Search(Query{Text: "discounts"}); resultIDs(results) != "p_title" {
+		t.Errorf("new text: %s", resultIDs(results))
+	}
+	if _, err := database.Exec("DELETE FROM personas WHERE id = 'p_body'"); err != nil {
+		t.Fatal(err)
+	}
+	if results, _ := service.Search(Query{Text: "pricing"}); resultIDs(results) != "p_title" {
+		t.Errorf("after delete: %s", resultIDs(results))
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	query, err := ParseQuery("pricing type:idea,Document project:project_1 persona:cfo since:2024-01-01 until:2024-01-31 tiers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Text != "pricing tiers" || strings.Join(query.Kinds, ",") != "idea,document" || query.ProjectID != "project_1" || query.Persona != "cfo" {
+		t.Errorf("query %+v", query)
+	}
+	if got := query.Until.Sub(query.Since); got != 31*24*time.Hour {
+		t.Errorf("range %v, want 31 days", got)
+	}
+	if _, err := ParseQuery("since:yesterday"); err == nil {
+		t.Error("parsed an invalid date")
+	}
+}
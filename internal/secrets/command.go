@@ -0,0 +1,73 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// commandTimeout is how long a secret helper may take to answer
+const commandTimeout = 30 * time.Second
+
+// namePlaceholder marks where a helper's command line takes the secret's name
+const namePlaceholder = "{name}"
+
+// Command reads secrets from an external helper, such as a password manager's
+// CLI. The helper is run without a shell; the secret's name replaces {name}
+// in its arguments, or is appended when there is none, and the first line it
+// prints is the secret.
+type Command struct {
+	command string
+	timeout time.Duration
+}
+
+// NewCommand creates a backend that runs the helper command line
+func NewCommand(command string) *Command {
+	return &Command{command: command, timeout: commandTimeout}
+}
+
+// Get runs the helper for the secret
+func (c *Command) Get(name string) (string, error) {
+	args := strings.Fields(c.command)
+	if len(args) == 0 {
+		return "", fmt.Errorf("no secret helper command is configured")
+	}
+
+	named := false
+	for i, arg := range args {
+		if strings.Contains(arg, namePlaceholder) {
+			args[i] = strings.ReplaceAll(arg, namePlaceholder, name)
+			named = true
+		}
+	}
+	if !named {
+		args = append(args, name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("secret helper %s did not answer within %s", args[0], c.timeout)
+		}
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return "", fmt.Errorf("secret helper %s failed: %w: %s", args[0], err, detail)
+		}
+		return "", fmt.Errorf("secret helper %s failed: %w", args[0], err)
+	}
+
+	secret, _, _ := strings.Cut(stdout.String(), "\n")
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return "", fmt.Errorf("secret helper %s printed nothing for %s: %w", args[0], name, ErrNotFound)
+	}
+	return secret, nil
+}
@@ -0,0 +1,59 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// helperScript writes an executable shell script and returns its path
+func helperScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "helper")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCommand(t *testing.T) {
+	script := helperScript(t, `echo "key-for-$1-$2"; echo "second line"`)
+
+	if secret, err := NewCommand(script + " {name} pab").Get("openai"); err != nil || secret != "key-for-openai-pab" {
+		t.Errorf("with {name}: %q, %v", secret, err)
+	}
+	if secret, err := NewCommand(script + " pab").Get("openai"); err != nil || secret != "key-for-pab-openai" {
+		t.Errorf("name appended: %q, %v", secret, err)
+	}
+
+	if _, err := NewCommand(helperScript(t, "true")).Get("openai"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("no output: %v, want ErrNotFound", err)
+	}
+	if _, err := NewCommand("").Get("openai"); err == nil {
+		t.Error("ran without a command")
+	}
+}
+
+func TestCommandFailure(t *testing.T) {
+	_, err := NewCommand(helperScript(t, `echo "vault is locked" >&2; exit 3`)).Get("openai")
+	if err == nil || !strings.Contains(err.Error(), "vault is locked") || !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestCommandTimeout(t *testing.T) {
+	c := NewCommand(helperScript(t, "exec sleep 10"))
+	c.timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	_, err := c.Get("openai")
+	if err == nil || !strings.Contains(err.Error(), "did not answer within 100ms") {
+		t.Errorf("err = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("took %v", elapsed)
+	}
+}
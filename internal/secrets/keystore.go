@@ -0,0 +1,285 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// keystoreVersion is the version of the keystore file format
+const keystoreVersion = 1
+
+// scrypt parameters that derive the keystore's key from its passphrase
+const (
+	scryptN    = 1 << 15
+	scryptR    = 8
+	scryptP    = 1
+	keyLength  = 32
+	saltLength = 16
+)
+
+// minPassphraseLength is the length of the shortest passphrase accepted
+const minPassphraseLength = 8
+
+// ErrNoPassphrase is returned when the keystore's passphrase is not available
+var ErrNoPassphrase = errors.New("keystore passphrase not available")
+
+// keystoreFile is the content of a keystore file. The secrets are a JSON
+// object of names to values, sealed with AES-256-GCM under a key derived
+// from the passphrase with scrypt.
+type keystoreFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Keystore keeps secrets in a local file encrypted with a passphrase. The
+// file is only read, and the passphrase only asked for, when a secret is
+// first needed.
+type Keystore struct {
+	path       string
+	passphrase func() (string, error)
+
+	mu      sync.Mutex
+	key     []byte
+	salt    []byte
+	secrets map[string]string
+}
+
+// NewKeystore creates a keystore kept in the file at path, unlocked with the
+// passphrase returned by passphrase
+func NewKeystore(path string, passphrase func() (string, error)) *Keystore {
+	return &Keystore{path: path, passphrase: passphrase}
+}
+
+// Passphrase returns a passphrase source that reads the key file, when one is
+// given, or else the environment variable
+func Passphrase(keyFile, envVar string) func() (string, error) {
+	return func() (string, error) {
+		if keyFile != "" {
+			data, err := os.ReadFile(keyFile)
+			if err != nil {
+				return "", fmt.Errorf("failed to read keystore key file: %w", err)
+			}
+			return strings.TrimSpace(string(data)), nil
+		}
+		if passphrase := os.Getenv(envVar); passphrase != "" {
+			return passphrase, nil
+		}
+		return "", fmt.Errorf("%w: set %s or a key file", ErrNoPassphrase, envVar)
+	}
+}
+
+// Path returns the file the keystore is kept in
+func (k *Keystore) Path() string {
+	return k.path
+}
+
+// Get returns a secret
+func (k *Keystore) Get(name string) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if err := k.unlock(); err != nil {
+		return "", err
+	}
+	value, ok := k.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%s is not in the keystore %s: %w", name, k.path, ErrNotFound)
+	}
+	return value, nil
+}
+
+// Set stores a secret, creating the keystore if it does not exist
+func (k *Keystore) Set(name, value string) error {
+	if name == "" || value == "" {
+		return fmt.Errorf("secret name and value cannot be empty")
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if err := k.unlock(); err != nil {
+		return err
+	}
+	k.secrets[name] = value
+	return k.save()
+}
+
+// Delete removes a secret
+func (k *Keystore) Delete(name string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if err := k.unlock(); err != nil {
+		return err
+	}
+	if _, ok := k.secrets[name]; !ok {
+		return fmt.Errorf("%s is not in the keystore %s: %w", name, k.path, ErrNotFound)
+	}
+	delete(k.secrets, name)
+	return k.save()
+}
+
+// Names returns the names of the stored secrets in order
+func (k *Keystore) Names() ([]string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if err := k.unlock(); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(k.secrets))
+	for name := range k.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// unlock reads and decrypts the keystore file, once. A keystore that does not
+// exist yet is empty, and gets a new salt.
+func (k *Keystore) unlock() error {
+	if k.secrets != nil {
+		return nil
+	}
+
+	passphrase, err := k.passphrase()
+	if err != nil {
+		return err
+	}
+	if len(passphrase) < minPassphraseLength {
+		return fmt.Errorf("keystore passphrase must be at least %d characters", minPassphraseLength)
+	}
+
+	data, err := os.ReadFile(k.path)
+	if errors.Is(err, os.ErrNotExist) {
+		salt := make([]byte, saltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return fmt.Errorf("failed to generate keystore salt: %w", err)
+		}
+		if k.key, err = deriveKey(passphrase, salt); err != nil {
+			return err
+		}
+		k.salt = salt
+		k.secrets = make(map[string]string)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read keystore: %w", err)
+	}
+
+	var file keystoreFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse keystore %s: %w", k.path, err)
+	}
+	if file.Version != keystoreVersion || file.KDF != "scrypt" {
+		return fmt.Errorf("keystore %s has an unsupported format (version %d, %s)", k.path, file.Version, file.KDF)
+	}
+
+	key, err := deriveKey(passphrase, file.Salt)
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	plaintext, err := aead.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return fmt.Errorf("failed to unlock keystore %s: wrong passphrase or damaged file", k.path)
+	}
+
+	secrets := make(map[string]string)
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return fmt.Errorf("failed to parse keystore %s: %w", k.path, err)
+	}
+
+	k.key, k.salt, k.secrets = key, file.Salt, secrets
+	return nil
+}
+
+// save encrypts the secrets under a new nonce and replaces the keystore file
+func (k *Keystore) save() error {
+	plaintext, err := json.Marshal(k.secrets)
+	if err != nil {
+		return fmt.Errorf("failed to encode keystore: %w", err)
+	}
+
+	aead, err := newAEAD(k.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate keystore nonce: %w", err)
+	}
+
+	data, err := json.MarshalIndent(keystoreFile{
+		Version:    keystoreVersion,
+		KDF:        "scrypt",
+		Salt:       k.salt,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, nil),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode keystore: %w", err)
+	}
+
+	// Write a new file and rename it over the old one, so that a failed
+	// write never leaves a damaged keystore
+	tmp, err := os.CreateTemp(filepath.Dir(k.path), filepath.Base(k.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), k.path); err != nil {
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+	return nil
+}
+
+// deriveKey derives the keystore's encryption key from its passphrase
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive keystore key: %w", err)
+	}
+	return key, nil
+}
+
+// newAEAD creates the AES-256-GCM cipher the keystore is sealed with
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create keystore cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create keystore cipher: %w", err)
+	}
+	return aead, nil
+}
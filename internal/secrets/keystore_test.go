@@ -0,0 +1,95 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fixedPassphrase returns a passphrase source that always gives passphrase
+func fixedPassphrase(passphrase string) func() (string, error) {
+	return func() (string, error) { return passphrase, nil }
+}
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	keystore := NewKeystore(path, fixedPassphrase("correct horse battery"))
+	if err := keystore.Set("openai", "sk-openai-secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := keystore.Set("anthropic", "sk-ant-secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-openai-secret") || !strings.Contains(string(data), `"kdf": "scrypt"`) {
+		t.Errorf("keystore file:\n%s", data)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("file mode: %v, %v", info, err)
+	}
+
+	// A new keystore reads the file back
+	reopened := NewKeystore(path, fixedPassphrase("correct horse battery"))
+	if value, err := reopened.Get("openai"); err != nil || value != "sk-openai-secret" {
+		t.Errorf("openai = %q, %v", value, err)
+	}
+	if names, err := reopened.Names(); err != nil || strings.Join(names, ",") != "anthropic,openai" {
+		t.Errorf("names %v, %v", names, err)
+	}
+	if err := reopened.Delete("anthropic"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewKeystore(path, fixedPassphrase("correct horse battery")).Get("anthropic"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("deleted secret: %v", err)
+	}
+}
+
+func TestKeystoreWrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	if err := NewKeystore(path, fixedPassphrase("correct horse battery")).Set("openai", "sk-openai-secret"); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(path)
+
+	wrong := NewKeystore(path, fixedPassphrase("incorrect horse battery"))
+	if _, err := wrong.Get("openai"); err == nil || !strings.Contains(err.Error(), "wrong passphrase") || errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want wrong passphrase", err)
+	}
+	if err := wrong.Set("google", "key"); err == nil {
+		t.Error("saved with the wrong passphrase")
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(before) {
+		t.Error("keystore changed by the wrong passphrase")
+	}
+
+	if _, err := NewKeystore(path, fixedPassphrase("short")).Get("openai"); err == nil {
+		t.Error("unlocked with a short passphrase")
+	}
+}
+
+func TestPassphrase(t *testing.T) {
+	t.Setenv("PAB_TEST_PASSPHRASE", "")
+	if _, err := Passphrase("", "PAB_TEST_PASSPHRASE")(); !errors.Is(err, ErrNoPassphrase) {
+		t.Errorf("err = %v, want ErrNoPassphrase", err)
+	}
+
+	t.Setenv("PAB_TEST_PASSPHRASE", "from the environment")
+	if passphrase, err := Passphrase("", "PAB_TEST_PASSPHRASE")(); err != nil || passphrase != "from the environment" {
+		t.Errorf("passphrase %q, %v", passphrase, err)
+	}
+
+	// A key file is preferred to the environment
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte("from the key file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if passphrase, err := Passphrase(keyFile, "PAB_TEST_PASSPHRASE")(); err != nil || passphrase != "from the key file" {
+		t.Errorf("passphrase %q, %v", passphrase, err)
+	}
+}
@@ -0,0 +1,127 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Schemes of the references that name a secret and where it is kept
+const (
+	SchemeEnv      = "env"
+	SchemeKeystore = "keystore"
+	SchemeCommand  = "command"
+)
+
+// Schemes lists every reference scheme
+var Schemes = []string{SchemeEnv, SchemeKeystore, SchemeCommand}
+
+// ErrNotFound is returned for a secret that a backend does not hold
+var ErrNotFound = errors.New("secret not found")
+
+// Backend looks up secrets by name
+type Backend interface {
+	Get(name string) (string, error)
+}
+
+// ParseReference splits a reference such as keystore:openai into its scheme
+// and the name of the secret. ${NAME} is read as env:NAME. It reports false
+// for a value that is not a reference, such as a key given in clear text.
+func ParseReference(value string) (string, string, bool) {
+	if strings.HasPrefix(value, "${") && strings.HasSuffix(value, "}") && len(value) > 3 {
+		return SchemeEnv, value[2 : len(value)-1], true
+	}
+
+	scheme, name, ok := strings.Cut(value, ":")
+	if !ok || name == "" {
+		return "", "", false
+	}
+	for _, known := range Schemes {
+		if scheme == known {
+			return scheme, name, true
+		}
+	}
+	return "", "", false
+}
+
+// IsReference reports whether value refers to a secret rather than being one
+func IsReference(value string) bool {
+	_, _, ok := ParseReference(value)
+	return ok
+}
+
+// Resolver turns references into the secrets they name, looking each one up
+// in the backend registered for its scheme only when it is asked for
+type Resolver struct {
+	mu        sync.RWMutex
+	backends  map[string]Backend
+	observers []func(secret string)
+}
+
+// NewResolver creates a resolver with no backends
+func NewResolver() *Resolver {
+	return &Resolver{backends: make(map[string]Backend)}
+}
+
+// Register sets the backend that resolves references with the scheme
+func (r *Resolver) Register(scheme string, backend Backend) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.backends[scheme] = backend
+}
+
+// Observe calls fn with every secret resolved from now on, so that it can be
+// redacted from logs
+func (r *Resolver) Observe(fn func(secret string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.observers = append(r.observers, fn)
+}
+
+// Resolve returns the secret value refers to. A value that is not a reference
+// is returned as it is.
+func (r *Resolver) Resolve(value string) (string, error) {
+	secret := value
+	if scheme, name, ok := ParseReference(value); ok {
+		r.mu.RLock()
+		backend := r.backends[scheme]
+		r.mu.RUnlock()
+		if backend == nil {
+			return "", fmt.Errorf("no %s backend to resolve %s", scheme, value)
+		}
+
+		var err error
+		if secret, err = backend.Get(name); err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", value, err)
+		}
+	}
+
+	if secret != "" {
+		r.mu.RLock()
+		observers := r.observers
+		r.mu.RUnlock()
+		for _, observe := range observers {
+			observe(secret)
+		}
+	}
+	return secret, nil
+}
+
+// Env reads secrets from environment variables
+type Env struct{}
+
+// NewEnv creates an environment variable backend
+func NewEnv() *Env {
+	return &Env{}
+}
+
+// Get returns the value of the environment variable
+func (e *Env) Get(name string) (string, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return "", fmt.Errorf("environment variable %s is not set: %w", name, ErrNotFound)
+	}
+	return value, nil
+}
@@ -0,0 +1,64 @@
+package secrets
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	for value, want := range map[string][2]string{
+		"env:OPENAI_API_KEY": {SchemeEnv, "OPENAI_API_KEY"},
+		"${OPENAI_API_KEY}":  {SchemeEnv, "OPENAI_API_KEY"},
+		"keystore:openai":    {SchemeKeystore, "openai"},
+		"command:openai":     {SchemeCommand, "openai"},
+		"sk-plain-key":       {},
+		"https://host:443":   {},
+		"keystore:":          {},
+		"${}":                {},
+	} {
+		scheme, name, ok := ParseReference(value)
+		if ok != (want[0] != "") || scheme != want[0] || name != want[1] {
+			t.Errorf("ParseReference(%q) = %q, %q, %v", value, scheme, name, ok)
+		}
+	}
+}
+
+func TestResolver(t *testing.T) {
+	t.Setenv("PAB_TEST_KEY", "key-from-env")
+	keystore := NewKeystore(filepath.Join(t.TempDir(), "keys"), fixedPassphrase("correct horse battery"))
+	if err := keystore.Set("openai", "key-from-keystore"); err != nil {
+		t.Fatal(err)
+	}
+
+	resolver := NewResolver()
+	resolver.Register(SchemeEnv, NewEnv())
+	resolver.Register(SchemeKeystore, keystore)
+	resolver.Register(SchemeCommand, NewCommand(helperScript(t, `echo "key-from-command-$1"`)))
+	var observed []string
+	resolver.Observe(func(secret string) { observed = append(observed, secret) })
+
+	for value, want := range map[string]string{
+		"env:PAB_TEST_KEY": "key-from-env",
+		"${PAB_TEST_KEY}":  "key-from-env",
+		"keystore:openai":  "key-from-keystore",
+		"command:openai":   "key-from-command-openai",
+		"sk-plain-key":     "sk-plain-key",
+	} {
+		if got, err := resolver.Resolve(value); err != nil || got != want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if len(observed) != 5 {
+		t.Errorf("observed %d secrets, want 5", len(observed))
+	}
+
+	for _, value := range []string{"env:PAB_TEST_UNSET", "keystore:google"} {
+		if _, err := resolver.Resolve(value); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Resolve(%q): %v, want ErrNotFound", value, err)
+		}
+	}
+	if _, err := NewResolver().Resolve("keystore:openai"); err == nil {
+		t.Error("resolved without a keystore backend")
+	}
+}
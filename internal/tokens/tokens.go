@@ -0,0 +1,86 @@
+package tokens
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Estimate approximates how many tokens a BPE tokenizer such as those of
+// GPT, Claude and Gemini splits text into. Common words are one token and
+// longer ones a token for every four letters or so, numbers a token for
+// every three digits, and punctuation, symbols and ideographs a token each.
+// Whitespace is folded into the word that follows it.
+func Estimate(text string) int {
+	count := 0
+	letters, digits := 0, 0
+	flush := func() {
+		if letters > 0 {
+			count += 1 + (letters-1)/4
+		}
+		if digits > 0 {
+			count += (digits + 2) / 3
+		}
+		letters, digits = 0, 0
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.IsDigit(r):
+			if letters > 0 {
+				flush()
+			}
+			digits++
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+			flush()
+			count++
+		case unicode.IsLetter(r) || unicode.IsMark(r):
+			if digits > 0 {
+				flush()
+			}
+			// Letters outside ASCII take more bytes and so more tokens
+			letters += utf8.RuneLen(r)
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			flush()
+			count++
+		}
+	}
+	flush()
+	return count
+}
+
+// Truncate shortens text to at most max tokens as counted by count, cutting
+// at a word boundary where there is one, and reports whether it was shortened
+func Truncate(text string, max int, count func(string) int) (string, bool) {
+	if count(text) <= max {
+		return text, false
+	}
+	if max <= 0 {
+		return "", true
+	}
+
+	// Find the longest prefix that fits, leaving room for the ellipsis
+	runes := []rune(text)
+	low, high := 0, len(runes)
+	for low < high {
+		mid := (low + high + 1) / 2
+		if count(string(runes[:mid])+"…") <= max {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+
+	cut := low
+	for i := low; i > low/2; i-- {
+		if unicode.IsSpace(runes[i-1]) {
+			cut = i - 1
+			break
+		}
+	}
+	if cut == 0 {
+		return "", true
+	}
+	return string(runes[:cut]) + "…", true
+}
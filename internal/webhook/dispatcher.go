@@ -0,0 +1,272 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// pollInterval is how often a started dispatcher looks for due deliveries
+	pollInterval = 5 * time.Second
+
+	// maxBackoff caps the wait between attempts of a delivery
+	maxBackoff = time.Hour
+
+	// batchSize is how many deliveries are attempted in one pass
+	batchSize = 100
+
+	// maxErrorBody is how much of a failed response is kept in the delivery log
+	maxErrorBody = 512
+
+	// claimMargin is how much longer than a request's timeout a delivery stays
+	// claimed, before another pass may send it again
+	claimMargin = time.Minute
+)
+
+// Policy sets how deliveries are attempted
+type Policy struct {
+	MaxAttempts int           // Attempts before a delivery fails for good
+	Backoff     time.Duration // Wait before the second attempt, doubled after each one
+	Timeout     time.Duration // Time allowed for each request
+}
+
+// Dispatcher sends queued deliveries to their webhooks, signing each request
+// and retrying failures with exponential backoff
+type Dispatcher struct {
+	storage *Storage
+	policy  Policy
+	client  *http.Client
+	logger  Logger
+
+	// running serializes this dispatcher's passes; deliveries are claimed in
+	// the database, so other processes do not send them at the same time
+	running sync.Mutex
+}
+
+// NewDispatcher creates a dispatcher for the deliveries queued in storage
+func NewDispatcher(storage *Storage, policy Policy, logger Logger) *Dispatcher {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 5
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = 30 * time.Second
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = 10 * time.Second
+	}
+
+	return &Dispatcher{
+		storage: storage,
+		policy:  policy,
+		client:  &http.Client{Timeout: policy.Timeout},
+		logger:  logger,
+	}
+}
+
+// Start sends due deliveries immediately and then on every poll interval until
+// ctx is cancelled
+func (d *Dispatcher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := d.RunOnce(ctx); err != nil {
+				d.logger.Error("Webhook delivery failed", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce attempts every delivery that is due, returning how many were
+// attempted. A delivery that cannot be attempted does not hold up the others;
+// the errors of all of them are returned together.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	d.running.Lock()
+	defer d.running.Unlock()
+
+	deliveries, err := d.storage.DueDeliveries(time.Now(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	webhooks := make(map[string]*Webhook)
+	attempted := 0
+	var errs []error
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			break
+		}
+
+		w, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			if w, err = d.storage.deliveryWebhook(delivery.WebhookID); err != nil {
+				errs = append(errs, fmt.Errorf("delivery %s: %w", delivery.ID, err))
+				continue
+			}
+			webhooks[w.ID] = w
+		}
+
+		claimed, err := d.storage.claimDelivery(delivery.ID, time.Now(), d.policy.Timeout+claimMargin)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("delivery %s: %w", delivery.ID, err))
+			continue
+		}
+		if !claimed {
+			d.logger.Debug("Webhook delivery claimed by another dispatcher", "delivery_id", delivery.ID)
+			continue
+		}
+
+		if err := d.Attempt(ctx, w, delivery); err != nil {
+			errs = append(errs, fmt.Errorf("delivery %s: %w", delivery.ID, err))
+			continue
+		}
+		attempted++
+	}
+
+	return attempted, errors.Join(errs...)
+}
+
+// Attempt sends a delivery once and records the outcome. A failed attempt is
+// scheduled for a retry until the policy's attempts run out. RunOnce claims
+// each delivery before attempting it.
+func (d *Dispatcher) Attempt(ctx context.Context, w *Webhook, delivery *Delivery) error {
+	statusCode, duration, sendErr := d.send(ctx, w.URL, w.Secret, delivery.ID, delivery.EventType, []byte(delivery.Payload))
+	if sendErr != nil && ctx.Err() != nil {
+		// Interrupted rather than failed; the delivery is due again as it was
+		delivery.Status = StatusPending
+		return d.storage.SaveDelivery(delivery)
+	}
+
+	delivery.Attempts++
+	delivery.StatusCode = statusCode
+	delivery.Duration = duration
+	delivery.Error = ""
+	delivery.NextAttemptAt = nil
+
+	switch {
+	case sendErr == nil:
+		delivery.Status = StatusDelivered
+		d.logger.Debug("Webhook delivered", "webhook_id", w.ID, "delivery_id", delivery.ID, "event", delivery.EventType)
+	case delivery.Attempts >= d.policy.MaxAttempts:
+		delivery.Status = StatusFailed
+		delivery.Error = sendErr.Error()
+		d.logger.Warn("Webhook delivery failed for good", "webhook_id", w.ID, "delivery_id", delivery.ID,
+			"attempts", delivery.Attempts, "error", sendErr)
+	default:
+		next := time.Now().Add(d.backoff(delivery.Attempts))
+		delivery.Status = StatusPending
+		delivery.Error = sendErr.Error()
+		delivery.NextAttemptAt = &next
+		d.logger.Debug("Webhook delivery will be retried", "webhook_id", w.ID, "delivery_id", delivery.ID,
+			"attempts", delivery.Attempts, "next_attempt_at", next, "error", sendErr)
+	}
+
+	return d.storage.SaveDelivery(delivery)
+}
+
+// Test sends a webhook.test event to a webhook once, without retrying it. The
+// attempt is added to the delivery log if the webhook is saved, that is if it
+// has an ID.
+func (d *Dispatcher) Test(ctx context.Context, w *Webhook) (*Delivery, error) {
+	if err := ValidateURL(w.URL); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	payload := Payload{
+		ID:        fmt.Sprintf("event_%d", now.UnixNano()),
+		Type:      EventTest,
+		CreatedAt: now,
+		Data:      map[string]string{"message": "This is a test event"},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize webhook event: %w", err)
+	}
+
+	delivery := &Delivery{
+		ID:        fmt.Sprintf("delivery_%d", now.UnixNano()),
+		WebhookID: w.ID,
+		EventID:   payload.ID,
+		EventType: EventTest,
+		Payload:   string(body),
+		Status:    StatusDelivered,
+		Attempts:  1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	statusCode, duration, sendErr := d.send(ctx, w.URL, w.Secret, delivery.ID, EventTest, body)
+	delivery.StatusCode = statusCode
+	delivery.Duration = duration
+	if sendErr != nil {
+		delivery.Status = StatusFailed
+		delivery.Error = sendErr.Error()
+	}
+
+	if w.ID != "" {
+		if err := d.storage.SaveDelivery(delivery); err != nil {
+			return delivery, err
+		}
+	}
+	return delivery, nil
+}
+
+// backoff returns the wait after a delivery's nth failed attempt
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.policy.Backoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxBackoff {
+		wait = maxBackoff
+	}
+	return wait
+}
+
+// send posts a signed payload. Any response outside 2xx is an error.
+func (d *Dispatcher) send(ctx context.Context, endpoint, secret, deliveryID, eventType string, body []byte) (int, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "personal-ai-board-webhooks")
+	req.Header.Set(HeaderEvent, eventType)
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	start := time.Now()
+	resp, err := d.client.Do(req)
+	duration := time.Since(start)
+	if err != nil {
+		return 0, duration, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return resp.StatusCode, duration, fmt.Errorf("webhook responded %s: %s", resp.Status, bytes.TrimSpace(snippet))
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+
+	return resp.StatusCode, duration, nil
+}
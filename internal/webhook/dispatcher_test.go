@@ -0,0 +1,235 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"personal-ai-board/internal/db"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Warn(string, ...interface{})  {}
+
+// testStorage returns webhook storage in a migrated temporary database
+func testStorage(t *testing.T) *Storage {
+	t.Helper()
+	config := db.DefaultConfig()
+	config.Path = filepath.Join(t.TempDir(), "webhooks.db")
+	database, err := db.Connect(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return NewStorage(database.DB)
+}
+
+// addWebhook saves a webhook to an endpoint, subscribed to every event
+func addWebhook(t *testing.T, storage *Storage, id, endpoint string) *Webhook {
+	t.Helper()
+	w, err := New(id, endpoint, "whsec_test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.SaveWebhook(w); err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+// recorder is an endpoint that fails its first requests and then accepts
+// them, checking each signature
+type recorder struct {
+	t        *testing.T
+	failures int
+
+	mu       sync.Mutex
+	requests int
+}
+
+func (r *recorder) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	timestamp, _ := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	if !Verify("whsec_test", timestamp, body, req.Header.Get(HeaderSignature)) {
+		r.t.Errorf("request %s has an invalid signature", req.Header.Get(HeaderDelivery))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests++
+	if r.requests <= r.failures {
+		http.Error(rw, "try again later", http.StatusServiceUnavailable)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (r *recorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(nil, Policy{Backoff: 30 * time.Second}, nopLogger{})
+	for attempts, want := range map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		4:  4 * time.Minute,
+		7:  32 * time.Minute,
+		8:  maxBackoff,
+		50: maxBackoff,
+	} {
+		if got := d.backoff(attempts); got != want {
+			t.Errorf("backoff after %d attempts = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestRunOnceRetries(t *testing.T) {
+	storage := testStorage(t)
+	endpoint := &recorder{t: t, failures: 2}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+	addWebhook(t, storage, "hook_1", server.URL)
+
+	if queued, err := storage.Notify(EventPersonaCreated, map[string]string{"persona_id": "p1"}); err != nil || queued != 1 {
+		t.Fatalf("queued %d: %v", queued, err)
+	}
+
+	d := NewDispatcher(storage, Policy{MaxAttempts: 5, Backoff: time.Millisecond}, nopLogger{})
+	for pass := 1; pass <= 3; pass++ {
+		time.Sleep(5 * time.Millisecond)
+		attempted, err := d.RunOnce(context.Background())
+		if err != nil || attempted != 1 {
+			t.Fatalf("pass %d: attempted %d: %v", pass, attempted, err)
+		}
+	}
+	if attempted, err := d.RunOnce(context.Background()); err != nil || attempted != 0 {
+		t.Errorf("after delivery: attempted %d: %v", attempted, err)
+	}
+
+	deliveries, err := storage.ListDeliveries("hook_1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delivery := deliveries[0]
+	if endpoint.count() != 3 || delivery.Status != StatusDelivered || delivery.Attempts != 3 || delivery.StatusCode != http.StatusNoContent || delivery.Error != "" {
+		t.Errorf("%d requests, delivery %+v", endpoint.count(), delivery)
+	}
+}
+
+func TestRunOnceGivesUp(t *testing.T) {
+	storage := testStorage(t)
+	endpoint := &recorder{t: t, failures: 100}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+	addWebhook(t, storage, "hook_1", server.URL)
+	if _, err := storage.Notify(EventAnalysisFailed, map[string]string{"session_id": "s1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDispatcher(storage, Policy{MaxAttempts: 2, Backoff: time.Hour}, nopLogger{})
+	if _, err := d.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	deliveries, _ := storage.ListDeliveries("hook_1", 0)
+	if got := deliveries[0]; got.Status != StatusPending || got.Attempts != 1 || got.NextAttemptAt == nil || time.Until(*got.NextAttemptAt) < 59*time.Minute {
+		t.Fatalf("after a failure: %+v", got)
+	}
+
+	// Not due again until the backoff passes
+	if attempted, err := d.RunOnce(context.Background()); err != nil || attempted != 0 {
+		t.Errorf("attempted %d before the backoff passed: %v", attempted, err)
+	}
+
+	d.policy.Backoff = time.Millisecond
+	past := time.Now().Add(-time.Second)
+	deliveries[0].NextAttemptAt = &past
+	if err := storage.SaveDelivery(deliveries[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	deliveries, _ = storage.ListDeliveries("hook_1", 0)
+	if got := deliveries[0]; got.Status != StatusFailed || got.Attempts != 2 || got.StatusCode != http.StatusServiceUnavailable || got.Error == "" {
+		t.Errorf("after the last attempt: %+v", got)
+	}
+}
+
+func TestRunOnceContinuesAfterError(t *testing.T) {
+	storage := testStorage(t)
+	endpoint := &recorder{t: t}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+	addWebhook(t, storage, "hook_broken", server.URL)
+	if _, err := storage.Notify(EventPersonaCreated, map[string]string{"persona_id": "p1"}); err != nil {
+		t.Fatal(err)
+	}
+	addWebhook(t, storage, "hook_ok", server.URL)
+	if _, err := storage.Notify(EventPersonaCreated, map[string]string{"persona_id": "p2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The oldest delivery's webhook cannot be loaded
+	if _, err := storage.db.Exec("UPDATE webhooks SET events = 'not json' WHERE id = 'hook_broken'"); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDispatcher(storage, Policy{}, nopLogger{})
+	attempted, err := d.RunOnce(context.Background())
+	if err == nil || attempted != 1 {
+		t.Errorf("attempted %d: %v; want 1 and the broken webhook's error", attempted, err)
+	}
+	deliveries, _ := storage.ListDeliveries("hook_ok", 0)
+	if len(deliveries) != 1 || deliveries[0].Status != StatusDelivered {
+		t.Errorf("deliveries to the working webhook: %+v", deliveries)
+	}
+}
+
+func TestClaimDelivery(t *testing.T) {
+	storage := testStorage(t)
+	addWebhook(t, storage, "hook_1", "http://127.0.0.1:1/hook")
+	if _, err := storage.Notify(EventPersonaCreated, nil); err != nil {
+		t.Fatal(err)
+	}
+	due, err := storage.DueDeliveries(time.Now(), 10)
+	if err != nil || len(due) != 1 {
+		t.Fatalf("due %d: %v", len(due), err)
+	}
+
+	now := time.Now()
+	if claimed, err := storage.claimDelivery(due[0].ID, now, time.Minute); err != nil || !claimed {
+		t.Fatalf("first claim: %v, %v", claimed, err)
+	}
+	if claimed, err := storage.claimDelivery(due[0].ID, now, time.Minute); err != nil || claimed {
+		t.Errorf("claimed twice: %v, %v", claimed, err)
+	}
+	if due, _ := storage.DueDeliveries(now, 10); len(due) != 0 {
+		t.Errorf("claimed delivery still due")
+	}
+
+	// A claim that has expired, as its sender stopped, can be taken over
+	later := now.Add(2 * time.Minute)
+	if due, _ := storage.DueDeliveries(later, 10); len(due) != 1 || due[0].Status != StatusSending {
+		t.Errorf("expired claim not due: %+v", due)
+	}
+	if claimed, err := storage.claimDelivery(due[0].ID, later, time.Minute); err != nil || !claimed {
+		t.Errorf("expired claim not taken over: %v, %v", claimed, err)
+	}
+}
@@ -0,0 +1,34 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Headers sent with every webhook request
+const (
+	HeaderEvent     = "X-PAB-Event"
+	HeaderDelivery  = "X-PAB-Delivery"
+	HeaderTimestamp = "X-PAB-Timestamp"
+	HeaderSignature = "X-PAB-Signature"
+)
+
+// Sign returns the signature of a payload sent at a Unix timestamp: the
+// hex-encoded HMAC-SHA256 of "timestamp.body" keyed with the webhook's
+// secret, prefixed with "sha256=". Covering the timestamp lets receivers
+// reject replayed requests.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether a signature matches a payload, comparing in constant
+// time
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
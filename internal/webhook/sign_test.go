@@ -0,0 +1,26 @@
+package webhook
+
+import "testing"
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":"event_1"}`)
+	// Computed independently: HMAC-SHA256 of "1700000000.<body>"
+	want := "sha256=bd16c109caa92cfe8d5daacd311cb08f74c555053ad58c9a7e99898007f9b17c"
+	if got := Sign("whsec_test", 1700000000, body); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+
+	if !Verify("whsec_test", 1700000000, body, want) {
+		t.Error("signature not verified")
+	}
+	for name, ok := range map[string]bool{
+		"other secret":    Verify("whsec_other", 1700000000, body, want),
+		"other timestamp": Verify("whsec_test", 1700000001, body, want),
+		"other body":      Verify("whsec_test", 1700000000, []byte(`{"id":"event_2"}`), want),
+		"no prefix":       Verify("whsec_test", 1700000000, body, want[len("sha256="):]),
+	} {
+		if ok {
+			t.Errorf("%s verified", name)
+		}
+	}
+}
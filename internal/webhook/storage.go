@@ -0,0 +1,319 @@
+package webhook
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"personal-ai-board/internal/auth"
+)
+
+// Storage handles database operations for the webhooks of one workspace and
+// their deliveries. The deliveries of every workspace form one queue, which
+// DueDeliveries reads.
+type Storage struct {
+	db        *sql.DB
+	workspace string
+}
+
+// NewStorage creates a storage instance for the default workspace
+func NewStorage(db *sql.DB) *Storage {
+	return NewWorkspaceStorage(db, auth.DefaultWorkspaceID)
+}
+
+// NewWorkspaceStorage creates a storage instance for the webhooks of a
+// workspace
+func NewWorkspaceStorage(db *sql.DB, workspaceID string) *Storage {
+	return &Storage{db: db, workspace: workspaceID}
+}
+
+// SaveWebhook saves a webhook to the database
+func (s *Storage) SaveWebhook(w *Webhook) error {
+	events, err := json.Marshal(w.Events)
+	if err != nil {
+		return fmt.Errorf("failed to serialize webhook events: %w", err)
+	}
+
+	// A webhook of another workspace is left alone
+	result, err := s.db.Exec(`
+		INSERT INTO webhooks (id, workspace_id, url, secret, events, description, active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			url = excluded.url,
+			secret = excluded.secret,
+			events = excluded.events,
+			description = excluded.description,
+			active = excluded.active,
+			updated_at = excluded.updated_at
+		WHERE webhooks.workspace_id = excluded.workspace_id
+	`, w.ID, s.workspace, w.URL, w.Secret, string(events), w.Description, w.Active, w.CreatedAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save webhook: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("failed to save webhook %s: %w", w.ID, sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// LoadWebhook loads a webhook from the database
+func (s *Storage) LoadWebhook(id string) (*Webhook, error) {
+	row := s.db.QueryRow(`
+		SELECT id, url, secret, events, description, active, created_at, updated_at
+		FROM webhooks WHERE id = ? AND workspace_id = ?
+	`, id, s.workspace)
+
+	w, err := scanWebhook(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook: %w", err)
+	}
+	return w, nil
+}
+
+// deliveryWebhook loads the webhook of a queued delivery, whatever its
+// workspace
+func (s *Storage) deliveryWebhook(id string) (*Webhook, error) {
+	row := s.db.QueryRow(`
+		SELECT id, url, secret, events, description, active, created_at, updated_at
+		FROM webhooks WHERE id = ?
+	`, id)
+
+	w, err := scanWebhook(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook: %w", err)
+	}
+	return w, nil
+}
+
+// ListWebhooks returns every webhook, oldest first
+func (s *Storage) ListWebhooks() ([]*Webhook, error) {
+	rows, err := s.db.Query(`
+		SELECT id, url, secret, events, description, active, created_at, updated_at
+		FROM webhooks WHERE workspace_id = ? ORDER BY created_at
+	`, s.workspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []*Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, w)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// DeleteWebhook removes a webhook and its delivery log
+func (s *Storage) DeleteWebhook(id string) error {
+	result, err := s.db.Exec("DELETE FROM webhooks WHERE id = ? AND workspace_id = ?", id, s.workspace)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook not found: %s", id)
+	}
+
+	return nil
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanWebhook reads a webhook from a row
+func scanWebhook(row scanner) (*Webhook, error) {
+	var w Webhook
+	var events string
+	var description sql.NullString
+
+	err := row.Scan(&w.ID, &w.URL, &w.Secret, &events, &description, &w.Active, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	w.Description = description.String
+	if err := json.Unmarshal([]byte(events), &w.Events); err != nil {
+		return nil, fmt.Errorf("failed to deserialize webhook events: %w", err)
+	}
+	return &w, nil
+}
+
+// Notify queues an event for every active webhook that subscribes to it,
+// returning how many deliveries were queued. The deliveries are sent by a
+// Dispatcher.
+func (s *Storage) Notify(eventType string, data interface{}) (int, error) {
+	webhooks, err := s.ListWebhooks()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	payload := Payload{
+		ID:        fmt.Sprintf("event_%d", now.UnixNano()),
+		Type:      eventType,
+		CreatedAt: now,
+		Data:      data,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to serialize webhook event: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	queued := 0
+	for _, w := range webhooks {
+		if !w.Active || !w.Subscribes(eventType) {
+			continue
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO webhook_deliveries (
+				id, webhook_id, event_id, event_type, payload, status, attempts,
+				next_attempt_at, created_at, updated_at
+			) VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?, ?)
+		`, fmt.Sprintf("delivery_%d_%d", now.UnixNano(), queued), w.ID, payload.ID, eventType, string(body),
+			StatusPending, now.UTC(), now, now)
+		if err != nil {
+			return 0, fmt.Errorf("failed to queue webhook delivery: %w", err)
+		}
+		queued++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit webhook deliveries: %w", err)
+	}
+
+	return queued, nil
+}
+
+// SaveDelivery records a delivery and the outcome of its latest attempt
+func (s *Storage) SaveDelivery(d *Delivery) error {
+	// Attempt times are compared as text, so they are all stored in UTC
+	var nextAttemptAt *time.Time
+	if d.NextAttemptAt != nil {
+		utc := d.NextAttemptAt.UTC()
+		nextAttemptAt = &utc
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO webhook_deliveries (
+			id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+			status_code, error, duration_ms, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			status = excluded.status,
+			attempts = excluded.attempts,
+			next_attempt_at = excluded.next_attempt_at,
+			status_code = excluded.status_code,
+			error = excluded.error,
+			duration_ms = excluded.duration_ms,
+			updated_at = excluded.updated_at
+	`, d.ID, d.WebhookID, d.EventID, d.EventType, d.Payload, d.Status, d.Attempts, nextAttemptAt,
+		d.StatusCode, d.Error, d.Duration.Milliseconds(), d.CreatedAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// DueDeliveries returns up to limit deliveries to active webhooks whose next
+// attempt is due, oldest first. A delivery whose claim has expired, because
+// its dispatcher stopped before recording the outcome, is due again.
+func (s *Storage) DueDeliveries(now time.Time, limit int) ([]*Delivery, error) {
+	return s.queryDeliveries(`
+		SELECT d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+		       d.next_attempt_at, d.status_code, d.error, d.duration_ms, d.created_at, d.updated_at
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.status IN (?, ?) AND w.active = 1 AND (d.next_attempt_at IS NULL OR d.next_attempt_at <= ?)
+		ORDER BY d.created_at
+		LIMIT ?
+	`, StatusPending, StatusSending, now.UTC(), limit)
+}
+
+// claimDelivery marks a due delivery as being sent until the lease passes,
+// reporting whether the caller claimed it. It is not claimed if another
+// dispatcher has claimed it since it was read.
+func (s *Storage) claimDelivery(id string, now time.Time, lease time.Duration) (bool, error) {
+	result, err := s.db.Exec(`
+		UPDATE webhook_deliveries SET status = ?, next_attempt_at = ?, updated_at = ?
+		WHERE id = ? AND status IN (?, ?) AND (next_attempt_at IS NULL OR next_attempt_at <= ?)
+	`, StatusSending, now.Add(lease).UTC(), now, id, StatusPending, StatusSending, now.UTC())
+	if err != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// ListDeliveries returns the delivery log, most recent first. An empty
+// webhookID lists the deliveries to every webhook of the workspace; a limit
+// of 0 lists them all.
+func (s *Storage) ListDeliveries(webhookID string, limit int) ([]*Delivery, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	return s.queryDeliveries(`
+		SELECT id, webhook_id, event_id, event_type, payload, status, attempts,
+		       next_attempt_at, status_code, error, duration_ms, created_at, updated_at
+		FROM webhook_deliveries
+		WHERE (? = '' OR webhook_id = ?) AND webhook_id IN (SELECT id FROM webhooks WHERE workspace_id = ?)
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, webhookID, webhookID, s.workspace, limit)
+}
+
+// queryDeliveries runs a query that selects deliveries
+func (s *Storage) queryDeliveries(query string, args ...interface{}) ([]*Delivery, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*Delivery
+	for rows.Next() {
+		var d Delivery
+		var nextAttemptAt sql.NullTime
+		var errorText sql.NullString
+		var durationMs int64
+
+		err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&nextAttemptAt, &d.StatusCode, &errorText, &durationMs, &d.CreatedAt, &d.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+
+		if nextAttemptAt.Valid {
+			d.NextAttemptAt = &nextAttemptAt.Time
+		}
+		d.Error = errorText.String
+		d.Duration = time.Duration(durationMs) * time.Millisecond
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,165 @@
+package webhook
+
+import (
+	"database/sql"
+	"time"
+
+	"personal-ai-board/internal/analysis"
+)
+
+// watchBuffer is how many analysis events may queue for a watcher
+const watchBuffer = 256
+
+// AnalysisData is the data of the analysis.completed and analysis.failed
+// events
+type AnalysisData struct {
+	ResultID  string           `json:"result_id"`
+	RequestID string           `json:"request_id"`
+	ProjectID string           `json:"project_id,omitempty"`
+	BoardID   string           `json:"board_id,omitempty"`
+	Mode      string           `json:"mode,omitempty"`
+	Topic     string           `json:"topic,omitempty"`
+	Status    string           `json:"status"`
+	Summary   string           `json:"summary,omitempty"`
+	Insights  []string         `json:"insights,omitempty"`
+	Metrics   analysis.Metrics `json:"metrics"`
+	Error     string           `json:"error,omitempty"`
+	Duration  time.Duration    `json:"duration"`
+}
+
+// BudgetData is the data of the budget.threshold event
+type BudgetData struct {
+	ResultID    string  `json:"result_id"`
+	RequestID   string  `json:"request_id"`
+	BudgetUSD   float64 `json:"budget_usd"`
+	CostUSD     float64 `json:"cost_usd"`
+	TotalTokens int     `json:"total_tokens"`
+}
+
+// Watcher queues webhook events for the analyses an engine runs: when one
+// completes or fails, and when its cost reaches the budget. Each event goes
+// to the webhooks of the analysis's workspace.
+type Watcher struct {
+	db        *sql.DB
+	budgetUSD float64
+	logger    Logger
+
+	// overBudget holds the running analyses that have reached the budget
+	overBudget map[string]bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Watch starts queueing webhook events for an engine's analyses, which are
+// saved in db. budgetUSD is the cost of an analysis that triggers
+// budget.threshold, or 0 for none.
+func Watch(engine *analysis.Engine, db *sql.DB, budgetUSD float64, logger Logger) *Watcher {
+	w := &Watcher{
+		db:         db,
+		budgetUSD:  budgetUSD,
+		logger:     logger,
+		overBudget: make(map[string]bool),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+
+	events, unsubscribe := engine.Subscribe(watchBuffer)
+	go func() {
+		defer close(w.done)
+		defer unsubscribe()
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					w.logger.Warn("Stopped queueing webhook events after falling behind the analyses")
+					return
+				}
+				w.handle(event)
+			case <-w.stop:
+				// Queue what the engine has already published
+				for {
+					select {
+					case event, ok := <-events:
+						if !ok {
+							return
+						}
+						w.handle(event)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return w
+}
+
+// Stop queues the events already published and stops watching
+func (w *Watcher) Stop() {
+	close(w.stop)
+	<-w.done
+}
+
+// handle queues the webhook events an analysis event calls for
+func (w *Watcher) handle(event analysis.Event) {
+	if w.budgetUSD > 0 && event.Metrics.CostUSD >= w.budgetUSD && !w.overBudget[event.ResultID] {
+		w.overBudget[event.ResultID] = true
+		w.notify(event.WorkspaceID, EventBudgetThreshold, BudgetData{
+			ResultID:    event.ResultID,
+			RequestID:   event.RequestID,
+			BudgetUSD:   w.budgetUSD,
+			CostUSD:     event.Metrics.CostUSD,
+			TotalTokens: event.Metrics.TotalTokens,
+		})
+	}
+
+	if !event.Final() {
+		return
+	}
+	delete(w.overBudget, event.ResultID)
+
+	var eventType string
+	switch event.Type {
+	case analysis.EventCompleted:
+		eventType = EventAnalysisCompleted
+	case analysis.EventFailed:
+		eventType = EventAnalysisFailed
+	default:
+		return
+	}
+
+	data := AnalysisData{
+		ResultID:  event.ResultID,
+		RequestID: event.RequestID,
+		Status:    string(event.Type),
+		Metrics:   event.Metrics,
+		Error:     event.Error,
+	}
+	if result, err := analysis.NewWorkspaceStorage(w.db, event.WorkspaceID).LoadResult(event.ResultID); err != nil {
+		w.logger.Warn("Webhook event sent without analysis details", "result_id", event.ResultID, "error", err)
+	} else {
+		data.ProjectID = result.ProjectID
+		data.BoardID = result.BoardID
+		data.Mode = result.Mode
+		data.Topic = result.Topic
+		data.Status = result.Status
+		data.Summary = result.Summary
+		data.Insights = result.Insights
+		data.Duration = result.Duration
+	}
+	w.notify(event.WorkspaceID, eventType, data)
+}
+
+// notify queues an event for the webhooks of a workspace, logging failures
+func (w *Watcher) notify(workspaceID, eventType string, data interface{}) {
+	queued, err := NewWorkspaceStorage(w.db, workspaceID).Notify(eventType, data)
+	if err != nil {
+		w.logger.Error("Failed to queue webhook event", "event", eventType, "error", err)
+		return
+	}
+	if queued > 0 {
+		w.logger.Debug("Queued webhook event", "event", eventType, "deliveries", queued)
+	}
+}
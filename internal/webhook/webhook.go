@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"personal-ai-board/internal/persona"
+)
+
+// Events a webhook can subscribe to
+const (
+	EventAnalysisCompleted = "analysis.completed"
+	EventAnalysisFailed    = "analysis.failed"
+	EventBudgetThreshold   = "budget.threshold"
+	EventPersonaCreated    = "persona.created"
+)
+
+// EventTest is sent by the test command, whether or not a webhook subscribes
+// to it
+const EventTest = "webhook.test"
+
+// Events lists the events a webhook can subscribe to
+var Events = []string{EventAnalysisCompleted, EventAnalysisFailed, EventBudgetThreshold, EventPersonaCreated}
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"
+	StatusSending   = "sending" // Claimed by a dispatcher that is sending it
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// Logger interface for structured logging
+type Logger interface {
+	Info(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+	Debug(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+}
+
+// Webhook is an endpoint that is sent the events it subscribes to
+type Webhook struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"-"` // Signs the payloads
+	Events      []string  `json:"events"`
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// New creates an active webhook. Without events it subscribes to all of them,
+// and without a secret it is given a random one.
+func New(id, endpoint, secret string, events []string) (*Webhook, error) {
+	if err := ValidateURL(endpoint); err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		events = Events
+	}
+	for _, event := range events {
+		if !ValidEvent(event) {
+			return nil, fmt.Errorf("unknown webhook event: %s (must be one of: %s)", event, strings.Join(Events, ", "))
+		}
+	}
+	if secret == "" {
+		var err error
+		if secret, err = NewSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	return &Webhook{
+		ID:        id,
+		URL:       endpoint,
+		Secret:    secret,
+		Events:    append([]string(nil), events...),
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// Subscribes reports whether the webhook is sent an event
+func (w *Webhook) Subscribes(eventType string) bool {
+	for _, event := range w.Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidEvent reports whether a webhook can subscribe to an event
+func ValidEvent(eventType string) bool {
+	for _, event := range Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateURL checks that an endpoint is an absolute http or https URL
+func ValidateURL(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook URL: %q (must be an http or https URL)", endpoint)
+	}
+	return nil
+}
+
+// NewSecret returns a random signing secret
+func NewSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// Payload is the body of every webhook request
+type Payload struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// Delivery is an event sent, or still to be sent, to a webhook
+type Delivery struct {
+	ID            string        `json:"id"`
+	WebhookID     string        `json:"webhook_id"`
+	EventID       string        `json:"event_id"`
+	EventType     string        `json:"event_type"`
+	Payload       string        `json:"payload"`
+	Status        string        `json:"status"`
+	Attempts      int           `json:"attempts"`
+	NextAttemptAt *time.Time    `json:"next_attempt_at,omitempty"`
+	StatusCode    int           `json:"status_code,omitempty"`
+	Error         string        `json:"error,omitempty"`
+	Duration      time.Duration `json:"duration"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+// PersonaData is the data of a persona.created event
+type PersonaData struct {
+	PersonaID   string `json:"persona_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// NewPersonaData describes a created persona
+func NewPersonaData(p *persona.Persona) PersonaData {
+	return PersonaData{
+		PersonaID:   p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+	}
+}
@@ -2,11 +2,49 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"reflect"
 	"strings"
+	"sync"
 )
 
+// redacted replaces secrets in log records
+const redacted = "[REDACTED]"
+
+// minRedactedLength is the length of the shortest value Redact hides, so that
+// short values cannot blank out ordinary words
+const minRedactedLength = 8
+
+// sensitiveKeys are the attribute keys whose values are never logged. Keys
+// ending in _ and one of them are hidden too, as are the keys of nested maps
+// and dotted configuration keys such as llm.openai.api_key.
+var sensitiveKeys = []string{"api_key", "apikey", "token", "secret", "password", "passphrase", "authorization"}
+
+// secretValues are the values registered with Redact
+var secretValues struct {
+	sync.RWMutex
+	values []string
+}
+
+// Redact hides value wherever it appears in the records every logger writes
+// from now on, such as an API key once it has been resolved
+func Redact(value string) {
+	if len(value) < minRedactedLength {
+		return
+	}
+
+	secretValues.Lock()
+	defer secretValues.Unlock()
+	for _, existing := range secretValues.values {
+		if existing == value {
+			return
+		}
+	}
+	secretValues.values = append(secretValues.values, value)
+}
+
 // Logger interface for structured logging
 type Logger interface {
 	Info(msg string, args ...interface{})
@@ -22,79 +60,67 @@ type SlogLogger struct {
 
 // New creates a new logger with the specified level
 func New(level string) Logger {
-	var logLevel slog.Level
+	return NewWithWriter(level, "text", os.Stdout)
+}
 
-	switch strings.ToLower(level) {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "info":
-		logLevel = slog.LevelInfo
-	case "warn", "warning":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	default:
-		logLevel = slog.LevelInfo
-	}
+// NewJSON creates a new JSON logger
+func NewJSON(level string) Logger {
+	return NewWithWriter(level, "json", os.Stdout)
+}
 
+// NewWithWriter creates a logger that writes text or JSON records to w
+func NewWithWriter(level, format string, w io.Writer) Logger {
 	opts := &slog.HandlerOptions{
-		Level: logLevel,
+		Level: parseLevel(level),
 	}
 
-	handler := slog.NewTextHandler(os.Stdout, opts)
-	logger := slog.New(handler)
+	var handler slog.Handler
+	if format == "json" {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
 
-	return &SlogLogger{logger: logger}
+	return &SlogLogger{logger: slog.New(handler)}
 }
 
-// NewJSON creates a new JSON logger
-func NewJSON(level string) Logger {
-	var logLevel slog.Level
-
+// parseLevel converts a level name to a slog level, defaulting to info
+func parseLevel(level string) slog.Level {
 	switch strings.ToLower(level) {
 	case "debug":
-		logLevel = slog.LevelDebug
+		return slog.LevelDebug
 	case "info":
-		logLevel = slog.LevelInfo
+		return slog.LevelInfo
 	case "warn", "warning":
-		logLevel = slog.LevelWarn
+		return slog.LevelWarn
 	case "error":
-		logLevel = slog.LevelError
+		return slog.LevelError
 	default:
-		logLevel = slog.LevelInfo
-	}
-
-	opts := &slog.HandlerOptions{
-		Level: logLevel,
+		return slog.LevelInfo
 	}
-
-	handler := slog.NewJSONHandler(os.Stdout, opts)
-	logger := slog.New(handler)
-
-	return &SlogLogger{logger: logger}
 }
 
 // Info logs an info message
 func (l *SlogLogger) Info(msg string, args ...interface{}) {
-	l.logger.Info(msg, convertArgs(args...)...)
+	l.logger.Info(redactString(msg), convertArgs(args...)...)
 }
 
 // Error logs an error message
 func (l *SlogLogger) Error(msg string, args ...interface{}) {
-	l.logger.Error(msg, convertArgs(args...)...)
+	l.logger.Error(redactString(msg), convertArgs(args...)...)
 }
 
 // Debug logs a debug message
 func (l *SlogLogger) Debug(msg string, args ...interface{}) {
-	l.logger.Debug(msg, convertArgs(args...)...)
+	l.logger.Debug(redactString(msg), convertArgs(args...)...)
 }
 
 // Warn logs a warning message
 func (l *SlogLogger) Warn(msg string, args ...interface{}) {
-	l.logger.Warn(msg, convertArgs(args...)...)
+	l.logger.Warn(redactString(msg), convertArgs(args...)...)
 }
 
-// convertArgs converts variadic args to slog.Attr format
+// convertArgs converts variadic args to slog.Attr format, redacting secrets
 func convertArgs(args ...interface{}) []any {
 	if len(args)%2 != 0 {
 		// If odd number of args, treat the last one as a value with "extra" key
@@ -106,12 +132,93 @@ func convertArgs(args ...interface{}) []any {
 	for i := 0; i < len(args); i += 2 {
 		key := fmt.Sprintf("%v", args[i])
 		value := args[i+1]
+		if sensitiveKey(key) {
+			value = redacted
+		} else {
+			value = redactValue(value)
+		}
 		converted = append(converted, slog.Any(key, value))
 	}
 
 	return converted
 }
 
+// sensitiveKey reports whether the values logged under key are secret
+func sensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		key = key[i+1:]
+	}
+	for _, sensitive := range sensitiveKeys {
+		if key == sensitive || strings.HasSuffix(key, "_"+sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue hides the registered secrets in a string, error or Stringer, and
+// in the maps and slices holding them, where the values of sensitive keys are
+// hidden too. Other values are returned as they are.
+func redactValue(value interface{}) interface{} {
+	var text string
+	switch v := value.(type) {
+	case string:
+		return redactString(v)
+	case []byte:
+		return value
+	case error:
+		text = v.Error()
+	case fmt.Stringer:
+		text = v.String()
+	default:
+		return redactCollection(value)
+	}
+
+	if hidden := redactString(text); hidden != text {
+		return hidden
+	}
+	return value
+}
+
+// redactCollection redacts the elements of a map with string keys or of a
+// slice, copying it. Other values are returned as they are.
+func redactCollection(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	switch {
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		redactedMap := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if sensitiveKey(key) {
+				redactedMap[key] = redacted
+			} else {
+				redactedMap[key] = redactValue(iter.Value().Interface())
+			}
+		}
+		return redactedMap
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		redactedSlice := make([]interface{}, v.Len())
+		for i := range redactedSlice {
+			redactedSlice[i] = redactValue(v.Index(i).Interface())
+		}
+		return redactedSlice
+	default:
+		return value
+	}
+}
+
+// redactString replaces every registered secret in text
+func redactString(text string) string {
+	secretValues.RLock()
+	defer secretValues.RUnlock()
+	for _, secret := range secretValues.values {
+		text = strings.ReplaceAll(text, secret, redacted)
+	}
+	return text
+}
+
 // NoOpLogger is a logger that does nothing (for testing)
 type NoOpLogger struct{}
 
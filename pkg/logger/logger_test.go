@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSensitiveKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"api_key":            true,
+		"openai_api_key":     true,
+		"Authorization":      true,
+		"llm.openai.api_key": true,
+		"PASSWORD":           true,
+		"keystore_token":     true,
+		"tokens_used":        false,
+		"secrets.command":    false,
+		"model":              false,
+	} {
+		if got := sensitiveKey(key); got != want {
+			t.Errorf("sensitiveKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	const key = "sk-test-0123456789abcdef"
+	Redact(key)
+	Redact("short")
+
+	var out bytes.Buffer
+	log := NewWithWriter("debug", "json", &out)
+	log.Info("Calling the API with "+key,
+		"api_key", "plain-value-of-a-key",
+		"provider", map[string]interface{}{
+			"name":     "openai",
+			"settings": map[string]string{"password": "hunter2-hunter2", "base_url": "https://api.example.com"},
+			"headers":  []interface{}{map[string]string{"authorization": "Bearer abc"}},
+		},
+		"error", errors.New("request with "+key+" failed"),
+		"note", "a short word",
+		"tokens_used", 42,
+	)
+
+	record := out.String()
+	for _, secret := range []string{key, "plain-value-of-a-key", "hunter2-hunter2", "Bearer abc"} {
+		if strings.Contains(record, secret) {
+			t.Errorf("logged %q:\n%s", secret, record)
+		}
+	}
+	for _, kept := range []string{"openai", "https://api.example.com", "a short word", `"tokens_used":42`} {
+		if !strings.Contains(record, kept) {
+			t.Errorf("hid %q:\n%s", kept, record)
+		}
+	}
+	if strings.Count(record, redacted) != 5 {
+		t.Errorf("%d values redacted, want 5:\n%s", strings.Count(record, redacted), record)
+	}
+}